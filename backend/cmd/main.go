@@ -1,11 +1,18 @@
 package main
 
 import (
+	"context"
 	"log"
 
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 	"main.go/config"
+	"main.go/internal/auth"
+	"main.go/internal/cache"
 	"main.go/internal/handlers"
+	"main.go/internal/jobs"
+	"main.go/internal/middleware"
+	"main.go/internal/payroll"
 	"main.go/internal/repository"
 	"main.go/internal/routes"
 	"main.go/internal/service"
@@ -22,16 +29,186 @@ func server() {
 	if err != nil {
 		log.Fatalf("Error loading .env file: %v", err)
 	}
+	auth.SetKeySet(config.NewJWTKeySet())
+	auth.SetTokenTTLs(config.TokenTTLs())
+
 	g := gin.Default()
+	g.ContextWithFallback = true
 	g.Use(gin.Recovery())
+	g.Use(middleware.RequestID())
+	g.Use(middleware.ErrorHandler())
 
-	usersGroup := g.Group("/users")
 	gorm := config.NewGormPostgres()
+	redisClient := config.NewRedis()
+	revocationStore := newRevocationStore(gorm, redisClient)
+
+	appCache := newCache(redisClient)
+
+	departmentRepo := repository.NewDepartmentQuery(gorm)
 	userRepo := repository.NewUserQuery(gorm)
-	userSvc := service.NewUserService(userRepo)
+	if appCache != nil {
+		departmentRepo = repository.NewCachedDepartmentQuery(departmentRepo, appCache)
+		userRepo = repository.NewCachedUserQuery(userRepo, departmentRepo, appCache, config.CacheTTL())
+	}
+
+	auditGroup := g.Group("/audit")
+	auditRepo := repository.NewAuditQuery(gorm)
+	auditSvc := service.NewAuditService(auditRepo)
+	auditHdl := handlers.NewAuditHandler(auditSvc)
+	auditRouter := routes.NewAuditRouter(auditGroup, auditHdl, userRepo, revocationStore)
+	auditRouter.Mount()
+
+	usersGroup := g.Group("/users")
+	refreshTokenRepo := repository.NewRefreshTokenQuery(gorm)
+	userSvc := service.NewUserService(userRepo, refreshTokenRepo, revocationStore, departmentRepo, config.DeletedUserRetention(), auditSvc)
 	userHdl := handlers.NewUserHandler(userSvc)
-	userRouter := routes.NewUserRouter(usersGroup, userHdl, gorm)
+	userRouter := routes.NewUserRouter(usersGroup, userHdl, userRepo, revocationStore, userSvc)
 	userRouter.Mount()
 
+	rolesGroup := g.Group("/roles")
+	roleRepo := repository.NewRoleQuery(gorm)
+	if appCache != nil {
+		roleRepo = repository.NewCachedRoleQuery(roleRepo, appCache)
+	}
+	roleSvc := service.NewRoleService(roleRepo, auditSvc)
+	roleHdl := handlers.NewRoleHandler(roleSvc)
+	roleRouter := routes.NewRoleRouter(rolesGroup, roleHdl, userRepo, revocationStore)
+	roleRouter.Mount()
+
+	positionsGroup := g.Group("/positions")
+	positionRepo := repository.NewPositionQuery(gorm)
+	if appCache != nil {
+		positionRepo = repository.NewCachedPositionQuery(positionRepo, appCache)
+	}
+	positionSvc := service.NewPositionService(positionRepo, auditSvc)
+	positionHdl := handlers.NewPositionHandler(positionSvc)
+	positionRouter := routes.NewPositionRouter(positionsGroup, positionHdl, userRepo, revocationStore)
+	positionRouter.Mount()
+
+	calendarsGroup := g.Group("/calendars")
+	calendarRepo := repository.NewCalendarQuery(gorm)
+	calendarSvc := service.NewCalendarService(calendarRepo, auditSvc)
+	calendarHdl := handlers.NewCalendarHandler(calendarSvc)
+	calendarRouter := routes.NewCalendarRouter(calendarsGroup, calendarHdl, userRepo, revocationStore, userSvc)
+	calendarRouter.Mount()
+
+	departmentsGroup := g.Group("/departments")
+	departmentSvc := service.NewDepartmentService(departmentRepo, calendarRepo, auditSvc)
+	departmentHdl := handlers.NewDepartmentHandler(departmentSvc)
+	departmentRouter := routes.NewDepartmentRouter(departmentsGroup, departmentHdl, userRepo, revocationStore)
+	departmentRouter.Mount()
+
+	workSchedule := config.NewWorkSchedule()
+	leaveRepo := repository.NewLeaveQuery(gorm)
+
+	shiftsGroup := g.Group("/shifts")
+	shiftRepo := repository.NewShiftQuery(gorm)
+	shiftSvc := service.NewShiftService(shiftRepo, leaveRepo, userRepo, departmentRepo, workSchedule.Location, auditSvc)
+	shiftHdl := handlers.NewShiftHandler(shiftSvc)
+	shiftRouter := routes.NewShiftRouter(shiftsGroup, shiftHdl, userRepo, revocationStore, userSvc)
+	shiftRouter.Mount()
+
+	attendanceRepo := repository.NewAttendanceQuery(gorm)
+	payPeriodRepo := repository.NewPayPeriodQuery(gorm)
+	payPeriodSvc := service.NewPayPeriodService(payPeriodRepo, auditSvc)
+
+	overtimeGroup := g.Group("/overtime")
+	overtimeRepo := repository.NewOvertimeQuery(gorm)
+	overtimeSvc := service.NewOvertimeService(overtimeRepo, attendanceRepo, userRepo, calendarSvc, payPeriodSvc, workSchedule.Location, auditSvc)
+	overtimeHdl := handlers.NewOvertimeHandler(overtimeSvc)
+	overtimeRouter := routes.NewOvertimeRouter(overtimeGroup, overtimeHdl, userRepo, revocationStore)
+	overtimeRouter.Mount()
+
+	timesheetsGroup := g.Group("/timesheets")
+	timesheetRepo := repository.NewTimesheetQuery(gorm)
+	timesheetSvc := service.NewTimesheetService(timesheetRepo, attendanceRepo, userRepo, payPeriodSvc, config.TimesheetPeriodWeeks(), workSchedule.Location, auditSvc)
+	timesheetHdl := handlers.NewTimesheetHandler(timesheetSvc)
+	payPeriodHdl := handlers.NewPayPeriodHandler(payPeriodSvc)
+	timesheetRouter := routes.NewTimesheetRouter(timesheetsGroup, timesheetHdl, payPeriodHdl, userRepo, revocationStore)
+	timesheetRouter.Mount()
+
+	attendanceGroup := g.Group("/attendance")
+	attendanceSvc := service.NewAttendanceService(attendanceRepo, calendarSvc, shiftSvc, overtimeSvc, payPeriodSvc, workSchedule, auditSvc)
+	attendanceHdl := handlers.NewAttendanceHandler(attendanceSvc)
+	attendanceRouter := routes.NewAttendanceRouter(attendanceGroup, attendanceHdl, userRepo, revocationStore, userSvc)
+	attendanceRouter.Mount()
+
+	monitoringGroup := g.Group("/monitoring")
+	heartbeatRepo := repository.NewHeartbeatQuery(gorm)
+	monitoringSvc := service.NewMonitoringService(heartbeatRepo, userRepo, config.HeartbeatTimeout(), workSchedule.Location)
+	monitoringHdl := handlers.NewMonitoringHandler(monitoringSvc)
+	monitoringRouter := routes.NewMonitoringRouter(monitoringGroup, monitoringHdl, userRepo, revocationStore, userSvc)
+	monitoringRouter.Mount()
+
+	leaveGroup := g.Group("/leave")
+	leaveSvc := service.NewLeaveService(leaveRepo, userRepo, calendarSvc, payPeriodSvc, workSchedule.Location, auditSvc)
+	leaveHdl := handlers.NewLeaveHandler(leaveSvc)
+	leavePolicyRepo := repository.NewLeavePolicyQuery(gorm)
+	leaveAccrualSvc := service.NewLeaveAccrualService(leavePolicyRepo, leaveRepo, positionRepo, workSchedule.Location, auditSvc)
+	leaveAccrualHdl := handlers.NewLeaveAccrualHandler(leaveAccrualSvc)
+	leaveRouter := routes.NewLeaveRouter(leaveGroup, leaveHdl, leaveAccrualHdl, userRepo, revocationStore, userSvc)
+	leaveRouter.Mount()
+
+	compensationGroup := g.Group("/compensation")
+	compensationRepo := repository.NewCompensationQuery(gorm)
+	compensationSvc := service.NewCompensationService(compensationRepo, userRepo, workSchedule.Location, auditSvc)
+	compensationHdl := handlers.NewCompensationHandler(compensationSvc)
+	compensationRouter := routes.NewCompensationRouter(compensationGroup, compensationHdl, userRepo, revocationStore)
+	compensationRouter.Mount()
+
+	payrollGroup := g.Group("/payroll")
+	payrollRepo := repository.NewPayrollQuery(gorm)
+	deductionRuleRepo := repository.NewDeductionRuleQuery(gorm)
+	payrollEngine := payroll.NewEngine(payroll.DefaultRules()...)
+	payrollSvc := service.NewPayrollService(payrollRepo, payPeriodRepo, compensationRepo, attendanceRepo, overtimeRepo, leaveRepo, userRepo, deductionRuleRepo, calendarSvc, payrollEngine, config.PayrollHoursPerWeek(), auditSvc)
+	payrollHdl := handlers.NewPayrollHandler(payrollSvc)
+	deductionRuleSvc := service.NewDeductionRuleService(deductionRuleRepo, workSchedule.Location, auditSvc)
+	deductionRuleHdl := handlers.NewDeductionRuleHandler(deductionRuleSvc)
+	payrollRouter := routes.NewPayrollRouter(payrollGroup, payrollHdl, deductionRuleHdl, userRepo, revocationStore)
+	payrollRouter.Mount()
+
+	wellKnownGroup := g.Group("/.well-known")
+	jwksHdl := handlers.NewJWKSHandler()
+	wellKnownRouter := routes.NewWellKnownRouter(wellKnownGroup, jwksHdl)
+	wellKnownRouter.Mount()
+
+	jobs.Start(context.Background(), "blacklist-sweeper", config.BlacklistSweepInterval(), jobs.NewBlacklistSweeper(revocationStore, refreshTokenRepo))
+	jobs.Start(context.Background(), "leave-accrual", config.LeaveAccrualInterval(), jobs.NewLeaveAccrual(leaveAccrualSvc))
+	jobs.Start(context.Background(), "user-purge", config.UserPurgeInterval(), jobs.NewUserPurge(userSvc))
+
 	g.Run(":8080")
 }
+
+func newRevocationStore(gorm config.GormPostgres, redisClient *redis.Client) repository.RevocationStore {
+	switch driver := config.RevocationStoreDriver(); driver {
+	case "postgres":
+		return repository.NewPostgresRevocationStore(gorm)
+	case "redis":
+		if redisClient == nil {
+			log.Fatalf("REVOCATION_STORE is redis but REDIS_ADDR is not set")
+		}
+		return repository.NewRedisRevocationStore(redisClient)
+	case "memory":
+		return repository.NewMemoryRevocationStore()
+	default:
+		log.Fatalf("Unknown REVOCATION_STORE %q", driver)
+		return nil
+	}
+}
+
+func newCache(redisClient *redis.Client) cache.Cache {
+	switch driver := config.CacheDriver(); driver {
+	case "none":
+		return nil
+	case "redis":
+		if redisClient == nil {
+			log.Fatalf("CACHE_STORE is redis but REDIS_ADDR is not set")
+		}
+		return cache.NewRedisCache(redisClient, "cache:")
+	case "memory":
+		return cache.NewMemoryCache()
+	default:
+		log.Fatalf("Unknown CACHE_STORE %q", driver)
+		return nil
+	}
+}
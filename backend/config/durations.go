@@ -0,0 +1,39 @@
+package config
+
+import "time"
+
+// BlacklistSweepInterval controls how often expired revoked tokens are
+// removed, read from BLACKLIST_SWEEP_INTERVAL (default 1h).
+func BlacklistSweepInterval() time.Duration {
+	return durationFromEnv("BLACKLIST_SWEEP_INTERVAL", time.Hour)
+}
+
+// CacheTTL is how long cached lookups live, read from CACHE_TTL (default 5m).
+func CacheTTL() time.Duration {
+	return durationFromEnv("CACHE_TTL", 5*time.Minute)
+}
+
+// HeartbeatTimeout is how long one WFH heartbeat counts for, read from
+// HEARTBEAT_TIMEOUT (default 5m). A user without a heartbeat for longer is
+// considered offline.
+func HeartbeatTimeout() time.Duration {
+	return durationFromEnv("HEARTBEAT_TIMEOUT", 5*time.Minute)
+}
+
+// DeletedUserRetention is how long deleted users are kept before they can be
+// purged, read from DELETED_USER_RETENTION (default 720h, 30 days).
+func DeletedUserRetention() time.Duration {
+	return durationFromEnv("DELETED_USER_RETENTION", 30*24*time.Hour)
+}
+
+// UserPurgeInterval controls how often users past their retention period are
+// purged, read from USER_PURGE_INTERVAL (default 24h).
+func UserPurgeInterval() time.Duration {
+	return durationFromEnv("USER_PURGE_INTERVAL", 24*time.Hour)
+}
+
+// LeaveAccrualInterval controls how often due leave accruals are posted,
+// read from LEAVE_ACCRUAL_INTERVAL (default 24h).
+func LeaveAccrualInterval() time.Duration {
+	return durationFromEnv("LEAVE_ACCRUAL_INTERVAL", 24*time.Hour)
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"main.go/internal/auth"
+)
+
+// NewJWTKeySet builds the token signing keys from the environment.
+//
+//	JWT_SECRETS        comma separated "kid:secret" HS256 keys
+//	JWT_KEYS_DIR       directory of "<kid>.pem" RSA or Ed25519 keys
+//	JWT_ACTIVE_KEY_ID  kid used to sign new tokens
+//
+// Every loaded key verifies tokens, so a key can be rotated by adding a new
+// one, switching JWT_ACTIVE_KEY_ID and removing the old one once the tokens
+// it signed have expired. JWT_SECRET is accepted as a single HS256 key with
+// kid "default" for simple setups.
+func NewJWTKeySet() *auth.KeySet {
+	var keys []auth.SigningKey
+
+	if secret := os.Getenv("JWT_SECRET"); secret != "" {
+		keys = append(keys, auth.NewHMACKey("default", []byte(secret)))
+	}
+
+	for _, entry := range strings.Split(os.Getenv("JWT_SECRETS"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		kid, secret, ok := strings.Cut(entry, ":")
+		if !ok || kid == "" || secret == "" {
+			panic("JWT_SECRETS entries must look like kid:secret")
+		}
+		keys = append(keys, auth.NewHMACKey(kid, []byte(secret)))
+	}
+
+	if dir := os.Getenv("JWT_KEYS_DIR"); dir != "" {
+		files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+		if err != nil {
+			panic(err)
+		}
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				panic(err)
+			}
+			kid := strings.TrimSuffix(filepath.Base(file), ".pem")
+			key, err := auth.ParsePEMKey(kid, data)
+			if err != nil {
+				panic(err)
+			}
+			keys = append(keys, key)
+		}
+	}
+
+	if len(keys) == 0 {
+		panic("JWT_SECRET, JWT_SECRETS or JWT_KEYS_DIR must be set in the environment variables")
+	}
+
+	activeID := os.Getenv("JWT_ACTIVE_KEY_ID")
+	if activeID == "" {
+		if len(keys) > 1 {
+			panic("JWT_ACTIVE_KEY_ID is required when more than one JWT key is configured")
+		}
+		activeID = keys[0].ID
+	}
+
+	keySet, err := auth.NewKeySet(activeID, keys...)
+	if err != nil {
+		panic(err)
+	}
+	return keySet
+}
+
+// TokenTTLs reads JWT_ACCESS_TTL and JWT_REFRESH_TTL as Go durations such as
+// "15m" or "168h", falling back to the defaults in the auth package.
+func TokenTTLs() (time.Duration, time.Duration) {
+	return durationFromEnv("JWT_ACCESS_TTL", auth.AccessTokenTTL),
+		durationFromEnv("JWT_REFRESH_TTL", auth.RefreshTokenTTL)
+}
@@ -0,0 +1,21 @@
+package config
+
+import (
+	"os"
+	"strconv"
+)
+
+// PayrollHoursPerWeek is the full-time working week salaries are divided by
+// to get an hourly overtime rate, read from PAYROLL_HOURS_PER_WEEK (default
+// 40).
+func PayrollHoursPerWeek() float64 {
+	value := os.Getenv("PAYROLL_HOURS_PER_WEEK")
+	if value == "" {
+		return 40
+	}
+	hours, err := strconv.ParseFloat(value, 64)
+	if err != nil || hours <= 0 || hours > 168 {
+		panic("PAYROLL_HOURS_PER_WEEK must be a number of hours between 0 and 168")
+	}
+	return hours
+}
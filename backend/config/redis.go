@@ -0,0 +1,57 @@
+package config
+
+import (
+	"context"
+	"os"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// NewRedis connects to REDIS_ADDR. It returns nil when REDIS_ADDR is not set
+// so that Redis stays optional for local development.
+func NewRedis() *redis.Client {
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		return nil
+	}
+
+	db := 0
+	if value := os.Getenv("REDIS_DB"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			panic("REDIS_DB must be a number")
+		}
+		db = parsed
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: os.Getenv("REDIS_PASSWORD"),
+		DB:       db,
+	})
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		panic(err)
+	}
+	return client
+}
+
+// RevocationStoreDriver reads REVOCATION_STORE ("postgres", "redis" or
+// "memory"). It defaults to redis when Redis is configured.
+func RevocationStoreDriver() string {
+	if driver := os.Getenv("REVOCATION_STORE"); driver != "" {
+		return driver
+	}
+	if os.Getenv("REDIS_ADDR") != "" {
+		return "redis"
+	}
+	return "postgres"
+}
+
+// CacheDriver reads CACHE_STORE ("redis", "memory" or "none", the default).
+func CacheDriver() string {
+	if driver := os.Getenv("CACHE_STORE"); driver != "" {
+		return driver
+	}
+	return "none"
+}
@@ -0,0 +1,20 @@
+package config
+
+import (
+	"os"
+	"strconv"
+)
+
+// TimesheetPeriodWeeks is how many weeks one timesheet covers, read from
+// TIMESHEET_PERIOD_WEEKS (1 or 2, default 1).
+func TimesheetPeriodWeeks() int {
+	value := os.Getenv("TIMESHEET_PERIOD_WEEKS")
+	if value == "" {
+		return 1
+	}
+	weeks, err := strconv.Atoi(value)
+	if err != nil || weeks < 1 || weeks > 2 {
+		panic("TIMESHEET_PERIOD_WEEKS must be 1 or 2")
+	}
+	return weeks
+}
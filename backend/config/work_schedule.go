@@ -0,0 +1,69 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+// WorkSchedule is the default working day used when an employee has no
+// other schedule. Start and End are offsets from midnight in Location.
+type WorkSchedule struct {
+	Start       time.Duration
+	End         time.Duration
+	GracePeriod time.Duration
+	Location    *time.Location
+}
+
+// NewWorkSchedule reads WORK_START and WORK_END ("15:04", default 09:00 to
+// 17:00), WORK_GRACE_PERIOD (default 0) and WORK_TIMEZONE (an IANA name,
+// default the server's local time zone).
+func NewWorkSchedule() WorkSchedule {
+	schedule := WorkSchedule{
+		Start:    clockFromEnv("WORK_START", 9*time.Hour),
+		End:      clockFromEnv("WORK_END", 17*time.Hour),
+		Location: time.Local,
+	}
+
+	if value := os.Getenv("WORK_GRACE_PERIOD"); value != "" {
+		grace, err := time.ParseDuration(value)
+		if err != nil || grace < 0 {
+			panic("WORK_GRACE_PERIOD must be a duration such as 10m")
+		}
+		schedule.GracePeriod = grace
+	}
+
+	if name := os.Getenv("WORK_TIMEZONE"); name != "" {
+		location, err := time.LoadLocation(name)
+		if err != nil {
+			panic("WORK_TIMEZONE is not a valid time zone: " + err.Error())
+		}
+		schedule.Location = location
+	}
+
+	if schedule.End <= schedule.Start {
+		panic("WORK_END must be after WORK_START")
+	}
+	return schedule
+}
+
+func clockFromEnv(name string, fallback time.Duration) time.Duration {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return fallback
+	}
+	clock, err := ParseClock(value)
+	if err != nil {
+		panic(name + " must look like 15:04")
+	}
+	return clock
+}
+
+// ParseClock turns a "15:04" time of day into an offset from midnight.
+func ParseClock(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
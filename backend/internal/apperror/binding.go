@@ -0,0 +1,74 @@
+package apperror
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	"main.go/internal/validation"
+)
+
+// Binding turns a failure to bind a request body or query into a
+// validation error, with one detail per field that failed a rule. The
+// details are in English until Localize picks the client's language.
+func Binding(err error) *Error {
+	details := bindingDetails(err, validation.Translator(""))
+	if details == nil {
+		return &Error{Kind: KindValidation, Code: "invalid_request", Message: "invalid request data", Err: err}
+	}
+	return &Error{Kind: KindValidation, Code: "validation_failed", Message: "request validation failed", Details: details, Err: err}
+}
+
+// Localize returns e with its field details in the language the
+// Accept-Language header prefers. Errors without details are returned as
+// they are.
+func (e *Error) Localize(acceptLanguage string) *Error {
+	if len(e.Details) == 0 || e.Err == nil {
+		return e
+	}
+	details := bindingDetails(e.Err, validation.Translator(acceptLanguage))
+	if details == nil {
+		return e
+	}
+
+	localized := *e
+	localized.Details = details
+	return &localized
+}
+
+func bindingDetails(err error, trans ut.Translator) []FieldError {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		details := make([]FieldError, 0, len(validationErrors))
+		for _, fieldErr := range validationErrors {
+			details = append(details, FieldError{
+				Field:   fieldPath(fieldErr),
+				Rule:    fieldErr.Tag(),
+				Message: validation.Message(trans, fieldErr),
+			})
+		}
+		return details
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return []FieldError{{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Message: validation.TypeMessage(trans, typeErr.Field, typeErr.Type.Kind()),
+		}}
+	}
+	return nil
+}
+
+// fieldPath is the path of a field without its top level struct, like
+// rules[0].code.
+func fieldPath(fieldErr validator.FieldError) string {
+	namespace := fieldErr.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
@@ -1,34 +1,105 @@
 package auth
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 )
 
-var jwtSecret = []byte("your_secret_key")
+type Claims struct {
+	Email      string `json:"email"`
+	RoleID     int    `json:"role_id"`
+	Role       string `json:"role"`
+	PositionID int    `json:"position_id"`
+	Position   string `json:"position"`
+	jwt.RegisteredClaims
+}
+
+// Principal converts validated claims into the caller of a request. The
+// permissions are not part of the token and have to be filled in separately.
+func (c *Claims) Principal() Principal {
+	userID, _ := strconv.Atoi(c.Subject)
+
+	principal := Principal{
+		UserID:     userID,
+		Email:      c.Email,
+		RoleID:     c.RoleID,
+		Role:       c.Role,
+		PositionID: c.PositionID,
+		Position:   c.Position,
+		TokenID:    c.ID,
+	}
+	if c.ExpiresAt != nil {
+		principal.ExpiresAt = c.ExpiresAt.Time
+	}
+	return principal
+}
+
+func GenerateJWT(principal Principal) (string, error) {
+	ks, err := currentKeySet()
+	if err != nil {
+		return "", err
+	}
+	key := ks.signingKey()
 
-func GenerateJWT(email string) (string, error) {
-	claims := jwt.MapClaims{
-		"email": email,
-		"exp":   time.Now().Add(time.Hour * 3).Unix(),
+	tokenID, err := newTokenID()
+	if err != nil {
+		return "", err
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+
+	now := time.Now()
+	claims := Claims{
+		Email:      principal.Email,
+		RoleID:     principal.RoleID,
+		Role:       principal.Role,
+		PositionID: principal.PositionID,
+		Position:   principal.Position,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.Itoa(principal.UserID),
+			ID:        tokenID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenTTL)),
+		},
+	}
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.Private)
 }
 
-func ValidateJWT(tokenString string) (*jwt.Token, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+func ValidateJWT(tokenString string) (*Claims, error) {
+	ks, err := currentKeySet()
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := ks.verificationKey(kid)
+		if !ok {
+			return nil, errors.New("unknown signing key")
+		}
+		if token.Method.Alg() != key.Method.Alg() {
 			return nil, errors.New("unexpected signing method")
 		}
-		return jwtSecret, nil
+		return key.Public, nil
 	})
 
-	if err != nil || !token.Valid {
+	if err != nil || !token.Valid || claims.Subject == "" || claims.ID == "" {
 		return nil, errors.New("invalid token")
 	}
 
-	return token, nil
+	return claims, nil
+}
+
+func newTokenID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
 }
@@ -0,0 +1,95 @@
+package auth
+
+// Permissions granted to roles through the role_permissions table.
+// Every authenticated user can always read and edit their own record,
+// so the users permissions only describe access to other users' data.
+const (
+	PermissionUsersRead   = "users:read"
+	PermissionUsersWrite  = "users:write"
+	PermissionUsersDelete = "users:delete"
+	PermissionUsersPurge  = "users:purge"
+
+	PermissionRolesRead  = "roles:read"
+	PermissionRolesWrite = "roles:write"
+
+	PermissionPositionsRead  = "positions:read"
+	PermissionPositionsWrite = "positions:write"
+
+	PermissionDepartmentsRead  = "departments:read"
+	PermissionDepartmentsWrite = "departments:write"
+
+	PermissionAttendanceRead = "attendance:read"
+
+	PermissionMonitoringRead = "monitoring:read"
+
+	PermissionLeaveRead    = "leave:read"
+	PermissionLeaveWrite   = "leave:write"
+	PermissionLeaveApprove = "leave:approve"
+
+	PermissionCalendarsWrite = "calendars:write"
+
+	PermissionShiftsRead  = "shifts:read"
+	PermissionShiftsWrite = "shifts:write"
+
+	PermissionOvertimeRead    = "overtime:read"
+	PermissionOvertimeWrite   = "overtime:write"
+	PermissionOvertimeApprove = "overtime:approve"
+
+	PermissionTimesheetsRead    = "timesheets:read"
+	PermissionTimesheetsApprove = "timesheets:approve"
+	PermissionTimesheetsLock    = "timesheets:lock"
+
+	PermissionCompensationRead  = "compensation:read"
+	PermissionCompensationWrite = "compensation:write"
+
+	PermissionPayrollRead  = "payroll:read"
+	PermissionPayrollWrite = "payroll:write"
+
+	PermissionAuditRead = "audit:read"
+)
+
+// Permissions lists every permission that can be granted to a role.
+var Permissions = []string{
+	PermissionUsersRead,
+	PermissionUsersWrite,
+	PermissionUsersDelete,
+	PermissionUsersPurge,
+	PermissionRolesRead,
+	PermissionRolesWrite,
+	PermissionPositionsRead,
+	PermissionPositionsWrite,
+	PermissionDepartmentsRead,
+	PermissionDepartmentsWrite,
+	PermissionAttendanceRead,
+	PermissionMonitoringRead,
+	PermissionLeaveRead,
+	PermissionLeaveWrite,
+	PermissionLeaveApprove,
+	PermissionCalendarsWrite,
+	PermissionShiftsRead,
+	PermissionShiftsWrite,
+	PermissionOvertimeRead,
+	PermissionOvertimeWrite,
+	PermissionOvertimeApprove,
+	PermissionTimesheetsRead,
+	PermissionTimesheetsApprove,
+	PermissionTimesheetsLock,
+	PermissionCompensationRead,
+	PermissionCompensationWrite,
+	PermissionPayrollRead,
+	PermissionPayrollWrite,
+	PermissionAuditRead,
+}
+
+func HasPermission(permissions []string, permission string) bool {
+	for _, p := range permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+func IsKnownPermission(permission string) bool {
+	return HasPermission(Permissions, permission)
+}
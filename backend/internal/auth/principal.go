@@ -0,0 +1,37 @@
+package auth
+
+import (
+	"context"
+	"time"
+)
+
+// Principal describes the authenticated caller of a request. AuthMiddleware
+// builds it from the access token claims and stores it in the request
+// context, so services and repositories can find out who is calling without
+// looking the user up again.
+type Principal struct {
+	UserID      int
+	Email       string
+	RoleID      int
+	Role        string
+	PositionID  int
+	Position    string
+	TokenID     string
+	ExpiresAt   time.Time
+	Permissions []string
+}
+
+func (p Principal) HasPermission(permission string) bool {
+	return HasPermission(p.Permissions, permission)
+}
+
+type principalContextKey struct{}
+
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey{}).(Principal)
+	return principal, ok
+}
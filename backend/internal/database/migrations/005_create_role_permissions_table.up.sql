@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS role_permissions(
+    id SERIAL PRIMARY KEY UNIQUE NOT NULL,
+    role_id INT NOT NULL REFERENCES roles(id),
+    permission VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (role_id, permission)
+);
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT r.id, p.permission
+FROM roles r
+JOIN (VALUES
+    ('admin', 'users:read'),
+    ('admin', 'users:write'),
+    ('admin', 'users:delete'),
+    ('hr', 'users:read'),
+    ('hr', 'users:write'),
+    ('hr', 'users:delete')
+) AS p(role_name, permission) ON LOWER(r.name) = p.role_name
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_black_listed_tokens_expired_at;
+DROP INDEX IF EXISTS idx_black_listed_tokens_token;
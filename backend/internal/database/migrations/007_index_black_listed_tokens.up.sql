@@ -0,0 +1,6 @@
+-- Revocations are now keyed by the token's jti instead of the raw token.
+-- Rows written before this change can't match anything anymore.
+DELETE FROM black_listed_tokens;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_black_listed_tokens_token ON black_listed_tokens(token);
+CREATE INDEX IF NOT EXISTS idx_black_listed_tokens_expired_at ON black_listed_tokens(expired_at);
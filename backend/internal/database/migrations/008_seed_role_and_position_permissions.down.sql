@@ -0,0 +1,2 @@
+DELETE FROM role_permissions
+WHERE permission IN ('roles:read', 'roles:write', 'positions:read', 'positions:write');
@@ -0,0 +1,13 @@
+INSERT INTO role_permissions (role_id, permission)
+SELECT r.id, p.permission
+FROM roles r
+JOIN (VALUES
+    ('admin', 'roles:read'),
+    ('admin', 'roles:write'),
+    ('admin', 'positions:read'),
+    ('admin', 'positions:write'),
+    ('hr', 'roles:read'),
+    ('hr', 'positions:read'),
+    ('hr', 'positions:write')
+) AS p(role_name, permission) ON LOWER(r.name) = p.role_name
+ON CONFLICT DO NOTHING;
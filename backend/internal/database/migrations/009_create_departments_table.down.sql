@@ -0,0 +1,9 @@
+DELETE FROM role_permissions WHERE permission IN ('departments:read', 'departments:write');
+
+DROP INDEX IF EXISTS idx_users_manager_id;
+DROP INDEX IF EXISTS idx_users_department_id;
+
+ALTER TABLE users DROP COLUMN IF EXISTS manager_id;
+ALTER TABLE users DROP COLUMN IF EXISTS department_id;
+
+DROP TABLE IF EXISTS departments;
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS departments(
+    id SERIAL PRIMARY KEY UNIQUE NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP DEFAULT NULL
+);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS department_id INT DEFAULT NULL REFERENCES departments(id);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS manager_id INT DEFAULT NULL REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_users_department_id ON users(department_id);
+CREATE INDEX IF NOT EXISTS idx_users_manager_id ON users(manager_id);
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT r.id, p.permission
+FROM roles r
+JOIN (VALUES
+    ('admin', 'departments:read'),
+    ('admin', 'departments:write'),
+    ('hr', 'departments:read'),
+    ('hr', 'departments:write')
+) AS p(role_name, permission) ON LOWER(r.name) = p.role_name
+ON CONFLICT DO NOTHING;
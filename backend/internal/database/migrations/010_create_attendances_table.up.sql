@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS attendances(
+    id SERIAL PRIMARY KEY UNIQUE NOT NULL,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    work_date DATE NOT NULL,
+    check_in_at TIMESTAMP NOT NULL,
+    check_out_at TIMESTAMP DEFAULT NULL,
+    scheduled_start TIMESTAMP NOT NULL,
+    scheduled_end TIMESTAMP NOT NULL,
+    worked_minutes INT NOT NULL DEFAULT 0,
+    late_minutes INT NOT NULL DEFAULT 0,
+    early_leave_minutes INT NOT NULL DEFAULT 0,
+    is_late BOOLEAN NOT NULL DEFAULT FALSE,
+    is_early_leave BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, work_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_attendances_work_date ON attendances(work_date);
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT r.id, p.permission
+FROM roles r
+JOIN (VALUES
+    ('admin', 'attendance:read'),
+    ('hr', 'attendance:read')
+) AS p(role_name, permission) ON LOWER(r.name) = p.role_name
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,3 @@
+DELETE FROM role_permissions WHERE permission = 'monitoring:read';
+
+DROP TABLE IF EXISTS activity_heartbeats;
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS activity_heartbeats(
+    id BIGSERIAL PRIMARY KEY UNIQUE NOT NULL,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event_id VARCHAR(64) NOT NULL,
+    state VARCHAR(10) NOT NULL CHECK (state IN ('active', 'idle')),
+    occurred_at TIMESTAMP NOT NULL,
+    received_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_activity_heartbeats_user_occurred_at ON activity_heartbeats(user_id, occurred_at);
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT r.id, p.permission
+FROM roles r
+JOIN (VALUES
+    ('admin', 'monitoring:read'),
+    ('hr', 'monitoring:read')
+) AS p(role_name, permission) ON LOWER(r.name) = p.role_name
+ON CONFLICT DO NOTHING;
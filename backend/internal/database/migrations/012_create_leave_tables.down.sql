@@ -0,0 +1,5 @@
+DELETE FROM role_permissions WHERE permission IN ('leave:read', 'leave:write', 'leave:approve');
+
+DROP TABLE IF EXISTS leave_requests;
+DROP TABLE IF EXISTS leave_balances;
+DROP TABLE IF EXISTS leave_types;
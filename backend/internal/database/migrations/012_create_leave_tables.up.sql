@@ -0,0 +1,61 @@
+CREATE TABLE IF NOT EXISTS leave_types(
+    id SERIAL PRIMARY KEY UNIQUE NOT NULL,
+    name VARCHAR(50) UNIQUE NOT NULL,
+    is_paid BOOLEAN NOT NULL DEFAULT TRUE,
+    default_days INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO leave_types (name, is_paid, default_days) VALUES
+    ('annual', TRUE, 12),
+    ('sick', TRUE, 14),
+    ('unpaid', FALSE, 0)
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS leave_balances(
+    id SERIAL PRIMARY KEY UNIQUE NOT NULL,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    leave_type_id INT NOT NULL REFERENCES leave_types(id) ON DELETE CASCADE,
+    year INT NOT NULL,
+    entitled_days INT NOT NULL DEFAULT 0,
+    used_days INT NOT NULL DEFAULT 0 CHECK (used_days >= 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, leave_type_id, year)
+);
+
+CREATE TABLE IF NOT EXISTS leave_requests(
+    id SERIAL PRIMARY KEY UNIQUE NOT NULL,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    leave_type_id INT NOT NULL REFERENCES leave_types(id),
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    days INT NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'submitted', 'approved', 'rejected', 'cancelled')),
+    decided_by INT DEFAULT NULL REFERENCES users(id) ON DELETE SET NULL,
+    decision_note TEXT NOT NULL DEFAULT '',
+    submitted_at TIMESTAMP DEFAULT NULL,
+    decided_at TIMESTAMP DEFAULT NULL,
+    cancelled_at TIMESTAMP DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (end_date >= start_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_leave_requests_user_dates ON leave_requests(user_id, start_date, end_date);
+CREATE INDEX IF NOT EXISTS idx_leave_requests_status ON leave_requests(status);
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT r.id, p.permission
+FROM roles r
+JOIN (VALUES
+    ('admin', 'leave:read'),
+    ('admin', 'leave:write'),
+    ('admin', 'leave:approve'),
+    ('hr', 'leave:read'),
+    ('hr', 'leave:write'),
+    ('hr', 'leave:approve')
+) AS p(role_name, permission) ON LOWER(r.name) = p.role_name
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,14 @@
+ALTER TABLE leave_types ADD COLUMN IF NOT EXISTS default_days INT NOT NULL DEFAULT 0;
+UPDATE leave_types lt
+SET default_days = lap.days_per_year::int
+FROM leave_accrual_policies lap
+WHERE lap.leave_type_id = lt.id AND lap.name = 'Standard ' || lt.name;
+
+DROP TABLE IF EXISTS leave_ledger_entries;
+DROP TABLE IF EXISTS position_leave_policies;
+DROP TABLE IF EXISTS leave_accrual_policies;
+
+ALTER TABLE leave_balances ALTER COLUMN used_days TYPE INT USING ROUND(used_days)::int;
+ALTER TABLE leave_balances ALTER COLUMN entitled_days TYPE INT USING ROUND(entitled_days)::int;
+
+ALTER TABLE users DROP COLUMN IF EXISTS hire_date;
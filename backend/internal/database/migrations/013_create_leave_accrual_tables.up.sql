@@ -0,0 +1,74 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS hire_date DATE DEFAULT NULL;
+UPDATE users SET hire_date = created_at::date WHERE hire_date IS NULL;
+
+-- Accrued days are fractional once they are pro-rated.
+ALTER TABLE leave_balances ALTER COLUMN entitled_days TYPE NUMERIC(7,2);
+ALTER TABLE leave_balances ALTER COLUMN used_days TYPE NUMERIC(7,2);
+
+CREATE TABLE IF NOT EXISTS leave_accrual_policies(
+    id SERIAL PRIMARY KEY UNIQUE NOT NULL,
+    name VARCHAR(100) UNIQUE NOT NULL,
+    leave_type_id INT NOT NULL REFERENCES leave_types(id) ON DELETE CASCADE,
+    frequency VARCHAR(10) NOT NULL CHECK (frequency IN ('monthly', 'yearly')),
+    days_per_year NUMERIC(7,2) NOT NULL CHECK (days_per_year >= 0),
+    max_carry_over NUMERIC(7,2) NOT NULL DEFAULT 0 CHECK (max_carry_over >= 0),
+    carry_over_expiry_months INT NOT NULL DEFAULT 0 CHECK (carry_over_expiry_months >= 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS position_leave_policies(
+    position_id INT NOT NULL REFERENCES positions(id) ON DELETE CASCADE,
+    policy_id INT NOT NULL REFERENCES leave_accrual_policies(id) ON DELETE CASCADE,
+    PRIMARY KEY (position_id, policy_id)
+);
+
+CREATE TABLE IF NOT EXISTS leave_ledger_entries(
+    id BIGSERIAL PRIMARY KEY UNIQUE NOT NULL,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    leave_type_id INT NOT NULL REFERENCES leave_types(id) ON DELETE CASCADE,
+    year INT NOT NULL,
+    entry_type VARCHAR(20) NOT NULL CHECK (entry_type IN ('accrual', 'carry_over', 'expiry', 'adjustment', 'usage', 'restore')),
+    days NUMERIC(7,2) NOT NULL,
+    period_key VARCHAR(32) DEFAULT NULL,
+    policy_id INT DEFAULT NULL REFERENCES leave_accrual_policies(id) ON DELETE SET NULL,
+    leave_request_id INT DEFAULT NULL REFERENCES leave_requests(id) ON DELETE SET NULL,
+    note TEXT NOT NULL DEFAULT '',
+    effective_date DATE NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, leave_type_id, entry_type, period_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_leave_ledger_entries_balance ON leave_ledger_entries(user_id, leave_type_id, year);
+
+-- The yearly entitlement now comes from accrual policies. The old defaults
+-- become standard policies of every existing position.
+INSERT INTO leave_accrual_policies (name, leave_type_id, frequency, days_per_year, max_carry_over, carry_over_expiry_months)
+SELECT 'Standard ' || name, id, 'yearly', default_days, CASE WHEN name = 'annual' THEN 5 ELSE 0 END, CASE WHEN name = 'annual' THEN 3 ELSE 0 END
+FROM leave_types
+WHERE is_paid
+ON CONFLICT DO NOTHING;
+
+INSERT INTO position_leave_policies (position_id, policy_id)
+SELECT p.id, lap.id
+FROM positions p
+CROSS JOIN leave_accrual_policies lap
+ON CONFLICT DO NOTHING;
+
+-- Balances entered so far count as that year's accrual, so the ledger
+-- explains them and the accrual job doesn't post the year twice.
+INSERT INTO leave_ledger_entries (user_id, leave_type_id, year, entry_type, days, period_key, policy_id, note, effective_date)
+SELECT lb.user_id, lb.leave_type_id, lb.year, 'accrual', lb.entitled_days, lb.year::text, lap.id, 'opening balance', make_date(lb.year, 1, 1)
+FROM leave_balances lb
+LEFT JOIN leave_accrual_policies lap ON lap.leave_type_id = lb.leave_type_id
+WHERE lb.entitled_days <> 0
+ON CONFLICT DO NOTHING;
+
+INSERT INTO leave_ledger_entries (user_id, leave_type_id, year, entry_type, days, period_key, leave_request_id, note, effective_date)
+SELECT lr.user_id, lr.leave_type_id, EXTRACT(YEAR FROM lr.start_date)::int, 'usage', -lr.days, 'request:' || lr.id, lr.id, '', lr.start_date
+FROM leave_requests lr
+JOIN leave_types lt ON lt.id = lr.leave_type_id
+WHERE lr.status = 'approved' AND lt.is_paid
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE leave_types DROP COLUMN IF EXISTS default_days;
@@ -0,0 +1,7 @@
+DELETE FROM role_permissions WHERE permission = 'calendars:write';
+
+ALTER TABLE attendances DROP COLUMN IF EXISTS is_working_day;
+ALTER TABLE departments DROP COLUMN IF EXISTS calendar_id;
+
+DROP TABLE IF EXISTS holidays;
+DROP TABLE IF EXISTS calendars;
@@ -0,0 +1,42 @@
+CREATE TABLE IF NOT EXISTS calendars(
+    id SERIAL PRIMARY KEY UNIQUE NOT NULL,
+    name VARCHAR(100) UNIQUE NOT NULL,
+    -- Bit n is set when weekday n (0 = Sunday) is a working day.
+    working_weekdays SMALLINT NOT NULL DEFAULT 62,
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_calendars_default ON calendars(is_default) WHERE is_default;
+
+INSERT INTO calendars (name, is_default) VALUES ('Default', TRUE) ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS holidays(
+    id SERIAL PRIMARY KEY UNIQUE NOT NULL,
+    calendar_id INT NOT NULL REFERENCES calendars(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    date DATE NOT NULL,
+    -- Recurring holidays repeat every year on the same month and day,
+    -- starting with the year of date.
+    recurring BOOLEAN NOT NULL DEFAULT FALSE,
+    uid VARCHAR(255) DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (calendar_id, uid)
+);
+
+CREATE INDEX IF NOT EXISTS idx_holidays_calendar_date ON holidays(calendar_id, date);
+
+ALTER TABLE departments ADD COLUMN IF NOT EXISTS calendar_id INT DEFAULT NULL REFERENCES calendars(id) ON DELETE SET NULL;
+
+ALTER TABLE attendances ADD COLUMN IF NOT EXISTS is_working_day BOOLEAN NOT NULL DEFAULT TRUE;
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT r.id, p.permission
+FROM roles r
+JOIN (VALUES
+    ('admin', 'calendars:write'),
+    ('hr', 'calendars:write')
+) AS p(role_name, permission) ON LOWER(r.name) = p.role_name
+ON CONFLICT DO NOTHING;
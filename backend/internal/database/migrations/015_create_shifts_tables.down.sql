@@ -0,0 +1,6 @@
+DELETE FROM role_permissions WHERE permission IN ('shifts:read', 'shifts:write');
+
+ALTER TABLE attendances DROP COLUMN IF EXISTS shift_id;
+
+DROP TABLE IF EXISTS shift_assignments;
+DROP TABLE IF EXISTS shifts;
@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS shifts(
+    id SERIAL PRIMARY KEY UNIQUE NOT NULL,
+    name VARCHAR(100) UNIQUE NOT NULL,
+    -- A shift ending at or before its start time ends on the next day.
+    start_time TIME NOT NULL,
+    end_time TIME NOT NULL,
+    break_minutes INT NOT NULL DEFAULT 0 CHECK (break_minutes >= 0),
+    -- Bit n is set when the shift runs on weekday n (0 = Sunday).
+    weekdays SMALLINT NOT NULL DEFAULT 62,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS shift_assignments(
+    id SERIAL PRIMARY KEY UNIQUE NOT NULL,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    shift_id INT NOT NULL REFERENCES shifts(id),
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (end_date >= start_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_shift_assignments_user_dates ON shift_assignments(user_id, start_date, end_date);
+
+ALTER TABLE attendances ADD COLUMN IF NOT EXISTS shift_id INT DEFAULT NULL REFERENCES shifts(id) ON DELETE SET NULL;
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT r.id, p.permission
+FROM roles r
+JOIN (VALUES
+    ('admin', 'shifts:read'),
+    ('admin', 'shifts:write'),
+    ('hr', 'shifts:read'),
+    ('hr', 'shifts:write')
+) AS p(role_name, permission) ON LOWER(r.name) = p.role_name
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,4 @@
+DELETE FROM role_permissions WHERE permission IN ('overtime:read', 'overtime:write', 'overtime:approve');
+
+DROP TABLE IF EXISTS overtime_requests;
+DROP TABLE IF EXISTS overtime_rates;
@@ -0,0 +1,49 @@
+CREATE TABLE IF NOT EXISTS overtime_rates(
+    day_type VARCHAR(20) PRIMARY KEY CHECK (day_type IN ('weekday', 'weekend', 'holiday')),
+    multiplier NUMERIC(4,2) NOT NULL CHECK (multiplier > 0),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO overtime_rates (day_type, multiplier) VALUES
+    ('weekday', 1.5),
+    ('weekend', 2),
+    ('holiday', 3)
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS overtime_requests(
+    id SERIAL PRIMARY KEY UNIQUE NOT NULL,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    attendance_id INT DEFAULT NULL REFERENCES attendances(id) ON DELETE SET NULL,
+    work_date DATE NOT NULL,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('pre_approval', 'claim')),
+    day_type VARCHAR(20) NOT NULL CHECK (day_type IN ('weekday', 'weekend', 'holiday')),
+    minutes INT NOT NULL CHECK (minutes > 0),
+    -- The rate of day_type when the request was approved.
+    multiplier NUMERIC(4,2) DEFAULT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected', 'cancelled')),
+    decided_by INT DEFAULT NULL REFERENCES users(id) ON DELETE SET NULL,
+    decision_note TEXT NOT NULL DEFAULT '',
+    decided_at TIMESTAMP DEFAULT NULL,
+    cancelled_at TIMESTAMP DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- One pending or approved request per user and day, a pre-approval covers
+-- the claim of the same day.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_overtime_requests_active ON overtime_requests(user_id, work_date) WHERE status IN ('pending', 'approved');
+CREATE INDEX IF NOT EXISTS idx_overtime_requests_work_date ON overtime_requests(work_date);
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT r.id, p.permission
+FROM roles r
+JOIN (VALUES
+    ('admin', 'overtime:read'),
+    ('admin', 'overtime:write'),
+    ('admin', 'overtime:approve'),
+    ('hr', 'overtime:read'),
+    ('hr', 'overtime:write'),
+    ('hr', 'overtime:approve')
+) AS p(role_name, permission) ON LOWER(r.name) = p.role_name
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,6 @@
+DELETE FROM role_permissions WHERE permission IN ('timesheets:read', 'timesheets:approve', 'timesheets:lock');
+
+DROP TABLE IF EXISTS timesheet_entries;
+DROP TABLE IF EXISTS timesheets;
+DROP TABLE IF EXISTS pay_period_events;
+DROP TABLE IF EXISTS pay_periods;
@@ -0,0 +1,72 @@
+CREATE TABLE IF NOT EXISTS pay_periods(
+    id SERIAL PRIMARY KEY UNIQUE NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'closed')),
+    closed_by INT DEFAULT NULL REFERENCES users(id) ON DELETE SET NULL,
+    closed_at TIMESTAMP DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (end_date >= start_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pay_periods_dates ON pay_periods(start_date, end_date);
+
+-- Every close and reopen of a pay period, with who did it and why.
+CREATE TABLE IF NOT EXISTS pay_period_events(
+    id SERIAL PRIMARY KEY UNIQUE NOT NULL,
+    pay_period_id INT NOT NULL REFERENCES pay_periods(id) ON DELETE CASCADE,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('close', 'reopen')),
+    user_id INT DEFAULT NULL REFERENCES users(id) ON DELETE SET NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_pay_period_events_pay_period_id ON pay_period_events(pay_period_id);
+
+CREATE TABLE IF NOT EXISTS timesheets(
+    id SERIAL PRIMARY KEY UNIQUE NOT NULL,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    period_start DATE NOT NULL,
+    period_end DATE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'submitted', 'approved', 'rejected')),
+    total_minutes INT NOT NULL DEFAULT 0,
+    submitted_at TIMESTAMP DEFAULT NULL,
+    decided_by INT DEFAULT NULL REFERENCES users(id) ON DELETE SET NULL,
+    decision_note TEXT NOT NULL DEFAULT '',
+    decided_at TIMESTAMP DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, period_start),
+    CHECK (period_end >= period_start)
+);
+
+CREATE INDEX IF NOT EXISTS idx_timesheets_period_start ON timesheets(period_start);
+
+CREATE TABLE IF NOT EXISTS timesheet_entries(
+    id SERIAL PRIMARY KEY UNIQUE NOT NULL,
+    timesheet_id INT NOT NULL REFERENCES timesheets(id) ON DELETE CASCADE,
+    attendance_id INT DEFAULT NULL REFERENCES attendances(id) ON DELETE SET NULL,
+    work_date DATE NOT NULL,
+    minutes INT NOT NULL CHECK (minutes > 0),
+    project VARCHAR(100) NOT NULL DEFAULT '',
+    task VARCHAR(255) NOT NULL DEFAULT '',
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_timesheet_entries_timesheet_id ON timesheet_entries(timesheet_id);
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT r.id, p.permission
+FROM roles r
+JOIN (VALUES
+    ('admin', 'timesheets:read'),
+    ('admin', 'timesheets:approve'),
+    ('admin', 'timesheets:lock'),
+    ('hr', 'timesheets:read'),
+    ('hr', 'timesheets:approve'),
+    ('hr', 'timesheets:lock')
+) AS p(role_name, permission) ON LOWER(r.name) = p.role_name
+ON CONFLICT DO NOTHING;
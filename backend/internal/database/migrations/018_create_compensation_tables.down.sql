@@ -0,0 +1,4 @@
+DELETE FROM role_permissions WHERE permission IN ('compensation:read', 'compensation:write');
+
+DROP TABLE IF EXISTS compensation_allowances;
+DROP TABLE IF EXISTS compensations;
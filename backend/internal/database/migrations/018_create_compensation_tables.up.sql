@@ -0,0 +1,39 @@
+-- A compensation applies from effective_date until the next one of the same
+-- user. Rows with an effective_date in the future are scheduled raises.
+CREATE TABLE IF NOT EXISTS compensations(
+    id SERIAL PRIMARY KEY UNIQUE NOT NULL,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    effective_date DATE NOT NULL,
+    base_pay NUMERIC(14,2) NOT NULL CHECK (base_pay > 0),
+    currency CHAR(3) NOT NULL,
+    pay_frequency VARCHAR(20) NOT NULL CHECK (pay_frequency IN ('hourly', 'weekly', 'biweekly', 'monthly', 'annual')),
+    reason TEXT NOT NULL DEFAULT '',
+    created_by INT DEFAULT NULL REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, effective_date)
+);
+
+-- Allowances are paid on top of base_pay with every pay, in the currency of
+-- their compensation.
+CREATE TABLE IF NOT EXISTS compensation_allowances(
+    id SERIAL PRIMARY KEY UNIQUE NOT NULL,
+    compensation_id INT NOT NULL REFERENCES compensations(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    amount NUMERIC(14,2) NOT NULL CHECK (amount > 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (compensation_id, name)
+);
+
+-- Pay is confidential, so only HR and payroll get access by default.
+INSERT INTO role_permissions (role_id, permission)
+SELECT r.id, p.permission
+FROM roles r
+JOIN (VALUES
+    ('hr', 'compensation:read'),
+    ('hr', 'compensation:write'),
+    ('payroll', 'compensation:read'),
+    ('payroll', 'compensation:write')
+) AS p(role_name, permission) ON LOWER(r.name) = p.role_name
+ON CONFLICT DO NOTHING;
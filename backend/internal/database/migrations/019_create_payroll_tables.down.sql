@@ -0,0 +1,6 @@
+DELETE FROM role_permissions WHERE permission IN ('payroll:read', 'payroll:write');
+
+DROP TABLE IF EXISTS payslip_lines;
+DROP TABLE IF EXISTS payslips;
+DROP TABLE IF EXISTS payroll_runs;
+DROP TABLE IF EXISTS bank_accounts;
@@ -0,0 +1,64 @@
+CREATE TABLE IF NOT EXISTS bank_accounts(
+    id SERIAL PRIMARY KEY UNIQUE NOT NULL,
+    user_id INT NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    bank_name VARCHAR(100) NOT NULL,
+    account_number VARCHAR(50) NOT NULL,
+    account_holder VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- A payroll run pays one pay period. It can be recalculated until it is
+-- finalized, after that its payslips never change.
+CREATE TABLE IF NOT EXISTS payroll_runs(
+    id SERIAL PRIMARY KEY UNIQUE NOT NULL,
+    pay_period_id INT NOT NULL UNIQUE REFERENCES pay_periods(id),
+    pay_date DATE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'calculated', 'finalized')),
+    created_by INT DEFAULT NULL REFERENCES users(id) ON DELETE SET NULL,
+    calculated_at TIMESTAMP DEFAULT NULL,
+    finalized_by INT DEFAULT NULL REFERENCES users(id) ON DELETE SET NULL,
+    finalized_at TIMESTAMP DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS payslips(
+    id SERIAL PRIMARY KEY UNIQUE NOT NULL,
+    payroll_run_id INT NOT NULL REFERENCES payroll_runs(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id),
+    currency CHAR(3) NOT NULL,
+    worked_minutes INT NOT NULL DEFAULT 0,
+    overtime_minutes INT NOT NULL DEFAULT 0,
+    gross_pay NUMERIC(14,2) NOT NULL,
+    total_deductions NUMERIC(14,2) NOT NULL,
+    net_pay NUMERIC(14,2) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (payroll_run_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_payslips_user_id ON payslips(user_id);
+
+CREATE TABLE IF NOT EXISTS payslip_lines(
+    id SERIAL PRIMARY KEY UNIQUE NOT NULL,
+    payslip_id INT NOT NULL REFERENCES payslips(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('earning', 'deduction')),
+    code VARCHAR(50) NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    amount NUMERIC(14,2) NOT NULL,
+    position INT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_payslip_lines_payslip_id ON payslip_lines(payslip_id);
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT r.id, p.permission
+FROM roles r
+JOIN (VALUES
+    ('hr', 'payroll:read'),
+    ('hr', 'payroll:write'),
+    ('payroll', 'payroll:read'),
+    ('payroll', 'payroll:write')
+) AS p(role_name, permission) ON LOWER(r.name) = p.role_name
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,14 @@
+-- A deduction rule set applies to pay in its currency from effective_date
+-- until the next set of the same currency. rules holds the tax brackets,
+-- contributions and fixed deductions as JSON, in the order they apply.
+CREATE TABLE IF NOT EXISTS deduction_rule_sets(
+    id SERIAL PRIMARY KEY UNIQUE NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    effective_date DATE NOT NULL,
+    rules JSONB NOT NULL DEFAULT '[]',
+    created_by INT DEFAULT NULL REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (currency, effective_date)
+);
@@ -0,0 +1,5 @@
+DELETE FROM role_permissions WHERE permission = 'users:purge';
+
+DROP INDEX IF EXISTS users_deleted_at_idx;
+DROP INDEX IF EXISTS users_email_active_key;
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"main.go/internal/apperror"
+	"main.go/internal/middleware"
+	"main.go/internal/models"
+	"main.go/internal/service"
+)
+
+type AttendanceHandler interface {
+	CheckIn(ctx *gin.Context)
+	CheckOut(ctx *gin.Context)
+	GetMyAttendances(ctx *gin.Context)
+	GetUserAttendances(ctx *gin.Context)
+	GetAttendances(ctx *gin.Context)
+}
+
+type attendanceHandlerImpl struct {
+	svc service.AttendanceService
+}
+
+func NewAttendanceHandler(svc service.AttendanceService) AttendanceHandler {
+	return &attendanceHandlerImpl{svc: svc}
+}
+
+func (a *attendanceHandlerImpl) CheckIn(ctx *gin.Context) {
+	principal, _ := middleware.CurrentPrincipal(ctx)
+
+	attendance, err := a.svc.CheckIn(ctx, uint64(principal.UserID))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.AttendanceResponse{
+		Status:  http.StatusOK,
+		Message: "Checked in successfully",
+		Data:    &attendance,
+		Error:   false,
+	})
+}
+
+func (a *attendanceHandlerImpl) CheckOut(ctx *gin.Context) {
+	principal, _ := middleware.CurrentPrincipal(ctx)
+
+	attendance, err := a.svc.CheckOut(ctx, uint64(principal.UserID))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.AttendanceResponse{
+		Status:  http.StatusOK,
+		Message: "Checked out successfully",
+		Data:    &attendance,
+		Error:   false,
+	})
+}
+
+func (a *attendanceHandlerImpl) GetMyAttendances(ctx *gin.Context) {
+	principal, _ := middleware.CurrentPrincipal(ctx)
+	a.listAttendances(ctx, principal.UserID)
+}
+
+func (a *attendanceHandlerImpl) GetUserAttendances(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if id == 0 || err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+	a.listAttendances(ctx, id)
+}
+
+func (a *attendanceHandlerImpl) GetAttendances(ctx *gin.Context) {
+	a.listAttendances(ctx, 0)
+}
+
+// listAttendances answers the list endpoints. A non-zero userID overrides
+// any user_id query parameter.
+func (a *attendanceHandlerImpl) listAttendances(ctx *gin.Context, userID int) {
+	var filter models.AttendanceFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+	if userID != 0 {
+		filter.UserID = userID
+	}
+
+	attendances, err := a.svc.GetAttendances(ctx, filter)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.AttendancesResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get attendances",
+		Data:    &attendances,
+		Error:   false,
+	})
+}
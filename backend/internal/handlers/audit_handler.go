@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"main.go/internal/apperror"
+	"main.go/internal/models"
+	"main.go/internal/service"
+)
+
+type AuditHandler interface {
+	GetAuditLogs(ctx *gin.Context)
+	VerifyAuditLogs(ctx *gin.Context)
+}
+
+type auditHandlerImpl struct {
+	svc service.AuditService
+}
+
+func NewAuditHandler(svc service.AuditService) AuditHandler {
+	return &auditHandlerImpl{svc: svc}
+}
+
+func (a *auditHandlerImpl) GetAuditLogs(ctx *gin.Context) {
+	var filter models.AuditLogFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	logs, meta, err := a.svc.GetAuditLogs(ctx, filter)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.AuditLogsResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get audit logs",
+		Data:    &logs,
+		Meta:    &meta,
+		Error:   false,
+	})
+}
+
+func (a *auditHandlerImpl) VerifyAuditLogs(ctx *gin.Context) {
+	verification, err := a.svc.VerifyAuditLogs(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	message := "Audit log chain is intact"
+	if !verification.Valid {
+		message = "Audit log chain is broken"
+	}
+	ctx.JSON(http.StatusOK, models.AuditVerificationResponse{
+		Status:  http.StatusOK,
+		Message: message,
+		Data:    &verification,
+		Error:   false,
+	})
+}
@@ -0,0 +1,345 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"main.go/internal/apperror"
+	"main.go/internal/middleware"
+	"main.go/internal/models"
+	"main.go/internal/service"
+)
+
+// maxCalendarFileSize bounds the size of imported iCalendar files.
+const maxCalendarFileSize = 1 << 20
+
+type CalendarHandler interface {
+	GetCalendars(ctx *gin.Context)
+	GetCalendarByID(ctx *gin.Context)
+	CreateCalendar(ctx *gin.Context)
+	UpdateCalendar(ctx *gin.Context)
+	DeleteCalendar(ctx *gin.Context)
+
+	GetHolidays(ctx *gin.Context)
+	CreateHoliday(ctx *gin.Context)
+	UpdateHoliday(ctx *gin.Context)
+	DeleteHoliday(ctx *gin.Context)
+	ImportHolidays(ctx *gin.Context)
+
+	GetMyWorkingDays(ctx *gin.Context)
+	GetUserWorkingDays(ctx *gin.Context)
+}
+
+type calendarHandlerImpl struct {
+	svc service.CalendarService
+}
+
+func NewCalendarHandler(svc service.CalendarService) CalendarHandler {
+	return &calendarHandlerImpl{svc: svc}
+}
+
+func (c *calendarHandlerImpl) GetCalendars(ctx *gin.Context) {
+	calendars, err := c.svc.GetCalendars(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.CalendarsResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get calendars",
+		Data:    &calendars,
+		Error:   false,
+	})
+}
+
+func (c *calendarHandlerImpl) GetCalendarByID(ctx *gin.Context) {
+	id, ok := calendarID(ctx)
+	if !ok {
+		return
+	}
+
+	calendar, err := c.svc.GetCalendarByID(ctx, id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.CalendarResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get calendar",
+		Data:    &calendar,
+		Error:   false,
+	})
+}
+
+func (c *calendarHandlerImpl) CreateCalendar(ctx *gin.Context) {
+	var createCalendarRequest models.CalendarRequest
+	if err := ctx.ShouldBindJSON(&createCalendarRequest); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	calendar, err := c.svc.CreateCalendar(ctx, createCalendarRequest)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.CalendarResponse{
+		Status:  http.StatusOK,
+		Message: "Calendar created successfully",
+		Data:    &calendar,
+		Error:   false,
+	})
+}
+
+func (c *calendarHandlerImpl) UpdateCalendar(ctx *gin.Context) {
+	id, ok := calendarID(ctx)
+	if !ok {
+		return
+	}
+
+	var updateCalendarRequest models.CalendarRequest
+	if err := ctx.ShouldBindJSON(&updateCalendarRequest); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	calendar, err := c.svc.UpdateCalendar(ctx, id, updateCalendarRequest)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.CalendarResponse{
+		Status:  http.StatusOK,
+		Message: "Calendar updated successfully",
+		Data:    &calendar,
+		Error:   false,
+	})
+}
+
+func (c *calendarHandlerImpl) DeleteCalendar(ctx *gin.Context) {
+	id, ok := calendarID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.svc.DeleteCalendar(ctx, id); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.CalendarResponse{
+		Status:  http.StatusOK,
+		Message: "Calendar deleted successfully",
+		Data:    nil,
+		Error:   false,
+	})
+}
+
+func (c *calendarHandlerImpl) GetHolidays(ctx *gin.Context) {
+	id, ok := calendarID(ctx)
+	if !ok {
+		return
+	}
+
+	var filter models.HolidayFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	holidays, err := c.svc.GetHolidays(ctx, id, filter)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.HolidaysResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get holidays",
+		Data:    &holidays,
+		Error:   false,
+	})
+}
+
+func (c *calendarHandlerImpl) CreateHoliday(ctx *gin.Context) {
+	id, ok := calendarID(ctx)
+	if !ok {
+		return
+	}
+
+	var createHolidayRequest models.HolidayRequest
+	if err := ctx.ShouldBindJSON(&createHolidayRequest); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	holiday, err := c.svc.CreateHoliday(ctx, id, createHolidayRequest)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.HolidayResponse{
+		Status:  http.StatusOK,
+		Message: "Holiday created successfully",
+		Data:    &holiday,
+		Error:   false,
+	})
+}
+
+func (c *calendarHandlerImpl) UpdateHoliday(ctx *gin.Context) {
+	id, ok := calendarID(ctx)
+	if !ok {
+		return
+	}
+	holidayID, ok := holidayID(ctx)
+	if !ok {
+		return
+	}
+
+	var updateHolidayRequest models.HolidayRequest
+	if err := ctx.ShouldBindJSON(&updateHolidayRequest); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	holiday, err := c.svc.UpdateHoliday(ctx, id, holidayID, updateHolidayRequest)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.HolidayResponse{
+		Status:  http.StatusOK,
+		Message: "Holiday updated successfully",
+		Data:    &holiday,
+		Error:   false,
+	})
+}
+
+func (c *calendarHandlerImpl) DeleteHoliday(ctx *gin.Context) {
+	id, ok := calendarID(ctx)
+	if !ok {
+		return
+	}
+	holidayID, ok := holidayID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.svc.DeleteHoliday(ctx, id, holidayID); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.HolidayResponse{
+		Status:  http.StatusOK,
+		Message: "Holiday deleted successfully",
+		Data:    nil,
+		Error:   false,
+	})
+}
+
+// ImportHolidays accepts an iCalendar file either as the "file" field of a
+// multipart form or as the raw request body.
+func (c *calendarHandlerImpl) ImportHolidays(ctx *gin.Context) {
+	id, ok := calendarID(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxCalendarFileSize)
+
+	var file io.Reader = ctx.Request.Body
+	if strings.HasPrefix(ctx.ContentType(), "multipart/") {
+		header, err := ctx.FormFile("file")
+		if err != nil {
+			ctx.Error(apperror.Validation("missing_calendar_file", "missing calendar file"))
+			return
+		}
+		opened, err := header.Open()
+		if err != nil {
+			ctx.Error(err)
+			return
+		}
+		defer opened.Close()
+		file = opened
+	}
+
+	result, err := c.svc.ImportHolidays(ctx, id, file)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.HolidayImportResponse{
+		Status:  http.StatusOK,
+		Message: "Holidays imported successfully",
+		Data:    &result,
+		Error:   false,
+	})
+}
+
+func (c *calendarHandlerImpl) GetMyWorkingDays(ctx *gin.Context) {
+	principal, _ := middleware.CurrentPrincipal(ctx)
+	c.workingDays(ctx, uint64(principal.UserID))
+}
+
+func (c *calendarHandlerImpl) GetUserWorkingDays(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if id == 0 || err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+	c.workingDays(ctx, uint64(id))
+}
+
+func (c *calendarHandlerImpl) workingDays(ctx *gin.Context, userID uint64) {
+	var query models.WorkingDaysQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	summary, err := c.svc.GetWorkingDaysSummary(ctx, userID, query.From, query.To)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.WorkingDaysResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get working days",
+		Data:    &summary,
+		Error:   false,
+	})
+}
+
+func calendarID(ctx *gin.Context) (uint64, bool) {
+	return uintParam(ctx, "id")
+}
+
+func holidayID(ctx *gin.Context) (uint64, bool) {
+	return uintParam(ctx, "holiday_id")
+}
+
+// uintParam reads a positive ID path parameter and answers 400 when it is
+// missing or malformed.
+// errInvalidID is reported for a missing, zero or non-numeric :id.
+var errInvalidID = apperror.Validation("invalid_id", "invalid or missing ID parameter")
+
+func uintParam(ctx *gin.Context, name string) (uint64, bool) {
+	id, err := strconv.Atoi(ctx.Param(name))
+	if id <= 0 || err != nil {
+		ctx.Error(errInvalidID)
+		return 0, false
+	}
+	return uint64(id), true
+}
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"main.go/internal/apperror"
+	"main.go/internal/models"
+	"main.go/internal/service"
+)
+
+type CompensationHandler interface {
+	GetCurrentCompensations(ctx *gin.Context)
+	GetCompensationByID(ctx *gin.Context)
+	UpdateCompensation(ctx *gin.Context)
+	DeleteCompensation(ctx *gin.Context)
+
+	GetUserCompensationHistory(ctx *gin.Context)
+	GetUserCurrentCompensation(ctx *gin.Context)
+	GetUserScheduledCompensations(ctx *gin.Context)
+	CreateUserCompensation(ctx *gin.Context)
+}
+
+type compensationHandlerImpl struct {
+	svc service.CompensationService
+}
+
+func NewCompensationHandler(svc service.CompensationService) CompensationHandler {
+	return &compensationHandlerImpl{svc: svc}
+}
+
+func (c *compensationHandlerImpl) GetCurrentCompensations(ctx *gin.Context) {
+	var filter models.CompensationFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	compensations, err := c.svc.GetCurrentCompensations(ctx, filter)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.CompensationsResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get compensations",
+		Data:    &compensations,
+		Error:   false,
+	})
+}
+
+func (c *compensationHandlerImpl) GetCompensationByID(ctx *gin.Context) {
+	id, ok := uintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	compensation, err := c.svc.GetCompensationByID(ctx, id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.CompensationResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get compensation",
+		Data:    &compensation,
+		Error:   false,
+	})
+}
+
+func (c *compensationHandlerImpl) UpdateCompensation(ctx *gin.Context) {
+	id, ok := uintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var updateCompensationRequest models.CompensationRequest
+	if err := ctx.ShouldBindJSON(&updateCompensationRequest); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	compensation, err := c.svc.UpdateCompensation(ctx, id, updateCompensationRequest)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.CompensationResponse{
+		Status:  http.StatusOK,
+		Message: "Compensation updated successfully",
+		Data:    &compensation,
+		Error:   false,
+	})
+}
+
+func (c *compensationHandlerImpl) DeleteCompensation(ctx *gin.Context) {
+	id, ok := uintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	if err := c.svc.DeleteCompensation(ctx, id); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.CompensationResponse{
+		Status:  http.StatusOK,
+		Message: "Compensation deleted successfully",
+		Data:    nil,
+		Error:   false,
+	})
+}
+
+func (c *compensationHandlerImpl) GetUserCompensationHistory(ctx *gin.Context) {
+	c.listUserCompensations(ctx, c.svc.GetCompensationHistory, "Success to get compensation history")
+}
+
+func (c *compensationHandlerImpl) GetUserScheduledCompensations(ctx *gin.Context) {
+	c.listUserCompensations(ctx, c.svc.GetScheduledCompensations, "Success to get scheduled compensations")
+}
+
+func (c *compensationHandlerImpl) listUserCompensations(ctx *gin.Context, list func(context.Context, uint64) ([]models.Compensation, error), message string) {
+	userID, ok := uintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	compensations, err := list(ctx, userID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.CompensationsResponse{
+		Status:  http.StatusOK,
+		Message: message,
+		Data:    &compensations,
+		Error:   false,
+	})
+}
+
+func (c *compensationHandlerImpl) GetUserCurrentCompensation(ctx *gin.Context) {
+	userID, ok := uintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	compensation, err := c.svc.GetCurrentCompensation(ctx, userID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.CompensationResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get current compensation",
+		Data:    &compensation,
+		Error:   false,
+	})
+}
+
+func (c *compensationHandlerImpl) CreateUserCompensation(ctx *gin.Context) {
+	userID, ok := uintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var createCompensationRequest models.CompensationRequest
+	if err := ctx.ShouldBindJSON(&createCompensationRequest); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	compensation, err := c.svc.CreateCompensation(ctx, userID, createCompensationRequest)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.CompensationResponse{
+		Status:  http.StatusOK,
+		Message: "Compensation created successfully",
+		Data:    &compensation,
+		Error:   false,
+	})
+}
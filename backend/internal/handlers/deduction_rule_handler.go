@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"main.go/internal/apperror"
+	"main.go/internal/models"
+	"main.go/internal/service"
+)
+
+type DeductionRuleHandler interface {
+	GetDeductionRuleSets(ctx *gin.Context)
+	GetDeductionRuleSetByID(ctx *gin.Context)
+	CreateDeductionRuleSet(ctx *gin.Context)
+	UpdateDeductionRuleSet(ctx *gin.Context)
+	DeleteDeductionRuleSet(ctx *gin.Context)
+	DryRun(ctx *gin.Context)
+}
+
+type deductionRuleHandlerImpl struct {
+	svc service.DeductionRuleService
+}
+
+func NewDeductionRuleHandler(svc service.DeductionRuleService) DeductionRuleHandler {
+	return &deductionRuleHandlerImpl{svc: svc}
+}
+
+func (d *deductionRuleHandlerImpl) GetDeductionRuleSets(ctx *gin.Context) {
+	var filter models.DeductionRuleSetFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	sets, err := d.svc.GetDeductionRuleSets(ctx, filter)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.DeductionRuleSetsResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get deduction rule sets",
+		Data:    &sets,
+		Error:   false,
+	})
+}
+
+func (d *deductionRuleHandlerImpl) GetDeductionRuleSetByID(ctx *gin.Context) {
+	id, ok := uintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	set, err := d.svc.GetDeductionRuleSetByID(ctx, id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.DeductionRuleSetResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get deduction rule set",
+		Data:    &set,
+		Error:   false,
+	})
+}
+
+func (d *deductionRuleHandlerImpl) CreateDeductionRuleSet(ctx *gin.Context) {
+	createRuleSetRequest, ok := d.bindRuleSet(ctx)
+	if !ok {
+		return
+	}
+
+	set, err := d.svc.CreateDeductionRuleSet(ctx, createRuleSetRequest)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.DeductionRuleSetResponse{
+		Status:  http.StatusOK,
+		Message: "Deduction rule set created successfully",
+		Data:    &set,
+		Error:   false,
+	})
+}
+
+func (d *deductionRuleHandlerImpl) UpdateDeductionRuleSet(ctx *gin.Context) {
+	id, ok := uintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	updateRuleSetRequest, ok := d.bindRuleSet(ctx)
+	if !ok {
+		return
+	}
+
+	set, err := d.svc.UpdateDeductionRuleSet(ctx, id, updateRuleSetRequest)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.DeductionRuleSetResponse{
+		Status:  http.StatusOK,
+		Message: "Deduction rule set updated successfully",
+		Data:    &set,
+		Error:   false,
+	})
+}
+
+func (d *deductionRuleHandlerImpl) DeleteDeductionRuleSet(ctx *gin.Context) {
+	id, ok := uintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	if err := d.svc.DeleteDeductionRuleSet(ctx, id); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.DeductionRuleSetResponse{
+		Status:  http.StatusOK,
+		Message: "Deduction rule set deleted successfully",
+		Data:    nil,
+		Error:   false,
+	})
+}
+
+func (d *deductionRuleHandlerImpl) DryRun(ctx *gin.Context) {
+	var dryRunRequest models.DeductionDryRunRequest
+	if err := ctx.ShouldBindJSON(&dryRunRequest); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	result, err := d.svc.DryRun(ctx, dryRunRequest)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.DeductionDryRunResponse{
+		Status:  http.StatusOK,
+		Message: "Success to calculate deductions",
+		Data:    &result,
+		Error:   false,
+	})
+}
+
+// bindRuleSet reads a rule set sent as YAML when the Content-Type says so,
+// and as JSON otherwise.
+func (d *deductionRuleHandlerImpl) bindRuleSet(ctx *gin.Context) (models.DeductionRuleSetRequest, bool) {
+	var request models.DeductionRuleSetRequest
+
+	var err error
+	switch ctx.ContentType() {
+	case "application/yaml", "application/x-yaml", "text/yaml":
+		err = ctx.ShouldBindWith(&request, binding.YAML)
+	default:
+		err = ctx.ShouldBindJSON(&request)
+	}
+	if err != nil {
+		ctx.Error(apperror.Binding(err))
+		return models.DeductionRuleSetRequest{}, false
+	}
+	return request, true
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"main.go/internal/apperror"
+	"main.go/internal/models"
+	"main.go/internal/service"
+)
+
+type DepartmentHandler interface {
+	GetDepartments(ctx *gin.Context)
+	GetDepartmentByID(ctx *gin.Context)
+	CreateDepartment(ctx *gin.Context)
+	UpdateDepartment(ctx *gin.Context)
+	DeleteDepartment(ctx *gin.Context)
+}
+
+type departmentHandlerImpl struct {
+	svc service.DepartmentService
+}
+
+func NewDepartmentHandler(svc service.DepartmentService) DepartmentHandler {
+	return &departmentHandlerImpl{svc: svc}
+}
+
+func (d *departmentHandlerImpl) GetDepartments(ctx *gin.Context) {
+	departments, err := d.svc.GetDepartments(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.DepartmentsResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get departments",
+		Data:    &departments,
+		Error:   false,
+	})
+}
+
+func (d *departmentHandlerImpl) GetDepartmentByID(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if id == 0 || err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	department, err := d.svc.GetDepartmentByID(ctx, uint64(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.DepartmentResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get department",
+		Data:    &department,
+		Error:   false,
+	})
+}
+
+func (d *departmentHandlerImpl) CreateDepartment(ctx *gin.Context) {
+	var createDepartmentRequest models.DepartmentRequest
+	if err := ctx.ShouldBindJSON(&createDepartmentRequest); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	department, err := d.svc.CreateDepartment(ctx, createDepartmentRequest)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.DepartmentResponse{
+		Status:  http.StatusOK,
+		Message: "Department created successfully",
+		Data:    &department,
+		Error:   false,
+	})
+}
+
+func (d *departmentHandlerImpl) UpdateDepartment(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if id == 0 || err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	var updateDepartmentRequest models.DepartmentRequest
+	if err := ctx.ShouldBindJSON(&updateDepartmentRequest); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	department, err := d.svc.UpdateDepartment(ctx, uint64(id), updateDepartmentRequest)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.DepartmentResponse{
+		Status:  http.StatusOK,
+		Message: "Department updated successfully",
+		Data:    &department,
+		Error:   false,
+	})
+}
+
+func (d *departmentHandlerImpl) DeleteDepartment(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if id == 0 || err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	if err := d.svc.DeleteDepartment(ctx, uint64(id)); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.DepartmentResponse{
+		Status:  http.StatusOK,
+		Message: "Department deleted successfully",
+		Data:    nil,
+		Error:   false,
+	})
+}
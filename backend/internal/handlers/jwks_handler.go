@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"main.go/internal/auth"
+)
+
+type JWKSHandler interface {
+	GetJWKS(ctx *gin.Context)
+}
+
+type jwksHandlerImpl struct{}
+
+func NewJWKSHandler() JWKSHandler {
+	return &jwksHandlerImpl{}
+}
+
+func (j *jwksHandlerImpl) GetJWKS(ctx *gin.Context) {
+	jwks, err := auth.PublicJWKS()
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, jwks)
+}
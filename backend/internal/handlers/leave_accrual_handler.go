@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"main.go/internal/apperror"
+	"main.go/internal/models"
+	"main.go/internal/service"
+)
+
+type LeaveAccrualHandler interface {
+	GetPolicies(ctx *gin.Context)
+	GetPolicyByID(ctx *gin.Context)
+	CreatePolicy(ctx *gin.Context)
+	UpdatePolicy(ctx *gin.Context)
+	DeletePolicy(ctx *gin.Context)
+
+	GetPositionPolicies(ctx *gin.Context)
+	SetPositionPolicies(ctx *gin.Context)
+
+	RunAccruals(ctx *gin.Context)
+}
+
+type leaveAccrualHandlerImpl struct {
+	svc service.LeaveAccrualService
+}
+
+func NewLeaveAccrualHandler(svc service.LeaveAccrualService) LeaveAccrualHandler {
+	return &leaveAccrualHandlerImpl{svc: svc}
+}
+
+func (l *leaveAccrualHandlerImpl) GetPolicies(ctx *gin.Context) {
+	policies, err := l.svc.GetPolicies(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.LeaveAccrualPoliciesResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get leave policies",
+		Data:    &policies,
+		Error:   false,
+	})
+}
+
+func (l *leaveAccrualHandlerImpl) GetPolicyByID(ctx *gin.Context) {
+	id, ok := l.policyID(ctx)
+	if !ok {
+		return
+	}
+
+	policy, err := l.svc.GetPolicyByID(ctx, id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.LeaveAccrualPolicyResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get leave policy",
+		Data:    &policy,
+		Error:   false,
+	})
+}
+
+func (l *leaveAccrualHandlerImpl) CreatePolicy(ctx *gin.Context) {
+	var createPolicyRequest models.LeaveAccrualPolicyRequest
+	if err := ctx.ShouldBindJSON(&createPolicyRequest); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	policy, err := l.svc.CreatePolicy(ctx, createPolicyRequest)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, models.LeaveAccrualPolicyResponse{
+		Status:  http.StatusCreated,
+		Message: "Leave policy created successfully",
+		Data:    &policy,
+		Error:   false,
+	})
+}
+
+func (l *leaveAccrualHandlerImpl) UpdatePolicy(ctx *gin.Context) {
+	id, ok := l.policyID(ctx)
+	if !ok {
+		return
+	}
+
+	var updatePolicyRequest models.LeaveAccrualPolicyRequest
+	if err := ctx.ShouldBindJSON(&updatePolicyRequest); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	policy, err := l.svc.UpdatePolicy(ctx, id, updatePolicyRequest)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.LeaveAccrualPolicyResponse{
+		Status:  http.StatusOK,
+		Message: "Leave policy updated successfully",
+		Data:    &policy,
+		Error:   false,
+	})
+}
+
+func (l *leaveAccrualHandlerImpl) DeletePolicy(ctx *gin.Context) {
+	id, ok := l.policyID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := l.svc.DeletePolicy(ctx, id); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.LeaveAccrualPolicyResponse{
+		Status:  http.StatusOK,
+		Message: "Leave policy deleted successfully",
+		Data:    nil,
+		Error:   false,
+	})
+}
+
+func (l *leaveAccrualHandlerImpl) GetPositionPolicies(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if id <= 0 || err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	policies, err := l.svc.GetPositionPolicies(ctx, uint64(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.LeaveAccrualPoliciesResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get position leave policies",
+		Data:    &policies,
+		Error:   false,
+	})
+}
+
+func (l *leaveAccrualHandlerImpl) SetPositionPolicies(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if id <= 0 || err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	var policiesRequest models.PositionLeavePoliciesRequest
+	if err := ctx.ShouldBindJSON(&policiesRequest); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	policies, err := l.svc.SetPositionPolicies(ctx, uint64(id), policiesRequest.PolicyIDs)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.LeaveAccrualPoliciesResponse{
+		Status:  http.StatusOK,
+		Message: "Position leave policies updated successfully",
+		Data:    &policies,
+		Error:   false,
+	})
+}
+
+func (l *leaveAccrualHandlerImpl) RunAccruals(ctx *gin.Context) {
+	// The date is optional, so an empty body runs the accruals for today.
+	var runRequest models.AccrualRunRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&runRequest); err != nil {
+			ctx.Error(apperror.Binding(err))
+			return
+		}
+	}
+
+	result, err := l.svc.RunAccruals(ctx, runRequest.Date)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.AccrualRunResponse{
+		Status:  http.StatusOK,
+		Message: "Leave accruals posted successfully",
+		Data:    &result,
+		Error:   false,
+	})
+}
+
+func (l *leaveAccrualHandlerImpl) policyID(ctx *gin.Context) (uint64, bool) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if id <= 0 || err != nil {
+		ctx.Error(errInvalidID)
+		return 0, false
+	}
+	return uint64(id), true
+}
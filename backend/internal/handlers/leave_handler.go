@@ -0,0 +1,349 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"main.go/internal/apperror"
+	"main.go/internal/middleware"
+	"main.go/internal/models"
+	"main.go/internal/service"
+)
+
+type LeaveHandler interface {
+	GetLeaveTypes(ctx *gin.Context)
+	GetMyLeaveBalances(ctx *gin.Context)
+	GetUserLeaveBalances(ctx *gin.Context)
+	AdjustUserLeaveBalance(ctx *gin.Context)
+	GetMyLeaveLedger(ctx *gin.Context)
+	GetUserLeaveLedger(ctx *gin.Context)
+
+	GetMyLeaveRequests(ctx *gin.Context)
+	GetTeamLeaveRequests(ctx *gin.Context)
+	GetLeaveRequests(ctx *gin.Context)
+	GetLeaveRequestByID(ctx *gin.Context)
+	CreateLeaveRequest(ctx *gin.Context)
+	UpdateLeaveRequest(ctx *gin.Context)
+	SubmitLeaveRequest(ctx *gin.Context)
+	ApproveLeaveRequest(ctx *gin.Context)
+	RejectLeaveRequest(ctx *gin.Context)
+	CancelLeaveRequest(ctx *gin.Context)
+}
+
+type leaveHandlerImpl struct {
+	svc service.LeaveService
+}
+
+func NewLeaveHandler(svc service.LeaveService) LeaveHandler {
+	return &leaveHandlerImpl{svc: svc}
+}
+
+func (l *leaveHandlerImpl) GetLeaveTypes(ctx *gin.Context) {
+	leaveTypes, err := l.svc.GetLeaveTypes(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.LeaveTypesResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get leave types",
+		Data:    &leaveTypes,
+		Error:   false,
+	})
+}
+
+func (l *leaveHandlerImpl) GetMyLeaveBalances(ctx *gin.Context) {
+	principal, _ := middleware.CurrentPrincipal(ctx)
+	l.listLeaveBalances(ctx, principal.UserID)
+}
+
+func (l *leaveHandlerImpl) GetUserLeaveBalances(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if id == 0 || err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+	l.listLeaveBalances(ctx, id)
+}
+
+func (l *leaveHandlerImpl) listLeaveBalances(ctx *gin.Context, userID int) {
+	var query models.LeaveBalanceQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	balances, err := l.svc.GetLeaveBalances(ctx, uint64(userID), query.Year)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.LeaveBalancesResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get leave balances",
+		Data:    &balances,
+		Error:   false,
+	})
+}
+
+func (l *leaveHandlerImpl) AdjustUserLeaveBalance(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if id == 0 || err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	var adjustmentRequest models.LeaveAdjustmentRequest
+	if err := ctx.ShouldBindJSON(&adjustmentRequest); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	entry, err := l.svc.AdjustLeaveBalance(ctx, uint64(id), adjustmentRequest)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, models.LeaveLedgerEntryResponse{
+		Status:  http.StatusCreated,
+		Message: "Leave balance adjusted successfully",
+		Data:    &entry,
+		Error:   false,
+	})
+}
+
+func (l *leaveHandlerImpl) GetMyLeaveLedger(ctx *gin.Context) {
+	principal, _ := middleware.CurrentPrincipal(ctx)
+	l.listLeaveLedger(ctx, principal.UserID)
+}
+
+func (l *leaveHandlerImpl) GetUserLeaveLedger(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if id == 0 || err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+	l.listLeaveLedger(ctx, id)
+}
+
+func (l *leaveHandlerImpl) listLeaveLedger(ctx *gin.Context, userID int) {
+	var filter models.LeaveLedgerFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	entries, err := l.svc.GetLedger(ctx, uint64(userID), filter)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.LeaveLedgerResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get leave ledger",
+		Data:    &entries,
+		Error:   false,
+	})
+}
+
+func (l *leaveHandlerImpl) GetMyLeaveRequests(ctx *gin.Context) {
+	principal, _ := middleware.CurrentPrincipal(ctx)
+	l.listLeaveRequests(ctx, func(c context.Context, filter models.LeaveRequestFilter) ([]models.LeaveRequest, error) {
+		filter.UserID = principal.UserID
+		return l.svc.GetLeaveRequests(c, filter)
+	})
+}
+
+func (l *leaveHandlerImpl) GetTeamLeaveRequests(ctx *gin.Context) {
+	principal, _ := middleware.CurrentPrincipal(ctx)
+	l.listLeaveRequests(ctx, func(c context.Context, filter models.LeaveRequestFilter) ([]models.LeaveRequest, error) {
+		return l.svc.GetTeamLeaveRequests(c, uint64(principal.UserID), filter)
+	})
+}
+
+func (l *leaveHandlerImpl) GetLeaveRequests(ctx *gin.Context) {
+	l.listLeaveRequests(ctx, l.svc.GetLeaveRequests)
+}
+
+// listLeaveRequests answers the list endpoints, list decides whose requests
+// are returned.
+func (l *leaveHandlerImpl) listLeaveRequests(ctx *gin.Context, list func(context.Context, models.LeaveRequestFilter) ([]models.LeaveRequest, error)) {
+	var filter models.LeaveRequestFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	requests, err := list(ctx, filter)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.LeaveRequestsResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get leave requests",
+		Data:    &requests,
+		Error:   false,
+	})
+}
+
+func (l *leaveHandlerImpl) GetLeaveRequestByID(ctx *gin.Context) {
+	id, ok := l.requestID(ctx)
+	if !ok {
+		return
+	}
+
+	request, err := l.svc.GetLeaveRequestByID(ctx, id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.LeaveRequestResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get leave request",
+		Data:    &request,
+		Error:   false,
+	})
+}
+
+func (l *leaveHandlerImpl) CreateLeaveRequest(ctx *gin.Context) {
+	var leaveRequest models.LeaveRequestRequest
+	if err := ctx.ShouldBindJSON(&leaveRequest); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	principal, _ := middleware.CurrentPrincipal(ctx)
+	request, err := l.svc.CreateLeaveRequest(ctx, uint64(principal.UserID), leaveRequest)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, models.LeaveRequestResponse{
+		Status:  http.StatusCreated,
+		Message: "Leave request created successfully",
+		Data:    &request,
+		Error:   false,
+	})
+}
+
+func (l *leaveHandlerImpl) UpdateLeaveRequest(ctx *gin.Context) {
+	id, ok := l.requestID(ctx)
+	if !ok {
+		return
+	}
+
+	var leaveRequest models.LeaveRequestRequest
+	if err := ctx.ShouldBindJSON(&leaveRequest); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	request, err := l.svc.UpdateLeaveRequest(ctx, id, leaveRequest)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.LeaveRequestResponse{
+		Status:  http.StatusOK,
+		Message: "Leave request updated successfully",
+		Data:    &request,
+		Error:   false,
+	})
+}
+
+func (l *leaveHandlerImpl) SubmitLeaveRequest(ctx *gin.Context) {
+	id, ok := l.requestID(ctx)
+	if !ok {
+		return
+	}
+
+	request, err := l.svc.SubmitLeaveRequest(ctx, id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.LeaveRequestResponse{
+		Status:  http.StatusOK,
+		Message: "Leave request submitted successfully",
+		Data:    &request,
+		Error:   false,
+	})
+}
+
+func (l *leaveHandlerImpl) ApproveLeaveRequest(ctx *gin.Context) {
+	l.decideLeaveRequest(ctx, l.svc.ApproveLeaveRequest, "Leave request approved successfully")
+}
+
+func (l *leaveHandlerImpl) RejectLeaveRequest(ctx *gin.Context) {
+	l.decideLeaveRequest(ctx, l.svc.RejectLeaveRequest, "Leave request rejected successfully")
+}
+
+func (l *leaveHandlerImpl) decideLeaveRequest(ctx *gin.Context, decide func(context.Context, uint64, string) (models.LeaveRequest, error), message string) {
+	id, ok := l.requestID(ctx)
+	if !ok {
+		return
+	}
+
+	// The note is optional, so an empty body is fine.
+	var decision models.LeaveDecisionRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&decision); err != nil {
+			ctx.Error(apperror.Binding(err))
+			return
+		}
+	}
+
+	request, err := decide(ctx, id, decision.Note)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.LeaveRequestResponse{
+		Status:  http.StatusOK,
+		Message: message,
+		Data:    &request,
+		Error:   false,
+	})
+}
+
+func (l *leaveHandlerImpl) CancelLeaveRequest(ctx *gin.Context) {
+	id, ok := l.requestID(ctx)
+	if !ok {
+		return
+	}
+
+	request, err := l.svc.CancelLeaveRequest(ctx, id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.LeaveRequestResponse{
+		Status:  http.StatusOK,
+		Message: "Leave request cancelled successfully",
+		Data:    &request,
+		Error:   false,
+	})
+}
+
+func (l *leaveHandlerImpl) requestID(ctx *gin.Context) (uint64, bool) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if id <= 0 || err != nil {
+		ctx.Error(errInvalidID)
+		return 0, false
+	}
+	return uint64(id), true
+}
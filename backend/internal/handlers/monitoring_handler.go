@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"main.go/internal/apperror"
+	"main.go/internal/middleware"
+	"main.go/internal/models"
+	"main.go/internal/service"
+)
+
+type MonitoringHandler interface {
+	RecordHeartbeats(ctx *gin.Context)
+	GetTeamStatus(ctx *gin.Context)
+	GetTeamSummary(ctx *gin.Context)
+	GetUserSummary(ctx *gin.Context)
+}
+
+type monitoringHandlerImpl struct {
+	svc service.MonitoringService
+}
+
+func NewMonitoringHandler(svc service.MonitoringService) MonitoringHandler {
+	return &monitoringHandlerImpl{svc: svc}
+}
+
+func (m *monitoringHandlerImpl) RecordHeartbeats(ctx *gin.Context) {
+	var heartbeatsRequest models.HeartbeatsRequest
+	if err := ctx.ShouldBindJSON(&heartbeatsRequest); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	principal, _ := middleware.CurrentPrincipal(ctx)
+	result, err := m.svc.RecordHeartbeats(ctx, uint64(principal.UserID), heartbeatsRequest.Heartbeats)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.HeartbeatsResponse{
+		Status:  http.StatusOK,
+		Message: "Heartbeats recorded successfully",
+		Data:    &result,
+		Error:   false,
+	})
+}
+
+func (m *monitoringHandlerImpl) GetTeamStatus(ctx *gin.Context) {
+	query, ok := m.bindQuery(ctx)
+	if !ok {
+		return
+	}
+
+	statuses, err := m.svc.GetTeamStatus(ctx, uint64(query.ManagerID))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.TeamStatusResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get team status",
+		Data:    &statuses,
+		Error:   false,
+	})
+}
+
+func (m *monitoringHandlerImpl) GetTeamSummary(ctx *gin.Context) {
+	query, ok := m.bindQuery(ctx)
+	if !ok {
+		return
+	}
+
+	summaries, err := m.svc.GetTeamSummary(ctx, uint64(query.ManagerID), query.Date)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.ActivitySummariesResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get team summary",
+		Data:    &summaries,
+		Error:   false,
+	})
+}
+
+func (m *monitoringHandlerImpl) GetUserSummary(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if id == 0 || err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	query, ok := m.bindQuery(ctx)
+	if !ok {
+		return
+	}
+
+	summary, err := m.svc.GetDailySummary(ctx, uint64(id), query.Date)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.ActivitySummaryResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get activity summary",
+		Data:    &summary,
+		Error:   false,
+	})
+}
+
+// bindQuery reads date and manager_id. The manager defaults to the caller.
+func (m *monitoringHandlerImpl) bindQuery(ctx *gin.Context) (models.MonitoringQuery, bool) {
+	var query models.MonitoringQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return query, false
+	}
+
+	if query.ManagerID == 0 {
+		principal, _ := middleware.CurrentPrincipal(ctx)
+		query.ManagerID = principal.UserID
+	}
+	return query, true
+}
@@ -0,0 +1,299 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"main.go/internal/apperror"
+	"main.go/internal/middleware"
+	"main.go/internal/models"
+	"main.go/internal/service"
+)
+
+type OvertimeHandler interface {
+	GetRates(ctx *gin.Context)
+	UpdateRates(ctx *gin.Context)
+
+	GetMyOvertimeRequests(ctx *gin.Context)
+	GetTeamOvertimeRequests(ctx *gin.Context)
+	GetOvertimeRequests(ctx *gin.Context)
+	GetOvertimeRequestByID(ctx *gin.Context)
+	CreateOvertimeRequest(ctx *gin.Context)
+	ApproveOvertimeRequest(ctx *gin.Context)
+	RejectOvertimeRequest(ctx *gin.Context)
+	CancelOvertimeRequest(ctx *gin.Context)
+
+	GetSummary(ctx *gin.Context)
+	GetMySummary(ctx *gin.Context)
+	ExportSummary(ctx *gin.Context)
+}
+
+type overtimeHandlerImpl struct {
+	svc service.OvertimeService
+}
+
+func NewOvertimeHandler(svc service.OvertimeService) OvertimeHandler {
+	return &overtimeHandlerImpl{svc: svc}
+}
+
+func (o *overtimeHandlerImpl) GetRates(ctx *gin.Context) {
+	rates, err := o.svc.GetRates(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.OvertimeRatesResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get overtime rates",
+		Data:    &rates,
+		Error:   false,
+	})
+}
+
+func (o *overtimeHandlerImpl) UpdateRates(ctx *gin.Context) {
+	var ratesRequest models.OvertimeRatesRequest
+	if err := ctx.ShouldBindJSON(&ratesRequest); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	rates, err := o.svc.UpdateRates(ctx, ratesRequest)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.OvertimeRatesResponse{
+		Status:  http.StatusOK,
+		Message: "Overtime rates updated successfully",
+		Data:    &rates,
+		Error:   false,
+	})
+}
+
+func (o *overtimeHandlerImpl) GetMyOvertimeRequests(ctx *gin.Context) {
+	principal, _ := middleware.CurrentPrincipal(ctx)
+	o.listOvertimeRequests(ctx, func(ctx context.Context, filter models.OvertimeFilter) ([]models.OvertimeRequest, error) {
+		filter.UserID = principal.UserID
+		return o.svc.GetOvertimeRequests(ctx, filter)
+	})
+}
+
+func (o *overtimeHandlerImpl) GetTeamOvertimeRequests(ctx *gin.Context) {
+	principal, _ := middleware.CurrentPrincipal(ctx)
+	o.listOvertimeRequests(ctx, func(ctx context.Context, filter models.OvertimeFilter) ([]models.OvertimeRequest, error) {
+		return o.svc.GetTeamOvertimeRequests(ctx, uint64(principal.UserID), filter)
+	})
+}
+
+func (o *overtimeHandlerImpl) GetOvertimeRequests(ctx *gin.Context) {
+	o.listOvertimeRequests(ctx, o.svc.GetOvertimeRequests)
+}
+
+func (o *overtimeHandlerImpl) listOvertimeRequests(ctx *gin.Context, list func(context.Context, models.OvertimeFilter) ([]models.OvertimeRequest, error)) {
+	var filter models.OvertimeFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	requests, err := list(ctx, filter)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.OvertimeRequestsResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get overtime requests",
+		Data:    &requests,
+		Error:   false,
+	})
+}
+
+func (o *overtimeHandlerImpl) GetOvertimeRequestByID(ctx *gin.Context) {
+	id, ok := o.requestID(ctx)
+	if !ok {
+		return
+	}
+
+	request, err := o.svc.GetOvertimeRequestByID(ctx, id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.OvertimeRequestResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get overtime request",
+		Data:    &request,
+		Error:   false,
+	})
+}
+
+func (o *overtimeHandlerImpl) CreateOvertimeRequest(ctx *gin.Context) {
+	var createRequest models.OvertimeRequestRequest
+	if err := ctx.ShouldBindJSON(&createRequest); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	principal, _ := middleware.CurrentPrincipal(ctx)
+	request, err := o.svc.CreateOvertimeRequest(ctx, uint64(principal.UserID), createRequest)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.OvertimeRequestResponse{
+		Status:  http.StatusOK,
+		Message: "Overtime request created successfully",
+		Data:    &request,
+		Error:   false,
+	})
+}
+
+func (o *overtimeHandlerImpl) ApproveOvertimeRequest(ctx *gin.Context) {
+	o.decideOvertimeRequest(ctx, o.svc.ApproveOvertimeRequest, "Overtime request approved successfully")
+}
+
+func (o *overtimeHandlerImpl) RejectOvertimeRequest(ctx *gin.Context) {
+	o.decideOvertimeRequest(ctx, o.svc.RejectOvertimeRequest, "Overtime request rejected successfully")
+}
+
+func (o *overtimeHandlerImpl) decideOvertimeRequest(ctx *gin.Context, decide func(context.Context, uint64, string) (models.OvertimeRequest, error), message string) {
+	id, ok := o.requestID(ctx)
+	if !ok {
+		return
+	}
+
+	// The note is optional, so an empty body is fine.
+	var decision models.OvertimeDecisionRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&decision); err != nil {
+			ctx.Error(apperror.Binding(err))
+			return
+		}
+	}
+
+	request, err := decide(ctx, id, decision.Note)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.OvertimeRequestResponse{
+		Status:  http.StatusOK,
+		Message: message,
+		Data:    &request,
+		Error:   false,
+	})
+}
+
+func (o *overtimeHandlerImpl) CancelOvertimeRequest(ctx *gin.Context) {
+	id, ok := o.requestID(ctx)
+	if !ok {
+		return
+	}
+
+	request, err := o.svc.CancelOvertimeRequest(ctx, id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.OvertimeRequestResponse{
+		Status:  http.StatusOK,
+		Message: "Overtime request cancelled successfully",
+		Data:    &request,
+		Error:   false,
+	})
+}
+
+func (o *overtimeHandlerImpl) GetSummary(ctx *gin.Context) {
+	o.summary(ctx, 0)
+}
+
+func (o *overtimeHandlerImpl) GetMySummary(ctx *gin.Context) {
+	principal, _ := middleware.CurrentPrincipal(ctx)
+	o.summary(ctx, uint64(principal.UserID))
+}
+
+func (o *overtimeHandlerImpl) summary(ctx *gin.Context, userID uint64) {
+	var query models.OvertimeSummaryQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	summaries, err := o.svc.GetMonthlySummary(ctx, query.Month, userID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.OvertimeSummariesResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get overtime summary",
+		Data:    &summaries,
+		Error:   false,
+	})
+}
+
+// ExportSummary writes the monthly summary of every user as CSV for
+// payroll.
+func (o *overtimeHandlerImpl) ExportSummary(ctx *gin.Context) {
+	var query models.OvertimeSummaryQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	summaries, err := o.svc.GetMonthlySummary(ctx, query.Month, 0)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	month := query.Month
+	if month == "" {
+		month = "current"
+	}
+	ctx.Header("Content-Type", "text/csv; charset=utf-8")
+	ctx.Header("Content-Disposition", `attachment; filename="overtime-`+month+`.csv"`)
+	ctx.Status(http.StatusOK)
+
+	writer := csv.NewWriter(ctx.Writer)
+	writer.Write([]string{
+		"user_id", "firstname", "lastname", "email", "month",
+		"weekday_minutes", "weekend_minutes", "holiday_minutes", "total_minutes", "weighted_hours",
+	})
+	for _, summary := range summaries {
+		writer.Write([]string{
+			strconv.Itoa(summary.UserID),
+			summary.Firstname,
+			summary.Lastname,
+			summary.Email,
+			summary.Month,
+			strconv.Itoa(summary.WeekdayMinutes),
+			strconv.Itoa(summary.WeekendMinutes),
+			strconv.Itoa(summary.HolidayMinutes),
+			strconv.Itoa(summary.TotalMinutes),
+			strconv.FormatFloat(summary.WeightedHours, 'f', 2, 64),
+		})
+	}
+	writer.Flush()
+}
+
+func (o *overtimeHandlerImpl) requestID(ctx *gin.Context) (uint64, bool) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if id <= 0 || err != nil {
+		ctx.Error(errInvalidID)
+		return 0, false
+	}
+	return uint64(id), true
+}
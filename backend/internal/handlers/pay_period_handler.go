@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"main.go/internal/apperror"
+	"main.go/internal/models"
+	"main.go/internal/service"
+)
+
+type PayPeriodHandler interface {
+	GetPayPeriods(ctx *gin.Context)
+	GetPayPeriodByID(ctx *gin.Context)
+	GetPayPeriodEvents(ctx *gin.Context)
+	CreatePayPeriod(ctx *gin.Context)
+	ClosePayPeriod(ctx *gin.Context)
+	ReopenPayPeriod(ctx *gin.Context)
+}
+
+type payPeriodHandlerImpl struct {
+	svc service.PayPeriodService
+}
+
+func NewPayPeriodHandler(svc service.PayPeriodService) PayPeriodHandler {
+	return &payPeriodHandlerImpl{svc: svc}
+}
+
+func (p *payPeriodHandlerImpl) GetPayPeriods(ctx *gin.Context) {
+	var filter models.PayPeriodFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	periods, err := p.svc.GetPayPeriods(ctx, filter)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.PayPeriodsResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get pay periods",
+		Data:    &periods,
+		Error:   false,
+	})
+}
+
+func (p *payPeriodHandlerImpl) GetPayPeriodByID(ctx *gin.Context) {
+	id, ok := uintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	period, err := p.svc.GetPayPeriodByID(ctx, id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.PayPeriodResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get pay period",
+		Data:    &period,
+		Error:   false,
+	})
+}
+
+func (p *payPeriodHandlerImpl) GetPayPeriodEvents(ctx *gin.Context) {
+	id, ok := uintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	events, err := p.svc.GetPayPeriodEvents(ctx, id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.PayPeriodEventsResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get pay period events",
+		Data:    &events,
+		Error:   false,
+	})
+}
+
+func (p *payPeriodHandlerImpl) CreatePayPeriod(ctx *gin.Context) {
+	var createPayPeriodRequest models.PayPeriodRequest
+	if err := ctx.ShouldBindJSON(&createPayPeriodRequest); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	period, err := p.svc.CreatePayPeriod(ctx, createPayPeriodRequest)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.PayPeriodResponse{
+		Status:  http.StatusOK,
+		Message: "Pay period created successfully",
+		Data:    &period,
+		Error:   false,
+	})
+}
+
+func (p *payPeriodHandlerImpl) ClosePayPeriod(ctx *gin.Context) {
+	p.changePayPeriod(ctx, p.svc.ClosePayPeriod, "Pay period closed successfully")
+}
+
+func (p *payPeriodHandlerImpl) ReopenPayPeriod(ctx *gin.Context) {
+	p.changePayPeriod(ctx, p.svc.ReopenPayPeriod, "Pay period reopened successfully")
+}
+
+func (p *payPeriodHandlerImpl) changePayPeriod(ctx *gin.Context, change func(context.Context, uint64, string) (models.PayPeriod, error), message string) {
+	id, ok := uintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	// Closing doesn't need a reason, so an empty body is fine. The service
+	// insists on one for reopening.
+	var action models.PayPeriodActionRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&action); err != nil {
+			ctx.Error(apperror.Binding(err))
+			return
+		}
+	}
+
+	period, err := change(ctx, id, action.Reason)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.PayPeriodResponse{
+		Status:  http.StatusOK,
+		Message: message,
+		Data:    &period,
+		Error:   false,
+	})
+}
@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"main.go/internal/apperror"
+	"main.go/internal/middleware"
+	"main.go/internal/models"
+	"main.go/internal/payroll"
+	"main.go/internal/service"
+)
+
+type PayrollHandler interface {
+	GetPayrollRuns(ctx *gin.Context)
+	GetPayrollRunByID(ctx *gin.Context)
+	CreatePayrollRun(ctx *gin.Context)
+	DeletePayrollRun(ctx *gin.Context)
+	CalculatePayrollRun(ctx *gin.Context)
+	FinalizePayrollRun(ctx *gin.Context)
+	GetRunPayslips(ctx *gin.Context)
+	ExportBankTransfers(ctx *gin.Context)
+
+	GetMyPayslips(ctx *gin.Context)
+	GetPayslipByID(ctx *gin.Context)
+	GetPayslipPDF(ctx *gin.Context)
+
+	GetBankAccount(ctx *gin.Context)
+	SaveBankAccount(ctx *gin.Context)
+}
+
+type payrollHandlerImpl struct {
+	svc service.PayrollService
+}
+
+func NewPayrollHandler(svc service.PayrollService) PayrollHandler {
+	return &payrollHandlerImpl{svc: svc}
+}
+
+func (p *payrollHandlerImpl) GetPayrollRuns(ctx *gin.Context) {
+	var filter models.PayrollRunFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	runs, err := p.svc.GetPayrollRuns(ctx, filter)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.PayrollRunsResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get payroll runs",
+		Data:    &runs,
+		Error:   false,
+	})
+}
+
+func (p *payrollHandlerImpl) GetPayrollRunByID(ctx *gin.Context) {
+	id, ok := uintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	run, err := p.svc.GetPayrollRunByID(ctx, id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.PayrollRunResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get payroll run",
+		Data:    &run,
+		Error:   false,
+	})
+}
+
+func (p *payrollHandlerImpl) CreatePayrollRun(ctx *gin.Context) {
+	var createRunRequest models.PayrollRunRequest
+	if err := ctx.ShouldBindJSON(&createRunRequest); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	run, err := p.svc.CreatePayrollRun(ctx, createRunRequest)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.PayrollRunResponse{
+		Status:  http.StatusOK,
+		Message: "Payroll run created successfully",
+		Data:    &run,
+		Error:   false,
+	})
+}
+
+func (p *payrollHandlerImpl) DeletePayrollRun(ctx *gin.Context) {
+	id, ok := uintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	if err := p.svc.DeletePayrollRun(ctx, id); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.PayrollRunResponse{
+		Status:  http.StatusOK,
+		Message: "Payroll run deleted successfully",
+		Data:    nil,
+		Error:   false,
+	})
+}
+
+func (p *payrollHandlerImpl) CalculatePayrollRun(ctx *gin.Context) {
+	id, ok := uintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	run, err := p.svc.CalculatePayrollRun(ctx, id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.PayrollRunResponse{
+		Status:  http.StatusOK,
+		Message: "Payroll run calculated successfully",
+		Data:    &run,
+		Error:   false,
+	})
+}
+
+func (p *payrollHandlerImpl) FinalizePayrollRun(ctx *gin.Context) {
+	id, ok := uintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	run, err := p.svc.FinalizePayrollRun(ctx, id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.PayrollRunResponse{
+		Status:  http.StatusOK,
+		Message: "Payroll run finalized successfully",
+		Data:    &run,
+		Error:   false,
+	})
+}
+
+func (p *payrollHandlerImpl) GetRunPayslips(ctx *gin.Context) {
+	id, ok := uintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	payslips, err := p.svc.GetRunPayslips(ctx, id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.PayslipsResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get payslips",
+		Data:    &payslips,
+		Error:   false,
+	})
+}
+
+// ExportBankTransfers writes the net pay of a finalized run as CSV for the
+// bank.
+func (p *payrollHandlerImpl) ExportBankTransfers(ctx *gin.Context) {
+	id, ok := uintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	transfers, err := p.svc.GetBankTransfers(ctx, id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Header("Content-Type", "text/csv; charset=utf-8")
+	ctx.Header("Content-Disposition", `attachment; filename="payroll-run-`+strconv.FormatUint(id, 10)+`-bank.csv"`)
+	ctx.Status(http.StatusOK)
+
+	writer := csv.NewWriter(ctx.Writer)
+	writer.Write([]string{
+		"user_id", "account_holder", "bank_name", "account_number", "amount", "currency", "reference",
+	})
+	for _, transfer := range transfers {
+		writer.Write([]string{
+			strconv.Itoa(transfer.Payslip.UserID),
+			transfer.Account.AccountHolder,
+			transfer.Account.BankName,
+			transfer.Account.AccountNumber,
+			transfer.Payslip.NetPay.StringFixed(2),
+			transfer.Payslip.Currency,
+			"PAYSLIP-" + strconv.Itoa(transfer.Payslip.ID),
+		})
+	}
+	writer.Flush()
+}
+
+func (p *payrollHandlerImpl) GetMyPayslips(ctx *gin.Context) {
+	principal, _ := middleware.CurrentPrincipal(ctx)
+
+	payslips, err := p.svc.GetUserPayslips(ctx, uint64(principal.UserID))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.PayslipsResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get payslips",
+		Data:    &payslips,
+		Error:   false,
+	})
+}
+
+func (p *payrollHandlerImpl) GetPayslipByID(ctx *gin.Context) {
+	id, ok := uintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	payslip, err := p.svc.GetPayslipByID(ctx, id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.PayslipResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get payslip",
+		Data:    &payslip,
+		Error:   false,
+	})
+}
+
+func (p *payrollHandlerImpl) GetPayslipPDF(ctx *gin.Context) {
+	id, ok := uintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	payslip, err := p.svc.GetFinalizedPayslip(ctx, id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	// Rendered into a buffer first, so a failure can still be answered with
+	// JSON.
+	var buf bytes.Buffer
+	if err := payroll.WritePayslipPDF(&buf, payslip); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Header("Content-Disposition", `attachment; filename="payslip-`+strconv.FormatUint(id, 10)+`.pdf"`)
+	ctx.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
+
+func (p *payrollHandlerImpl) GetBankAccount(ctx *gin.Context) {
+	id, ok := uintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	account, err := p.svc.GetBankAccount(ctx, id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.BankAccountResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get bank account",
+		Data:    &account,
+		Error:   false,
+	})
+}
+
+func (p *payrollHandlerImpl) SaveBankAccount(ctx *gin.Context) {
+	id, ok := uintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var bankAccountRequest models.BankAccountRequest
+	if err := ctx.ShouldBindJSON(&bankAccountRequest); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	account, err := p.svc.SaveBankAccount(ctx, id, bankAccountRequest)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.BankAccountResponse{
+		Status:  http.StatusOK,
+		Message: "Bank account saved successfully",
+		Data:    &account,
+		Error:   false,
+	})
+}
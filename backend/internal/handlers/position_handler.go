@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"main.go/internal/apperror"
+	"main.go/internal/models"
+	"main.go/internal/service"
+)
+
+type PositionHandler interface {
+	GetPositions(ctx *gin.Context)
+	GetPositionByID(ctx *gin.Context)
+	CreatePosition(ctx *gin.Context)
+	UpdatePosition(ctx *gin.Context)
+	DeletePosition(ctx *gin.Context)
+}
+
+type positionHandlerImpl struct {
+	svc service.PositionService
+}
+
+func NewPositionHandler(svc service.PositionService) PositionHandler {
+	return &positionHandlerImpl{svc: svc}
+}
+
+func (p *positionHandlerImpl) GetPositions(ctx *gin.Context) {
+	positions, err := p.svc.GetPositions(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.PositionsResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get positions",
+		Data:    &positions,
+		Error:   false,
+	})
+}
+
+func (p *positionHandlerImpl) GetPositionByID(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if id == 0 || err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	position, err := p.svc.GetPositionByID(ctx, uint64(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.PositionResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get position",
+		Data:    &position,
+		Error:   false,
+	})
+}
+
+func (p *positionHandlerImpl) CreatePosition(ctx *gin.Context) {
+	var createPositionRequest models.PositionRequest
+	if err := ctx.ShouldBindJSON(&createPositionRequest); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	position, err := p.svc.CreatePosition(ctx, createPositionRequest)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.PositionResponse{
+		Status:  http.StatusOK,
+		Message: "Position created successfully",
+		Data:    &position,
+		Error:   false,
+	})
+}
+
+func (p *positionHandlerImpl) UpdatePosition(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if id == 0 || err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	var updatePositionRequest models.PositionRequest
+	if err := ctx.ShouldBindJSON(&updatePositionRequest); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	position, err := p.svc.UpdatePosition(ctx, uint64(id), updatePositionRequest)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.PositionResponse{
+		Status:  http.StatusOK,
+		Message: "Position updated successfully",
+		Data:    &position,
+		Error:   false,
+	})
+}
+
+func (p *positionHandlerImpl) DeletePosition(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if id == 0 || err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	if err := p.svc.DeletePosition(ctx, uint64(id)); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.PositionResponse{
+		Status:  http.StatusOK,
+		Message: "Position deleted successfully",
+		Data:    nil,
+		Error:   false,
+	})
+}
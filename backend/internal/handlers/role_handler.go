@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"main.go/internal/apperror"
+	"main.go/internal/models"
+	"main.go/internal/service"
+)
+
+type RoleHandler interface {
+	GetRoles(ctx *gin.Context)
+	GetRoleByID(ctx *gin.Context)
+	CreateRole(ctx *gin.Context)
+	UpdateRole(ctx *gin.Context)
+	DeleteRole(ctx *gin.Context)
+}
+
+type roleHandlerImpl struct {
+	svc service.RoleService
+}
+
+func NewRoleHandler(svc service.RoleService) RoleHandler {
+	return &roleHandlerImpl{svc: svc}
+}
+
+func (r *roleHandlerImpl) GetRoles(ctx *gin.Context) {
+	roles, err := r.svc.GetRoles(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.RolesResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get roles",
+		Data:    &roles,
+		Error:   false,
+	})
+}
+
+func (r *roleHandlerImpl) GetRoleByID(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if id == 0 || err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	role, err := r.svc.GetRoleByID(ctx, uint64(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.RoleResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get role",
+		Data:    &role,
+		Error:   false,
+	})
+}
+
+func (r *roleHandlerImpl) CreateRole(ctx *gin.Context) {
+	var createRoleRequest models.RoleRequest
+	if err := ctx.ShouldBindJSON(&createRoleRequest); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	role, err := r.svc.CreateRole(ctx, createRoleRequest)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.RoleResponse{
+		Status:  http.StatusOK,
+		Message: "Role created successfully",
+		Data:    &role,
+		Error:   false,
+	})
+}
+
+func (r *roleHandlerImpl) UpdateRole(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if id == 0 || err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	var updateRoleRequest models.RoleRequest
+	if err := ctx.ShouldBindJSON(&updateRoleRequest); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	role, err := r.svc.UpdateRole(ctx, uint64(id), updateRoleRequest)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.RoleResponse{
+		Status:  http.StatusOK,
+		Message: "Role updated successfully",
+		Data:    &role,
+		Error:   false,
+	})
+}
+
+func (r *roleHandlerImpl) DeleteRole(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if id == 0 || err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	if err := r.svc.DeleteRole(ctx, uint64(id)); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.RoleResponse{
+		Status:  http.StatusOK,
+		Message: "Role deleted successfully",
+		Data:    nil,
+		Error:   false,
+	})
+}
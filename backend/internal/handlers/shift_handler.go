@@ -0,0 +1,289 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"main.go/internal/apperror"
+	"main.go/internal/middleware"
+	"main.go/internal/models"
+	"main.go/internal/service"
+)
+
+type ShiftHandler interface {
+	GetShifts(ctx *gin.Context)
+	GetShiftByID(ctx *gin.Context)
+	CreateShift(ctx *gin.Context)
+	UpdateShift(ctx *gin.Context)
+	DeleteShift(ctx *gin.Context)
+
+	GetAssignments(ctx *gin.Context)
+	GetMyAssignments(ctx *gin.Context)
+	GetUserAssignments(ctx *gin.Context)
+	CreateAssignment(ctx *gin.Context)
+	UpdateAssignment(ctx *gin.Context)
+	DeleteAssignment(ctx *gin.Context)
+
+	GetRoster(ctx *gin.Context)
+	GetConflicts(ctx *gin.Context)
+}
+
+type shiftHandlerImpl struct {
+	svc service.ShiftService
+}
+
+func NewShiftHandler(svc service.ShiftService) ShiftHandler {
+	return &shiftHandlerImpl{svc: svc}
+}
+
+func (s *shiftHandlerImpl) GetShifts(ctx *gin.Context) {
+	shifts, err := s.svc.GetShifts(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.ShiftsResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get shifts",
+		Data:    &shifts,
+		Error:   false,
+	})
+}
+
+func (s *shiftHandlerImpl) GetShiftByID(ctx *gin.Context) {
+	id, ok := uintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	shift, err := s.svc.GetShiftByID(ctx, id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.ShiftResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get shift",
+		Data:    &shift,
+		Error:   false,
+	})
+}
+
+func (s *shiftHandlerImpl) CreateShift(ctx *gin.Context) {
+	var createShiftRequest models.ShiftRequest
+	if err := ctx.ShouldBindJSON(&createShiftRequest); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	shift, err := s.svc.CreateShift(ctx, createShiftRequest)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.ShiftResponse{
+		Status:  http.StatusOK,
+		Message: "Shift created successfully",
+		Data:    &shift,
+		Error:   false,
+	})
+}
+
+func (s *shiftHandlerImpl) UpdateShift(ctx *gin.Context) {
+	id, ok := uintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var updateShiftRequest models.ShiftRequest
+	if err := ctx.ShouldBindJSON(&updateShiftRequest); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	shift, err := s.svc.UpdateShift(ctx, id, updateShiftRequest)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.ShiftResponse{
+		Status:  http.StatusOK,
+		Message: "Shift updated successfully",
+		Data:    &shift,
+		Error:   false,
+	})
+}
+
+func (s *shiftHandlerImpl) DeleteShift(ctx *gin.Context) {
+	id, ok := uintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	if err := s.svc.DeleteShift(ctx, id); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.ShiftResponse{
+		Status:  http.StatusOK,
+		Message: "Shift deleted successfully",
+		Data:    nil,
+		Error:   false,
+	})
+}
+
+func (s *shiftHandlerImpl) GetAssignments(ctx *gin.Context) {
+	s.listAssignments(ctx, 0)
+}
+
+func (s *shiftHandlerImpl) GetMyAssignments(ctx *gin.Context) {
+	principal, _ := middleware.CurrentPrincipal(ctx)
+	s.listAssignments(ctx, principal.UserID)
+}
+
+func (s *shiftHandlerImpl) GetUserAssignments(ctx *gin.Context) {
+	id, ok := uintParam(ctx, "id")
+	if !ok {
+		return
+	}
+	s.listAssignments(ctx, int(id))
+}
+
+// listAssignments answers the assignment list endpoints. A non-zero userID
+// overrides any user_id query parameter.
+func (s *shiftHandlerImpl) listAssignments(ctx *gin.Context, userID int) {
+	var filter models.ShiftAssignmentFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+	if userID != 0 {
+		filter.UserID = userID
+	}
+
+	assignments, err := s.svc.GetAssignments(ctx, filter)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.ShiftAssignmentsResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get shift assignments",
+		Data:    &assignments,
+		Error:   false,
+	})
+}
+
+func (s *shiftHandlerImpl) CreateAssignment(ctx *gin.Context) {
+	var createAssignmentRequest models.ShiftAssignmentRequest
+	if err := ctx.ShouldBindJSON(&createAssignmentRequest); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	assignment, err := s.svc.CreateAssignment(ctx, createAssignmentRequest)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.ShiftAssignmentResponse{
+		Status:  http.StatusOK,
+		Message: "Shift assigned successfully",
+		Data:    &assignment,
+		Error:   false,
+	})
+}
+
+func (s *shiftHandlerImpl) UpdateAssignment(ctx *gin.Context) {
+	id, ok := uintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var updateAssignmentRequest models.ShiftAssignmentRequest
+	if err := ctx.ShouldBindJSON(&updateAssignmentRequest); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	assignment, err := s.svc.UpdateAssignment(ctx, id, updateAssignmentRequest)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.ShiftAssignmentResponse{
+		Status:  http.StatusOK,
+		Message: "Shift assignment updated successfully",
+		Data:    &assignment,
+		Error:   false,
+	})
+}
+
+func (s *shiftHandlerImpl) DeleteAssignment(ctx *gin.Context) {
+	id, ok := uintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	if err := s.svc.DeleteAssignment(ctx, id); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.ShiftAssignmentResponse{
+		Status:  http.StatusOK,
+		Message: "Shift assignment deleted successfully",
+		Data:    nil,
+		Error:   false,
+	})
+}
+
+func (s *shiftHandlerImpl) GetRoster(ctx *gin.Context) {
+	var query models.RosterQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	roster, err := s.svc.GetRoster(ctx, query)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.RosterResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get roster",
+		Data:    &roster,
+		Error:   false,
+	})
+}
+
+func (s *shiftHandlerImpl) GetConflicts(ctx *gin.Context) {
+	var query models.ShiftConflictQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	conflicts, err := s.svc.GetConflicts(ctx, query)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.ShiftConflictsResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get shift conflicts",
+		Data:    &conflicts,
+		Error:   false,
+	})
+}
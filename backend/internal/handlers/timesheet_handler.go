@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"main.go/internal/apperror"
+	"main.go/internal/middleware"
+	"main.go/internal/models"
+	"main.go/internal/service"
+)
+
+type TimesheetHandler interface {
+	GetMyTimesheets(ctx *gin.Context)
+	GetTeamTimesheets(ctx *gin.Context)
+	GetTimesheets(ctx *gin.Context)
+	GetTimesheetByID(ctx *gin.Context)
+	CreateTimesheet(ctx *gin.Context)
+	UpdateEntries(ctx *gin.Context)
+
+	SubmitTimesheet(ctx *gin.Context)
+	ApproveTimesheet(ctx *gin.Context)
+	RejectTimesheet(ctx *gin.Context)
+}
+
+type timesheetHandlerImpl struct {
+	svc service.TimesheetService
+}
+
+func NewTimesheetHandler(svc service.TimesheetService) TimesheetHandler {
+	return &timesheetHandlerImpl{svc: svc}
+}
+
+func (t *timesheetHandlerImpl) GetMyTimesheets(ctx *gin.Context) {
+	principal, _ := middleware.CurrentPrincipal(ctx)
+	t.listTimesheets(ctx, func(ctx context.Context, filter models.TimesheetFilter) ([]models.Timesheet, error) {
+		filter.UserID = principal.UserID
+		return t.svc.GetTimesheets(ctx, filter)
+	})
+}
+
+func (t *timesheetHandlerImpl) GetTeamTimesheets(ctx *gin.Context) {
+	principal, _ := middleware.CurrentPrincipal(ctx)
+	t.listTimesheets(ctx, func(ctx context.Context, filter models.TimesheetFilter) ([]models.Timesheet, error) {
+		return t.svc.GetTeamTimesheets(ctx, uint64(principal.UserID), filter)
+	})
+}
+
+func (t *timesheetHandlerImpl) GetTimesheets(ctx *gin.Context) {
+	t.listTimesheets(ctx, t.svc.GetTimesheets)
+}
+
+func (t *timesheetHandlerImpl) listTimesheets(ctx *gin.Context, list func(context.Context, models.TimesheetFilter) ([]models.Timesheet, error)) {
+	var filter models.TimesheetFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	timesheets, err := list(ctx, filter)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.TimesheetsResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get timesheets",
+		Data:    &timesheets,
+		Error:   false,
+	})
+}
+
+func (t *timesheetHandlerImpl) GetTimesheetByID(ctx *gin.Context) {
+	id, ok := uintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	timesheet, err := t.svc.GetTimesheetByID(ctx, id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.TimesheetResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get timesheet",
+		Data:    &timesheet,
+		Error:   false,
+	})
+}
+
+func (t *timesheetHandlerImpl) CreateTimesheet(ctx *gin.Context) {
+	// The period is optional, so an empty body starts the current one.
+	var createTimesheetRequest models.TimesheetRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&createTimesheetRequest); err != nil {
+			ctx.Error(apperror.Binding(err))
+			return
+		}
+	}
+
+	principal, _ := middleware.CurrentPrincipal(ctx)
+	timesheet, err := t.svc.CreateTimesheet(ctx, uint64(principal.UserID), createTimesheetRequest)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.TimesheetResponse{
+		Status:  http.StatusOK,
+		Message: "Timesheet created successfully",
+		Data:    &timesheet,
+		Error:   false,
+	})
+}
+
+func (t *timesheetHandlerImpl) UpdateEntries(ctx *gin.Context) {
+	id, ok := uintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var entriesRequest models.TimesheetEntriesRequest
+	if err := ctx.ShouldBindJSON(&entriesRequest); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	timesheet, err := t.svc.UpdateEntries(ctx, id, entriesRequest)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.TimesheetResponse{
+		Status:  http.StatusOK,
+		Message: "Timesheet updated successfully",
+		Data:    &timesheet,
+		Error:   false,
+	})
+}
+
+func (t *timesheetHandlerImpl) SubmitTimesheet(ctx *gin.Context) {
+	id, ok := uintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	timesheet, err := t.svc.SubmitTimesheet(ctx, id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.TimesheetResponse{
+		Status:  http.StatusOK,
+		Message: "Timesheet submitted successfully",
+		Data:    &timesheet,
+		Error:   false,
+	})
+}
+
+func (t *timesheetHandlerImpl) ApproveTimesheet(ctx *gin.Context) {
+	t.decideTimesheet(ctx, t.svc.ApproveTimesheet, "Timesheet approved successfully")
+}
+
+func (t *timesheetHandlerImpl) RejectTimesheet(ctx *gin.Context) {
+	t.decideTimesheet(ctx, t.svc.RejectTimesheet, "Timesheet rejected successfully")
+}
+
+func (t *timesheetHandlerImpl) decideTimesheet(ctx *gin.Context, decide func(context.Context, uint64, string) (models.Timesheet, error), message string) {
+	id, ok := uintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	// The note is optional, so an empty body is fine.
+	var decision models.TimesheetDecisionRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&decision); err != nil {
+			ctx.Error(apperror.Binding(err))
+			return
+		}
+	}
+
+	timesheet, err := decide(ctx, id, decision.Note)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.TimesheetResponse{
+		Status:  http.StatusOK,
+		Message: message,
+		Data:    &timesheet,
+		Error:   false,
+	})
+}
@@ -6,6 +6,8 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"main.go/internal/apperror"
+	"main.go/internal/middleware"
 	"main.go/internal/models"
 	"main.go/internal/service"
 )
@@ -15,10 +17,19 @@ type UserHandler interface {
 	GetUserByID(ctx *gin.Context)
 	CreateUser(ctx *gin.Context)
 	UpdateUser(ctx *gin.Context)
+	PatchUser(ctx *gin.Context)
 	DeleteUser(ctx *gin.Context)
+	GetDeletedUsers(ctx *gin.Context)
+	RestoreUser(ctx *gin.Context)
+	PurgeUser(ctx *gin.Context)
+	GetReports(ctx *gin.Context)
+	GetOrgChart(ctx *gin.Context)
+	MoveUserToDepartment(ctx *gin.Context)
+	SetUserManager(ctx *gin.Context)
 
 	LoginUser(ctx *gin.Context)
 	LogoutUser(ctx *gin.Context)
+	RefreshToken(ctx *gin.Context)
 }
 
 type userHandlerImpl struct {
@@ -30,14 +41,15 @@ func NewUserHandler(svc service.UserService) UserHandler {
 }
 
 func (u *userHandlerImpl) GetUsers(ctx *gin.Context) {
-	users, err := u.svc.GetUsers(ctx)
+	var filter models.UserFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	users, meta, err := u.svc.GetUsers(ctx, filter)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, models.UsersResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to get users",
-			Data:    nil,
-			Error:   true,
-		})
+		ctx.Error(err)
 		return
 	}
 
@@ -46,6 +58,7 @@ func (u *userHandlerImpl) GetUsers(ctx *gin.Context) {
 			Status:  http.StatusNotFound,
 			Message: "Users not found",
 			Data:    nil,
+			Meta:    &meta,
 			Error:   false,
 		})
 		return
@@ -54,6 +67,7 @@ func (u *userHandlerImpl) GetUsers(ctx *gin.Context) {
 		Status:  http.StatusOK,
 		Message: "Success to get users",
 		Data:    &users,
+		Meta:    &meta,
 		Error:   false,
 	})
 }
@@ -61,35 +75,17 @@ func (u *userHandlerImpl) GetUsers(ctx *gin.Context) {
 func (u *userHandlerImpl) GetUserByID(ctx *gin.Context) {
 	id, err := strconv.Atoi(ctx.Param("id"))
 	if id == 0 || err != nil {
-		ctx.JSON(http.StatusBadRequest, models.UserResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid or missing ID parameter",
-			Data:    nil,
-			Error:   true,
-		})
+		ctx.Error(errInvalidID)
 		return
 	}
 
 	user, err := u.svc.GetUserByID(ctx, uint64(id))
 	if err != nil {
-		if err.Error() == "user not found" {
-			ctx.JSON(http.StatusNotFound, models.UserResponse{
-				Status:  http.StatusNotFound,
-				Message: "User not found",
-				Data:    nil,
-				Error:   true,
-			})
-		} else {
-			ctx.JSON(http.StatusInternalServerError, models.UserResponse{
-				Status:  http.StatusInternalServerError,
-				Message: "Failed to get user",
-				Data:    nil,
-				Error:   true,
-			})
-		}
+		ctx.Error(err)
 		return
 	}
 
+	ctx.Header("ETag", userETag(user))
 	ctx.JSON(http.StatusOK, models.UserResponse{
 		Status:  http.StatusOK,
 		Message: "Success to get user",
@@ -101,29 +97,12 @@ func (u *userHandlerImpl) GetUserByID(ctx *gin.Context) {
 func (u *userHandlerImpl) CreateUser(ctx *gin.Context) {
 	var createUserRequest models.UserRequest
 	if err := ctx.ShouldBindJSON(&createUserRequest); err != nil {
-		ctx.JSON(http.StatusBadRequest, models.UserResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid request data",
-			Data:    nil,
-			Error:   true,
-		})
+		ctx.Error(apperror.Binding(err))
 		return
 	}
 	userResponse, err := u.svc.CreateUser(ctx, createUserRequest)
 	if err != nil {
-		statusCode := http.StatusInternalServerError
-		if err.Error() == "email already exists" ||
-			err.Error() == "role not found" ||
-			err.Error() == "position not found" {
-			statusCode = http.StatusBadRequest
-		}
-
-		ctx.JSON(statusCode, models.UserResponse{
-			Status:  statusCode,
-			Message: err.Error(),
-			Data:    nil,
-			Error:   true,
-		})
+		ctx.Error(err)
 		return
 	}
 
@@ -138,46 +117,24 @@ func (u *userHandlerImpl) CreateUser(ctx *gin.Context) {
 func (u *userHandlerImpl) UpdateUser(ctx *gin.Context) {
 	id, err := strconv.Atoi(ctx.Param("id"))
 	if id == 0 || err != nil {
-		ctx.JSON(http.StatusBadRequest, models.UserResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid or missing ID parameter",
-			Data:    nil,
-			Error:   true,
-		})
+		ctx.Error(errInvalidID)
 		return
 	}
 
 	var updateUserRequest models.UserRequest
 	if err := ctx.ShouldBindJSON(&updateUserRequest); err != nil {
-		ctx.JSON(http.StatusBadRequest, models.UserResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid request data",
-			Data:    nil,
-			Error:   true,
-		})
+		ctx.Error(apperror.Binding(err))
 		return
 	}
 
-	userResponse, err := u.svc.UpdateUser(ctx, uint64(id), updateUserRequest)
+	versions, _ := ifMatchVersions(ctx)
+	userResponse, err := u.svc.UpdateUser(ctx, uint64(id), updateUserRequest, versions)
 	if err != nil {
-		statusCode := http.StatusInternalServerError
-		if err.Error() == "email already exists" ||
-			err.Error() == "role not found" ||
-			err.Error() == "position not found" {
-			statusCode = http.StatusBadRequest
-		} else if err.Error() == "user not found" {
-			statusCode = http.StatusNotFound
-		}
-
-		ctx.JSON(statusCode, models.UserResponse{
-			Status:  statusCode,
-			Message: err.Error(),
-			Data:    nil,
-			Error:   true,
-		})
+		ctx.Error(err)
 		return
 	}
 
+	ctx.Header("ETag", userETag(userResponse))
 	ctx.JSON(http.StatusOK, models.UserResponse{
 		Status:  http.StatusOK,
 		Message: "User updated successfully",
@@ -186,37 +143,52 @@ func (u *userHandlerImpl) UpdateUser(ctx *gin.Context) {
 	})
 }
 
-func (u *userHandlerImpl) DeleteUser(ctx *gin.Context) {
+// PatchUser changes the fields of a JSON Merge Patch body. It needs the
+// user's ETag in If-Match so concurrent edits can't overwrite each other.
+func (u *userHandlerImpl) PatchUser(ctx *gin.Context) {
 	id, err := strconv.Atoi(ctx.Param("id"))
 	if id == 0 || err != nil {
-		ctx.JSON(http.StatusBadRequest, models.UserResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid or missing ID parameter",
-			Data:    nil,
-			Error:   true,
-		})
+		ctx.Error(errInvalidID)
 		return
 	}
 
-	user, _ := u.svc.GetUserByID(ctx, uint64(id))
-	if user.Id == 0 {
-		ctx.JSON(http.StatusNotFound, models.UserResponse{
-			Status:  http.StatusNotFound,
-			Message: "User not found",
-			Data:    nil,
-			Error:   false,
-		})
+	versions, ok := ifMatchVersions(ctx)
+	if !ok {
+		ctx.Error(apperror.PreconditionRequired("if_match_required", "If-Match header with the user's ETag is required"))
+		return
+	}
+
+	patch, err := ctx.GetRawData()
+	if err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	userResponse, err := u.svc.PatchUser(ctx, uint64(id), patch, versions)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Header("ETag", userETag(userResponse))
+	ctx.JSON(http.StatusOK, models.UserResponse{
+		Status:  http.StatusOK,
+		Message: "User updated successfully",
+		Data:    &userResponse,
+		Error:   false,
+	})
+}
+
+func (u *userHandlerImpl) DeleteUser(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if id == 0 || err != nil {
+		ctx.Error(errInvalidID)
 		return
 	}
 
 	err = u.svc.DeleteUser(ctx, uint64(id))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, models.UserResponse{
-			Status:  http.StatusInternalServerError,
-			Message: err.Error(),
-			Data:    nil,
-			Error:   true,
-		})
+		ctx.Error(err)
 		return
 	}
 
@@ -228,40 +200,267 @@ func (u *userHandlerImpl) DeleteUser(ctx *gin.Context) {
 	})
 }
 
+func (u *userHandlerImpl) GetDeletedUsers(ctx *gin.Context) {
+	var filter models.DeletedUserFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	users, meta, err := u.svc.GetDeletedUsers(ctx, filter)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.DeletedUsersResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get deleted users",
+		Data:    &users,
+		Meta:    &meta,
+		Error:   false,
+	})
+}
+
+func (u *userHandlerImpl) RestoreUser(ctx *gin.Context) {
+	id, ok := uintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	user, err := u.svc.RestoreUser(ctx, id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Header("ETag", userETag(user))
+	ctx.JSON(http.StatusOK, models.UserResponse{
+		Status:  http.StatusOK,
+		Message: "User restored successfully",
+		Data:    &user,
+		Error:   false,
+	})
+}
+
+func (u *userHandlerImpl) PurgeUser(ctx *gin.Context) {
+	id, ok := uintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	if err := u.svc.PurgeUser(ctx, id); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.UserResponse{
+		Status:  http.StatusOK,
+		Message: "User purged successfully",
+		Data:    nil,
+		Error:   false,
+	})
+}
+
 func (u *userHandlerImpl) LoginUser(ctx *gin.Context) {
 	var authUserRequest models.AuthRequest
 
 	if err := ctx.ShouldBindJSON(&authUserRequest); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(apperror.Binding(err))
 		return
 	}
 
-	token, err := u.svc.Login(ctx, authUserRequest.Email, authUserRequest.Password)
+	tokens, err := u.svc.Login(ctx, authUserRequest.Email, authUserRequest.Password)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
-	ctx.JSON(http.StatusOK, gin.H{"token": token})
+
+	ctx.JSON(http.StatusOK, models.AuthResponse{
+		Status:  http.StatusOK,
+		Message: "Login successful",
+		Data:    &tokens,
+		Error:   false,
+	})
 }
 
 func (u *userHandlerImpl) LogoutUser(ctx *gin.Context) {
-	authHeader := ctx.GetHeader("Authorization")
-	if authHeader == "" {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
+	token, err := middleware.BearerToken(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var logoutRequest models.LogoutRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&logoutRequest); err != nil {
+			ctx.Error(apperror.Binding(err))
+			return
+		}
+	}
+
+	if err := u.svc.Logout(ctx, token, logoutRequest.RefreshToken); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.AuthResponse{
+		Status:  http.StatusOK,
+		Message: "Logout successful",
+		Data:    nil,
+		Error:   false,
+	})
+}
+
+func (u *userHandlerImpl) RefreshToken(ctx *gin.Context) {
+	var refreshTokenRequest models.RefreshTokenRequest
+
+	if err := ctx.ShouldBindJSON(&refreshTokenRequest); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	tokens, err := u.svc.RefreshToken(ctx, refreshTokenRequest.RefreshToken)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.AuthResponse{
+		Status:  http.StatusOK,
+		Message: "Token refreshed successfully",
+		Data:    &tokens,
+		Error:   false,
+	})
+}
+
+func (u *userHandlerImpl) GetReports(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if id == 0 || err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	recursive := ctx.Query("recursive") == "true"
+	reports, err := u.svc.GetReports(ctx, uint64(id), recursive)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.ReportsResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get reports",
+		Data:    &reports,
+		Error:   false,
+	})
+}
+
+func (u *userHandlerImpl) GetOrgChart(ctx *gin.Context) {
+	rootID := 0
+	if value := ctx.Query("root_id"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			ctx.Error(apperror.Validation("invalid_root_id", "invalid root_id parameter"))
+			return
+		}
+		rootID = parsed
+	}
+
+	chart, err := u.svc.GetOrgChart(ctx, uint64(rootID))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.OrgChartResponse{
+		Status:  http.StatusOK,
+		Message: "Success to get org chart",
+		Data:    chart,
+		Error:   false,
+	})
+}
+
+func (u *userHandlerImpl) MoveUserToDepartment(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if id == 0 || err != nil {
+		ctx.Error(errInvalidID)
 		return
 	}
 
-	token := strings.TrimPrefix(authHeader, "Bearer ")
-	if token == authHeader {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Bearer token is required"})
+	var departmentRequest models.UserDepartmentRequest
+	if err := ctx.ShouldBindJSON(&departmentRequest); err != nil {
+		ctx.Error(apperror.Binding(err))
 		return
 	}
 
-	err := u.svc.Logout(ctx, token)
+	user, err := u.svc.MoveUserToDepartment(ctx, uint64(id), departmentRequest.DepartmentID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"message": "Logout successful"})
+	ctx.JSON(http.StatusOK, models.UserResponse{
+		Status:  http.StatusOK,
+		Message: "User moved successfully",
+		Data:    &user,
+		Error:   false,
+	})
+}
+
+func (u *userHandlerImpl) SetUserManager(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if id == 0 || err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	var managerRequest models.UserManagerRequest
+	if err := ctx.ShouldBindJSON(&managerRequest); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+
+	user, err := u.svc.SetUserManager(ctx, uint64(id), managerRequest.ManagerID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.UserResponse{
+		Status:  http.StatusOK,
+		Message: "Manager updated successfully",
+		Data:    &user,
+		Error:   false,
+	})
+}
+
+// userETag is the ETag of a user, which changes with every update.
+func userETag(user models.User) string {
+	return `"` + strconv.Itoa(user.Version) + `"`
+}
+
+// ifMatchVersions reads the user versions of the If-Match header. ok is
+// false without the header, and versions is nil for "*", which matches any
+// version. Weak or malformed ETags never match.
+func ifMatchVersions(ctx *gin.Context) (versions []int, ok bool) {
+	header := strings.TrimSpace(ctx.GetHeader("If-Match"))
+	if header == "" {
+		return nil, false
+	}
+	if header == "*" {
+		return nil, true
+	}
+
+	versions = []int{}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+		if version, err := strconv.Atoi(tag[1 : len(tag)-1]); err == nil {
+			versions = append(versions, version)
+		}
+	}
+	return versions, true
 }
@@ -0,0 +1,32 @@
+package jobs
+
+import (
+	"context"
+	"log"
+
+	"main.go/internal/repository"
+)
+
+// NewBlacklistSweeper removes revoked tokens whose expiry has passed, and
+// refresh tokens that expired or were revoked. They can't be used anymore,
+// so keeping them only slows down the lookups.
+func NewBlacklistSweeper(store repository.RevocationStore, refreshTokens repository.RefreshTokenQuery) Job {
+	return func(ctx context.Context) error {
+		removed, err := store.RemoveExpired(ctx)
+		if err != nil {
+			return err
+		}
+		if removed > 0 {
+			log.Printf("removed %d expired blacklisted tokens", removed)
+		}
+
+		removed, err = refreshTokens.DeleteStaleRefreshTokens(ctx)
+		if err != nil {
+			return err
+		}
+		if removed > 0 {
+			log.Printf("removed %d expired or revoked refresh tokens", removed)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,17 @@
+package jobs
+
+import (
+	"context"
+
+	"main.go/internal/models"
+	"main.go/internal/service"
+)
+
+// NewLeaveAccrual posts the leave accruals, carry-overs and expiries that
+// are due today. Postings are idempotent, so running it often is harmless.
+func NewLeaveAccrual(svc service.LeaveAccrualService) Job {
+	return func(ctx context.Context) error {
+		_, err := svc.RunAccruals(ctx, models.Date{})
+		return err
+	}
+}
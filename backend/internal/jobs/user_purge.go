@@ -0,0 +1,23 @@
+package jobs
+
+import (
+	"context"
+	"log"
+
+	"main.go/internal/service"
+)
+
+// NewUserPurge deletes the users whose retention period after being deleted
+// is over for good.
+func NewUserPurge(svc service.UserService) Job {
+	return func(ctx context.Context) error {
+		purged, err := svc.PurgeDeletedUsers(ctx)
+		if err != nil {
+			return err
+		}
+		if purged > 0 {
+			log.Printf("purged %d deleted users", purged)
+		}
+		return nil
+	}
+}
@@ -1,55 +1,105 @@
 package middleware
 
 import (
-	"net/http"
+	"context"
+	"errors"
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
+	"main.go/internal/apperror"
 	"main.go/internal/auth"
 	"main.go/internal/models"
+	"main.go/internal/repository"
 )
 
-func AuthMiddleware(db *gorm.DB) gin.HandlerFunc {
+const ContextPrincipalKey = "principal"
+
+// PrincipalLoader looks up the user a token was issued to and the role whose
+// permissions they get. The user repository, cached or not, is one.
+type PrincipalLoader interface {
+	GetUserByID(ctx context.Context, id uint64) (models.User, error)
+	GetRoleByID(ctx context.Context, id uint64) (models.Role, error)
+}
+
+func AuthMiddleware(users PrincipalLoader, revocations repository.RevocationStore) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		authHeader := ctx.GetHeader("Authorization")
-		if authHeader == "" {
-			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
-			ctx.Abort()
+		token, err := BearerToken(ctx)
+		if err != nil {
+			abortWithError(ctx, err)
 			return
 		}
 
-		token := strings.TrimPrefix(authHeader, "Bearer ")
-		if token == authHeader {
-			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Bearer token is required"})
-			ctx.Abort()
+		claims, err := auth.ValidateJWT(token)
+		if err != nil {
+			abortWithError(ctx, apperror.Unauthorized("invalid_token", "invalid token"))
 			return
 		}
 
-		var blacklisted models.BlackListedToken
-		if db == nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection is nil"})
-			ctx.Abort()
+		if users == nil {
+			abortWithError(ctx, apperror.Internal(errors.New("user lookup is nil")))
+			return
+		}
+
+		revoked, err := revocations.IsRevoked(ctx, claims.ID)
+		if err != nil {
+			abortWithError(ctx, apperror.Internal(err))
+			return
+		}
+		if revoked {
+			abortWithError(ctx, apperror.Unauthorized("token_revoked", "token is blacklisted"))
 			return
 		}
 
-		if err := db.Where("token = ?", token).First(&blacklisted).Error; err == nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Token is blacklisted"})
-			ctx.Abort()
+		// Tokens outlive changes to their user. A deleted user loses access
+		// at once, and a user whose role changed has to refresh their token
+		// to get the permissions of the new role.
+		principal := claims.Principal()
+		user, err := users.GetUserByID(ctx, uint64(principal.UserID))
+		if err != nil {
+			abortWithError(ctx, apperror.Internal(err))
+			return
+		}
+		if user.Id == 0 {
+			abortWithError(ctx, apperror.Unauthorized("user_no_longer_exists", "user no longer exists"))
 			return
-		} else if err != gorm.ErrRecordNotFound {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error checking blacklisted token", "details": err.Error()})
-			ctx.Abort()
+		}
+		if user.RoleID != principal.RoleID {
+			abortWithError(ctx, apperror.Unauthorized("role_changed", "role has changed, refresh the token"))
 			return
 		}
 
-		_, err := auth.ValidateJWT(token)
+		role, err := users.GetRoleByID(ctx, uint64(principal.RoleID))
 		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-			ctx.Abort()
+			var appErr *apperror.Error
+			if errors.As(err, &appErr) {
+				abortWithError(ctx, apperror.Unauthorized("role_no_longer_exists", "role no longer exists"))
+				return
+			}
+			abortWithError(ctx, apperror.Internal(err))
 			return
 		}
+		principal.Permissions = make([]string, 0, len(role.Permissions))
+		for _, permission := range role.Permissions {
+			principal.Permissions = append(principal.Permissions, permission.Permission)
+		}
+
+		ctx.Set(ContextPrincipalKey, principal)
+		ctx.Request = ctx.Request.WithContext(auth.WithPrincipal(ctx.Request.Context(), principal))
 
 		ctx.Next()
 	}
 }
+
+// BearerToken returns the token of the request's Authorization header.
+func BearerToken(ctx *gin.Context) (string, error) {
+	authHeader := ctx.GetHeader("Authorization")
+	if authHeader == "" {
+		return "", apperror.Unauthorized("missing_authorization", "authorization header is required")
+	}
+
+	token := strings.TrimPrefix(authHeader, "Bearer ")
+	if token == authHeader {
+		return "", apperror.Unauthorized("missing_bearer_token", "bearer token is required")
+	}
+	return token, nil
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"main.go/internal/apperror"
+	"main.go/internal/models"
+)
+
+// ErrorHandler answers requests that recorded an error with ctx.Error and
+// wrote nothing else, using the error envelope. It has to run before every
+// other middleware so it sees their errors too.
+func ErrorHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Next()
+
+		if len(ctx.Errors) == 0 || ctx.Writer.Written() {
+			return
+		}
+		err := ctx.Errors.Last().Err
+		appErr := apperror.From(err).Localize(ctx.GetHeader("Accept-Language"))
+		if appErr.Kind == apperror.KindInternal {
+			log.Printf("%s %s [%s]: %v", ctx.Request.Method, ctx.Request.URL.Path, ctx.Writer.Header().Get(RequestIDHeader), err)
+		}
+
+		status := appErr.Kind.HTTPStatus()
+		ctx.JSON(status, models.ErrorResponse{
+			Status:  status,
+			Message: appErr.Message,
+			Code:    appErr.Code,
+			Details: appErr.Details,
+			Data:    nil,
+			Error:   true,
+		})
+	}
+}
+
+// abortWithError stops the request with err, which ErrorHandler answers.
+func abortWithError(ctx *gin.Context, err error) {
+	ctx.Error(err)
+	ctx.Abort()
+}
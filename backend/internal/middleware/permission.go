@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"main.go/internal/apperror"
+	"main.go/internal/auth"
+)
+
+var errForbidden = apperror.Forbidden("permission_denied", "you do not have permission to access this resource")
+
+// RequirePermission only lets the request through when the authenticated
+// user's role grants the given permission. It must run after AuthMiddleware.
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !HasPermission(ctx, permission) {
+			abortWithError(ctx, errForbidden)
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// RequirePermissionOrSelf behaves like RequirePermission but also lets users
+// through when the :id route parameter is their own user ID.
+func RequirePermissionOrSelf(permission string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if IsSelf(ctx) || HasPermission(ctx, permission) {
+			ctx.Next()
+			return
+		}
+
+		abortWithError(ctx, errForbidden)
+	}
+}
+
+// ManagerChecker tells whether a user is somewhere above another user in the
+// reporting line.
+type ManagerChecker interface {
+	IsManagerOf(ctx context.Context, managerID uint64, userID uint64) (bool, error)
+}
+
+// RequirePermissionSelfOrManager behaves like RequirePermissionOrSelf but
+// also lets managers through for their direct and indirect reports.
+func RequirePermissionSelfOrManager(permission string, managers ManagerChecker) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if IsSelf(ctx) || HasPermission(ctx, permission) || isManagerOfParam(ctx, managers) {
+			ctx.Next()
+			return
+		}
+
+		abortWithError(ctx, errForbidden)
+	}
+}
+
+func isManagerOfParam(ctx *gin.Context, managers ManagerChecker) bool {
+	principal, ok := CurrentPrincipal(ctx)
+	if !ok {
+		return false
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id <= 0 {
+		return false
+	}
+
+	isManager, err := managers.IsManagerOf(ctx, uint64(principal.UserID), uint64(id))
+	if err != nil {
+		log.Printf("checking manager of user %d failed: %v", id, err)
+		return false
+	}
+	return isManager
+}
+
+func HasPermission(ctx *gin.Context, permission string) bool {
+	principal, ok := CurrentPrincipal(ctx)
+	return ok && principal.HasPermission(permission)
+}
+
+func IsSelf(ctx *gin.Context) bool {
+	principal, ok := CurrentPrincipal(ctx)
+	if !ok {
+		return false
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	return err == nil && id == principal.UserID
+}
+
+func CurrentPrincipal(ctx *gin.Context) (auth.Principal, bool) {
+	value, exists := ctx.Get(ContextPrincipalKey)
+	if !exists {
+		return auth.Principal{}, false
+	}
+
+	principal, ok := value.(auth.Principal)
+	return principal, ok
+}
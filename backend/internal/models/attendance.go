@@ -0,0 +1,81 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type AttendancesResponse struct {
+	Status  int           `json:"status"`
+	Message string        `json:"message"`
+	Data    *[]Attendance `json:"data"`
+	Error   bool          `json:"error"`
+}
+
+type AttendanceResponse struct {
+	Status  int         `json:"status"`
+	Message string      `json:"message"`
+	Data    *Attendance `json:"data"`
+	Error   bool        `json:"error"`
+}
+
+// Attendance is one employee's working day. Check-in and check-out times are
+// stored in UTC, the schedule columns hold the expected start and end of the
+// day they were measured against.
+type Attendance struct {
+	ID                int        `json:"id"`
+	UserID            int        `json:"user_id"`
+	User              *User      `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	WorkDate          Date       `json:"work_date"`
+	ShiftID           *int       `json:"shift_id"`
+	CheckInAt         time.Time  `json:"check_in_at"`
+	CheckOutAt        *time.Time `json:"check_out_at"`
+	ScheduledStart    time.Time  `json:"scheduled_start"`
+	ScheduledEnd      time.Time  `json:"scheduled_end"`
+	WorkedMinutes     int        `json:"worked_minutes"`
+	WorkedHours       float64    `json:"worked_hours" gorm:"-"`
+	LateMinutes       int        `json:"late_minutes"`
+	EarlyLeaveMinutes int        `json:"early_leave_minutes"`
+	IsLate            bool       `json:"is_late"`
+	IsEarlyLeave      bool       `json:"is_early_leave"`
+	IsWorkingDay      bool       `json:"is_working_day"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// SetWorkedMinutes stores the worked time and keeps WorkedHours in sync.
+func (a *Attendance) SetWorkedMinutes(minutes int) {
+	a.WorkedMinutes = minutes
+	a.WorkedHours = float64(minutes) / 60
+}
+
+// OvertimeMinutes returns the time worked past the scheduled end, or all
+// the worked time on days that aren't working days. Open attendances have
+// none yet.
+func (a *Attendance) OvertimeMinutes() int {
+	if a.CheckOutAt == nil {
+		return 0
+	}
+	if !a.IsWorkingDay {
+		return a.WorkedMinutes
+	}
+	if overtime := a.CheckOutAt.Sub(a.ScheduledEnd); overtime > 0 {
+		return int(overtime.Minutes())
+	}
+	return 0
+}
+
+func (a *Attendance) AfterFind(tx *gorm.DB) error {
+	a.SetWorkedMinutes(a.WorkedMinutes)
+	return nil
+}
+
+// AttendanceFilter holds the query parameters for listing attendance.
+// From and To are inclusive.
+type AttendanceFilter struct {
+	UserID       int  `form:"user_id" binding:"omitempty,min=1"`
+	DepartmentID int  `form:"department_id" binding:"omitempty,min=1"`
+	From         Date `form:"from"`
+	To           Date `form:"to"`
+}
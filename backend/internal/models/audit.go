@@ -0,0 +1,149 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+type AuditAction string
+
+const (
+	AuditActionCreate AuditAction = "create"
+	AuditActionUpdate AuditAction = "update"
+	AuditActionDelete AuditAction = "delete"
+	AuditActionLogin  AuditAction = "login"
+	AuditActionLogout AuditAction = "logout"
+)
+
+// Entity types of the audit log. Leave balance adjustments are logged under
+// the ID of the user whose balance changed.
+const (
+	AuditEntityUser                = "user"
+	AuditEntityRole                = "role"
+	AuditEntityPosition            = "position"
+	AuditEntityDepartment          = "department"
+	AuditEntityCalendar            = "calendar"
+	AuditEntityHoliday             = "holiday"
+	AuditEntityAttendance          = "attendance"
+	AuditEntityLeaveRequest        = "leave_request"
+	AuditEntityLeaveBalance        = "leave_balance"
+	AuditEntityLeavePolicy         = "leave_accrual_policy"
+	AuditEntityPositionLeavePolicy = "position_leave_policies"
+	AuditEntityShift               = "shift"
+	AuditEntityShiftAssignment     = "shift_assignment"
+	AuditEntityOvertimeRates       = "overtime_rates"
+	AuditEntityOvertimeRequest     = "overtime_request"
+	AuditEntityPayPeriod           = "pay_period"
+	AuditEntityTimesheet           = "timesheet"
+	AuditEntityCompensation        = "compensation"
+	AuditEntityPayrollRun          = "payroll_run"
+	AuditEntityBankAccount         = "bank_account"
+	AuditEntityDeductionRuleSet    = "deduction_rule_set"
+)
+
+// AuditGenesisHash is the previous hash of the first audit log.
+const AuditGenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+type AuditLogsResponse struct {
+	Status  int             `json:"status"`
+	Message string          `json:"message"`
+	Data    *[]AuditLog     `json:"data"`
+	Meta    *PaginationMeta `json:"meta,omitempty"`
+	Error   bool            `json:"error"`
+}
+
+type AuditVerificationResponse struct {
+	Status  int                `json:"status"`
+	Message string             `json:"message"`
+	Data    *AuditVerification `json:"data"`
+	Error   bool               `json:"error"`
+}
+
+// AuditEntry is a change a service hands to the audit log. Before is empty
+// for creates and After for deletes. ActorID defaults to the authenticated
+// caller.
+type AuditEntry struct {
+	Action     AuditAction
+	EntityType string
+	EntityID   uint64
+	Before     interface{}
+	After      interface{}
+	ActorID    int
+}
+
+// AuditLog is one row of the append-only audit trail. Changes maps every
+// changed field to its before and after values. Hash seals the row together
+// with the hash of the row before it, so changing or removing a row breaks
+// the chain from there on.
+type AuditLog struct {
+	ID         uint64          `json:"id"`
+	ActorID    *int            `json:"actor_id"`
+	Action     AuditAction     `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   uint64          `json:"entity_id"`
+	Changes    json.RawMessage `json:"changes" gorm:"type:json"`
+	RequestID  string          `json:"request_id"`
+	ClientIP   string          `json:"client_ip"`
+	CreatedAt  time.Time       `json:"created_at" gorm:"autoCreateTime:false"`
+	PrevHash   string          `json:"prev_hash"`
+	Hash       string          `json:"hash"`
+}
+
+// AuditChange is the value of a field before and after a change.
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// Seal chains the log to the log with prevHash.
+func (l *AuditLog) Seal(prevHash string) {
+	l.PrevHash = prevHash
+	l.Hash = l.ComputeHash()
+}
+
+// ComputeHash hashes the log's content and PrevHash.
+func (l AuditLog) ComputeHash() string {
+	actorID := ""
+	if l.ActorID != nil {
+		actorID = strconv.Itoa(*l.ActorID)
+	}
+
+	// Encoding the fields as a JSON array keeps their boundaries unambiguous.
+	content, _ := json.Marshal([]string{
+		l.PrevHash,
+		l.CreatedAt.UTC().Format(time.RFC3339Nano),
+		actorID,
+		string(l.Action),
+		l.EntityType,
+		strconv.FormatUint(l.EntityID, 10),
+		string(l.Changes),
+		l.RequestID,
+		l.ClientIP,
+	})
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditLogFilter holds the query parameters of GET /audit. From and To are
+// RFC 3339 times.
+type AuditLogFilter struct {
+	EntityType string    `form:"entity_type"`
+	EntityID   uint64    `form:"entity_id"`
+	ActorID    int       `form:"actor_id" binding:"omitempty,min=1"`
+	Action     string    `form:"action" binding:"omitempty,oneof=create update delete login logout"`
+	From       time.Time `form:"from"`
+	To         time.Time `form:"to"`
+	Page       int       `form:"page" binding:"omitempty,min=1"`
+	Limit      int       `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// AuditVerification is the result of checking the hash chain. BrokenAt is
+// the first log that doesn't match its hash or its predecessor.
+type AuditVerification struct {
+	Checked  int64   `json:"checked"`
+	Valid    bool    `json:"valid"`
+	BrokenAt *uint64 `json:"broken_at,omitempty"`
+}
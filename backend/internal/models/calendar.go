@@ -0,0 +1,160 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type CalendarsResponse struct {
+	Status  int         `json:"status"`
+	Message string      `json:"message"`
+	Data    *[]Calendar `json:"data"`
+	Error   bool        `json:"error"`
+}
+
+type CalendarResponse struct {
+	Status  int       `json:"status"`
+	Message string    `json:"message"`
+	Data    *Calendar `json:"data"`
+	Error   bool      `json:"error"`
+}
+
+type HolidaysResponse struct {
+	Status  int        `json:"status"`
+	Message string     `json:"message"`
+	Data    *[]Holiday `json:"data"`
+	Error   bool       `json:"error"`
+}
+
+type HolidayResponse struct {
+	Status  int      `json:"status"`
+	Message string   `json:"message"`
+	Data    *Holiday `json:"data"`
+	Error   bool     `json:"error"`
+}
+
+type HolidayImportResponse struct {
+	Status  int                  `json:"status"`
+	Message string               `json:"message"`
+	Data    *HolidayImportResult `json:"data"`
+	Error   bool                 `json:"error"`
+}
+
+type WorkingDaysResponse struct {
+	Status  int                 `json:"status"`
+	Message string              `json:"message"`
+	Data    *WorkingDaysSummary `json:"data"`
+	Error   bool                `json:"error"`
+}
+
+// MondayToFriday is the weekday mask of a Monday to Friday week, the
+// working weekdays of a new calendar and of users without any calendar.
+const MondayToFriday = 1<<time.Monday | 1<<time.Tuesday | 1<<time.Wednesday | 1<<time.Thursday | 1<<time.Friday
+
+// Calendar is a set of working weekdays and holidays. Departments use the
+// calendar assigned to them and fall back to the default calendar.
+type Calendar struct {
+	ID              int       `json:"id"`
+	Name            string    `json:"name"`
+	WorkingWeekdays []int     `json:"working_weekdays" gorm:"-"`
+	WeekdayMask     int       `json:"-" gorm:"column:working_weekdays"`
+	IsDefault       bool      `json:"is_default"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// IsWorkingWeekday tells whether the weekday is a working day of the
+// calendar.
+func (c *Calendar) IsWorkingWeekday(weekday time.Weekday) bool {
+	return c.WeekdayMask&(1<<uint(weekday)) != 0
+}
+
+// SetWorkingWeekdays stores the working weekdays, 0 being Sunday.
+func (c *Calendar) SetWorkingWeekdays(weekdays []int) {
+	c.WeekdayMask = 0
+	for _, weekday := range weekdays {
+		c.WeekdayMask |= 1 << uint(weekday)
+	}
+	c.syncWorkingWeekdays()
+}
+
+func (c *Calendar) syncWorkingWeekdays() {
+	c.WorkingWeekdays = []int{}
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		if c.IsWorkingWeekday(weekday) {
+			c.WorkingWeekdays = append(c.WorkingWeekdays, int(weekday))
+		}
+	}
+}
+
+func (c *Calendar) AfterFind(tx *gorm.DB) error {
+	c.syncWorkingWeekdays()
+	return nil
+}
+
+type CalendarRequest struct {
+	Name            string `json:"name" binding:"required,max=100"`
+	WorkingWeekdays []int  `json:"working_weekdays" binding:"required,dive,min=0,max=6"`
+	IsDefault       bool   `json:"is_default"`
+}
+
+// Holiday is a non-working day of a calendar. Recurring holidays repeat on
+// the same month and day every year from Date on. UID identifies holidays
+// imported from iCalendar files so a second import updates them.
+type Holiday struct {
+	ID         int       `json:"id"`
+	CalendarID int       `json:"calendar_id"`
+	Name       string    `json:"name"`
+	Date       Date      `json:"date"`
+	Recurring  bool      `json:"recurring"`
+	UID        *string   `json:"uid"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// OccursOn tells whether the holiday falls on day.
+func (h Holiday) OccursOn(day Date) bool {
+	if !h.Recurring {
+		return h.Date.Equal(day)
+	}
+	return !day.Before(h.Date) && h.Date.Month() == day.Month() && h.Date.Day() == day.Day()
+}
+
+type HolidayRequest struct {
+	Name      string `json:"name" binding:"required,max=255"`
+	Date      Date   `json:"date"`
+	Recurring bool   `json:"recurring"`
+}
+
+// HolidayFilter holds the query parameters for listing holidays. Recurring
+// holidays are listed when they started before To.
+type HolidayFilter struct {
+	From Date `form:"from"`
+	To   Date `form:"to"`
+}
+
+type HolidayImportResult struct {
+	Events   int `json:"events"`
+	Holidays int `json:"holidays"`
+}
+
+type WorkingDaysQuery struct {
+	From Date `form:"from"`
+	To   Date `form:"to"`
+}
+
+// HolidayOccurrence is a holiday on one day of a range.
+type HolidayOccurrence struct {
+	Date Date   `json:"date"`
+	Name string `json:"name"`
+}
+
+type WorkingDaysSummary struct {
+	UserID      int                 `json:"user_id"`
+	CalendarID  int                 `json:"calendar_id"`
+	From        Date                `json:"from"`
+	To          Date                `json:"to"`
+	WorkingDays int                 `json:"working_days"`
+	Holidays    []HolidayOccurrence `json:"holidays"`
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type PayFrequency string
+
+const (
+	PayFrequencyHourly   PayFrequency = "hourly"
+	PayFrequencyWeekly   PayFrequency = "weekly"
+	PayFrequencyBiweekly PayFrequency = "biweekly"
+	PayFrequencyMonthly  PayFrequency = "monthly"
+	PayFrequencyAnnual   PayFrequency = "annual"
+)
+
+type CompensationsResponse struct {
+	Status  int             `json:"status"`
+	Message string          `json:"message"`
+	Data    *[]Compensation `json:"data"`
+	Error   bool            `json:"error"`
+}
+
+type CompensationResponse struct {
+	Status  int           `json:"status"`
+	Message string        `json:"message"`
+	Data    *Compensation `json:"data"`
+	Error   bool          `json:"error"`
+}
+
+// Compensation is what a user is paid from EffectiveDate until the user's
+// next compensation takes effect. BasePay is paid per PayFrequency, for
+// hourly pay per hour worked. Amounts are written as strings in JSON so no
+// precision is lost.
+type Compensation struct {
+	ID            int                     `json:"id"`
+	UserID        int                     `json:"user_id"`
+	User          *User                   `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	EffectiveDate Date                    `json:"effective_date"`
+	BasePay       decimal.Decimal         `json:"base_pay" gorm:"type:numeric(14,2)"`
+	Currency      string                  `json:"currency"`
+	PayFrequency  PayFrequency            `json:"pay_frequency"`
+	Allowances    []CompensationAllowance `json:"allowances" gorm:"foreignKey:CompensationID"`
+	Reason        string                  `json:"reason"`
+	CreatedBy     *int                    `json:"created_by"`
+	CreatedAt     time.Time               `json:"created_at"`
+	UpdatedAt     time.Time               `json:"updated_at"`
+}
+
+// TotalAllowances adds up the allowances paid with every pay.
+func (c *Compensation) TotalAllowances() decimal.Decimal {
+	total := decimal.Zero
+	for _, allowance := range c.Allowances {
+		total = total.Add(allowance.Amount)
+	}
+	return total
+}
+
+// CompensationAllowance is a fixed amount paid on top of the base pay.
+type CompensationAllowance struct {
+	ID             int             `json:"id"`
+	CompensationID int             `json:"compensation_id"`
+	Name           string          `json:"name"`
+	Amount         decimal.Decimal `json:"amount" gorm:"type:numeric(14,2)"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// CompensationRequest records a compensation. Amounts may be sent as JSON
+// numbers or strings and have at most two decimal places.
+type CompensationRequest struct {
+	EffectiveDate Date                           `json:"effective_date"`
+	BasePay       decimal.Decimal                `json:"base_pay"`
+	Currency      string                         `json:"currency" binding:"required,iso4217"`
+	PayFrequency  PayFrequency                   `json:"pay_frequency" binding:"required,oneof=hourly weekly biweekly monthly annual"`
+	Allowances    []CompensationAllowanceRequest `json:"allowances" binding:"dive"`
+	Reason        string                         `json:"reason" binding:"max=1000"`
+}
+
+type CompensationAllowanceRequest struct {
+	Name   string          `json:"name" binding:"required,max=100"`
+	Amount decimal.Decimal `json:"amount"`
+}
+
+// CompensationFilter holds the query parameters for listing the current
+// compensation of many users.
+type CompensationFilter struct {
+	DepartmentID int  `form:"department_id" binding:"omitempty,min=1"`
+	Date         Date `form:"date"`
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const DateLayout = "2006-01-02"
+
+// Date is a calendar day without a time of day, stored in DATE columns and
+// written as "2006-01-02" in JSON and query parameters.
+type Date struct {
+	time.Time
+}
+
+// NewDate returns the calendar day of t in t's own location.
+func NewDate(t time.Time) Date {
+	return Date{time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)}
+}
+
+func ParseDate(value string) (Date, error) {
+	t, err := time.Parse(DateLayout, value)
+	if err != nil {
+		return Date{}, err
+	}
+	return Date{t}, nil
+}
+
+// At returns the moment offset after midnight of this date in loc.
+func (d Date) At(loc *time.Location, offset time.Duration) time.Time {
+	return time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, loc).Add(offset)
+}
+
+func (d Date) AddDays(days int) Date {
+	return Date{d.Time.AddDate(0, 0, days)}
+}
+
+func (d Date) Before(other Date) bool {
+	return d.Time.Before(other.Time)
+}
+
+func (d Date) After(other Date) bool {
+	return d.Time.After(other.Time)
+}
+
+func (d Date) Equal(other Date) bool {
+	return d.Time.Equal(other.Time)
+}
+
+func (d Date) String() string {
+	return d.Format(DateLayout)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return []byte(`"` + d.String() + `"`), nil
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+	value := strings.Trim(string(data), `"`)
+	if value == "" || value == "null" {
+		*d = Date{}
+		return nil
+	}
+	parsed, err := ParseDate(value)
+	if err != nil {
+		return fmt.Errorf("invalid date %q, expected YYYY-MM-DD", value)
+	}
+	*d = parsed
+	return nil
+}
+
+// UnmarshalParam lets gin bind dates from query and form parameters.
+func (d *Date) UnmarshalParam(param string) error {
+	return d.UnmarshalJSON([]byte(param))
+}
+
+// UnmarshalText reads dates from YAML and other text formats, instead of
+// the RFC 3339 time.Time would expect.
+func (d *Date) UnmarshalText(text []byte) error {
+	return d.UnmarshalJSON(text)
+}
+
+func (d Date) Value() (driver.Value, error) {
+	if d.IsZero() {
+		return nil, nil
+	}
+	return d.String(), nil
+}
+
+func (d *Date) Scan(src interface{}) error {
+	switch value := src.(type) {
+	case nil:
+		*d = Date{}
+	case time.Time:
+		*d = NewDate(value)
+	case string:
+		return d.UnmarshalJSON([]byte(value))
+	case []byte:
+		return d.UnmarshalJSON(value)
+	default:
+		return fmt.Errorf("cannot scan %T into Date", src)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type DeductionRuleType string
+
+const (
+	// DeductionProgressiveTax taxes each slice of the taxable pay at the
+	// rate of its bracket.
+	DeductionProgressiveTax DeductionRuleType = "progressive_tax"
+	// DeductionContribution takes a rate of the pay, up to an optional
+	// ceiling on the pay it is taken from.
+	DeductionContribution DeductionRuleType = "contribution"
+	// DeductionFixed takes the same amount from every payslip.
+	DeductionFixed DeductionRuleType = "fixed"
+)
+
+type DeductionRuleSetsResponse struct {
+	Status  int                 `json:"status"`
+	Message string              `json:"message"`
+	Data    *[]DeductionRuleSet `json:"data"`
+	Error   bool                `json:"error"`
+}
+
+type DeductionRuleSetResponse struct {
+	Status  int               `json:"status"`
+	Message string            `json:"message"`
+	Data    *DeductionRuleSet `json:"data"`
+	Error   bool              `json:"error"`
+}
+
+type DeductionDryRunResponse struct {
+	Status  int              `json:"status"`
+	Message string           `json:"message"`
+	Data    *DeductionDryRun `json:"data"`
+	Error   bool             `json:"error"`
+}
+
+// DeductionRuleSet holds the statutory deductions of pay in Currency from
+// EffectiveDate until the next set of the same currency.
+type DeductionRuleSet struct {
+	ID            int             `json:"id"`
+	Name          string          `json:"name"`
+	Currency      string          `json:"currency"`
+	EffectiveDate Date            `json:"effective_date"`
+	Rules         []DeductionRule `json:"rules" gorm:"serializer:json"`
+	CreatedBy     *int            `json:"created_by"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+// DeductionRule is one deduction line of a payslip. Which of the amount
+// fields are used depends on Type. TaxDeductible contributions lower the pay
+// later progressive_tax rules are applied to.
+type DeductionRule struct {
+	Code          string            `json:"code" yaml:"code" binding:"required,max=50"`
+	Description   string            `json:"description" yaml:"description" binding:"max=255"`
+	Type          DeductionRuleType `json:"type" yaml:"type" binding:"required,oneof=progressive_tax contribution fixed"`
+	Brackets      []TaxBracket      `json:"brackets,omitempty" yaml:"brackets"`
+	Rate          *decimal.Decimal  `json:"rate,omitempty" yaml:"rate"`
+	BaseCap       *decimal.Decimal  `json:"base_cap,omitempty" yaml:"base_cap"`
+	TaxDeductible bool              `json:"tax_deductible,omitempty" yaml:"tax_deductible"`
+	Amount        *decimal.Decimal  `json:"amount,omitempty" yaml:"amount"`
+}
+
+// TaxBracket taxes pay up to UpTo at Rate. The last bracket has no UpTo and
+// covers the rest.
+type TaxBracket struct {
+	UpTo *decimal.Decimal `json:"up_to" yaml:"up_to"`
+	Rate decimal.Decimal  `json:"rate" yaml:"rate"`
+}
+
+// DeductionRuleSetRequest is read from JSON or YAML.
+type DeductionRuleSetRequest struct {
+	Name          string          `json:"name" yaml:"name" binding:"required,max=100"`
+	Currency      string          `json:"currency" yaml:"currency" binding:"required,iso4217"`
+	EffectiveDate Date            `json:"effective_date" yaml:"effective_date"`
+	Rules         []DeductionRule `json:"rules" yaml:"rules" binding:"required,min=1,dive"`
+}
+
+type DeductionRuleSetFilter struct {
+	Currency string `form:"currency" binding:"omitempty,iso4217"`
+}
+
+// DeductionDryRunRequest asks for the deductions of GrossPay under the rule
+// set in effect on Date, today when it is left out.
+type DeductionDryRunRequest struct {
+	Currency string          `json:"currency" binding:"required,iso4217"`
+	Date     Date            `json:"date"`
+	GrossPay decimal.Decimal `json:"gross_pay"`
+}
+
+// DeductionDryRun is what a payslip with the requested gross pay would
+// deduct.
+type DeductionDryRun struct {
+	RuleSet         *DeductionRuleSet `json:"rule_set"`
+	Currency        string            `json:"currency"`
+	GrossPay        decimal.Decimal   `json:"gross_pay"`
+	Lines           []PayslipLine     `json:"lines"`
+	TotalDeductions decimal.Decimal   `json:"total_deductions"`
+	NetPay          decimal.Decimal   `json:"net_pay"`
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type DepartmentsResponse struct {
+	Status  int           `json:"status"`
+	Message string        `json:"message"`
+	Data    *[]Department `json:"data"`
+	Error   bool          `json:"error"`
+}
+
+type DepartmentResponse struct {
+	Status  int         `json:"status"`
+	Message string      `json:"message"`
+	Data    *Department `json:"data"`
+	Error   bool        `json:"error"`
+}
+
+type Department struct {
+	ID         int            `json:"id"`
+	Name       string         `json:"name"`
+	CalendarID *int           `json:"calendar_id"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at"`
+}
+
+type DepartmentRequest struct {
+	Name       string `json:"name" binding:"required,max=100"`
+	CalendarID *int   `json:"calendar_id"`
+}
+
+type UserDepartmentRequest struct {
+	DepartmentID *int `json:"department_id"`
+}
+
+type UserManagerRequest struct {
+	ManagerID *int `json:"manager_id"`
+}
+
+// Report is a direct or indirect report of a manager. Depth is 1 for direct
+// reports and grows by one for every level below.
+type Report struct {
+	User
+	Depth int `json:"depth"`
+}
+
+type ReportsResponse struct {
+	Status  int       `json:"status"`
+	Message string    `json:"message"`
+	Data    *[]Report `json:"data"`
+	Error   bool      `json:"error"`
+}
+
+type OrgChartNode struct {
+	ID         int             `json:"id"`
+	Firstname  string          `json:"firstname"`
+	Lastname   string          `json:"lastname"`
+	Email      string          `json:"email"`
+	Position   string          `json:"position"`
+	Department string          `json:"department"`
+	ManagerID  *int            `json:"manager_id"`
+	Reports    []*OrgChartNode `json:"reports" gorm:"-"`
+}
+
+type OrgChartResponse struct {
+	Status  int             `json:"status"`
+	Message string          `json:"message"`
+	Data    []*OrgChartNode `json:"data"`
+	Error   bool            `json:"error"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "main.go/internal/apperror"
+
+// ErrorResponse is the envelope of every failed request. Code is stable for
+// clients to match on, Details lists the fields that failed validation.
+type ErrorResponse struct {
+	Status  int                   `json:"status"`
+	Message string                `json:"message"`
+	Code    string                `json:"code"`
+	Details []apperror.FieldError `json:"details,omitempty"`
+	Data    interface{}           `json:"data"`
+	Error   bool                  `json:"error"`
+}
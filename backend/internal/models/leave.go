@@ -0,0 +1,138 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type LeaveStatus string
+
+const (
+	LeaveStatusDraft     LeaveStatus = "draft"
+	LeaveStatusSubmitted LeaveStatus = "submitted"
+	LeaveStatusApproved  LeaveStatus = "approved"
+	LeaveStatusRejected  LeaveStatus = "rejected"
+	LeaveStatusCancelled LeaveStatus = "cancelled"
+)
+
+type LeaveTypesResponse struct {
+	Status  int          `json:"status"`
+	Message string       `json:"message"`
+	Data    *[]LeaveType `json:"data"`
+	Error   bool         `json:"error"`
+}
+
+type LeaveBalancesResponse struct {
+	Status  int             `json:"status"`
+	Message string          `json:"message"`
+	Data    *[]LeaveBalance `json:"data"`
+	Error   bool            `json:"error"`
+}
+
+type LeaveBalanceResponse struct {
+	Status  int           `json:"status"`
+	Message string        `json:"message"`
+	Data    *LeaveBalance `json:"data"`
+	Error   bool          `json:"error"`
+}
+
+type LeaveRequestsResponse struct {
+	Status  int             `json:"status"`
+	Message string          `json:"message"`
+	Data    *[]LeaveRequest `json:"data"`
+	Error   bool            `json:"error"`
+}
+
+type LeaveRequestResponse struct {
+	Status  int           `json:"status"`
+	Message string        `json:"message"`
+	Data    *LeaveRequest `json:"data"`
+	Error   bool          `json:"error"`
+}
+
+// LeaveType is a kind of leave. Only paid leave is taken from a balance.
+type LeaveType struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	IsPaid    bool      `json:"is_paid"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// LeaveBalance is how many days of one leave type a user may take in a year
+// and how many of them were already approved. Both totals are kept in step
+// with the user's leave ledger.
+type LeaveBalance struct {
+	ID            int        `json:"id"`
+	UserID        int        `json:"user_id"`
+	LeaveTypeID   int        `json:"leave_type_id"`
+	LeaveType     *LeaveType `json:"leave_type,omitempty" gorm:"foreignKey:LeaveTypeID"`
+	Year          int        `json:"year"`
+	EntitledDays  float64    `json:"entitled_days"`
+	UsedDays      float64    `json:"used_days"`
+	RemainingDays float64    `json:"remaining_days" gorm:"-"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func (l *LeaveBalance) AfterFind(tx *gorm.DB) error {
+	l.RemainingDays = l.EntitledDays - l.UsedDays
+	return nil
+}
+
+// LeaveAdjustmentRequest corrects a balance by hand. Negative days take
+// days away.
+type LeaveAdjustmentRequest struct {
+	LeaveTypeID int     `json:"leave_type_id" binding:"required,min=1"`
+	Year        int     `json:"year" binding:"required,min=2000,max=9999"`
+	Days        float64 `json:"days" binding:"required"`
+	Note        string  `json:"note" binding:"required,max=1000"`
+}
+
+// LeaveRequest is a request for time off. Days counts the working days
+// between StartDate and EndDate, both inclusive.
+type LeaveRequest struct {
+	ID           int         `json:"id"`
+	UserID       int         `json:"user_id"`
+	User         *User       `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	LeaveTypeID  int         `json:"leave_type_id"`
+	LeaveType    *LeaveType  `json:"leave_type,omitempty" gorm:"foreignKey:LeaveTypeID"`
+	StartDate    Date        `json:"start_date"`
+	EndDate      Date        `json:"end_date"`
+	Days         int         `json:"days"`
+	Reason       string      `json:"reason"`
+	Status       LeaveStatus `json:"status"`
+	DecidedBy    *int        `json:"decided_by"`
+	DecisionNote string      `json:"decision_note"`
+	SubmittedAt  *time.Time  `json:"submitted_at"`
+	DecidedAt    *time.Time  `json:"decided_at"`
+	CancelledAt  *time.Time  `json:"cancelled_at"`
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+}
+
+type LeaveRequestRequest struct {
+	LeaveTypeID int    `json:"leave_type_id" binding:"required,min=1"`
+	StartDate   Date   `json:"start_date"`
+	EndDate     Date   `json:"end_date"`
+	Reason      string `json:"reason" binding:"max=1000"`
+}
+
+type LeaveDecisionRequest struct {
+	Note string `json:"note" binding:"max=1000"`
+}
+
+// LeaveRequestFilter holds the query parameters for listing leave requests.
+// Requests are matched when they overlap the From and To range.
+type LeaveRequestFilter struct {
+	UserID  int         `form:"user_id" binding:"omitempty,min=1"`
+	UserIDs []uint64    `form:"-"`
+	Status  LeaveStatus `form:"status" binding:"omitempty,oneof=draft submitted approved rejected cancelled"`
+	From    Date        `form:"from"`
+	To      Date        `form:"to"`
+}
+
+type LeaveBalanceQuery struct {
+	Year int `form:"year" binding:"omitempty,min=2000,max=9999"`
+}
@@ -0,0 +1,141 @@
+package models
+
+import "time"
+
+type AccrualFrequency string
+
+const (
+	AccrualMonthly AccrualFrequency = "monthly"
+	AccrualYearly  AccrualFrequency = "yearly"
+)
+
+type LedgerEntryType string
+
+const (
+	LedgerAccrual    LedgerEntryType = "accrual"
+	LedgerCarryOver  LedgerEntryType = "carry_over"
+	LedgerExpiry     LedgerEntryType = "expiry"
+	LedgerAdjustment LedgerEntryType = "adjustment"
+	LedgerUsage      LedgerEntryType = "usage"
+	LedgerRestore    LedgerEntryType = "restore"
+)
+
+// IsUsage tells whether the entry changes the used days of a balance rather
+// than its entitlement.
+func (t LedgerEntryType) IsUsage() bool {
+	return t == LedgerUsage || t == LedgerRestore
+}
+
+type LeaveAccrualPoliciesResponse struct {
+	Status  int                   `json:"status"`
+	Message string                `json:"message"`
+	Data    *[]LeaveAccrualPolicy `json:"data"`
+	Error   bool                  `json:"error"`
+}
+
+type LeaveAccrualPolicyResponse struct {
+	Status  int                 `json:"status"`
+	Message string              `json:"message"`
+	Data    *LeaveAccrualPolicy `json:"data"`
+	Error   bool                `json:"error"`
+}
+
+type LeaveLedgerResponse struct {
+	Status  int                 `json:"status"`
+	Message string              `json:"message"`
+	Data    *[]LeaveLedgerEntry `json:"data"`
+	Error   bool                `json:"error"`
+}
+
+type LeaveLedgerEntryResponse struct {
+	Status  int               `json:"status"`
+	Message string            `json:"message"`
+	Data    *LeaveLedgerEntry `json:"data"`
+	Error   bool              `json:"error"`
+}
+
+type AccrualRunResponse struct {
+	Status  int               `json:"status"`
+	Message string            `json:"message"`
+	Data    *AccrualRunResult `json:"data"`
+	Error   bool              `json:"error"`
+}
+
+// LeaveAccrualPolicy describes how a leave type is earned. DaysPerYear is
+// granted once a year or spread over the months, and pro-rated in the
+// year a user is hired. Up to MaxCarryOver unused days move to the next
+// year and expire CarryOverExpiryMonths into it unless that is zero.
+type LeaveAccrualPolicy struct {
+	ID                    int              `json:"id"`
+	Name                  string           `json:"name"`
+	LeaveTypeID           int              `json:"leave_type_id"`
+	LeaveType             *LeaveType       `json:"leave_type,omitempty" gorm:"foreignKey:LeaveTypeID"`
+	Frequency             AccrualFrequency `json:"frequency"`
+	DaysPerYear           float64          `json:"days_per_year"`
+	MaxCarryOver          float64          `json:"max_carry_over"`
+	CarryOverExpiryMonths int              `json:"carry_over_expiry_months"`
+	CreatedAt             time.Time        `json:"created_at"`
+	UpdatedAt             time.Time        `json:"updated_at"`
+}
+
+type LeaveAccrualPolicyRequest struct {
+	Name                  string           `json:"name" binding:"required,max=100"`
+	LeaveTypeID           int              `json:"leave_type_id" binding:"required,min=1"`
+	Frequency             AccrualFrequency `json:"frequency" binding:"required,oneof=monthly yearly"`
+	DaysPerYear           float64          `json:"days_per_year" binding:"min=0,max=366"`
+	MaxCarryOver          float64          `json:"max_carry_over" binding:"min=0,max=366"`
+	CarryOverExpiryMonths int              `json:"carry_over_expiry_months" binding:"min=0,max=12"`
+}
+
+type PositionLeavePolicy struct {
+	PositionID int `json:"position_id"`
+	PolicyID   int `json:"policy_id"`
+}
+
+type PositionLeavePoliciesRequest struct {
+	PolicyIDs []int `json:"policy_ids" binding:"dive,min=1"`
+}
+
+// AccrualAssignment is a policy that applies to a user through the user's
+// position.
+type AccrualAssignment struct {
+	UserID    int
+	HireDate  Date
+	CreatedAt time.Time
+	PolicyID  int
+}
+
+// LeaveLedgerEntry is one change to a leave balance. Credits have positive
+// days, usage and expiry negative ones. PeriodKey makes scheduled entries
+// idempotent, Balance is the running remaining balance after the entry.
+type LeaveLedgerEntry struct {
+	ID             int             `json:"id"`
+	UserID         int             `json:"user_id"`
+	LeaveTypeID    int             `json:"leave_type_id"`
+	Year           int             `json:"year"`
+	EntryType      LedgerEntryType `json:"entry_type"`
+	Days           float64         `json:"days"`
+	PeriodKey      *string         `json:"period_key"`
+	PolicyID       *int            `json:"policy_id"`
+	LeaveRequestID *int            `json:"leave_request_id"`
+	Note           string          `json:"note"`
+	EffectiveDate  Date            `json:"effective_date"`
+	Balance        float64         `json:"balance" gorm:"-"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// LeaveLedgerFilter holds the query parameters of the ledger endpoints.
+type LeaveLedgerFilter struct {
+	Year        int `form:"year" binding:"omitempty,min=2000,max=9999"`
+	LeaveTypeID int `form:"leave_type_id" binding:"omitempty,min=1"`
+}
+
+type AccrualRunRequest struct {
+	Date Date `json:"date"`
+}
+
+type AccrualRunResult struct {
+	Date    Date `json:"date"`
+	Users   int  `json:"users"`
+	Entries int  `json:"entries"`
+}
@@ -0,0 +1,104 @@
+package models
+
+import "time"
+
+const (
+	ActivityStateActive  = "active"
+	ActivityStateIdle    = "idle"
+	ActivityStateOffline = "offline"
+)
+
+// Heartbeat is one activity sample sent by a remote employee's client.
+// EventID is chosen by the client so retried uploads are stored only once.
+type Heartbeat struct {
+	ID         int64     `json:"id"`
+	UserID     int       `json:"user_id"`
+	EventID    string    `json:"event_id"`
+	State      string    `json:"state"`
+	OccurredAt time.Time `json:"occurred_at"`
+	ReceivedAt time.Time `json:"received_at"`
+}
+
+func (Heartbeat) TableName() string {
+	return "activity_heartbeats"
+}
+
+type HeartbeatInput struct {
+	EventID    string    `json:"event_id" binding:"required,max=64"`
+	State      string    `json:"state" binding:"required,oneof=active idle"`
+	OccurredAt time.Time `json:"occurred_at" binding:"required"`
+}
+
+type HeartbeatsRequest struct {
+	Heartbeats []HeartbeatInput `json:"heartbeats" binding:"required,min=1,max=500,dive"`
+}
+
+type HeartbeatsResult struct {
+	Accepted   int `json:"accepted"`
+	Duplicates int `json:"duplicates"`
+}
+
+type HeartbeatsResponse struct {
+	Status  int               `json:"status"`
+	Message string            `json:"message"`
+	Data    *HeartbeatsResult `json:"data"`
+	Error   bool              `json:"error"`
+}
+
+// ActivityPeriod is a stretch of time spent in one state.
+type ActivityPeriod struct {
+	State   string    `json:"state"`
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	Minutes float64   `json:"minutes"`
+}
+
+// DailyActivitySummary aggregates one user's heartbeats over a day. Sessions
+// are the active periods, IdleGaps the idle or offline periods between the
+// first and the last heartbeat of the day.
+type DailyActivitySummary struct {
+	UserID        int              `json:"user_id"`
+	Firstname     string           `json:"firstname,omitempty"`
+	Lastname      string           `json:"lastname,omitempty"`
+	Date          Date             `json:"date"`
+	ActiveMinutes float64          `json:"active_minutes"`
+	IdleMinutes   float64          `json:"idle_minutes"`
+	FirstSeenAt   *time.Time       `json:"first_seen_at"`
+	LastSeenAt    *time.Time       `json:"last_seen_at"`
+	Sessions      []ActivityPeriod `json:"sessions"`
+	IdleGaps      []ActivityPeriod `json:"idle_gaps"`
+}
+
+type ActivitySummaryResponse struct {
+	Status  int                   `json:"status"`
+	Message string                `json:"message"`
+	Data    *DailyActivitySummary `json:"data"`
+	Error   bool                  `json:"error"`
+}
+
+type ActivitySummariesResponse struct {
+	Status  int                     `json:"status"`
+	Message string                  `json:"message"`
+	Data    *[]DailyActivitySummary `json:"data"`
+	Error   bool                    `json:"error"`
+}
+
+type TeamMemberStatus struct {
+	UserID     int        `json:"user_id"`
+	Firstname  string     `json:"firstname"`
+	Lastname   string     `json:"lastname"`
+	Status     string     `json:"status"`
+	LastSeenAt *time.Time `json:"last_seen_at"`
+}
+
+type TeamStatusResponse struct {
+	Status  int                 `json:"status"`
+	Message string              `json:"message"`
+	Data    *[]TeamMemberStatus `json:"data"`
+	Error   bool                `json:"error"`
+}
+
+type MonitoringQuery struct {
+	Date      Date `form:"date"`
+	ManagerID int  `form:"manager_id" binding:"omitempty,min=1"`
+}
@@ -0,0 +1,152 @@
+package models
+
+import "time"
+
+type OvertimeKind string
+
+const (
+	// OvertimePreApproval is overtime requested before it is worked.
+	OvertimePreApproval OvertimeKind = "pre_approval"
+	// OvertimeClaim is overtime claimed afterwards from an attendance record.
+	OvertimeClaim OvertimeKind = "claim"
+)
+
+type OvertimeStatus string
+
+const (
+	OvertimeStatusPending   OvertimeStatus = "pending"
+	OvertimeStatusApproved  OvertimeStatus = "approved"
+	OvertimeStatusRejected  OvertimeStatus = "rejected"
+	OvertimeStatusCancelled OvertimeStatus = "cancelled"
+)
+
+// DayType decides the overtime rate of a day.
+type DayType string
+
+const (
+	DayTypeWeekday DayType = "weekday"
+	DayTypeWeekend DayType = "weekend"
+	DayTypeHoliday DayType = "holiday"
+)
+
+type OvertimeRatesResponse struct {
+	Status  int             `json:"status"`
+	Message string          `json:"message"`
+	Data    *[]OvertimeRate `json:"data"`
+	Error   bool            `json:"error"`
+}
+
+type OvertimeRequestsResponse struct {
+	Status  int                `json:"status"`
+	Message string             `json:"message"`
+	Data    *[]OvertimeRequest `json:"data"`
+	Error   bool               `json:"error"`
+}
+
+type OvertimeRequestResponse struct {
+	Status  int              `json:"status"`
+	Message string           `json:"message"`
+	Data    *OvertimeRequest `json:"data"`
+	Error   bool             `json:"error"`
+}
+
+type OvertimeSummariesResponse struct {
+	Status  int                `json:"status"`
+	Message string             `json:"message"`
+	Data    *[]OvertimeSummary `json:"data"`
+	Error   bool               `json:"error"`
+}
+
+// OvertimeRate is the pay multiplier of overtime on a type of day.
+type OvertimeRate struct {
+	DayType    DayType   `json:"day_type" gorm:"primaryKey"`
+	Multiplier float64   `json:"multiplier"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type OvertimeRatesRequest struct {
+	Weekday float64 `json:"weekday" binding:"required,gt=0,lt=100"`
+	Weekend float64 `json:"weekend" binding:"required,gt=0,lt=100"`
+	Holiday float64 `json:"holiday" binding:"required,gt=0,lt=100"`
+}
+
+// OvertimeRequest is overtime of one day waiting for or given approval.
+// Claims are linked to the attendance they were claimed from, pre-approvals
+// when the user checks out that day. Multiplier is fixed on approval.
+type OvertimeRequest struct {
+	ID           int            `json:"id"`
+	UserID       int            `json:"user_id"`
+	User         *User          `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	AttendanceID *int           `json:"attendance_id"`
+	Attendance   *Attendance    `json:"attendance,omitempty" gorm:"foreignKey:AttendanceID"`
+	WorkDate     Date           `json:"work_date"`
+	Kind         OvertimeKind   `json:"kind"`
+	DayType      DayType        `json:"day_type"`
+	Minutes      int            `json:"minutes"`
+	Multiplier   *float64       `json:"multiplier"`
+	Reason       string         `json:"reason"`
+	Status       OvertimeStatus `json:"status"`
+	DecidedBy    *int           `json:"decided_by"`
+	DecisionNote string         `json:"decision_note"`
+	DecidedAt    *time.Time     `json:"decided_at"`
+	CancelledAt  *time.Time     `json:"cancelled_at"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+}
+
+// PaidMinutes returns the approved minutes the linked attendance backs up.
+// Overtime without an attendance, like a pre-approval nobody worked, pays
+// nothing.
+func (o *OvertimeRequest) PaidMinutes() int {
+	if o.Attendance == nil {
+		return 0
+	}
+	if worked := o.Attendance.OvertimeMinutes(); worked < o.Minutes {
+		return worked
+	}
+	return o.Minutes
+}
+
+// OvertimeRequestRequest asks for overtime on WorkDate. Claims default to
+// the overtime recorded on that day when Minutes is left out.
+type OvertimeRequestRequest struct {
+	Kind     OvertimeKind `json:"kind" binding:"required,oneof=pre_approval claim"`
+	WorkDate Date         `json:"work_date"`
+	Minutes  int          `json:"minutes" binding:"omitempty,min=1,max=1440"`
+	Reason   string       `json:"reason" binding:"max=1000"`
+}
+
+type OvertimeDecisionRequest struct {
+	Note string `json:"note" binding:"max=1000"`
+}
+
+// OvertimeFilter holds the query parameters for listing overtime requests.
+// From and To are inclusive.
+type OvertimeFilter struct {
+	UserID  int            `form:"user_id" binding:"omitempty,min=1"`
+	UserIDs []uint64       `form:"-"`
+	Status  OvertimeStatus `form:"status" binding:"omitempty,oneof=pending approved rejected cancelled"`
+	From    Date           `form:"from"`
+	To      Date           `form:"to"`
+}
+
+// OvertimeSummaryQuery selects the month of a summary as "2006-01". It
+// defaults to the current month.
+type OvertimeSummaryQuery struct {
+	Month string `form:"month"`
+}
+
+// OvertimeSummary is the approved overtime of a user in a month, split by
+// type of day. WeightedHours applies the approved multipliers.
+type OvertimeSummary struct {
+	UserID         int     `json:"user_id"`
+	Firstname      string  `json:"firstname"`
+	Lastname       string  `json:"lastname"`
+	Email          string  `json:"email"`
+	Month          string  `json:"month"`
+	WeekdayMinutes int     `json:"weekday_minutes"`
+	WeekendMinutes int     `json:"weekend_minutes"`
+	HolidayMinutes int     `json:"holiday_minutes"`
+	TotalMinutes   int     `json:"total_minutes"`
+	WeightedHours  float64 `json:"weighted_hours"`
+}
@@ -0,0 +1,81 @@
+package models
+
+import "time"
+
+type PayPeriodStatus string
+
+const (
+	PayPeriodStatusOpen   PayPeriodStatus = "open"
+	PayPeriodStatusClosed PayPeriodStatus = "closed"
+)
+
+type PayPeriodAction string
+
+const (
+	PayPeriodActionClose  PayPeriodAction = "close"
+	PayPeriodActionReopen PayPeriodAction = "reopen"
+)
+
+type PayPeriodsResponse struct {
+	Status  int          `json:"status"`
+	Message string       `json:"message"`
+	Data    *[]PayPeriod `json:"data"`
+	Error   bool         `json:"error"`
+}
+
+type PayPeriodResponse struct {
+	Status  int        `json:"status"`
+	Message string     `json:"message"`
+	Data    *PayPeriod `json:"data"`
+	Error   bool       `json:"error"`
+}
+
+type PayPeriodEventsResponse struct {
+	Status  int               `json:"status"`
+	Message string            `json:"message"`
+	Data    *[]PayPeriodEvent `json:"data"`
+	Error   bool              `json:"error"`
+}
+
+// PayPeriod is a range of days paid together. While it is closed the
+// attendance and timesheets of its days can't change.
+type PayPeriod struct {
+	ID        int             `json:"id"`
+	StartDate Date            `json:"start_date"`
+	EndDate   Date            `json:"end_date"`
+	Status    PayPeriodStatus `json:"status"`
+	ClosedBy  *int            `json:"closed_by"`
+	ClosedAt  *time.Time      `json:"closed_at"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+type PayPeriodRequest struct {
+	StartDate Date `json:"start_date"`
+	EndDate   Date `json:"end_date"`
+}
+
+// PayPeriodActionRequest closes or reopens a pay period. Reopening needs a
+// reason, it is kept with the event.
+type PayPeriodActionRequest struct {
+	Reason string `json:"reason" binding:"max=1000"`
+}
+
+// PayPeriodEvent records one close or reopen of a pay period.
+type PayPeriodEvent struct {
+	ID          int             `json:"id"`
+	PayPeriodID int             `json:"pay_period_id"`
+	Action      PayPeriodAction `json:"action"`
+	UserID      *int            `json:"user_id"`
+	User        *User           `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Reason      string          `json:"reason"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// PayPeriodFilter holds the query parameters for listing pay periods. A
+// period is listed when any of its days falls between From and To.
+type PayPeriodFilter struct {
+	Status PayPeriodStatus `form:"status" binding:"omitempty,oneof=open closed"`
+	From   Date            `form:"from"`
+	To     Date            `form:"to"`
+}
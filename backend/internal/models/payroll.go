@@ -0,0 +1,170 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type PayrollRunStatus string
+
+const (
+	PayrollRunStatusDraft      PayrollRunStatus = "draft"
+	PayrollRunStatusCalculated PayrollRunStatus = "calculated"
+	PayrollRunStatusFinalized  PayrollRunStatus = "finalized"
+)
+
+type PayslipLineKind string
+
+const (
+	PayslipLineEarning   PayslipLineKind = "earning"
+	PayslipLineDeduction PayslipLineKind = "deduction"
+)
+
+type PayrollRunsResponse struct {
+	Status  int           `json:"status"`
+	Message string        `json:"message"`
+	Data    *[]PayrollRun `json:"data"`
+	Error   bool          `json:"error"`
+}
+
+type PayrollRunResponse struct {
+	Status  int         `json:"status"`
+	Message string      `json:"message"`
+	Data    *PayrollRun `json:"data"`
+	Error   bool        `json:"error"`
+}
+
+type PayslipsResponse struct {
+	Status  int        `json:"status"`
+	Message string     `json:"message"`
+	Data    *[]Payslip `json:"data"`
+	Error   bool       `json:"error"`
+}
+
+type PayslipResponse struct {
+	Status  int      `json:"status"`
+	Message string   `json:"message"`
+	Data    *Payslip `json:"data"`
+	Error   bool     `json:"error"`
+}
+
+type BankAccountResponse struct {
+	Status  int          `json:"status"`
+	Message string       `json:"message"`
+	Data    *BankAccount `json:"data"`
+	Error   bool         `json:"error"`
+}
+
+// PayrollRun pays the employees for one pay period. Calculating it again
+// replaces its payslips until it is finalized.
+type PayrollRun struct {
+	ID           int              `json:"id"`
+	PayPeriodID  int              `json:"pay_period_id"`
+	PayPeriod    *PayPeriod       `json:"pay_period,omitempty" gorm:"foreignKey:PayPeriodID"`
+	PayDate      Date             `json:"pay_date"`
+	Status       PayrollRunStatus `json:"status"`
+	CreatedBy    *int             `json:"created_by"`
+	CalculatedAt *time.Time       `json:"calculated_at"`
+	FinalizedBy  *int             `json:"finalized_by"`
+	FinalizedAt  *time.Time       `json:"finalized_at"`
+	CreatedAt    time.Time        `json:"created_at"`
+	UpdatedAt    time.Time        `json:"updated_at"`
+}
+
+type PayrollRunRequest struct {
+	PayPeriodID int  `json:"pay_period_id" binding:"required,min=1"`
+	PayDate     Date `json:"pay_date"`
+}
+
+type PayrollRunFilter struct {
+	Status PayrollRunStatus `form:"status" binding:"omitempty,oneof=draft calculated finalized"`
+}
+
+// PayslipFilter selects payslips. FinalizedOnly leaves out the payslips of
+// runs that can still change.
+type PayslipFilter struct {
+	PayrollRunID  int
+	UserID        int
+	FinalizedOnly bool
+}
+
+// Payslip is what one employee earns in a payroll run. GrossPay adds up the
+// earning lines, TotalDeductions the deduction lines.
+type Payslip struct {
+	ID              int             `json:"id"`
+	PayrollRunID    int             `json:"payroll_run_id"`
+	PayrollRun      *PayrollRun     `json:"payroll_run,omitempty" gorm:"foreignKey:PayrollRunID"`
+	UserID          int             `json:"user_id"`
+	User            *User           `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Currency        string          `json:"currency"`
+	WorkedMinutes   int             `json:"worked_minutes"`
+	OvertimeMinutes int             `json:"overtime_minutes"`
+	GrossPay        decimal.Decimal `json:"gross_pay" gorm:"type:numeric(14,2)"`
+	TotalDeductions decimal.Decimal `json:"total_deductions" gorm:"type:numeric(14,2)"`
+	NetPay          decimal.Decimal `json:"net_pay" gorm:"type:numeric(14,2)"`
+	Lines           []PayslipLine   `json:"lines,omitempty" gorm:"foreignKey:PayslipID"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+}
+
+// AddEarning appends an earning line rounded to cents and updates the
+// totals. Zero amounts are left out.
+func (p *Payslip) AddEarning(code, description string, amount decimal.Decimal) {
+	p.addLine(PayslipLineEarning, code, description, amount)
+}
+
+// AddDeduction appends a deduction line rounded to cents and updates the
+// totals. Zero amounts are left out.
+func (p *Payslip) AddDeduction(code, description string, amount decimal.Decimal) {
+	p.addLine(PayslipLineDeduction, code, description, amount)
+}
+
+func (p *Payslip) addLine(kind PayslipLineKind, code, description string, amount decimal.Decimal) {
+	amount = amount.Round(2)
+	if amount.IsZero() {
+		return
+	}
+	p.Lines = append(p.Lines, PayslipLine{
+		Kind:        kind,
+		Code:        code,
+		Description: description,
+		Amount:      amount,
+		Position:    len(p.Lines),
+	})
+
+	if kind == PayslipLineEarning {
+		p.GrossPay = p.GrossPay.Add(amount)
+	} else {
+		p.TotalDeductions = p.TotalDeductions.Add(amount)
+	}
+	p.NetPay = p.GrossPay.Sub(p.TotalDeductions)
+}
+
+// PayslipLine is one earning or deduction on a payslip.
+type PayslipLine struct {
+	ID          int             `json:"id"`
+	PayslipID   int             `json:"payslip_id"`
+	Kind        PayslipLineKind `json:"kind"`
+	Code        string          `json:"code"`
+	Description string          `json:"description"`
+	Amount      decimal.Decimal `json:"amount" gorm:"type:numeric(14,2)"`
+	Position    int             `json:"position"`
+}
+
+// BankAccount is where an employee's net pay is transferred to.
+type BankAccount struct {
+	ID            int       `json:"id"`
+	UserID        int       `json:"user_id"`
+	BankName      string    `json:"bank_name"`
+	AccountNumber string    `json:"account_number"`
+	AccountHolder string    `json:"account_holder"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type BankAccountRequest struct {
+	BankName      string `json:"bank_name" binding:"required,max=100"`
+	AccountNumber string `json:"account_number" binding:"required,max=50"`
+	AccountHolder string `json:"account_holder" binding:"required,max=255"`
+}
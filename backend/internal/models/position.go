@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PositionsResponse struct {
+	Status  int         `json:"status"`
+	Message string      `json:"message"`
+	Data    *[]Position `json:"data"`
+	Error   bool        `json:"error"`
+}
+
+type PositionResponse struct {
+	Status  int       `json:"status"`
+	Message string    `json:"message"`
+	Data    *Position `json:"data"`
+	Error   bool      `json:"error"`
+}
+
+type Position struct {
+	ID        int            `json:"id"`
+	Name      string         `json:"name"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at"`
+}
+
+type PositionRequest struct {
+	Name string `json:"name" binding:"required,max=50"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type RolesResponse struct {
+	Status  int     `json:"status"`
+	Message string  `json:"message"`
+	Data    *[]Role `json:"data"`
+	Error   bool    `json:"error"`
+}
+
+type RoleResponse struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+	Data    *Role  `json:"data"`
+	Error   bool   `json:"error"`
+}
+
+type Role struct {
+	ID          int              `json:"id"`
+	Name        string           `json:"name"`
+	Permissions []RolePermission `json:"permissions,omitempty" gorm:"foreignKey:RoleID"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt   `json:"deleted_at" gorm:"column:deleted_at"`
+}
+
+type RolePermission struct {
+	ID         int       `json:"id"`
+	RoleID     int       `json:"role_id"`
+	Permission string    `json:"permission"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type RoleRequest struct {
+	Name        string   `json:"name" binding:"required,max=50"`
+	Permissions []string `json:"permissions"`
+}
@@ -0,0 +1,237 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ShiftsResponse struct {
+	Status  int      `json:"status"`
+	Message string   `json:"message"`
+	Data    *[]Shift `json:"data"`
+	Error   bool     `json:"error"`
+}
+
+type ShiftResponse struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+	Data    *Shift `json:"data"`
+	Error   bool   `json:"error"`
+}
+
+type ShiftAssignmentsResponse struct {
+	Status  int                `json:"status"`
+	Message string             `json:"message"`
+	Data    *[]ShiftAssignment `json:"data"`
+	Error   bool               `json:"error"`
+}
+
+type ShiftAssignmentResponse struct {
+	Status  int              `json:"status"`
+	Message string           `json:"message"`
+	Data    *ShiftAssignment `json:"data"`
+	Error   bool             `json:"error"`
+}
+
+type RosterResponse struct {
+	Status  int     `json:"status"`
+	Message string  `json:"message"`
+	Data    *Roster `json:"data"`
+	Error   bool    `json:"error"`
+}
+
+type ShiftConflictsResponse struct {
+	Status  int              `json:"status"`
+	Message string           `json:"message"`
+	Data    *[]ShiftConflict `json:"data"`
+	Error   bool             `json:"error"`
+}
+
+// Shift is a template for a working day. StartTime and EndTime are "15:04"
+// times of day; a shift ending at or before its start ends on the next day.
+type Shift struct {
+	ID           int       `json:"id"`
+	Name         string    `json:"name"`
+	StartTime    string    `json:"start_time"`
+	EndTime      string    `json:"end_time"`
+	BreakMinutes int       `json:"break_minutes"`
+	Weekdays     []int     `json:"weekdays" gorm:"-"`
+	WeekdayMask  int       `json:"-" gorm:"column:weekdays"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// RunsOn tells whether the shift is worked on the weekday.
+func (s *Shift) RunsOn(weekday time.Weekday) bool {
+	return s.WeekdayMask&(1<<uint(weekday)) != 0
+}
+
+// SetWeekdays stores the weekdays the shift runs on, 0 being Sunday.
+func (s *Shift) SetWeekdays(weekdays []int) {
+	s.WeekdayMask = 0
+	for _, weekday := range weekdays {
+		s.WeekdayMask |= 1 << uint(weekday)
+	}
+	s.syncWeekdays()
+}
+
+// Offsets returns the start and end of the shift as offsets from midnight
+// of the day it starts on.
+func (s *Shift) Offsets() (time.Duration, time.Duration) {
+	start, end := clockOffset(s.StartTime), clockOffset(s.EndTime)
+	if end <= start {
+		end += 24 * time.Hour
+	}
+	return start, end
+}
+
+func (s *Shift) syncWeekdays() {
+	s.Weekdays = []int{}
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		if s.RunsOn(weekday) {
+			s.Weekdays = append(s.Weekdays, int(weekday))
+		}
+	}
+}
+
+// AfterFind drops the seconds Postgres adds to TIME values.
+func (s *Shift) AfterFind(tx *gorm.DB) error {
+	if len(s.StartTime) > 5 {
+		s.StartTime = s.StartTime[:5]
+	}
+	if len(s.EndTime) > 5 {
+		s.EndTime = s.EndTime[:5]
+	}
+	s.syncWeekdays()
+	return nil
+}
+
+func clockOffset(value string) time.Duration {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+}
+
+type ShiftRequest struct {
+	Name         string `json:"name" binding:"required,max=100"`
+	StartTime    string `json:"start_time" binding:"required"`
+	EndTime      string `json:"end_time" binding:"required"`
+	BreakMinutes int    `json:"break_minutes" binding:"min=0"`
+	Weekdays     []int  `json:"weekdays" binding:"required,dive,min=0,max=6"`
+}
+
+// ShiftAssignment gives a user a shift on every day between StartDate and
+// EndDate, both inclusive, that the shift runs on. Conflicts is only filled
+// in when an assignment is created or changed.
+type ShiftAssignment struct {
+	ID        int             `json:"id"`
+	UserID    int             `json:"user_id"`
+	User      *User           `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	ShiftID   int             `json:"shift_id"`
+	Shift     *Shift          `json:"shift,omitempty" gorm:"foreignKey:ShiftID"`
+	StartDate Date            `json:"start_date"`
+	EndDate   Date            `json:"end_date"`
+	Conflicts []ShiftConflict `json:"conflicts,omitempty" gorm:"-"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// ScheduledOn tells whether the assignment puts the user on shift on day.
+// Shift must be loaded.
+func (a *ShiftAssignment) ScheduledOn(day Date) bool {
+	return a.Shift != nil && !day.Before(a.StartDate) && !day.After(a.EndDate) && a.Shift.RunsOn(day.Weekday())
+}
+
+// Overlaps tells whether both assignments schedule a shift on the same day.
+func (a *ShiftAssignment) Overlaps(other ShiftAssignment) bool {
+	from, to := a.StartDate, a.EndDate
+	if other.StartDate.After(from) {
+		from = other.StartDate
+	}
+	if other.EndDate.Before(to) {
+		to = other.EndDate
+	}
+	// Weekdays repeat, a week of common days covers every case.
+	for day, checked := from, 0; !day.After(to) && checked < 7; day, checked = day.AddDays(1), checked+1 {
+		if a.ScheduledOn(day) && other.ScheduledOn(day) {
+			return true
+		}
+	}
+	return false
+}
+
+type ShiftAssignmentRequest struct {
+	UserID    int  `json:"user_id" binding:"required,min=1"`
+	ShiftID   int  `json:"shift_id" binding:"required,min=1"`
+	StartDate Date `json:"start_date"`
+	EndDate   Date `json:"end_date"`
+}
+
+// ShiftAssignmentFilter holds the query parameters for listing shift
+// assignments. Assignments overlapping From to To are listed.
+type ShiftAssignmentFilter struct {
+	UserID       int  `form:"user_id" binding:"omitempty,min=1"`
+	DepartmentID int  `form:"department_id" binding:"omitempty,min=1"`
+	From         Date `form:"from"`
+	To           Date `form:"to"`
+}
+
+// RosterQuery selects the department and the week of a roster. Week may be
+// any day of the week and defaults to the current one.
+type RosterQuery struct {
+	DepartmentID int  `form:"department_id" binding:"required,min=1"`
+	Week         Date `form:"week"`
+}
+
+// Roster is the week of a department, Monday to Sunday.
+type Roster struct {
+	DepartmentID int          `json:"department_id"`
+	From         Date         `json:"from"`
+	To           Date         `json:"to"`
+	Users        []RosterUser `json:"users"`
+}
+
+type RosterUser struct {
+	UserID    int         `json:"user_id"`
+	Firstname string      `json:"firstname"`
+	Lastname  string      `json:"lastname"`
+	Days      []RosterDay `json:"days"`
+}
+
+// RosterDay is the shift of a user on one day, if any, and whether the user
+// is on approved leave.
+type RosterDay struct {
+	Date         Date   `json:"date"`
+	AssignmentID *int   `json:"assignment_id"`
+	Shift        *Shift `json:"shift"`
+	OnLeave      bool   `json:"on_leave"`
+}
+
+type ShiftConflictType string
+
+const (
+	// ShiftConflictOverlap is a day two assignments put the user on shift.
+	ShiftConflictOverlap ShiftConflictType = "overlap"
+	// ShiftConflictLeave is a shift on a day of approved leave.
+	ShiftConflictLeave ShiftConflictType = "leave"
+)
+
+type ShiftConflict struct {
+	Type              ShiftConflictType `json:"type"`
+	UserID            int               `json:"user_id"`
+	Date              Date              `json:"date"`
+	AssignmentID      int               `json:"assignment_id"`
+	OtherAssignmentID *int              `json:"other_assignment_id,omitempty"`
+	LeaveRequestID    *int              `json:"leave_request_id,omitempty"`
+}
+
+// ShiftConflictQuery holds the query parameters for listing conflicts.
+type ShiftConflictQuery struct {
+	UserID       int  `form:"user_id" binding:"omitempty,min=1"`
+	DepartmentID int  `form:"department_id" binding:"omitempty,min=1"`
+	From         Date `form:"from"`
+	To           Date `form:"to"`
+}
@@ -0,0 +1,99 @@
+package models
+
+import "time"
+
+type TimesheetStatus string
+
+const (
+	TimesheetStatusDraft     TimesheetStatus = "draft"
+	TimesheetStatusSubmitted TimesheetStatus = "submitted"
+	TimesheetStatusApproved  TimesheetStatus = "approved"
+	TimesheetStatusRejected  TimesheetStatus = "rejected"
+)
+
+type TimesheetsResponse struct {
+	Status  int          `json:"status"`
+	Message string       `json:"message"`
+	Data    *[]Timesheet `json:"data"`
+	Error   bool         `json:"error"`
+}
+
+type TimesheetResponse struct {
+	Status  int        `json:"status"`
+	Message string     `json:"message"`
+	Data    *Timesheet `json:"data"`
+	Error   bool       `json:"error"`
+}
+
+// Timesheet is the work of one user over one timesheet period, split into
+// entries per day and optionally per project and task. Only drafts and
+// rejected timesheets can be edited.
+type Timesheet struct {
+	ID           int              `json:"id"`
+	UserID       int              `json:"user_id"`
+	User         *User            `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	PeriodStart  Date             `json:"period_start"`
+	PeriodEnd    Date             `json:"period_end"`
+	Status       TimesheetStatus  `json:"status"`
+	TotalMinutes int              `json:"total_minutes"`
+	Entries      []TimesheetEntry `json:"entries,omitempty" gorm:"foreignKey:TimesheetID"`
+	SubmittedAt  *time.Time       `json:"submitted_at"`
+	DecidedBy    *int             `json:"decided_by"`
+	DecisionNote string           `json:"decision_note"`
+	DecidedAt    *time.Time       `json:"decided_at"`
+	CreatedAt    time.Time        `json:"created_at"`
+	UpdatedAt    time.Time        `json:"updated_at"`
+}
+
+// IsEditable tells whether the owner may still change the entries.
+func (t *Timesheet) IsEditable() bool {
+	return t.Status == TimesheetStatusDraft || t.Status == TimesheetStatusRejected
+}
+
+// TimesheetEntry is time worked on one day. Project and Task are empty for
+// time that isn't allocated.
+type TimesheetEntry struct {
+	ID           int       `json:"id"`
+	TimesheetID  int       `json:"timesheet_id"`
+	AttendanceID *int      `json:"attendance_id"`
+	WorkDate     Date      `json:"work_date"`
+	Minutes      int       `json:"minutes"`
+	Project      string    `json:"project"`
+	Task         string    `json:"task"`
+	Note         string    `json:"note"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// TimesheetRequest starts the timesheet of the period beginning on
+// PeriodStart, or of the current period when it is left out.
+type TimesheetRequest struct {
+	PeriodStart Date `json:"period_start"`
+}
+
+// TimesheetEntriesRequest replaces all entries of a timesheet.
+type TimesheetEntriesRequest struct {
+	Entries []TimesheetEntryRequest `json:"entries" binding:"dive"`
+}
+
+type TimesheetEntryRequest struct {
+	WorkDate Date   `json:"work_date"`
+	Minutes  int    `json:"minutes" binding:"required,min=1,max=1440"`
+	Project  string `json:"project" binding:"max=100"`
+	Task     string `json:"task" binding:"max=255"`
+	Note     string `json:"note" binding:"max=1000"`
+}
+
+type TimesheetDecisionRequest struct {
+	Note string `json:"note" binding:"max=1000"`
+}
+
+// TimesheetFilter holds the query parameters for listing timesheets. A
+// timesheet is listed when its period starts between From and To.
+type TimesheetFilter struct {
+	UserID  int             `form:"user_id" binding:"omitempty,min=1"`
+	UserIDs []uint64        `form:"-"`
+	Status  TimesheetStatus `form:"status" binding:"omitempty,oneof=draft submitted approved rejected"`
+	From    Date            `form:"from"`
+	To      Date            `form:"to"`
+}
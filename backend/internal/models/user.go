@@ -7,10 +7,43 @@ import (
 )
 
 type UsersResponse struct {
-	Status  int     `json:"status"`
-	Message string  `json:"message"`
-	Data    *[]User `json:"data"`
-	Error   bool    `json:"error"`
+	Status  int             `json:"status"`
+	Message string          `json:"message"`
+	Data    *[]User         `json:"data"`
+	Meta    *PaginationMeta `json:"meta,omitempty"`
+	Error   bool            `json:"error"`
+}
+
+type PaginationMeta struct {
+	Page       int    `json:"page,omitempty"`
+	Limit      int    `json:"limit"`
+	Total      int64  `json:"total"`
+	TotalPages int    `json:"total_pages"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// UserFilter holds the query parameters of GET /users. Cursor takes
+// precedence over Page and is decoded into After by the service.
+type UserFilter struct {
+	Page         int         `form:"page" binding:"omitempty,min=1"`
+	Limit        int         `form:"limit" binding:"omitempty,min=1,max=100"`
+	Cursor       string      `form:"cursor"`
+	Sort         string      `form:"sort" binding:"omitempty,oneof=name email created_at"`
+	Order        string      `form:"order" binding:"omitempty,oneof=asc desc"`
+	RoleID       int         `form:"role_id" binding:"omitempty,min=1"`
+	PositionID   int         `form:"position_id" binding:"omitempty,min=1"`
+	DepartmentID int         `form:"department_id" binding:"omitempty,min=1"`
+	Search       string      `form:"search"`
+	After        *UserCursor `form:"-"`
+}
+
+// UserCursor is the position of the last user of a page in the sort order.
+type UserCursor struct {
+	ID        int       `json:"id"`
+	Firstname string    `json:"firstname,omitempty"`
+	Lastname  string    `json:"lastname,omitempty"`
+	Email     string    `json:"email,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
 }
 
 type UserResponse struct {
@@ -21,43 +54,68 @@ type UserResponse struct {
 }
 
 type User struct {
-	Id         int            `json:"id"`
-	Firstname  string         `json:"firstname"`
-	Lastname   string         `json:"lastname"`
-	Password   string         `json:"password"`
-	Email      string         `json:"email"`
-	RoleID     int            `json:"role_id"`
-	PositionID int            `json:"position_id"`
-	Role       Role           `json:"role" gorm:"foreignKey:RoleID"`
-	Position   Position       `json:"position" gorm:"foreignKey:PositionID"`
-	CreatedAt  time.Time      `json:"created_at"`
-	UpdatedAt  time.Time      `json:"updated_at"`
-	DeletedAt  gorm.DeletedAt `json:"-" gorm:"column:deleted_at"`
-}
-
-type Role struct {
-	ID        int       `json:"id"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	DeletedAt time.Time `json:"deleted_at"`
+	Id           int            `json:"id"`
+	Firstname    string         `json:"firstname"`
+	Lastname     string         `json:"lastname"`
+	Password     string         `json:"-"`
+	Email        string         `json:"email"`
+	RoleID       int            `json:"role_id"`
+	PositionID   int            `json:"position_id"`
+	DepartmentID *int           `json:"department_id"`
+	ManagerID    *int           `json:"manager_id"`
+	HireDate     Date           `json:"hire_date"`
+	Version      int            `json:"version" gorm:"default:1"`
+	Role         Role           `json:"role" gorm:"foreignKey:RoleID"`
+	Position     Position       `json:"position" gorm:"foreignKey:PositionID"`
+	Department   *Department    `json:"department,omitempty" gorm:"foreignKey:DepartmentID"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `json:"-" gorm:"column:deleted_at"`
 }
 
-type Position struct {
-	ID        int       `json:"id"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	DeletedAt time.Time `json:"deleted_at"`
+// DeletedUserFilter holds the query parameters of GET /users/deleted.
+type DeletedUserFilter struct {
+	Page  int `form:"page" binding:"omitempty,min=1"`
+	Limit int `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// DeletedUser is a soft deleted user. PurgeAfter is when the retention
+// period ends and the user can be deleted for good.
+type DeletedUser struct {
+	User
+	DeletedAt  time.Time `json:"deleted_at"`
+	PurgeAfter time.Time `json:"purge_after"`
+}
+
+type DeletedUsersResponse struct {
+	Status  int             `json:"status"`
+	Message string          `json:"message"`
+	Data    *[]DeletedUser  `json:"data"`
+	Meta    *PaginationMeta `json:"meta,omitempty"`
+	Error   bool            `json:"error"`
 }
 
 type UserRequest struct {
-	Firstname  string `json:"firstname" binding:"required"`
-	Lastname   string `json:"lastname" binding:"required"`
+	Firstname  string `json:"firstname" binding:"required,max=50,personname"`
+	Lastname   string `json:"lastname" binding:"required,max=50,personname"`
 	Email      string `json:"email" binding:"required,email"`
-	Password   string `json:"password" binding:"required"`
+	Password   string `json:"password" binding:"required,max=72,password"`
 	RoleID     int    `json:"role_id" binding:"required"`
 	PositionID int    `json:"position_id" binding:"required"`
+	HireDate   Date   `json:"hire_date"`
+}
+
+// UserPatch is a user as PATCH /users/:id sees it. The merge patch is
+// applied to the current user and the result has to pass these rules.
+// Password is never read back, so it is only set when the patch sets it.
+type UserPatch struct {
+	Firstname  string `json:"firstname" binding:"required,max=50,personname"`
+	Lastname   string `json:"lastname" binding:"required,max=50,personname"`
+	Email      string `json:"email" binding:"required,email"`
+	Password   string `json:"password,omitempty" binding:"omitempty,max=72,password"`
+	RoleID     int    `json:"role_id" binding:"required"`
+	PositionID int    `json:"position_id" binding:"required"`
+	HireDate   Date   `json:"hire_date"`
 }
 
 type AuthRequest struct {
@@ -65,11 +123,27 @@ type AuthRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
 type AuthResponse struct {
-	Status  int    `json:"status"`
-	Message string `json:"message"`
-	Token   string `json:"token"`
-	Error   bool   `json:"error"`
+	Status  int         `json:"status"`
+	Message string      `json:"message"`
+	Data    *AuthTokens `json:"data"`
+	Error   bool        `json:"error"`
+}
+
+// AuthTokens is a new access token and the refresh token to renew it with.
+// ExpiresIn is the lifetime of the access token in seconds.
+type AuthTokens struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
 }
 
 type BlackListedToken struct {
@@ -78,3 +152,14 @@ type BlackListedToken struct {
 	CreatedAt time.Time `json:"created_at"`
 	ExpiredAt time.Time `json:"expired_at"`
 }
+
+type RefreshToken struct {
+	ID        int        `json:"id"`
+	UserID    int        `json:"user_id"`
+	FamilyID  string     `json:"family_id"`
+	TokenHash string     `json:"-"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiredAt time.Time  `json:"expired_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+}
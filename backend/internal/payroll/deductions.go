@@ -0,0 +1,79 @@
+package payroll
+
+import (
+	"github.com/shopspring/decimal"
+	"main.go/internal/models"
+)
+
+// StatutoryDeductions deducts tax and contributions with the rule set in
+// input.DeductionRules. Without a rule set it deducts nothing.
+type StatutoryDeductions struct{}
+
+func (StatutoryDeductions) Apply(input Input, payslip *models.Payslip) error {
+	if input.DeductionRules == nil {
+		return nil
+	}
+	ApplyDeductionRules(input.DeductionRules.Rules, payslip)
+	return nil
+}
+
+// ApplyDeductionRules adds a deduction line per rule, in order. Rules are
+// applied to the pay left after the deductions already on the payslip, such
+// as unpaid leave, not to what earlier rules of the set deducted.
+func ApplyDeductionRules(rules []models.DeductionRule, payslip *models.Payslip) {
+	pay := decimal.Max(payslip.NetPay, decimal.Zero)
+	taxable := pay
+
+	for _, rule := range rules {
+		description := rule.Description
+		if description == "" {
+			description = rule.Code
+		}
+
+		switch rule.Type {
+		case models.DeductionProgressiveTax:
+			payslip.AddDeduction(rule.Code, description, progressiveTax(rule.Brackets, taxable))
+		case models.DeductionContribution:
+			base := pay
+			if rule.BaseCap != nil && base.GreaterThan(*rule.BaseCap) {
+				base = *rule.BaseCap
+			}
+			amount := base.Mul(decimalOrZero(rule.Rate)).Round(2)
+			payslip.AddDeduction(rule.Code, description, amount)
+			if rule.TaxDeductible {
+				taxable = decimal.Max(taxable.Sub(amount), decimal.Zero)
+			}
+		case models.DeductionFixed:
+			payslip.AddDeduction(rule.Code, description, decimalOrZero(rule.Amount))
+		}
+	}
+}
+
+// progressiveTax taxes every slice of pay at the rate of the bracket it
+// falls in.
+func progressiveTax(brackets []models.TaxBracket, pay decimal.Decimal) decimal.Decimal {
+	tax := decimal.Zero
+	lower := decimal.Zero
+	for _, bracket := range brackets {
+		if !pay.GreaterThan(lower) {
+			break
+		}
+		upper := pay
+		if bracket.UpTo != nil && bracket.UpTo.LessThan(pay) {
+			upper = *bracket.UpTo
+		}
+		tax = tax.Add(upper.Sub(lower).Mul(bracket.Rate))
+		if bracket.UpTo == nil {
+			break
+		}
+		lower = *bracket.UpTo
+	}
+	return tax
+}
+
+func decimalOrZero(value *decimal.Decimal) decimal.Decimal {
+	if value == nil {
+		return decimal.Zero
+	}
+	return *value
+}
@@ -0,0 +1,127 @@
+package payroll
+
+import (
+	"testing"
+
+	"main.go/internal/models"
+)
+
+func TestProgressiveTax(t *testing.T) {
+	brackets := []models.TaxBracket{
+		{UpTo: decimalPtr("1000"), Rate: amount("0.1")},
+		{UpTo: decimalPtr("3000"), Rate: amount("0.2")},
+		{Rate: amount("0.3")},
+	}
+
+	tests := []struct {
+		name     string
+		brackets []models.TaxBracket
+		pay      string
+		want     string
+	}{
+		{name: "no pay", brackets: brackets, pay: "0", want: "0"},
+		{name: "within the first bracket", brackets: brackets, pay: "500", want: "50"},
+		{name: "at the first edge", brackets: brackets, pay: "1000", want: "100"},
+		{name: "a cent over the first edge", brackets: brackets, pay: "1000.01", want: "100.002"},
+		{name: "at the second edge", brackets: brackets, pay: "3000", want: "500"},
+		{name: "in the open bracket", brackets: brackets, pay: "5000", want: "1100"},
+		{name: "no brackets", pay: "5000", want: "0"},
+		{
+			name:     "pay above a closed last bracket is untaxed",
+			brackets: []models.TaxBracket{{UpTo: decimalPtr("1000"), Rate: amount("0.1")}},
+			pay:      "5000",
+			want:     "100",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := progressiveTax(tt.brackets, amount(tt.pay)); !got.Equal(amount(tt.want)) {
+				t.Errorf("tax = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyDeductionRules(t *testing.T) {
+	tax := models.DeductionRule{Code: "TAX", Type: models.DeductionProgressiveTax, Brackets: []models.TaxBracket{
+		{UpTo: decimalPtr("1000"), Rate: amount("0.1")},
+		{Rate: amount("0.2")},
+	}}
+
+	tests := []struct {
+		name       string
+		earnings   string
+		deductions string
+		rules      []models.DeductionRule
+		want       map[string]string
+	}{
+		{
+			name:     "contribution rounds to cents",
+			earnings: "1234.56",
+			rules:    []models.DeductionRule{{Code: "SOCIAL", Type: models.DeductionContribution, Rate: decimalPtr("0.062")}},
+			want:     map[string]string{"SOCIAL": "76.54"},
+		},
+		{
+			name:     "contribution capped at the base cap",
+			earnings: "20000",
+			rules:    []models.DeductionRule{{Code: "SOCIAL", Type: models.DeductionContribution, Rate: decimalPtr("0.062"), BaseCap: decimalPtr("14000")}},
+			want:     map[string]string{"SOCIAL": "868"},
+		},
+		{
+			name:     "tax deductible contribution lowers taxable pay",
+			earnings: "2000",
+			rules: []models.DeductionRule{
+				{Code: "SOCIAL", Type: models.DeductionContribution, Rate: decimalPtr("0.05"), TaxDeductible: true},
+				tax,
+			},
+			// Tax on 1900.
+			want: map[string]string{"SOCIAL": "100", "TAX": "280"},
+		},
+		{
+			name:     "other contributions don't lower taxable pay",
+			earnings: "2000",
+			rules: []models.DeductionRule{
+				{Code: "SOCIAL", Type: models.DeductionContribution, Rate: decimalPtr("0.05")},
+				tax,
+			},
+			want: map[string]string{"SOCIAL": "100", "TAX": "300"},
+		},
+		{
+			name:       "rules apply to pay after unpaid leave",
+			earnings:   "2000",
+			deductions: "500",
+			rules:      []models.DeductionRule{tax},
+			want:       map[string]string{"TAX": "200"},
+		},
+		{
+			name:     "tax rounds to cents",
+			earnings: "1000.03",
+			rules:    []models.DeductionRule{tax},
+			want:     map[string]string{"TAX": "100.01"},
+		},
+		{
+			name:     "fixed amount",
+			earnings: "2000",
+			rules:    []models.DeductionRule{{Code: "UNION", Type: models.DeductionFixed, Amount: decimalPtr("12.5")}},
+			want:     map[string]string{"UNION": "12.5"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payslip := models.Payslip{}
+			payslip.AddEarning("BASE", "Base pay", amount(tt.earnings))
+			if tt.deductions != "" {
+				payslip.AddDeduction("UNPAID_LEAVE", "Unpaid leave", amount(tt.deductions))
+			}
+
+			ApplyDeductionRules(tt.rules, &payslip)
+			for code, value := range tt.want {
+				if got := lineAmount(payslip, code); !got.Equal(amount(value)) {
+					t.Errorf("%s = %s, want %s", code, got, value)
+				}
+			}
+		})
+	}
+}
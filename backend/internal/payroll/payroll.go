@@ -0,0 +1,102 @@
+// Package payroll turns what is known about an employee in a pay period
+// into a payslip. The calculation is a list of rules, each adding earning or
+// deduction lines, so rules can be added or swapped without touching the
+// payroll runs around them.
+package payroll
+
+import (
+	"github.com/shopspring/decimal"
+	"main.go/internal/apperror"
+	"main.go/internal/models"
+)
+
+var (
+	ErrNoCompensation  = apperror.Conflict("no_compensation_in_the_pay_period", "no compensation in the pay period")
+	ErrMixedCurrencies = apperror.Conflict("compensation_currency_changes_within_the_pay_period", "compensation currency changes within the pay period")
+	ErrNegativeNetPay  = apperror.Conflict("deductions_exceed_gross_pay", "deductions exceed gross pay")
+)
+
+// Input is everything one employee's payslip is calculated from.
+type Input struct {
+	UserID int
+	From   models.Date
+	To     models.Date
+
+	// Compensations in effect on at least one day from From to To, oldest
+	// first.
+	Compensations []models.Compensation
+	// Attendances are the checked-out attendances of the period.
+	Attendances []models.Attendance
+	// Overtime is the approved overtime of the period.
+	Overtime []models.OvertimeRequest
+
+	// WorkingDays counts the working days of the period on the employee's
+	// calendar, UnpaidLeaveDays the ones taken as unpaid leave.
+	WorkingDays     int
+	UnpaidLeaveDays int
+
+	// HoursPerWeek turns salaries into an hourly rate for overtime.
+	HoursPerWeek decimal.Decimal
+
+	// DeductionRules is the rule set of the employee's currency on the pay
+	// date, or nil when there is none.
+	DeductionRules *models.DeductionRuleSet
+}
+
+// Rule adds its lines to a payslip.
+type Rule interface {
+	Apply(input Input, payslip *models.Payslip) error
+}
+
+// RuleFunc lets a plain function be used as a Rule.
+type RuleFunc func(input Input, payslip *models.Payslip) error
+
+func (f RuleFunc) Apply(input Input, payslip *models.Payslip) error {
+	return f(input, payslip)
+}
+
+// Engine applies its rules in order.
+type Engine struct {
+	rules []Rule
+}
+
+func NewEngine(rules ...Rule) *Engine {
+	return &Engine{rules: rules}
+}
+
+// DefaultRules pays base pay, allowances and overtime and deducts unpaid
+// leave and statutory deductions.
+func DefaultRules() []Rule {
+	return []Rule{BasePay{}, Allowances{}, Overtime{}, UnpaidLeave{}, StatutoryDeductions{}}
+}
+
+// Calculate returns the payslip of input, without a payroll run yet.
+func (e *Engine) Calculate(input Input) (models.Payslip, error) {
+	if len(input.Compensations) == 0 {
+		return models.Payslip{}, ErrNoCompensation
+	}
+	currency := input.Compensations[0].Currency
+	for _, compensation := range input.Compensations {
+		if compensation.Currency != currency {
+			return models.Payslip{}, ErrMixedCurrencies
+		}
+	}
+
+	payslip := models.Payslip{UserID: input.UserID, Currency: currency}
+	for _, attendance := range input.Attendances {
+		payslip.WorkedMinutes += attendance.WorkedMinutes
+	}
+	for i := range input.Overtime {
+		payslip.OvertimeMinutes += input.Overtime[i].PaidMinutes()
+	}
+
+	for _, rule := range e.rules {
+		if err := rule.Apply(input, &payslip); err != nil {
+			return models.Payslip{}, err
+		}
+	}
+	if payslip.NetPay.IsNegative() {
+		return models.Payslip{}, ErrNegativeNetPay
+	}
+	return payslip, nil
+}
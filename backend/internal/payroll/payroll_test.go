@@ -0,0 +1,275 @@
+package payroll
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"main.go/internal/models"
+)
+
+func date(t *testing.T, value string) models.Date {
+	t.Helper()
+	d, err := models.ParseDate(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func amount(value string) decimal.Decimal {
+	return decimal.RequireFromString(value)
+}
+
+func compensation(t *testing.T, effective string, frequency models.PayFrequency, basePay string) models.Compensation {
+	return models.Compensation{EffectiveDate: date(t, effective), BasePay: amount(basePay), Currency: "USD", PayFrequency: frequency}
+}
+
+func lineAmount(payslip models.Payslip, code string) decimal.Decimal {
+	total := decimal.Zero
+	for _, line := range payslip.Lines {
+		if line.Code == code {
+			total = total.Add(line.Amount)
+		}
+	}
+	return total
+}
+
+func TestCalculateBasePay(t *testing.T) {
+	tests := []struct {
+		name          string
+		from, to      string
+		compensations func(t *testing.T) []models.Compensation
+		attendances   []models.Attendance
+		want          string
+	}{
+		{
+			name: "monthly whole month",
+			from: "2026-01-01", to: "2026-01-31",
+			compensations: func(t *testing.T) []models.Compensation {
+				return []models.Compensation{compensation(t, "2025-06-01", models.PayFrequencyMonthly, "3000")}
+			},
+			want: "3000",
+		},
+		{
+			name: "monthly part month rounds to cents",
+			from: "2026-01-01", to: "2026-01-10",
+			compensations: func(t *testing.T) []models.Compensation {
+				return []models.Compensation{compensation(t, "2025-06-01", models.PayFrequencyMonthly, "3000")}
+			},
+			want: "967.74",
+		},
+		{
+			name: "monthly across two months",
+			from: "2026-01-17", to: "2026-02-14",
+			compensations: func(t *testing.T) []models.Compensation {
+				return []models.Compensation{compensation(t, "2025-06-01", models.PayFrequencyMonthly, "3100")}
+			},
+			// 15/31 of January and 14/28 of February.
+			want: "3050",
+		},
+		{
+			name: "raise within the period",
+			from: "2026-01-01", to: "2026-01-31",
+			compensations: func(t *testing.T) []models.Compensation {
+				return []models.Compensation{
+					compensation(t, "2025-06-01", models.PayFrequencyMonthly, "3100"),
+					compensation(t, "2026-01-16", models.PayFrequencyMonthly, "6200"),
+				}
+			},
+			want: "4700",
+		},
+		{
+			name: "hired within the period",
+			from: "2026-02-01", to: "2026-02-28",
+			compensations: func(t *testing.T) []models.Compensation {
+				return []models.Compensation{compensation(t, "2026-02-15", models.PayFrequencyMonthly, "2800")}
+			},
+			want: "1400",
+		},
+		{
+			name: "annual whole month",
+			from: "2026-03-01", to: "2026-03-31",
+			compensations: func(t *testing.T) []models.Compensation {
+				return []models.Compensation{compensation(t, "2025-06-01", models.PayFrequencyAnnual, "36000")}
+			},
+			want: "3000",
+		},
+		{
+			name: "weekly by days",
+			from: "2026-01-05", to: "2026-01-14",
+			compensations: func(t *testing.T) []models.Compensation {
+				return []models.Compensation{compensation(t, "2025-06-01", models.PayFrequencyWeekly, "700")}
+			},
+			want: "1000",
+		},
+		{
+			name: "biweekly by days",
+			from: "2026-01-05", to: "2026-01-11",
+			compensations: func(t *testing.T) []models.Compensation {
+				return []models.Compensation{compensation(t, "2025-06-01", models.PayFrequencyBiweekly, "1400")}
+			},
+			want: "700",
+		},
+		{
+			name: "hourly half cent rounds up",
+			from: "2026-01-01", to: "2026-01-31",
+			compensations: func(t *testing.T) []models.Compensation {
+				return []models.Compensation{compensation(t, "2025-06-01", models.PayFrequencyHourly, "10.005")}
+			},
+			attendances: []models.Attendance{{WorkDate: date(t, "2026-01-05"), WorkedMinutes: 90}},
+			want:        "15.01",
+		},
+		{
+			name: "hourly ignores attendance outside the period",
+			from: "2026-01-01", to: "2026-01-31",
+			compensations: func(t *testing.T) []models.Compensation {
+				return []models.Compensation{compensation(t, "2026-01-10", models.PayFrequencyHourly, "20")}
+			},
+			attendances: []models.Attendance{
+				{WorkDate: date(t, "2026-01-05"), WorkedMinutes: 480},
+				{WorkDate: date(t, "2026-01-12"), WorkedMinutes: 480},
+			},
+			want: "160",
+		},
+	}
+
+	engine := NewEngine(BasePay{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payslip, err := engine.Calculate(Input{
+				From:          date(t, tt.from),
+				To:            date(t, tt.to),
+				Compensations: tt.compensations(t),
+				Attendances:   tt.attendances,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := lineAmount(payslip, "BASE"); !got.Equal(amount(tt.want)) {
+				t.Errorf("base pay = %s, want %s", got, tt.want)
+			}
+			if !payslip.NetPay.Equal(amount(tt.want)) {
+				t.Errorf("net pay = %s, want %s", payslip.NetPay, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateDefaultRules(t *testing.T) {
+	multiplier := 1.5
+	checkOut := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	salary := compensation(t, "2025-06-01", models.PayFrequencyMonthly, "5200")
+	salary.Allowances = []models.CompensationAllowance{{Name: "Transport", Amount: amount("310")}}
+
+	payslip, err := NewEngine(DefaultRules()...).Calculate(Input{
+		From:          date(t, "2026-01-01"),
+		To:            date(t, "2026-01-31"),
+		Compensations: []models.Compensation{salary},
+		Overtime: []models.OvertimeRequest{{
+			WorkDate:   date(t, "2026-01-10"),
+			Status:     models.OvertimeStatusApproved,
+			Minutes:    120,
+			Multiplier: &multiplier,
+			Attendance: &models.Attendance{CheckOutAt: &checkOut, WorkedMinutes: 120},
+		}},
+		WorkingDays:     20,
+		UnpaidLeaveDays: 1,
+		HoursPerWeek:    amount("40"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"BASE":      "5200",
+		"ALLOWANCE": "310",
+		// 5200 * 12 / 52 / 40 = 30 an hour, two hours at 1.5.
+		"OVERTIME":     "90",
+		"UNPAID_LEAVE": "260",
+	}
+	for code, value := range want {
+		if got := lineAmount(payslip, code); !got.Equal(amount(value)) {
+			t.Errorf("%s = %s, want %s", code, got, value)
+		}
+	}
+	if !payslip.NetPay.Equal(amount("5340")) {
+		t.Errorf("net pay = %s, want 5340", payslip.NetPay)
+	}
+}
+
+func TestCalculateErrors(t *testing.T) {
+	euro := compensation(t, "2026-01-15", models.PayFrequencyMonthly, "3000")
+	euro.Currency = "EUR"
+
+	tests := []struct {
+		name  string
+		input Input
+		want  error
+	}{
+		{
+			name:  "no compensation",
+			input: Input{From: date(t, "2026-01-01"), To: date(t, "2026-01-31")},
+			want:  ErrNoCompensation,
+		},
+		{
+			name: "mixed currencies",
+			input: Input{
+				From:          date(t, "2026-01-01"),
+				To:            date(t, "2026-01-31"),
+				Compensations: []models.Compensation{compensation(t, "2025-06-01", models.PayFrequencyMonthly, "3000"), euro},
+			},
+			want: ErrMixedCurrencies,
+		},
+		{
+			name: "deductions exceed gross pay",
+			input: Input{
+				From:          date(t, "2026-01-01"),
+				To:            date(t, "2026-01-31"),
+				Compensations: []models.Compensation{compensation(t, "2025-06-01", models.PayFrequencyMonthly, "100")},
+				DeductionRules: &models.DeductionRuleSet{Rules: []models.DeductionRule{
+					{Code: "FEE", Type: models.DeductionFixed, Amount: decimalPtr("150")},
+				}},
+			},
+			want: ErrNegativeNetPay,
+		},
+	}
+
+	engine := NewEngine(DefaultRules()...)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := engine.Calculate(tt.input); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMonths(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     string
+	}{
+		{"2026-01-01", "2026-01-31", "1"},
+		{"2026-01-01", "2026-12-31", "12"},
+		{"2026-02-01", "2026-02-14", "0.5"},
+		{"2028-02-01", "2028-02-29", "1"},
+		{"2026-01-31", "2026-01-31", "0.0322580645161290"},
+		{"2026-01-17", "2026-02-14", "0.9838709677419355"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+" to "+tt.to, func(t *testing.T) {
+			got := months(date(t, tt.from), date(t, tt.to)).Round(16)
+			if !got.Equal(amount(tt.want)) {
+				t.Errorf("months = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func decimalPtr(value string) *decimal.Decimal {
+	d := amount(value)
+	return &d
+}
@@ -0,0 +1,86 @@
+package payroll
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/shopspring/decimal"
+	"main.go/internal/models"
+	"main.go/internal/pdf"
+)
+
+// WritePayslipPDF renders a payslip as a one page PDF. The payslip needs its
+// User, its lines and its PayrollRun with the PayPeriod loaded.
+func WritePayslipPDF(w io.Writer, payslip models.Payslip) error {
+	const (
+		left   = 56.0
+		right  = pdf.PageWidth - 56
+		amount = right
+		size   = 10.0
+	)
+
+	doc := pdf.New()
+	doc.AddPage()
+	y := pdf.PageHeight - 72
+
+	doc.Text(left, y, 18, true, "Payslip")
+	y -= 28
+
+	details := [][2]string{}
+	if payslip.User != nil {
+		details = append(details,
+			[2]string{"Employee", payslip.User.Firstname + " " + payslip.User.Lastname},
+			[2]string{"Email", payslip.User.Email},
+		)
+	}
+	if run := payslip.PayrollRun; run != nil {
+		if run.PayPeriod != nil {
+			details = append(details, [2]string{"Pay period", run.PayPeriod.StartDate.String() + " to " + run.PayPeriod.EndDate.String()})
+		}
+		details = append(details, [2]string{"Pay date", run.PayDate.String()})
+	}
+	details = append(details,
+		[2]string{"Worked hours", hours(payslip.WorkedMinutes)},
+		[2]string{"Overtime hours", hours(payslip.OvertimeMinutes)},
+	)
+	for _, detail := range details {
+		doc.Text(left, y, size, true, detail[0])
+		doc.Text(left+110, y, size, false, detail[1])
+		y -= 16
+	}
+	y -= 12
+
+	section := func(title string, kind models.PayslipLineKind, total decimal.Decimal, totalLabel string) {
+		doc.Text(left, y, 12, true, title)
+		doc.TextRight(amount, y, 12, true, payslip.Currency)
+		y -= 6
+		doc.Line(left, y, right, y)
+		y -= 16
+		for _, line := range payslip.Lines {
+			if line.Kind != kind {
+				continue
+			}
+			doc.Text(left, y, size, false, line.Description)
+			doc.TextRight(amount, y, size, false, line.Amount.StringFixed(2))
+			y -= 16
+		}
+		doc.Line(left, y+10, right, y+10)
+		y -= 4
+		doc.Text(left, y, size, true, totalLabel)
+		doc.TextRight(amount, y, size, true, total.StringFixed(2))
+		y -= 32
+	}
+	section("Earnings", models.PayslipLineEarning, payslip.GrossPay, "Gross pay")
+	section("Deductions", models.PayslipLineDeduction, payslip.TotalDeductions, "Total deductions")
+
+	doc.Line(left, y+14, right, y+14)
+	doc.Text(left, y, 12, true, "Net pay")
+	doc.TextRight(amount, y, 12, true, payslip.Currency+" "+payslip.NetPay.StringFixed(2))
+
+	_, err := doc.WriteTo(w)
+	return err
+}
+
+func hours(minutes int) string {
+	return fmt.Sprintf("%d:%02d", minutes/60, minutes%60)
+}
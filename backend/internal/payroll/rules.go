@@ -0,0 +1,205 @@
+package payroll
+
+import (
+	"github.com/shopspring/decimal"
+	"main.go/internal/models"
+)
+
+var (
+	weeksPerYear   = decimal.NewFromInt(52)
+	monthsPerYear  = decimal.NewFromInt(12)
+	minutesPerHour = decimal.NewFromInt(60)
+)
+
+// BasePay pays the base pay of every compensation for the days it was in
+// effect. Salaries are pro-rated by calendar days, hourly pay is paid for
+// the hours attended.
+type BasePay struct{}
+
+func (BasePay) Apply(input Input, payslip *models.Payslip) error {
+	total := decimal.Zero
+	for _, segment := range segments(input) {
+		total = total.Add(segment.pay(input, segment.compensation.BasePay))
+	}
+	payslip.AddEarning("BASE", "Base pay", total)
+	return nil
+}
+
+// Allowances pays every allowance like the base pay it comes with.
+type Allowances struct{}
+
+func (Allowances) Apply(input Input, payslip *models.Payslip) error {
+	totals := map[string]decimal.Decimal{}
+	names := []string{}
+	for _, segment := range segments(input) {
+		for _, allowance := range segment.compensation.Allowances {
+			if _, ok := totals[allowance.Name]; !ok {
+				names = append(names, allowance.Name)
+			}
+			totals[allowance.Name] = totals[allowance.Name].Add(segment.pay(input, allowance.Amount))
+		}
+	}
+	for _, name := range names {
+		payslip.AddEarning("ALLOWANCE", name, totals[name])
+	}
+	return nil
+}
+
+// Overtime pays approved overtime at the hourly rate of the day times the
+// multiplier it was approved with. Hourly pay already covers the hours
+// themselves, so only the premium is added for it.
+type Overtime struct{}
+
+func (Overtime) Apply(input Input, payslip *models.Payslip) error {
+	total := decimal.Zero
+	for i := range input.Overtime {
+		request := &input.Overtime[i]
+		compensation, ok := compensationOn(input, request.WorkDate)
+		if !ok {
+			continue
+		}
+
+		multiplier := decimal.NewFromInt(1)
+		if request.Multiplier != nil {
+			multiplier = decimal.NewFromFloat(*request.Multiplier)
+		}
+		if compensation.PayFrequency == models.PayFrequencyHourly {
+			multiplier = multiplier.Sub(decimal.NewFromInt(1))
+		}
+
+		hours := decimal.NewFromInt(int64(request.PaidMinutes())).Div(minutesPerHour)
+		total = total.Add(hourlyRate(compensation, input.HoursPerWeek).Mul(hours).Mul(multiplier))
+	}
+	payslip.AddEarning("OVERTIME", "Overtime", total)
+	return nil
+}
+
+// UnpaidLeave deducts a day's salary for every working day of unpaid leave,
+// at the last compensation of the period. Hourly pay isn't paid for days
+// not worked in the first place.
+type UnpaidLeave struct{}
+
+func (UnpaidLeave) Apply(input Input, payslip *models.Payslip) error {
+	if input.UnpaidLeaveDays == 0 || input.WorkingDays == 0 {
+		return nil
+	}
+	compensation := input.Compensations[len(input.Compensations)-1]
+	if compensation.PayFrequency == models.PayFrequencyHourly {
+		return nil
+	}
+
+	salary := salaryFor(compensation.PayFrequency, compensation.BasePay, input.From, input.To)
+	daily := salary.Div(decimal.NewFromInt(int64(input.WorkingDays)))
+	payslip.AddDeduction("UNPAID_LEAVE", "Unpaid leave", daily.Mul(decimal.NewFromInt(int64(input.UnpaidLeaveDays))))
+	return nil
+}
+
+// segment is the part of the pay period one compensation was in effect.
+type segment struct {
+	compensation models.Compensation
+	from         models.Date
+	to           models.Date
+}
+
+func segments(input Input) []segment {
+	result := []segment{}
+	for i, compensation := range input.Compensations {
+		from, to := input.From, input.To
+		if compensation.EffectiveDate.After(from) {
+			from = compensation.EffectiveDate
+		}
+		if i+1 < len(input.Compensations) {
+			if next := input.Compensations[i+1].EffectiveDate.AddDays(-1); next.Before(to) {
+				to = next
+			}
+		}
+		if !to.Before(from) {
+			result = append(result, segment{compensation: compensation, from: from, to: to})
+		}
+	}
+	return result
+}
+
+// pay returns amount, given at the segment's pay frequency, for the days of
+// the segment.
+func (s segment) pay(input Input, amount decimal.Decimal) decimal.Decimal {
+	if s.compensation.PayFrequency != models.PayFrequencyHourly {
+		return salaryFor(s.compensation.PayFrequency, amount, s.from, s.to)
+	}
+
+	minutes := 0
+	for _, attendance := range input.Attendances {
+		if !attendance.WorkDate.Before(s.from) && !attendance.WorkDate.After(s.to) {
+			minutes += attendance.WorkedMinutes
+		}
+	}
+	return amount.Mul(decimal.NewFromInt(int64(minutes))).Div(minutesPerHour)
+}
+
+// salaryFor pro-rates a salary paid per frequency to the days from from to
+// to. Monthly and yearly salaries are split by the days of each calendar
+// month, so a whole month pays exactly one month.
+func salaryFor(frequency models.PayFrequency, amount decimal.Decimal, from, to models.Date) decimal.Decimal {
+	days := decimal.NewFromInt(int64(to.Sub(from.Time).Hours()/24) + 1)
+	switch frequency {
+	case models.PayFrequencyWeekly:
+		return amount.Mul(days).Div(decimal.NewFromInt(7))
+	case models.PayFrequencyBiweekly:
+		return amount.Mul(days).Div(decimal.NewFromInt(14))
+	case models.PayFrequencyMonthly:
+		return amount.Mul(months(from, to))
+	case models.PayFrequencyAnnual:
+		return amount.Div(monthsPerYear).Mul(months(from, to))
+	}
+	return decimal.Zero
+}
+
+// months returns how many calendar months the days from from to to cover,
+// counting part months by their share of days.
+func months(from, to models.Date) decimal.Decimal {
+	total := decimal.Zero
+	for start := from; !start.After(to); {
+		firstOfNext := models.NewDate(start.AddDate(0, 1, -start.Day()+1))
+		daysInMonth := firstOfNext.AddDays(-1).Day()
+
+		end := firstOfNext.AddDays(-1)
+		if to.Before(end) {
+			end = to
+		}
+		days := end.Day() - start.Day() + 1
+		total = total.Add(decimal.NewFromInt(int64(days)).Div(decimal.NewFromInt(int64(daysInMonth))))
+		start = firstOfNext
+	}
+	return total
+}
+
+// hourlyRate returns the base pay of a compensation per hour.
+func hourlyRate(compensation models.Compensation, hoursPerWeek decimal.Decimal) decimal.Decimal {
+	if compensation.PayFrequency == models.PayFrequencyHourly {
+		return compensation.BasePay
+	}
+	if !hoursPerWeek.IsPositive() {
+		return decimal.Zero
+	}
+
+	yearly := compensation.BasePay
+	switch compensation.PayFrequency {
+	case models.PayFrequencyWeekly:
+		yearly = yearly.Mul(weeksPerYear)
+	case models.PayFrequencyBiweekly:
+		yearly = yearly.Mul(weeksPerYear).Div(decimal.NewFromInt(2))
+	case models.PayFrequencyMonthly:
+		yearly = yearly.Mul(monthsPerYear)
+	}
+	return yearly.Div(weeksPerYear.Mul(hoursPerWeek))
+}
+
+// compensationOn returns the compensation in effect on day.
+func compensationOn(input Input, day models.Date) (models.Compensation, bool) {
+	for i := len(input.Compensations) - 1; i >= 0; i-- {
+		if !input.Compensations[i].EffectiveDate.After(day) {
+			return input.Compensations[i], true
+		}
+	}
+	return models.Compensation{}, false
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"main.go/config"
+	"main.go/internal/models"
+)
+
+var ErrAttendanceExists = errors.New("attendance already exists")
+
+type AttendanceQuery interface {
+	// GET
+	GetAttendances(ctx context.Context, filter models.AttendanceFilter) ([]models.Attendance, error)
+	GetAttendanceByUserAndDate(ctx context.Context, userID uint64, date models.Date) (models.Attendance, error)
+	GetOpenAttendance(ctx context.Context, userID uint64, since time.Time) (models.Attendance, error)
+
+	// POST
+	CreateAttendance(ctx context.Context, attendance models.Attendance) (models.Attendance, error)
+	UpdateAttendance(ctx context.Context, attendance models.Attendance) (models.Attendance, error)
+}
+
+type attendanceQueryImpl struct {
+	db config.GormPostgres
+}
+
+func NewAttendanceQuery(db config.GormPostgres) AttendanceQuery {
+	return &attendanceQueryImpl{db: db}
+}
+
+func (a *attendanceQueryImpl) GetAttendances(ctx context.Context, filter models.AttendanceFilter) ([]models.Attendance, error) {
+	db := a.db.GetConnection()
+
+	query := db.WithContext(ctx).
+		Model(&models.Attendance{}).
+		Preload("User").
+		Preload("User.Position").
+		Preload("User.Department")
+
+	if filter.UserID != 0 {
+		query = query.Where("attendances.user_id = ?", filter.UserID)
+	}
+	if filter.DepartmentID != 0 {
+		query = query.
+			Joins("JOIN users ON users.id = attendances.user_id").
+			Where("users.department_id = ?", filter.DepartmentID)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("attendances.work_date >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("attendances.work_date <= ?", filter.To)
+	}
+
+	attendances := []models.Attendance{}
+	if err := query.
+		Order("attendances.work_date DESC, attendances.user_id").
+		Find(&attendances).Error; err != nil {
+		return []models.Attendance{}, err
+	}
+	return attendances, nil
+}
+
+func (a *attendanceQueryImpl) GetAttendanceByUserAndDate(ctx context.Context, userID uint64, date models.Date) (models.Attendance, error) {
+	db := a.db.GetConnection()
+	attendance := models.Attendance{}
+
+	if err := db.WithContext(ctx).
+		Where("user_id = ? AND work_date = ?", userID, date).
+		First(&attendance).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Attendance{}, nil
+		}
+		return models.Attendance{}, err
+	}
+	return attendance, nil
+}
+
+// GetOpenAttendance returns the latest attendance of the user that was
+// checked in after since and not checked out yet.
+func (a *attendanceQueryImpl) GetOpenAttendance(ctx context.Context, userID uint64, since time.Time) (models.Attendance, error) {
+	db := a.db.GetConnection()
+	attendance := models.Attendance{}
+
+	if err := db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Where("check_out_at IS NULL").
+		Where("check_in_at >= ?", since).
+		Order("check_in_at DESC").
+		First(&attendance).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Attendance{}, nil
+		}
+		return models.Attendance{}, err
+	}
+	return attendance, nil
+}
+
+func (a *attendanceQueryImpl) CreateAttendance(ctx context.Context, attendance models.Attendance) (models.Attendance, error) {
+	db := a.db.GetConnection()
+
+	if err := db.WithContext(ctx).Omit("User").Create(&attendance).Error; err != nil {
+		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+			return models.Attendance{}, ErrAttendanceExists
+		}
+		return models.Attendance{}, err
+	}
+	return attendance, nil
+}
+
+func (a *attendanceQueryImpl) UpdateAttendance(ctx context.Context, attendance models.Attendance) (models.Attendance, error) {
+	db := a.db.GetConnection()
+
+	if err := db.WithContext(ctx).Omit("User").Save(&attendance).Error; err != nil {
+		return models.Attendance{}, err
+	}
+	return attendance, nil
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"main.go/config"
+	"main.go/internal/models"
+)
+
+// auditChainLockKey serializes appends so every log is chained to the one
+// written just before it.
+const auditChainLockKey = 720905
+
+type AuditQuery interface {
+	// GET
+	GetAuditLogs(ctx context.Context, filter models.AuditLogFilter) ([]models.AuditLog, int64, error)
+	GetAuditLogsAfter(ctx context.Context, afterID uint64, limit int) ([]models.AuditLog, error)
+
+	// POST
+	AppendAuditLog(ctx context.Context, log models.AuditLog) (models.AuditLog, error)
+}
+
+type auditQueryImpl struct {
+	db config.GormPostgres
+}
+
+func NewAuditQuery(db config.GormPostgres) AuditQuery {
+	return &auditQueryImpl{db: db}
+}
+
+// GetAuditLogs returns a page of the logs matching the filter, the latest
+// first, together with the number of matching logs.
+func (a *auditQueryImpl) GetAuditLogs(ctx context.Context, filter models.AuditLogFilter) ([]models.AuditLog, int64, error) {
+	db := a.db.GetConnection()
+
+	query := db.WithContext(ctx).Model(&models.AuditLog{})
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != 0 {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.ActorID != 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return []models.AuditLog{}, 0, err
+	}
+
+	logs := []models.AuditLog{}
+	if err := query.
+		Order("id DESC").
+		Offset((filter.Page - 1) * filter.Limit).
+		Limit(filter.Limit).
+		Find(&logs).Error; err != nil {
+		return []models.AuditLog{}, 0, err
+	}
+	return logs, total, nil
+}
+
+// GetAuditLogsAfter returns up to limit logs following afterID in chain
+// order.
+func (a *auditQueryImpl) GetAuditLogsAfter(ctx context.Context, afterID uint64, limit int) ([]models.AuditLog, error) {
+	db := a.db.GetConnection()
+
+	logs := []models.AuditLog{}
+	if err := db.WithContext(ctx).
+		Where("id > ?", afterID).
+		Order("id").
+		Limit(limit).
+		Find(&logs).Error; err != nil {
+		return []models.AuditLog{}, err
+	}
+	return logs, nil
+}
+
+// AppendAuditLog seals the log to the latest one and stores it.
+func (a *auditQueryImpl) AppendAuditLog(ctx context.Context, log models.AuditLog) (models.AuditLog, error) {
+	db := a.db.GetConnection()
+
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", auditChainLockKey).Error; err != nil {
+			return err
+		}
+
+		var prevHash string
+		if err := tx.Model(&models.AuditLog{}).
+			Select("hash").
+			Order("id DESC").
+			Limit(1).
+			Scan(&prevHash).Error; err != nil {
+			return err
+		}
+		if prevHash == "" {
+			prevHash = models.AuditGenesisHash
+		}
+
+		log.Seal(prevHash)
+		return tx.Create(&log).Error
+	})
+	if err != nil {
+		return models.AuditLog{}, err
+	}
+	return log, nil
+}
@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"main.go/internal/cache"
+	"main.go/internal/models"
+)
+
+// cachedUserQuery is a read-through cache in front of a UserQuery for the
+// lookups that run on almost every request. Users are evicted when they
+// change, roles, positions and departments through cachedRoleQuery,
+// cachedPositionQuery and cachedDepartmentQuery. Users are cached without
+// their role, position and department, which are joined from their own
+// entries on every read so a rename shows up at once. Password hashes are
+// never cached, GetPasswordHash always reads the database.
+type cachedUserQuery struct {
+	UserQuery
+	departments DepartmentQuery
+	cache       cache.Cache
+	ttl         time.Duration
+}
+
+func NewCachedUserQuery(next UserQuery, departments DepartmentQuery, c cache.Cache, ttl time.Duration) UserQuery {
+	return &cachedUserQuery{UserQuery: next, departments: departments, cache: c, ttl: ttl}
+}
+
+func userCacheKey(id uint64) string {
+	return fmt.Sprintf("user:%d", id)
+}
+
+func roleCacheKey(id uint64) string {
+	return fmt.Sprintf("role:%d", id)
+}
+
+func positionCacheKey(id uint64) string {
+	return fmt.Sprintf("position:%d", id)
+}
+
+func departmentCacheKey(id uint64) string {
+	return fmt.Sprintf("department:%d", id)
+}
+
+func (c *cachedUserQuery) GetUserByID(ctx context.Context, id uint64) (models.User, error) {
+	user, err := readThrough(ctx, c, userCacheKey(id), func() (models.User, error) {
+		user, err := c.UserQuery.GetUserByID(ctx, id)
+		user.Password = ""
+		user.Role = models.Role{}
+		user.Position = models.Position{}
+		user.Department = nil
+		return user, err
+	}, func(user models.User) bool { return user.Id != 0 })
+	if err != nil || user.Id == 0 {
+		return user, err
+	}
+
+	if err := c.join(ctx, &user); err != nil {
+		// A role or position that is gone can't be joined from the cache,
+		// the database knows what to make of it.
+		user, err := c.UserQuery.GetUserByID(ctx, id)
+		user.Password = ""
+		return user, err
+	}
+	return user, nil
+}
+
+// join fills in the user's role, position and department.
+func (c *cachedUserQuery) join(ctx context.Context, user *models.User) error {
+	role, err := c.GetRoleByID(ctx, uint64(user.RoleID))
+	if err != nil {
+		return err
+	}
+	// Users carry their role without its permissions.
+	role.Permissions = nil
+	user.Role = role
+
+	position, err := c.GetPositionByID(ctx, uint64(user.PositionID))
+	if err != nil {
+		return err
+	}
+	user.Position = position
+
+	if user.DepartmentID != nil {
+		department, err := readThrough(ctx, c, departmentCacheKey(uint64(*user.DepartmentID)), func() (models.Department, error) {
+			return c.departments.GetDepartmentByID(ctx, uint64(*user.DepartmentID))
+		}, func(department models.Department) bool { return department.ID != 0 })
+		if err != nil {
+			return err
+		}
+		if department.ID != 0 {
+			user.Department = &department
+		}
+	}
+	return nil
+}
+
+func (c *cachedUserQuery) GetRoleByID(ctx context.Context, id uint64) (models.Role, error) {
+	return readThrough(ctx, c, roleCacheKey(id), func() (models.Role, error) {
+		return c.UserQuery.GetRoleByID(ctx, id)
+	}, func(role models.Role) bool { return role.ID != 0 })
+}
+
+func (c *cachedUserQuery) GetPositionByID(ctx context.Context, id uint64) (models.Position, error) {
+	return readThrough(ctx, c, positionCacheKey(id), func() (models.Position, error) {
+		return c.UserQuery.GetPositionByID(ctx, id)
+	}, func(position models.Position) bool { return position.ID != 0 })
+}
+
+func (c *cachedUserQuery) UpdateUser(ctx context.Context, id uint64, user models.User) (models.User, error) {
+	updated, err := c.UserQuery.UpdateUser(ctx, id, user)
+	c.evict(ctx, userCacheKey(id))
+	return updated, err
+}
+
+func (c *cachedUserQuery) DeleteUser(ctx context.Context, id uint64) ([]uint64, error) {
+	reportIDs, err := c.UserQuery.DeleteUser(ctx, id)
+	// The reports got a new manager and version.
+	keys := []string{userCacheKey(id)}
+	for _, reportID := range reportIDs {
+		keys = append(keys, userCacheKey(reportID))
+	}
+	c.evict(ctx, keys...)
+	return reportIDs, err
+}
+
+func (c *cachedUserQuery) RestoreUser(ctx context.Context, id uint64) error {
+	err := c.UserQuery.RestoreUser(ctx, id)
+	c.evict(ctx, userCacheKey(id))
+	return err
+}
+
+func (c *cachedUserQuery) UpdateUserDepartment(ctx context.Context, id uint64, departmentID *int) error {
+	err := c.UserQuery.UpdateUserDepartment(ctx, id, departmentID)
+	c.evict(ctx, userCacheKey(id))
+	return err
+}
+
+func (c *cachedUserQuery) UpdateUserManager(ctx context.Context, id uint64, managerID *int) error {
+	err := c.UserQuery.UpdateUserManager(ctx, id, managerID)
+	c.evict(ctx, userCacheKey(id))
+	return err
+}
+
+func (c *cachedUserQuery) evict(ctx context.Context, keys ...string) {
+	evictCacheKeys(ctx, c.cache, keys...)
+}
+
+func evictCacheKeys(ctx context.Context, c cache.Cache, keys ...string) {
+	if err := c.Delete(ctx, keys...); err != nil {
+		log.Printf("cache delete %v failed: %v", keys, err)
+	}
+}
+
+// readThrough serves key from the cache or loads and stores it. Cache
+// failures are logged and fall back to the database, a broken cache must
+// never fail a request.
+func readThrough[T any](ctx context.Context, c *cachedUserQuery, key string, load func() (T, error), found func(T) bool) (T, error) {
+	var cached T
+	hit, err := c.cache.Get(ctx, key, &cached)
+	if err != nil {
+		log.Printf("cache get %s failed: %v", key, err)
+	} else if hit {
+		return cached, nil
+	}
+
+	value, err := load()
+	if err != nil || !found(value) {
+		return value, err
+	}
+
+	if err := c.cache.Set(ctx, key, value, c.ttl); err != nil {
+		log.Printf("cache set %s failed: %v", key, err)
+	}
+	return value, nil
+}
+
+// cachedRoleQuery evicts the roles cached by cachedUserQuery when they
+// change. AuthMiddleware takes every request's permissions from these
+// entries, so a role's new permissions apply from its next request on.
+type cachedRoleQuery struct {
+	RoleQuery
+	cache cache.Cache
+}
+
+func NewCachedRoleQuery(next RoleQuery, c cache.Cache) RoleQuery {
+	return &cachedRoleQuery{RoleQuery: next, cache: c}
+}
+
+func (c *cachedRoleQuery) UpdateRole(ctx context.Context, id uint64, role models.Role, permissions []string) (models.Role, error) {
+	updated, err := c.RoleQuery.UpdateRole(ctx, id, role, permissions)
+	evictCacheKeys(ctx, c.cache, roleCacheKey(id))
+	return updated, err
+}
+
+func (c *cachedRoleQuery) DeleteRole(ctx context.Context, id uint64) error {
+	err := c.RoleQuery.DeleteRole(ctx, id)
+	evictCacheKeys(ctx, c.cache, roleCacheKey(id))
+	return err
+}
+
+// cachedPositionQuery evicts the positions cached by cachedUserQuery when
+// they change.
+type cachedPositionQuery struct {
+	PositionQuery
+	cache cache.Cache
+}
+
+func NewCachedPositionQuery(next PositionQuery, c cache.Cache) PositionQuery {
+	return &cachedPositionQuery{PositionQuery: next, cache: c}
+}
+
+func (c *cachedPositionQuery) UpdatePosition(ctx context.Context, id uint64, position models.Position) (models.Position, error) {
+	updated, err := c.PositionQuery.UpdatePosition(ctx, id, position)
+	evictCacheKeys(ctx, c.cache, positionCacheKey(id))
+	return updated, err
+}
+
+func (c *cachedPositionQuery) DeletePosition(ctx context.Context, id uint64) error {
+	err := c.PositionQuery.DeletePosition(ctx, id)
+	evictCacheKeys(ctx, c.cache, positionCacheKey(id))
+	return err
+}
+
+// cachedDepartmentQuery evicts the departments cached by cachedUserQuery
+// when they change.
+type cachedDepartmentQuery struct {
+	DepartmentQuery
+	cache cache.Cache
+}
+
+func NewCachedDepartmentQuery(next DepartmentQuery, c cache.Cache) DepartmentQuery {
+	return &cachedDepartmentQuery{DepartmentQuery: next, cache: c}
+}
+
+func (c *cachedDepartmentQuery) UpdateDepartment(ctx context.Context, id uint64, department models.Department) (models.Department, error) {
+	updated, err := c.DepartmentQuery.UpdateDepartment(ctx, id, department)
+	evictCacheKeys(ctx, c.cache, departmentCacheKey(id))
+	return updated, err
+}
+
+func (c *cachedDepartmentQuery) DeleteDepartment(ctx context.Context, id uint64) error {
+	err := c.DepartmentQuery.DeleteDepartment(ctx, id)
+	evictCacheKeys(ctx, c.cache, departmentCacheKey(id))
+	return err
+}
@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"main.go/config"
+	"main.go/internal/models"
+)
+
+type CalendarQuery interface {
+	// GET
+	GetCalendars(ctx context.Context) ([]models.Calendar, error)
+	GetCalendarByID(ctx context.Context, id uint64) (models.Calendar, error)
+	GetCalendarByName(ctx context.Context, name string) (models.Calendar, error)
+	GetUserCalendar(ctx context.Context, userID uint64) (models.Calendar, error)
+	GetHolidays(ctx context.Context, calendarID uint64, filter models.HolidayFilter) ([]models.Holiday, error)
+	GetHolidayByID(ctx context.Context, calendarID uint64, id uint64) (models.Holiday, error)
+
+	// POST
+	CreateCalendar(ctx context.Context, calendar models.Calendar) (models.Calendar, error)
+	UpdateCalendar(ctx context.Context, id uint64, calendar models.Calendar) (models.Calendar, error)
+	DeleteCalendar(ctx context.Context, id uint64) error
+	CreateHoliday(ctx context.Context, holiday models.Holiday) (models.Holiday, error)
+	UpdateHoliday(ctx context.Context, holiday models.Holiday) (models.Holiday, error)
+	DeleteHoliday(ctx context.Context, calendarID uint64, id uint64) error
+	UpsertHolidays(ctx context.Context, holidays []models.Holiday) error
+}
+
+type calendarQueryImpl struct {
+	db config.GormPostgres
+}
+
+func NewCalendarQuery(db config.GormPostgres) CalendarQuery {
+	return &calendarQueryImpl{db: db}
+}
+
+func (c *calendarQueryImpl) GetCalendars(ctx context.Context) ([]models.Calendar, error) {
+	db := c.db.GetConnection()
+	calendars := []models.Calendar{}
+	if err := db.WithContext(ctx).Order("id").Find(&calendars).Error; err != nil {
+		return []models.Calendar{}, err
+	}
+	return calendars, nil
+}
+
+func (c *calendarQueryImpl) GetCalendarByID(ctx context.Context, id uint64) (models.Calendar, error) {
+	db := c.db.GetConnection()
+	calendar := models.Calendar{}
+	if err := db.WithContext(ctx).Where("id = ?", id).First(&calendar).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Calendar{}, nil
+		}
+		return models.Calendar{}, err
+	}
+	return calendar, nil
+}
+
+func (c *calendarQueryImpl) GetCalendarByName(ctx context.Context, name string) (models.Calendar, error) {
+	db := c.db.GetConnection()
+	calendar := models.Calendar{}
+	if err := db.WithContext(ctx).Where("LOWER(name) = LOWER(?)", name).First(&calendar).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Calendar{}, nil
+		}
+		return models.Calendar{}, err
+	}
+	return calendar, nil
+}
+
+// GetUserCalendar returns the calendar of the user's department, or the
+// default calendar when the department has none.
+func (c *calendarQueryImpl) GetUserCalendar(ctx context.Context, userID uint64) (models.Calendar, error) {
+	db := c.db.GetConnection()
+	calendar := models.Calendar{}
+	if err := db.WithContext(ctx).
+		Where(`id = COALESCE(
+			(SELECT d.calendar_id FROM users u JOIN departments d ON d.id = u.department_id WHERE u.id = ?),
+			(SELECT id FROM calendars WHERE is_default)
+		)`, userID).
+		First(&calendar).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Calendar{}, nil
+		}
+		return models.Calendar{}, err
+	}
+	return calendar, nil
+}
+
+func (c *calendarQueryImpl) GetHolidays(ctx context.Context, calendarID uint64, filter models.HolidayFilter) ([]models.Holiday, error) {
+	db := c.db.GetConnection()
+
+	query := db.WithContext(ctx).Where("calendar_id = ?", calendarID)
+	if !filter.From.IsZero() {
+		query = query.Where("(date >= ? OR recurring)", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("date <= ?", filter.To)
+	}
+
+	holidays := []models.Holiday{}
+	if err := query.Order("date, id").Find(&holidays).Error; err != nil {
+		return []models.Holiday{}, err
+	}
+	return holidays, nil
+}
+
+func (c *calendarQueryImpl) GetHolidayByID(ctx context.Context, calendarID uint64, id uint64) (models.Holiday, error) {
+	db := c.db.GetConnection()
+	holiday := models.Holiday{}
+	if err := db.WithContext(ctx).
+		Where("id = ? AND calendar_id = ?", id, calendarID).
+		First(&holiday).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Holiday{}, nil
+		}
+		return models.Holiday{}, err
+	}
+	return holiday, nil
+}
+
+// CreateCalendar stores a calendar. A new default calendar replaces the old
+// one.
+func (c *calendarQueryImpl) CreateCalendar(ctx context.Context, calendar models.Calendar) (models.Calendar, error) {
+	db := c.db.GetConnection()
+
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if calendar.IsDefault {
+			if err := clearDefaultCalendar(tx); err != nil {
+				return err
+			}
+		}
+		return tx.Create(&calendar).Error
+	})
+	if err != nil {
+		return models.Calendar{}, err
+	}
+	return c.GetCalendarByID(ctx, uint64(calendar.ID))
+}
+
+func (c *calendarQueryImpl) UpdateCalendar(ctx context.Context, id uint64, calendar models.Calendar) (models.Calendar, error) {
+	db := c.db.GetConnection()
+
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if calendar.IsDefault {
+			if err := clearDefaultCalendar(tx); err != nil {
+				return err
+			}
+		}
+		return tx.Model(&models.Calendar{}).
+			Where("id = ?", id).
+			Updates(map[string]interface{}{
+				"name":             calendar.Name,
+				"working_weekdays": calendar.WeekdayMask,
+				"is_default":       calendar.IsDefault,
+			}).Error
+	})
+	if err != nil {
+		return models.Calendar{}, err
+	}
+	return c.GetCalendarByID(ctx, id)
+}
+
+func (c *calendarQueryImpl) DeleteCalendar(ctx context.Context, id uint64) error {
+	db := c.db.GetConnection()
+	return db.WithContext(ctx).Delete(&models.Calendar{}, id).Error
+}
+
+func (c *calendarQueryImpl) CreateHoliday(ctx context.Context, holiday models.Holiday) (models.Holiday, error) {
+	db := c.db.GetConnection()
+	if err := db.WithContext(ctx).Create(&holiday).Error; err != nil {
+		return models.Holiday{}, err
+	}
+	return holiday, nil
+}
+
+func (c *calendarQueryImpl) UpdateHoliday(ctx context.Context, holiday models.Holiday) (models.Holiday, error) {
+	db := c.db.GetConnection()
+	if err := db.WithContext(ctx).
+		Model(&holiday).
+		Select("name", "date", "recurring").
+		Updates(&holiday).Error; err != nil {
+		return models.Holiday{}, err
+	}
+	return c.GetHolidayByID(ctx, uint64(holiday.CalendarID), uint64(holiday.ID))
+}
+
+func (c *calendarQueryImpl) DeleteHoliday(ctx context.Context, calendarID uint64, id uint64) error {
+	db := c.db.GetConnection()
+	return db.WithContext(ctx).
+		Where("id = ? AND calendar_id = ?", id, calendarID).
+		Delete(&models.Holiday{}).Error
+}
+
+// UpsertHolidays stores imported holidays, updating the ones whose UID was
+// imported into the same calendar before.
+func (c *calendarQueryImpl) UpsertHolidays(ctx context.Context, holidays []models.Holiday) error {
+	if len(holidays) == 0 {
+		return nil
+	}
+
+	db := c.db.GetConnection()
+	return db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "calendar_id"}, {Name: "uid"}},
+			DoUpdates: clause.AssignmentColumns([]string{"name", "date", "recurring", "updated_at"}),
+		}).
+		CreateInBatches(&holidays, 500).Error
+}
+
+func clearDefaultCalendar(tx *gorm.DB) error {
+	return tx.Model(&models.Calendar{}).
+		Where("is_default").
+		Update("is_default", false).Error
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+	"main.go/config"
+	"main.go/internal/models"
+)
+
+var ErrCompensationExists = errors.New("compensation already exists")
+
+type CompensationQuery interface {
+	// GET
+	GetCompensations(ctx context.Context, userID uint64) ([]models.Compensation, error)
+	GetCompensationByID(ctx context.Context, id uint64) (models.Compensation, error)
+	GetCompensationAt(ctx context.Context, userID uint64, date models.Date) (models.Compensation, error)
+	GetCompensationsAt(ctx context.Context, filter models.CompensationFilter) ([]models.Compensation, error)
+	GetCompensationsBetween(ctx context.Context, from, to models.Date) ([]models.Compensation, error)
+
+	// POST
+	CreateCompensation(ctx context.Context, compensation models.Compensation) (models.Compensation, error)
+	UpdateCompensation(ctx context.Context, compensation models.Compensation) error
+	DeleteCompensation(ctx context.Context, id uint64) error
+}
+
+type compensationQueryImpl struct {
+	db config.GormPostgres
+}
+
+func NewCompensationQuery(db config.GormPostgres) CompensationQuery {
+	return &compensationQueryImpl{db: db}
+}
+
+// GetCompensations returns the whole compensation history of a user, the
+// latest effective date first.
+func (c *compensationQueryImpl) GetCompensations(ctx context.Context, userID uint64) ([]models.Compensation, error) {
+	db := c.db.GetConnection()
+	compensations := []models.Compensation{}
+	if err := c.withAllowances(db.WithContext(ctx)).
+		Where("user_id = ?", userID).
+		Order("effective_date DESC").
+		Find(&compensations).Error; err != nil {
+		return []models.Compensation{}, err
+	}
+	return compensations, nil
+}
+
+func (c *compensationQueryImpl) GetCompensationByID(ctx context.Context, id uint64) (models.Compensation, error) {
+	db := c.db.GetConnection()
+	compensation := models.Compensation{}
+	if err := c.withAllowances(db.WithContext(ctx)).
+		Preload("User").
+		Where("id = ?", id).
+		First(&compensation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Compensation{}, nil
+		}
+		return models.Compensation{}, err
+	}
+	return compensation, nil
+}
+
+// GetCompensationAt returns the compensation of a user in effect on date.
+func (c *compensationQueryImpl) GetCompensationAt(ctx context.Context, userID uint64, date models.Date) (models.Compensation, error) {
+	db := c.db.GetConnection()
+	compensation := models.Compensation{}
+	if err := c.withAllowances(db.WithContext(ctx)).
+		Where("user_id = ? AND effective_date <= ?", userID, date).
+		Order("effective_date DESC").
+		First(&compensation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Compensation{}, nil
+		}
+		return models.Compensation{}, err
+	}
+	return compensation, nil
+}
+
+// GetCompensationsAt returns the compensation in effect on filter.Date of
+// every user who has one.
+func (c *compensationQueryImpl) GetCompensationsAt(ctx context.Context, filter models.CompensationFilter) ([]models.Compensation, error) {
+	db := c.db.GetConnection()
+
+	latest := db.Model(&models.Compensation{}).
+		Select("DISTINCT ON (user_id) id").
+		Where("effective_date <= ?", filter.Date).
+		Order("user_id, effective_date DESC")
+	if filter.DepartmentID != 0 {
+		latest = latest.Where("user_id IN (SELECT id FROM users WHERE department_id = ? AND deleted_at IS NULL)", filter.DepartmentID)
+	} else {
+		latest = latest.Where("user_id IN (SELECT id FROM users WHERE deleted_at IS NULL)")
+	}
+
+	compensations := []models.Compensation{}
+	if err := c.withAllowances(db.WithContext(ctx)).
+		Preload("User").
+		Where("id IN (?)", latest).
+		Order("user_id").
+		Find(&compensations).Error; err != nil {
+		return []models.Compensation{}, err
+	}
+	return compensations, nil
+}
+
+// GetCompensationsBetween returns every compensation in effect on at least
+// one day from from to to, by user and then oldest first. Users deleted
+// during the period are included, with their User loaded so their pay can
+// end on the day they left.
+func (c *compensationQueryImpl) GetCompensationsBetween(ctx context.Context, from, to models.Date) ([]models.Compensation, error) {
+	db := c.db.GetConnection()
+
+	inEffectAtStart := db.Model(&models.Compensation{}).
+		Select("DISTINCT ON (user_id) id").
+		Where("effective_date <= ?", from).
+		Order("user_id, effective_date DESC")
+
+	compensations := []models.Compensation{}
+	if err := c.withAllowances(db.WithContext(ctx)).
+		Preload("User", func(tx *gorm.DB) *gorm.DB {
+			return tx.Unscoped()
+		}).
+		Where("user_id IN (SELECT id FROM users WHERE deleted_at IS NULL OR deleted_at >= ?)", from.Time).
+		Where("id IN (?) OR (effective_date > ? AND effective_date <= ?)", inEffectAtStart, from, to).
+		Order("user_id, effective_date").
+		Find(&compensations).Error; err != nil {
+		return []models.Compensation{}, err
+	}
+	return compensations, nil
+}
+
+// CreateCompensation stores a compensation with its allowances unless the
+// user already has one with the same effective date.
+func (c *compensationQueryImpl) CreateCompensation(ctx context.Context, compensation models.Compensation) (models.Compensation, error) {
+	db := c.db.GetConnection()
+
+	if err := db.WithContext(ctx).Omit("User").Create(&compensation).Error; err != nil {
+		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+			return models.Compensation{}, ErrCompensationExists
+		}
+		return models.Compensation{}, err
+	}
+	return compensation, nil
+}
+
+// UpdateCompensation overwrites a compensation and replaces its allowances.
+func (c *compensationQueryImpl) UpdateCompensation(ctx context.Context, compensation models.Compensation) error {
+	db := c.db.GetConnection()
+
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Compensation{}).
+			Where("id = ?", compensation.ID).
+			Updates(map[string]interface{}{
+				"effective_date": compensation.EffectiveDate,
+				"base_pay":       compensation.BasePay,
+				"currency":       compensation.Currency,
+				"pay_frequency":  compensation.PayFrequency,
+				"reason":         compensation.Reason,
+			}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("compensation_id = ?", compensation.ID).Delete(&models.CompensationAllowance{}).Error; err != nil {
+			return err
+		}
+		if len(compensation.Allowances) == 0 {
+			return nil
+		}
+		for i := range compensation.Allowances {
+			compensation.Allowances[i].ID = 0
+			compensation.Allowances[i].CompensationID = compensation.ID
+		}
+		return tx.Create(&compensation.Allowances).Error
+	})
+	if err != nil && strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+		return ErrCompensationExists
+	}
+	return err
+}
+
+func (c *compensationQueryImpl) DeleteCompensation(ctx context.Context, id uint64) error {
+	db := c.db.GetConnection()
+	return db.WithContext(ctx).Delete(&models.Compensation{}, id).Error
+}
+
+func (c *compensationQueryImpl) withAllowances(db *gorm.DB) *gorm.DB {
+	return db.Preload("Allowances", func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("name")
+	})
+}
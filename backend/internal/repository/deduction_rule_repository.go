@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+	"main.go/config"
+	"main.go/internal/models"
+)
+
+var ErrDeductionRuleSetExists = errors.New("deduction rule set already exists")
+
+type DeductionRuleQuery interface {
+	// GET
+	GetDeductionRuleSets(ctx context.Context, filter models.DeductionRuleSetFilter) ([]models.DeductionRuleSet, error)
+	GetDeductionRuleSetByID(ctx context.Context, id uint64) (models.DeductionRuleSet, error)
+	GetDeductionRuleSetAt(ctx context.Context, currency string, date models.Date) (models.DeductionRuleSet, error)
+
+	// POST
+	CreateDeductionRuleSet(ctx context.Context, set models.DeductionRuleSet) (models.DeductionRuleSet, error)
+	UpdateDeductionRuleSet(ctx context.Context, set models.DeductionRuleSet) error
+	DeleteDeductionRuleSet(ctx context.Context, id uint64) error
+}
+
+type deductionRuleQueryImpl struct {
+	db config.GormPostgres
+}
+
+func NewDeductionRuleQuery(db config.GormPostgres) DeductionRuleQuery {
+	return &deductionRuleQueryImpl{db: db}
+}
+
+func (d *deductionRuleQueryImpl) GetDeductionRuleSets(ctx context.Context, filter models.DeductionRuleSetFilter) ([]models.DeductionRuleSet, error) {
+	db := d.db.GetConnection()
+
+	query := db.WithContext(ctx).Model(&models.DeductionRuleSet{})
+	if filter.Currency != "" {
+		query = query.Where("currency = ?", filter.Currency)
+	}
+
+	sets := []models.DeductionRuleSet{}
+	if err := query.Order("currency, effective_date DESC").Find(&sets).Error; err != nil {
+		return []models.DeductionRuleSet{}, err
+	}
+	return sets, nil
+}
+
+func (d *deductionRuleQueryImpl) GetDeductionRuleSetByID(ctx context.Context, id uint64) (models.DeductionRuleSet, error) {
+	db := d.db.GetConnection()
+	set := models.DeductionRuleSet{}
+	if err := db.WithContext(ctx).Where("id = ?", id).First(&set).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.DeductionRuleSet{}, nil
+		}
+		return models.DeductionRuleSet{}, err
+	}
+	return set, nil
+}
+
+// GetDeductionRuleSetAt returns the rule set of currency in effect on date.
+func (d *deductionRuleQueryImpl) GetDeductionRuleSetAt(ctx context.Context, currency string, date models.Date) (models.DeductionRuleSet, error) {
+	db := d.db.GetConnection()
+	set := models.DeductionRuleSet{}
+	if err := db.WithContext(ctx).
+		Where("currency = ? AND effective_date <= ?", currency, date).
+		Order("effective_date DESC").
+		First(&set).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.DeductionRuleSet{}, nil
+		}
+		return models.DeductionRuleSet{}, err
+	}
+	return set, nil
+}
+
+// CreateDeductionRuleSet stores a rule set unless its currency already has
+// one with the same effective date.
+func (d *deductionRuleQueryImpl) CreateDeductionRuleSet(ctx context.Context, set models.DeductionRuleSet) (models.DeductionRuleSet, error) {
+	db := d.db.GetConnection()
+
+	if err := db.WithContext(ctx).Create(&set).Error; err != nil {
+		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+			return models.DeductionRuleSet{}, ErrDeductionRuleSetExists
+		}
+		return models.DeductionRuleSet{}, err
+	}
+	return set, nil
+}
+
+func (d *deductionRuleQueryImpl) UpdateDeductionRuleSet(ctx context.Context, set models.DeductionRuleSet) error {
+	db := d.db.GetConnection()
+
+	// A struct keeps the rules going through their JSON serializer.
+	err := db.WithContext(ctx).
+		Model(&models.DeductionRuleSet{ID: set.ID}).
+		Select("name", "currency", "effective_date", "rules").
+		Updates(&set).Error
+	if err != nil && strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+		return ErrDeductionRuleSetExists
+	}
+	return err
+}
+
+func (d *deductionRuleQueryImpl) DeleteDeductionRuleSet(ctx context.Context, id uint64) error {
+	db := d.db.GetConnection()
+	return db.WithContext(ctx).Delete(&models.DeductionRuleSet{}, id).Error
+}
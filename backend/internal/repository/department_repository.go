@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"main.go/config"
+	"main.go/internal/models"
+)
+
+type DepartmentQuery interface {
+	// GET
+	GetDepartments(ctx context.Context) ([]models.Department, error)
+	GetDepartmentByID(ctx context.Context, id uint64) (models.Department, error)
+	GetDepartmentByName(ctx context.Context, name string) (models.Department, error)
+	CountUsersInDepartment(ctx context.Context, id uint64) (int64, error)
+
+	// POST
+	CreateDepartment(ctx context.Context, department models.Department) (models.Department, error)
+	UpdateDepartment(ctx context.Context, id uint64, department models.Department) (models.Department, error)
+	DeleteDepartment(ctx context.Context, id uint64) error
+}
+
+type departmentQueryImpl struct {
+	db config.GormPostgres
+}
+
+func NewDepartmentQuery(db config.GormPostgres) DepartmentQuery {
+	return &departmentQueryImpl{db: db}
+}
+
+func (d *departmentQueryImpl) GetDepartments(ctx context.Context) ([]models.Department, error) {
+	db := d.db.GetConnection()
+	departments := []models.Department{}
+	if err := db.
+		WithContext(ctx).
+		Order("id").
+		Find(&departments).Error; err != nil {
+		return []models.Department{}, err
+	}
+	return departments, nil
+}
+
+func (d *departmentQueryImpl) GetDepartmentByID(ctx context.Context, id uint64) (models.Department, error) {
+	db := d.db.GetConnection()
+	department := models.Department{}
+	if err := db.
+		WithContext(ctx).
+		Where("id = ?", id).
+		First(&department).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Department{}, nil
+		}
+		return models.Department{}, err
+	}
+	return department, nil
+}
+
+func (d *departmentQueryImpl) GetDepartmentByName(ctx context.Context, name string) (models.Department, error) {
+	db := d.db.GetConnection()
+	department := models.Department{}
+	if err := db.
+		WithContext(ctx).
+		Where("LOWER(name) = LOWER(?)", name).
+		First(&department).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Department{}, nil
+		}
+		return models.Department{}, err
+	}
+	return department, nil
+}
+
+func (d *departmentQueryImpl) CountUsersInDepartment(ctx context.Context, id uint64) (int64, error) {
+	db := d.db.GetConnection()
+	var count int64
+	err := db.
+		WithContext(ctx).
+		Table("users").
+		Where("department_id = ?", id).
+		Where("deleted_at IS NULL").
+		Count(&count).Error
+	return count, err
+}
+
+func (d *departmentQueryImpl) CreateDepartment(ctx context.Context, department models.Department) (models.Department, error) {
+	db := d.db.GetConnection()
+	if err := db.WithContext(ctx).Create(&department).Error; err != nil {
+		return models.Department{}, err
+	}
+	return department, nil
+}
+
+func (d *departmentQueryImpl) UpdateDepartment(ctx context.Context, id uint64, department models.Department) (models.Department, error) {
+	db := d.db.GetConnection()
+	if err := db.WithContext(ctx).
+		Model(&models.Department{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"name":        department.Name,
+			"calendar_id": department.CalendarID,
+		}).Error; err != nil {
+		return models.Department{}, err
+	}
+	return d.GetDepartmentByID(ctx, id)
+}
+
+func (d *departmentQueryImpl) DeleteDepartment(ctx context.Context, id uint64) error {
+	db := d.db.GetConnection()
+	return db.WithContext(ctx).Delete(&models.Department{}, id).Error
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm/clause"
+	"main.go/config"
+	"main.go/internal/models"
+)
+
+type HeartbeatQuery interface {
+	// GET
+	GetHeartbeats(ctx context.Context, userIDs []int, from time.Time, to time.Time) ([]models.Heartbeat, error)
+	GetLatestHeartbeats(ctx context.Context, userIDs []int) ([]models.Heartbeat, error)
+
+	// POST
+	CreateHeartbeats(ctx context.Context, heartbeats []models.Heartbeat) (int64, error)
+}
+
+type heartbeatQueryImpl struct {
+	db config.GormPostgres
+}
+
+func NewHeartbeatQuery(db config.GormPostgres) HeartbeatQuery {
+	return &heartbeatQueryImpl{db: db}
+}
+
+// GetHeartbeats returns the heartbeats of the given users that occurred in
+// [from, to), ordered by user and time.
+func (h *heartbeatQueryImpl) GetHeartbeats(ctx context.Context, userIDs []int, from time.Time, to time.Time) ([]models.Heartbeat, error) {
+	db := h.db.GetConnection()
+	heartbeats := []models.Heartbeat{}
+
+	if len(userIDs) == 0 {
+		return heartbeats, nil
+	}
+	if err := db.WithContext(ctx).
+		Where("user_id IN ?", userIDs).
+		Where("occurred_at >= ? AND occurred_at < ?", from, to).
+		Order("user_id, occurred_at").
+		Find(&heartbeats).Error; err != nil {
+		return []models.Heartbeat{}, err
+	}
+	return heartbeats, nil
+}
+
+func (h *heartbeatQueryImpl) GetLatestHeartbeats(ctx context.Context, userIDs []int) ([]models.Heartbeat, error) {
+	db := h.db.GetConnection()
+	heartbeats := []models.Heartbeat{}
+
+	if len(userIDs) == 0 {
+		return heartbeats, nil
+	}
+	if err := db.WithContext(ctx).
+		Raw(`SELECT DISTINCT ON (user_id) * FROM activity_heartbeats
+			WHERE user_id IN ? ORDER BY user_id, occurred_at DESC`, userIDs).
+		Scan(&heartbeats).Error; err != nil {
+		return []models.Heartbeat{}, err
+	}
+	return heartbeats, nil
+}
+
+// CreateHeartbeats stores the heartbeats and returns how many were new.
+// Heartbeats whose event ID the user already sent are skipped.
+func (h *heartbeatQueryImpl) CreateHeartbeats(ctx context.Context, heartbeats []models.Heartbeat) (int64, error) {
+	db := h.db.GetConnection()
+
+	result := db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "event_id"}},
+			DoNothing: true,
+		}).
+		Create(&heartbeats)
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"main.go/config"
+	"main.go/internal/models"
+)
+
+type LeavePolicyQuery interface {
+	// GET
+	GetPolicies(ctx context.Context) ([]models.LeaveAccrualPolicy, error)
+	GetPolicyByID(ctx context.Context, id uint64) (models.LeaveAccrualPolicy, error)
+	GetPolicyByName(ctx context.Context, name string) (models.LeaveAccrualPolicy, error)
+	GetPositionPolicies(ctx context.Context, positionID uint64) ([]models.LeaveAccrualPolicy, error)
+	GetAccrualAssignments(ctx context.Context) ([]models.AccrualAssignment, error)
+
+	// POST
+	CreatePolicy(ctx context.Context, policy models.LeaveAccrualPolicy) (models.LeaveAccrualPolicy, error)
+	UpdatePolicy(ctx context.Context, id uint64, policy models.LeaveAccrualPolicy) (models.LeaveAccrualPolicy, error)
+	DeletePolicy(ctx context.Context, id uint64) error
+	SetPositionPolicies(ctx context.Context, positionID uint64, policyIDs []int) error
+}
+
+type leavePolicyQueryImpl struct {
+	db config.GormPostgres
+}
+
+func NewLeavePolicyQuery(db config.GormPostgres) LeavePolicyQuery {
+	return &leavePolicyQueryImpl{db: db}
+}
+
+func (l *leavePolicyQueryImpl) GetPolicies(ctx context.Context) ([]models.LeaveAccrualPolicy, error) {
+	db := l.db.GetConnection()
+	policies := []models.LeaveAccrualPolicy{}
+	if err := db.WithContext(ctx).
+		Preload("LeaveType").
+		Order("id").
+		Find(&policies).Error; err != nil {
+		return []models.LeaveAccrualPolicy{}, err
+	}
+	return policies, nil
+}
+
+func (l *leavePolicyQueryImpl) GetPolicyByID(ctx context.Context, id uint64) (models.LeaveAccrualPolicy, error) {
+	db := l.db.GetConnection()
+	policy := models.LeaveAccrualPolicy{}
+	if err := db.WithContext(ctx).
+		Preload("LeaveType").
+		Where("id = ?", id).
+		First(&policy).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.LeaveAccrualPolicy{}, nil
+		}
+		return models.LeaveAccrualPolicy{}, err
+	}
+	return policy, nil
+}
+
+func (l *leavePolicyQueryImpl) GetPolicyByName(ctx context.Context, name string) (models.LeaveAccrualPolicy, error) {
+	db := l.db.GetConnection()
+	policy := models.LeaveAccrualPolicy{}
+	if err := db.WithContext(ctx).
+		Where("LOWER(name) = LOWER(?)", name).
+		First(&policy).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.LeaveAccrualPolicy{}, nil
+		}
+		return models.LeaveAccrualPolicy{}, err
+	}
+	return policy, nil
+}
+
+func (l *leavePolicyQueryImpl) GetPositionPolicies(ctx context.Context, positionID uint64) ([]models.LeaveAccrualPolicy, error) {
+	db := l.db.GetConnection()
+	policies := []models.LeaveAccrualPolicy{}
+	if err := db.WithContext(ctx).
+		Preload("LeaveType").
+		Joins("JOIN position_leave_policies plp ON plp.policy_id = leave_accrual_policies.id").
+		Where("plp.position_id = ?", positionID).
+		Order("leave_accrual_policies.id").
+		Find(&policies).Error; err != nil {
+		return []models.LeaveAccrualPolicy{}, err
+	}
+	return policies, nil
+}
+
+// GetAccrualAssignments lists every policy that applies to an active user
+// through the user's position.
+func (l *leavePolicyQueryImpl) GetAccrualAssignments(ctx context.Context) ([]models.AccrualAssignment, error) {
+	db := l.db.GetConnection()
+	assignments := []models.AccrualAssignment{}
+	if err := db.WithContext(ctx).
+		Table("users").
+		Select("users.id AS user_id, users.hire_date, users.created_at, plp.policy_id").
+		Joins("JOIN position_leave_policies plp ON plp.position_id = users.position_id").
+		Where("users.deleted_at IS NULL").
+		Order("users.id, plp.policy_id").
+		Scan(&assignments).Error; err != nil {
+		return []models.AccrualAssignment{}, err
+	}
+	return assignments, nil
+}
+
+func (l *leavePolicyQueryImpl) CreatePolicy(ctx context.Context, policy models.LeaveAccrualPolicy) (models.LeaveAccrualPolicy, error) {
+	db := l.db.GetConnection()
+	if err := db.WithContext(ctx).Omit("LeaveType").Create(&policy).Error; err != nil {
+		return models.LeaveAccrualPolicy{}, err
+	}
+	return l.GetPolicyByID(ctx, uint64(policy.ID))
+}
+
+func (l *leavePolicyQueryImpl) UpdatePolicy(ctx context.Context, id uint64, policy models.LeaveAccrualPolicy) (models.LeaveAccrualPolicy, error) {
+	db := l.db.GetConnection()
+	if err := db.WithContext(ctx).
+		Model(&models.LeaveAccrualPolicy{}).
+		Where("id = ?", id).
+		Select("name", "leave_type_id", "frequency", "days_per_year", "max_carry_over", "carry_over_expiry_months").
+		Updates(&policy).Error; err != nil {
+		return models.LeaveAccrualPolicy{}, err
+	}
+	return l.GetPolicyByID(ctx, id)
+}
+
+func (l *leavePolicyQueryImpl) DeletePolicy(ctx context.Context, id uint64) error {
+	db := l.db.GetConnection()
+	return db.WithContext(ctx).Delete(&models.LeaveAccrualPolicy{}, id).Error
+}
+
+// SetPositionPolicies replaces the policies assigned to a position.
+func (l *leavePolicyQueryImpl) SetPositionPolicies(ctx context.Context, positionID uint64, policyIDs []int) error {
+	db := l.db.GetConnection()
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("position_id = ?", positionID).Delete(&models.PositionLeavePolicy{}).Error; err != nil {
+			return err
+		}
+		if len(policyIDs) == 0 {
+			return nil
+		}
+
+		assignments := make([]models.PositionLeavePolicy, 0, len(policyIDs))
+		for _, policyID := range policyIDs {
+			assignments = append(assignments, models.PositionLeavePolicy{PositionID: int(positionID), PolicyID: policyID})
+		}
+		return tx.Create(&assignments).Error
+	})
+}
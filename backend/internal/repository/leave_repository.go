@@ -0,0 +1,386 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"main.go/config"
+	"main.go/internal/models"
+)
+
+var (
+	ErrLeaveRequestOverlap      = errors.New("leave request overlaps an existing request")
+	ErrInsufficientLeaveBalance = errors.New("insufficient leave balance")
+	ErrLeaveRequestStateChanged = errors.New("leave request status changed")
+)
+
+// leaveRequestLockKey is the first half of the advisory lock that serializes
+// submissions of one user, the user ID is the second half. Without it two
+// overlapping requests submitted at once could both pass the overlap check.
+const leaveRequestLockKey = 720902
+
+type LeaveQuery interface {
+	// GET
+	GetLeaveTypes(ctx context.Context) ([]models.LeaveType, error)
+	GetLeaveTypeByID(ctx context.Context, id uint64) (models.LeaveType, error)
+	GetLeaveBalances(ctx context.Context, userID uint64, year int) ([]models.LeaveBalance, error)
+	GetLeaveBalance(ctx context.Context, userID uint64, leaveTypeID uint64, year int) (models.LeaveBalance, error)
+	GetLeaveRequests(ctx context.Context, filter models.LeaveRequestFilter) ([]models.LeaveRequest, error)
+	GetLeaveRequestByID(ctx context.Context, id uint64) (models.LeaveRequest, error)
+	GetLedgerEntries(ctx context.Context, userID uint64, filter models.LeaveLedgerFilter) ([]models.LeaveLedgerEntry, error)
+
+	// POST
+	EnsureLeaveBalances(ctx context.Context, userID uint64, year int) error
+	PostLedgerEntry(ctx context.Context, entry models.LeaveLedgerEntry) (bool, error)
+	CreateLeaveRequest(ctx context.Context, request models.LeaveRequest) (models.LeaveRequest, error)
+	UpdateLeaveRequest(ctx context.Context, request models.LeaveRequest) (models.LeaveRequest, error)
+	SubmitLeaveRequest(ctx context.Context, request models.LeaveRequest) error
+	ApproveLeaveRequest(ctx context.Context, request models.LeaveRequest, deduct bool) error
+	RejectLeaveRequest(ctx context.Context, request models.LeaveRequest) error
+	CancelLeaveRequest(ctx context.Context, request models.LeaveRequest, from models.LeaveStatus, restore bool) error
+}
+
+type leaveQueryImpl struct {
+	db config.GormPostgres
+}
+
+func NewLeaveQuery(db config.GormPostgres) LeaveQuery {
+	return &leaveQueryImpl{db: db}
+}
+
+func (l *leaveQueryImpl) GetLeaveTypes(ctx context.Context) ([]models.LeaveType, error) {
+	db := l.db.GetConnection()
+	leaveTypes := []models.LeaveType{}
+	if err := db.WithContext(ctx).Order("id").Find(&leaveTypes).Error; err != nil {
+		return []models.LeaveType{}, err
+	}
+	return leaveTypes, nil
+}
+
+func (l *leaveQueryImpl) GetLeaveTypeByID(ctx context.Context, id uint64) (models.LeaveType, error) {
+	db := l.db.GetConnection()
+	leaveType := models.LeaveType{}
+	if err := db.WithContext(ctx).Where("id = ?", id).First(&leaveType).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.LeaveType{}, nil
+		}
+		return models.LeaveType{}, err
+	}
+	return leaveType, nil
+}
+
+func (l *leaveQueryImpl) GetLeaveBalances(ctx context.Context, userID uint64, year int) ([]models.LeaveBalance, error) {
+	db := l.db.GetConnection()
+	balances := []models.LeaveBalance{}
+	if err := db.WithContext(ctx).
+		Preload("LeaveType").
+		Where("user_id = ? AND year = ?", userID, year).
+		Order("leave_type_id").
+		Find(&balances).Error; err != nil {
+		return []models.LeaveBalance{}, err
+	}
+	return balances, nil
+}
+
+func (l *leaveQueryImpl) GetLeaveBalance(ctx context.Context, userID uint64, leaveTypeID uint64, year int) (models.LeaveBalance, error) {
+	db := l.db.GetConnection()
+	balance := models.LeaveBalance{}
+	if err := db.WithContext(ctx).
+		Preload("LeaveType").
+		Where("user_id = ? AND leave_type_id = ? AND year = ?", userID, leaveTypeID, year).
+		First(&balance).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.LeaveBalance{}, nil
+		}
+		return models.LeaveBalance{}, err
+	}
+	return balance, nil
+}
+
+func (l *leaveQueryImpl) GetLeaveRequests(ctx context.Context, filter models.LeaveRequestFilter) ([]models.LeaveRequest, error) {
+	db := l.db.GetConnection()
+
+	query := db.WithContext(ctx).
+		Model(&models.LeaveRequest{}).
+		Preload("User").
+		Preload("LeaveType")
+
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.UserIDs != nil {
+		if len(filter.UserIDs) == 0 {
+			return []models.LeaveRequest{}, nil
+		}
+		query = query.Where("user_id IN ?", filter.UserIDs)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("end_date >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("start_date <= ?", filter.To)
+	}
+
+	requests := []models.LeaveRequest{}
+	if err := query.Order("start_date DESC, id DESC").Find(&requests).Error; err != nil {
+		return []models.LeaveRequest{}, err
+	}
+	return requests, nil
+}
+
+func (l *leaveQueryImpl) GetLeaveRequestByID(ctx context.Context, id uint64) (models.LeaveRequest, error) {
+	db := l.db.GetConnection()
+	request := models.LeaveRequest{}
+	if err := db.WithContext(ctx).
+		Preload("User").
+		Preload("LeaveType").
+		Where("id = ?", id).
+		First(&request).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.LeaveRequest{}, nil
+		}
+		return models.LeaveRequest{}, err
+	}
+	return request, nil
+}
+
+// EnsureLeaveBalances creates the missing, empty balances of every paid
+// leave type for the user's year.
+func (l *leaveQueryImpl) EnsureLeaveBalances(ctx context.Context, userID uint64, year int) error {
+	db := l.db.GetConnection()
+	return db.WithContext(ctx).Exec(`
+		INSERT INTO leave_balances (user_id, leave_type_id, year)
+		SELECT ?, id, ? FROM leave_types WHERE is_paid
+		ON CONFLICT (user_id, leave_type_id, year) DO NOTHING`,
+		userID, year,
+	).Error
+}
+
+// GetLedgerEntries returns the user's ledger in the order it was applied to
+// the balances.
+func (l *leaveQueryImpl) GetLedgerEntries(ctx context.Context, userID uint64, filter models.LeaveLedgerFilter) ([]models.LeaveLedgerEntry, error) {
+	db := l.db.GetConnection()
+
+	query := db.WithContext(ctx).Where("user_id = ?", userID)
+	if filter.Year != 0 {
+		query = query.Where("year = ?", filter.Year)
+	}
+	if filter.LeaveTypeID != 0 {
+		query = query.Where("leave_type_id = ?", filter.LeaveTypeID)
+	}
+
+	entries := []models.LeaveLedgerEntry{}
+	if err := query.
+		Order("year, leave_type_id, effective_date, id").
+		Find(&entries).Error; err != nil {
+		return []models.LeaveLedgerEntry{}, err
+	}
+	return entries, nil
+}
+
+// PostLedgerEntry records entry and applies it to the balance of its year.
+// Entries with a period key already posted are skipped and reported with
+// false, so scheduled postings can safely run again.
+func (l *leaveQueryImpl) PostLedgerEntry(ctx context.Context, entry models.LeaveLedgerEntry) (bool, error) {
+	db := l.db.GetConnection()
+	posted := false
+
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		inserted, err := insertLedgerEntry(tx, entry)
+		if err != nil || !inserted {
+			return err
+		}
+		posted = true
+
+		if err := tx.Exec(`
+			INSERT INTO leave_balances (user_id, leave_type_id, year)
+			VALUES (?, ?, ?)
+			ON CONFLICT (user_id, leave_type_id, year) DO NOTHING`,
+			entry.UserID, entry.LeaveTypeID, entry.Year,
+		).Error; err != nil {
+			return err
+		}
+
+		column := "entitled_days"
+		days := entry.Days
+		if entry.EntryType.IsUsage() {
+			column = "used_days"
+			days = -days
+		}
+		return tx.Exec(
+			"UPDATE leave_balances SET "+column+" = "+column+" + ?, updated_at = CURRENT_TIMESTAMP WHERE user_id = ? AND leave_type_id = ? AND year = ?",
+			days, entry.UserID, entry.LeaveTypeID, entry.Year,
+		).Error
+	})
+	if err != nil {
+		return false, err
+	}
+	return posted, nil
+}
+
+func (l *leaveQueryImpl) CreateLeaveRequest(ctx context.Context, request models.LeaveRequest) (models.LeaveRequest, error) {
+	db := l.db.GetConnection()
+	if err := db.WithContext(ctx).Omit("User", "LeaveType").Create(&request).Error; err != nil {
+		return models.LeaveRequest{}, err
+	}
+	return request, nil
+}
+
+// UpdateLeaveRequest saves the editable fields of a draft request.
+func (l *leaveQueryImpl) UpdateLeaveRequest(ctx context.Context, request models.LeaveRequest) (models.LeaveRequest, error) {
+	db := l.db.GetConnection()
+
+	result := db.WithContext(ctx).
+		Model(&request).
+		Where("status = ?", models.LeaveStatusDraft).
+		Select("leave_type_id", "start_date", "end_date", "days", "reason").
+		Updates(&request)
+	if result.Error != nil {
+		return models.LeaveRequest{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return models.LeaveRequest{}, ErrLeaveRequestStateChanged
+	}
+	return request, nil
+}
+
+func (l *leaveQueryImpl) SubmitLeaveRequest(ctx context.Context, request models.LeaveRequest) error {
+	db := l.db.GetConnection()
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?::int, ?::int)", leaveRequestLockKey, request.UserID).Error; err != nil {
+			return err
+		}
+
+		var overlapping int64
+		if err := tx.Model(&models.LeaveRequest{}).
+			Where("user_id = ? AND id <> ?", request.UserID, request.ID).
+			Where("status IN ?", []models.LeaveStatus{models.LeaveStatusSubmitted, models.LeaveStatusApproved}).
+			Where("start_date <= ? AND end_date >= ?", request.EndDate, request.StartDate).
+			Count(&overlapping).Error; err != nil {
+			return err
+		}
+		if overlapping > 0 {
+			return ErrLeaveRequestOverlap
+		}
+
+		return updateLeaveStatus(tx, request, models.LeaveStatusDraft)
+	})
+}
+
+// ApproveLeaveRequest approves a submitted request and, when deduct is set,
+// takes its days from the balance of the request's year.
+func (l *leaveQueryImpl) ApproveLeaveRequest(ctx context.Context, request models.LeaveRequest, deduct bool) error {
+	db := l.db.GetConnection()
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if deduct {
+			result := tx.Exec(`
+				UPDATE leave_balances
+				SET used_days = used_days + ?, updated_at = CURRENT_TIMESTAMP
+				WHERE user_id = ? AND leave_type_id = ? AND year = ?
+				AND entitled_days - used_days >= ?`,
+				request.Days, request.UserID, request.LeaveTypeID, request.StartDate.Year(), request.Days,
+			)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return ErrInsufficientLeaveBalance
+			}
+			if _, err := insertLedgerEntry(tx, requestLedgerEntry(request, models.LedgerUsage)); err != nil {
+				return err
+			}
+		}
+
+		return updateLeaveStatus(tx, request, models.LeaveStatusSubmitted)
+	})
+}
+
+func (l *leaveQueryImpl) RejectLeaveRequest(ctx context.Context, request models.LeaveRequest) error {
+	db := l.db.GetConnection()
+	return updateLeaveStatus(db.WithContext(ctx), request, models.LeaveStatusSubmitted)
+}
+
+// CancelLeaveRequest cancels a request that is still in the from status and,
+// when restore is set, gives its days back to the balance.
+func (l *leaveQueryImpl) CancelLeaveRequest(ctx context.Context, request models.LeaveRequest, from models.LeaveStatus, restore bool) error {
+	db := l.db.GetConnection()
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := updateLeaveStatus(tx, request, from); err != nil {
+			return err
+		}
+		if !restore {
+			return nil
+		}
+		if _, err := insertLedgerEntry(tx, requestLedgerEntry(request, models.LedgerRestore)); err != nil {
+			return err
+		}
+
+		return tx.Exec(`
+			UPDATE leave_balances
+			SET used_days = GREATEST(used_days - ?, 0), updated_at = CURRENT_TIMESTAMP
+			WHERE user_id = ? AND leave_type_id = ? AND year = ?`,
+			request.Days, request.UserID, request.LeaveTypeID, request.StartDate.Year(),
+		).Error
+	})
+}
+
+// updateLeaveStatus writes the status fields of request, but only while the
+// stored request is still in the from status.
+func updateLeaveStatus(tx *gorm.DB, request models.LeaveRequest, from models.LeaveStatus) error {
+	result := tx.Model(&models.LeaveRequest{}).
+		Where("id = ? AND status = ?", request.ID, from).
+		Updates(map[string]interface{}{
+			"status":        request.Status,
+			"decided_by":    request.DecidedBy,
+			"decision_note": request.DecisionNote,
+			"submitted_at":  request.SubmittedAt,
+			"decided_at":    request.DecidedAt,
+			"cancelled_at":  request.CancelledAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrLeaveRequestStateChanged
+	}
+	return nil
+}
+
+func insertLedgerEntry(tx *gorm.DB, entry models.LeaveLedgerEntry) (bool, error) {
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&entry)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// requestLedgerEntry is the ledger entry of taking or giving back the days of
+// an approved request.
+func requestLedgerEntry(request models.LeaveRequest, entryType models.LedgerEntryType) models.LeaveLedgerEntry {
+	days := float64(request.Days)
+	if entryType == models.LedgerUsage {
+		days = -days
+	}
+	periodKey := fmt.Sprintf("request:%d", request.ID)
+	requestID := request.ID
+
+	return models.LeaveLedgerEntry{
+		UserID:         request.UserID,
+		LeaveTypeID:    request.LeaveTypeID,
+		Year:           request.StartDate.Year(),
+		EntryType:      entryType,
+		Days:           days,
+		PeriodKey:      &periodKey,
+		LeaveRequestID: &requestID,
+		EffectiveDate:  request.StartDate,
+	}
+}
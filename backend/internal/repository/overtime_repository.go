@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+	"main.go/config"
+	"main.go/internal/models"
+)
+
+var (
+	ErrOvertimeRequestExists       = errors.New("overtime request already exists")
+	ErrOvertimeRequestStateChanged = errors.New("overtime request state changed")
+)
+
+type OvertimeQuery interface {
+	// GET
+	GetRates(ctx context.Context) ([]models.OvertimeRate, error)
+	GetOvertimeRequests(ctx context.Context, filter models.OvertimeFilter) ([]models.OvertimeRequest, error)
+	GetOvertimeRequestByID(ctx context.Context, id uint64) (models.OvertimeRequest, error)
+
+	// POST
+	UpdateRates(ctx context.Context, rates []models.OvertimeRate) error
+	CreateOvertimeRequest(ctx context.Context, request models.OvertimeRequest) (models.OvertimeRequest, error)
+	UpdateOvertimeStatus(ctx context.Context, request models.OvertimeRequest, from models.OvertimeStatus) error
+	LinkAttendance(ctx context.Context, attendance models.Attendance) error
+}
+
+type overtimeQueryImpl struct {
+	db config.GormPostgres
+}
+
+func NewOvertimeQuery(db config.GormPostgres) OvertimeQuery {
+	return &overtimeQueryImpl{db: db}
+}
+
+func (o *overtimeQueryImpl) GetRates(ctx context.Context) ([]models.OvertimeRate, error) {
+	db := o.db.GetConnection()
+	rates := []models.OvertimeRate{}
+	if err := db.WithContext(ctx).Order("day_type").Find(&rates).Error; err != nil {
+		return []models.OvertimeRate{}, err
+	}
+	return rates, nil
+}
+
+func (o *overtimeQueryImpl) GetOvertimeRequests(ctx context.Context, filter models.OvertimeFilter) ([]models.OvertimeRequest, error) {
+	db := o.db.GetConnection()
+
+	query := db.WithContext(ctx).
+		Model(&models.OvertimeRequest{}).
+		Preload("User").
+		Preload("Attendance")
+
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.UserIDs != nil {
+		if len(filter.UserIDs) == 0 {
+			return []models.OvertimeRequest{}, nil
+		}
+		query = query.Where("user_id IN ?", filter.UserIDs)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("work_date >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("work_date <= ?", filter.To)
+	}
+
+	requests := []models.OvertimeRequest{}
+	if err := query.Order("work_date DESC, id DESC").Find(&requests).Error; err != nil {
+		return []models.OvertimeRequest{}, err
+	}
+	return requests, nil
+}
+
+func (o *overtimeQueryImpl) GetOvertimeRequestByID(ctx context.Context, id uint64) (models.OvertimeRequest, error) {
+	db := o.db.GetConnection()
+	request := models.OvertimeRequest{}
+	if err := db.WithContext(ctx).
+		Preload("User").
+		Preload("Attendance").
+		Where("id = ?", id).
+		First(&request).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.OvertimeRequest{}, nil
+		}
+		return models.OvertimeRequest{}, err
+	}
+	return request, nil
+}
+
+func (o *overtimeQueryImpl) UpdateRates(ctx context.Context, rates []models.OvertimeRate) error {
+	db := o.db.GetConnection()
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, rate := range rates {
+			if err := tx.Model(&models.OvertimeRate{}).
+				Where("day_type = ?", rate.DayType).
+				Update("multiplier", rate.Multiplier).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// CreateOvertimeRequest stores a request unless the user already has a
+// pending or approved request for the day.
+func (o *overtimeQueryImpl) CreateOvertimeRequest(ctx context.Context, request models.OvertimeRequest) (models.OvertimeRequest, error) {
+	db := o.db.GetConnection()
+
+	if err := db.WithContext(ctx).Omit("User", "Attendance").Create(&request).Error; err != nil {
+		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+			return models.OvertimeRequest{}, ErrOvertimeRequestExists
+		}
+		return models.OvertimeRequest{}, err
+	}
+	return request, nil
+}
+
+// UpdateOvertimeStatus moves a request out of status from. Another change
+// in the meantime makes it fail with ErrOvertimeRequestStateChanged.
+func (o *overtimeQueryImpl) UpdateOvertimeStatus(ctx context.Context, request models.OvertimeRequest, from models.OvertimeStatus) error {
+	db := o.db.GetConnection()
+
+	result := db.WithContext(ctx).
+		Model(&models.OvertimeRequest{}).
+		Where("id = ? AND status = ?", request.ID, from).
+		Updates(map[string]interface{}{
+			"status":        request.Status,
+			"multiplier":    request.Multiplier,
+			"decided_by":    request.DecidedBy,
+			"decision_note": request.DecisionNote,
+			"decided_at":    request.DecidedAt,
+			"cancelled_at":  request.CancelledAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrOvertimeRequestStateChanged
+	}
+	return nil
+}
+
+// LinkAttendance attaches the attendance to the open pre-approval of its
+// user and day, if there is one.
+func (o *overtimeQueryImpl) LinkAttendance(ctx context.Context, attendance models.Attendance) error {
+	db := o.db.GetConnection()
+
+	return db.WithContext(ctx).
+		Model(&models.OvertimeRequest{}).
+		Where("user_id = ? AND work_date = ? AND kind = ? AND attendance_id IS NULL", attendance.UserID, attendance.WorkDate, models.OvertimePreApproval).
+		Where("status IN ?", []models.OvertimeStatus{models.OvertimeStatusPending, models.OvertimeStatusApproved}).
+		Update("attendance_id", attendance.ID).Error
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"main.go/config"
+	"main.go/internal/models"
+)
+
+// payPeriodLockKey serializes pay period creation so two overlapping periods
+// can't both pass the overlap check.
+const payPeriodLockKey = 720904
+
+var (
+	ErrPayPeriodOverlap      = errors.New("pay period overlaps another pay period")
+	ErrPayPeriodStateChanged = errors.New("pay period state changed")
+)
+
+type PayPeriodQuery interface {
+	// GET
+	GetPayPeriods(ctx context.Context, filter models.PayPeriodFilter) ([]models.PayPeriod, error)
+	GetPayPeriodByID(ctx context.Context, id uint64) (models.PayPeriod, error)
+	GetPayPeriodEvents(ctx context.Context, id uint64) ([]models.PayPeriodEvent, error)
+	HasClosedPayPeriod(ctx context.Context, from, to models.Date) (bool, error)
+
+	// POST
+	CreatePayPeriod(ctx context.Context, period models.PayPeriod) (models.PayPeriod, error)
+	UpdatePayPeriodStatus(ctx context.Context, period models.PayPeriod, from models.PayPeriodStatus, event models.PayPeriodEvent) error
+}
+
+type payPeriodQueryImpl struct {
+	db config.GormPostgres
+}
+
+func NewPayPeriodQuery(db config.GormPostgres) PayPeriodQuery {
+	return &payPeriodQueryImpl{db: db}
+}
+
+func (p *payPeriodQueryImpl) GetPayPeriods(ctx context.Context, filter models.PayPeriodFilter) ([]models.PayPeriod, error) {
+	db := p.db.GetConnection()
+
+	query := db.WithContext(ctx).Model(&models.PayPeriod{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("end_date >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("start_date <= ?", filter.To)
+	}
+
+	periods := []models.PayPeriod{}
+	if err := query.Order("start_date DESC").Find(&periods).Error; err != nil {
+		return []models.PayPeriod{}, err
+	}
+	return periods, nil
+}
+
+func (p *payPeriodQueryImpl) GetPayPeriodByID(ctx context.Context, id uint64) (models.PayPeriod, error) {
+	db := p.db.GetConnection()
+	period := models.PayPeriod{}
+	if err := db.WithContext(ctx).Where("id = ?", id).First(&period).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.PayPeriod{}, nil
+		}
+		return models.PayPeriod{}, err
+	}
+	return period, nil
+}
+
+func (p *payPeriodQueryImpl) GetPayPeriodEvents(ctx context.Context, id uint64) ([]models.PayPeriodEvent, error) {
+	db := p.db.GetConnection()
+	events := []models.PayPeriodEvent{}
+	if err := db.WithContext(ctx).
+		Preload("User").
+		Where("pay_period_id = ?", id).
+		Order("created_at, id").
+		Find(&events).Error; err != nil {
+		return []models.PayPeriodEvent{}, err
+	}
+	return events, nil
+}
+
+// HasClosedPayPeriod tells whether any day from from to to falls in a
+// closed pay period.
+func (p *payPeriodQueryImpl) HasClosedPayPeriod(ctx context.Context, from, to models.Date) (bool, error) {
+	db := p.db.GetConnection()
+	var count int64
+	if err := db.WithContext(ctx).
+		Model(&models.PayPeriod{}).
+		Where("status = ? AND start_date <= ? AND end_date >= ?", models.PayPeriodStatusClosed, to, from).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// CreatePayPeriod stores a period unless it shares a day with another one.
+func (p *payPeriodQueryImpl) CreatePayPeriod(ctx context.Context, period models.PayPeriod) (models.PayPeriod, error) {
+	db := p.db.GetConnection()
+
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", payPeriodLockKey).Error; err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&models.PayPeriod{}).
+			Where("start_date <= ? AND end_date >= ?", period.EndDate, period.StartDate).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrPayPeriodOverlap
+		}
+
+		return tx.Create(&period).Error
+	})
+	if err != nil {
+		return models.PayPeriod{}, err
+	}
+	return period, nil
+}
+
+// UpdatePayPeriodStatus moves a period out of status from and records event
+// in the same transaction. Another change in the meantime makes it fail
+// with ErrPayPeriodStateChanged.
+func (p *payPeriodQueryImpl) UpdatePayPeriodStatus(ctx context.Context, period models.PayPeriod, from models.PayPeriodStatus, event models.PayPeriodEvent) error {
+	db := p.db.GetConnection()
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.PayPeriod{}).
+			Where("id = ? AND status = ?", period.ID, from).
+			Updates(map[string]interface{}{
+				"status":    period.Status,
+				"closed_by": period.ClosedBy,
+				"closed_at": period.ClosedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrPayPeriodStateChanged
+		}
+
+		event.PayPeriodID = period.ID
+		return tx.Omit("User").Create(&event).Error
+	})
+}
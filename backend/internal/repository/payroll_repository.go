@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"main.go/config"
+	"main.go/internal/models"
+)
+
+var (
+	ErrPayrollRunExists       = errors.New("payroll run already exists")
+	ErrPayrollRunStateChanged = errors.New("payroll run state changed")
+)
+
+type PayrollQuery interface {
+	// GET
+	GetPayrollRuns(ctx context.Context, filter models.PayrollRunFilter) ([]models.PayrollRun, error)
+	GetPayrollRunByID(ctx context.Context, id uint64) (models.PayrollRun, error)
+	GetPayslips(ctx context.Context, filter models.PayslipFilter) ([]models.Payslip, error)
+	GetPayslipByID(ctx context.Context, id uint64) (models.Payslip, error)
+	GetBankAccount(ctx context.Context, userID uint64) (models.BankAccount, error)
+	GetBankAccounts(ctx context.Context, userIDs []int) ([]models.BankAccount, error)
+
+	// POST
+	CreatePayrollRun(ctx context.Context, run models.PayrollRun) (models.PayrollRun, error)
+	DeletePayrollRun(ctx context.Context, id uint64) error
+	SavePayslips(ctx context.Context, run models.PayrollRun, payslips []models.Payslip) error
+	FinalizePayrollRun(ctx context.Context, run models.PayrollRun) error
+	SaveBankAccount(ctx context.Context, account models.BankAccount) (models.BankAccount, error)
+}
+
+type payrollQueryImpl struct {
+	db config.GormPostgres
+}
+
+func NewPayrollQuery(db config.GormPostgres) PayrollQuery {
+	return &payrollQueryImpl{db: db}
+}
+
+func (p *payrollQueryImpl) GetPayrollRuns(ctx context.Context, filter models.PayrollRunFilter) ([]models.PayrollRun, error) {
+	db := p.db.GetConnection()
+
+	query := db.WithContext(ctx).
+		Model(&models.PayrollRun{}).
+		Preload("PayPeriod")
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	runs := []models.PayrollRun{}
+	if err := query.Order("pay_date DESC, id DESC").Find(&runs).Error; err != nil {
+		return []models.PayrollRun{}, err
+	}
+	return runs, nil
+}
+
+func (p *payrollQueryImpl) GetPayrollRunByID(ctx context.Context, id uint64) (models.PayrollRun, error) {
+	db := p.db.GetConnection()
+	run := models.PayrollRun{}
+	if err := db.WithContext(ctx).
+		Preload("PayPeriod").
+		Where("id = ?", id).
+		First(&run).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.PayrollRun{}, nil
+		}
+		return models.PayrollRun{}, err
+	}
+	return run, nil
+}
+
+func (p *payrollQueryImpl) GetPayslips(ctx context.Context, filter models.PayslipFilter) ([]models.Payslip, error) {
+	db := p.db.GetConnection()
+
+	query := db.WithContext(ctx).
+		Model(&models.Payslip{}).
+		Preload("User").
+		Preload("PayrollRun").
+		Preload("PayrollRun.PayPeriod")
+
+	if filter.PayrollRunID != 0 {
+		query = query.Where("payroll_run_id = ?", filter.PayrollRunID)
+	}
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.FinalizedOnly {
+		query = query.Where("payroll_run_id IN (SELECT id FROM payroll_runs WHERE status = ?)", models.PayrollRunStatusFinalized)
+	}
+
+	payslips := []models.Payslip{}
+	if err := query.Order("payroll_run_id DESC, user_id").Find(&payslips).Error; err != nil {
+		return []models.Payslip{}, err
+	}
+	return payslips, nil
+}
+
+func (p *payrollQueryImpl) GetPayslipByID(ctx context.Context, id uint64) (models.Payslip, error) {
+	db := p.db.GetConnection()
+	payslip := models.Payslip{}
+	if err := db.WithContext(ctx).
+		Preload("User").
+		Preload("PayrollRun").
+		Preload("PayrollRun.PayPeriod").
+		Preload("Lines", func(tx *gorm.DB) *gorm.DB {
+			return tx.Order("position")
+		}).
+		Where("id = ?", id).
+		First(&payslip).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Payslip{}, nil
+		}
+		return models.Payslip{}, err
+	}
+	return payslip, nil
+}
+
+func (p *payrollQueryImpl) GetBankAccount(ctx context.Context, userID uint64) (models.BankAccount, error) {
+	db := p.db.GetConnection()
+	account := models.BankAccount{}
+	if err := db.WithContext(ctx).Where("user_id = ?", userID).First(&account).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.BankAccount{}, nil
+		}
+		return models.BankAccount{}, err
+	}
+	return account, nil
+}
+
+func (p *payrollQueryImpl) GetBankAccounts(ctx context.Context, userIDs []int) ([]models.BankAccount, error) {
+	db := p.db.GetConnection()
+	accounts := []models.BankAccount{}
+	if len(userIDs) == 0 {
+		return accounts, nil
+	}
+	if err := db.WithContext(ctx).Where("user_id IN ?", userIDs).Find(&accounts).Error; err != nil {
+		return []models.BankAccount{}, err
+	}
+	return accounts, nil
+}
+
+// CreatePayrollRun stores a run unless its pay period already has one.
+func (p *payrollQueryImpl) CreatePayrollRun(ctx context.Context, run models.PayrollRun) (models.PayrollRun, error) {
+	db := p.db.GetConnection()
+
+	if err := db.WithContext(ctx).Omit("PayPeriod").Create(&run).Error; err != nil {
+		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+			return models.PayrollRun{}, ErrPayrollRunExists
+		}
+		return models.PayrollRun{}, err
+	}
+	return run, nil
+}
+
+// DeletePayrollRun removes a run that isn't finalized, with its payslips.
+func (p *payrollQueryImpl) DeletePayrollRun(ctx context.Context, id uint64) error {
+	db := p.db.GetConnection()
+
+	result := db.WithContext(ctx).
+		Where("id = ? AND status <> ?", id, models.PayrollRunStatusFinalized).
+		Delete(&models.PayrollRun{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrPayrollRunStateChanged
+	}
+	return nil
+}
+
+// SavePayslips replaces the payslips of a run that isn't finalized and marks
+// it calculated. A run finalized in the meantime makes it fail with
+// ErrPayrollRunStateChanged.
+func (p *payrollQueryImpl) SavePayslips(ctx context.Context, run models.PayrollRun, payslips []models.Payslip) error {
+	db := p.db.GetConnection()
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.PayrollRun{}).
+			Where("id = ? AND status <> ?", run.ID, models.PayrollRunStatusFinalized).
+			Updates(map[string]interface{}{
+				"status":        models.PayrollRunStatusCalculated,
+				"calculated_at": run.CalculatedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrPayrollRunStateChanged
+		}
+
+		if err := tx.Where("payroll_run_id = ?", run.ID).Delete(&models.Payslip{}).Error; err != nil {
+			return err
+		}
+		if len(payslips) == 0 {
+			return nil
+		}
+		for i := range payslips {
+			payslips[i].PayrollRunID = run.ID
+		}
+		return tx.Omit("User", "PayrollRun").Create(&payslips).Error
+	})
+}
+
+// FinalizePayrollRun locks a calculated run. Another change in the meantime
+// makes it fail with ErrPayrollRunStateChanged.
+func (p *payrollQueryImpl) FinalizePayrollRun(ctx context.Context, run models.PayrollRun) error {
+	db := p.db.GetConnection()
+
+	result := db.WithContext(ctx).
+		Model(&models.PayrollRun{}).
+		Where("id = ? AND status = ?", run.ID, models.PayrollRunStatusCalculated).
+		Updates(map[string]interface{}{
+			"status":       models.PayrollRunStatusFinalized,
+			"finalized_by": run.FinalizedBy,
+			"finalized_at": run.FinalizedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrPayrollRunStateChanged
+	}
+	return nil
+}
+
+// SaveBankAccount creates or replaces the bank account of a user.
+func (p *payrollQueryImpl) SaveBankAccount(ctx context.Context, account models.BankAccount) (models.BankAccount, error) {
+	db := p.db.GetConnection()
+
+	if err := db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"bank_name", "account_number", "account_holder", "updated_at"}),
+		}).
+		Create(&account).Error; err != nil {
+		return models.BankAccount{}, err
+	}
+	return p.GetBankAccount(ctx, uint64(account.UserID))
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"main.go/config"
+	"main.go/internal/models"
+)
+
+// ErrPositionInUse is returned by DeletePosition while active users still have
+// the position.
+var ErrPositionInUse = errors.New("position is still assigned to users")
+
+type PositionQuery interface {
+	// GET
+	GetPositions(ctx context.Context) ([]models.Position, error)
+	GetPositionByID(ctx context.Context, id uint64) (models.Position, error)
+	GetPositionByName(ctx context.Context, name string) (models.Position, error)
+
+	// POST
+	CreatePosition(ctx context.Context, position models.Position) (models.Position, error)
+	UpdatePosition(ctx context.Context, id uint64, position models.Position) (models.Position, error)
+	DeletePosition(ctx context.Context, id uint64) error
+}
+
+type positionQueryImpl struct {
+	db config.GormPostgres
+}
+
+func NewPositionQuery(db config.GormPostgres) PositionQuery {
+	return &positionQueryImpl{db: db}
+}
+
+func (p *positionQueryImpl) GetPositions(ctx context.Context) ([]models.Position, error) {
+	db := p.db.GetConnection()
+	positions := []models.Position{}
+	if err := db.
+		WithContext(ctx).
+		Order("id").
+		Find(&positions).Error; err != nil {
+		return []models.Position{}, err
+	}
+	return positions, nil
+}
+
+func (p *positionQueryImpl) GetPositionByID(ctx context.Context, id uint64) (models.Position, error) {
+	db := p.db.GetConnection()
+	position := models.Position{}
+	if err := db.
+		WithContext(ctx).
+		Where("id = ?", id).
+		First(&position).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Position{}, nil
+		}
+		return models.Position{}, err
+	}
+	return position, nil
+}
+
+func (p *positionQueryImpl) GetPositionByName(ctx context.Context, name string) (models.Position, error) {
+	db := p.db.GetConnection()
+	position := models.Position{}
+	if err := db.
+		WithContext(ctx).
+		Where("LOWER(name) = LOWER(?)", name).
+		First(&position).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Position{}, nil
+		}
+		return models.Position{}, err
+	}
+	return position, nil
+}
+
+func (p *positionQueryImpl) CreatePosition(ctx context.Context, position models.Position) (models.Position, error) {
+	db := p.db.GetConnection()
+	if err := db.WithContext(ctx).Create(&position).Error; err != nil {
+		return models.Position{}, err
+	}
+	return position, nil
+}
+
+func (p *positionQueryImpl) UpdatePosition(ctx context.Context, id uint64, position models.Position) (models.Position, error) {
+	db := p.db.GetConnection()
+	if err := db.WithContext(ctx).
+		Model(&models.Position{}).
+		Where("id = ?", id).
+		Update("name", position.Name).
+		Error; err != nil {
+		return models.Position{}, err
+	}
+	return p.GetPositionByID(ctx, id)
+}
+
+// DeletePosition soft deletes a position no active user has, and returns
+// ErrPositionInUse otherwise. The position stays locked until the delete commits
+// and users lock it before taking it, see lockRoleAndPosition, so nobody can
+// be given the position in between.
+func (p *positionQueryImpl) DeletePosition(ctx context.Context, id uint64) error {
+	db := p.db.GetConnection()
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT id FROM positions WHERE id = ? FOR UPDATE", id).Error; err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Table("users").
+			Where("position_id = ?", id).
+			Where("deleted_at IS NULL").
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrPositionInUse
+		}
+
+		return tx.Delete(&models.Position{}, id).Error
+	})
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"main.go/config"
+	"main.go/internal/models"
+)
+
+var ErrRefreshTokenAlreadyUsed = errors.New("refresh token already used")
+
+type RefreshTokenQuery interface {
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (models.RefreshToken, error)
+
+	CreateRefreshToken(ctx context.Context, token models.RefreshToken) error
+	RotateRefreshToken(ctx context.Context, current models.RefreshToken, next models.RefreshToken) error
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) error
+	DeleteStaleRefreshTokens(ctx context.Context) (int64, error)
+}
+
+type refreshTokenQueryImpl struct {
+	db config.GormPostgres
+}
+
+func NewRefreshTokenQuery(db config.GormPostgres) RefreshTokenQuery {
+	return &refreshTokenQueryImpl{db: db}
+}
+
+func (r *refreshTokenQueryImpl) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (models.RefreshToken, error) {
+	db := r.db.GetConnection()
+	token := models.RefreshToken{}
+
+	if err := db.WithContext(ctx).
+		Where("token_hash = ?", tokenHash).
+		First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.RefreshToken{}, nil
+		}
+		return models.RefreshToken{}, err
+	}
+	return token, nil
+}
+
+func (r *refreshTokenQueryImpl) CreateRefreshToken(ctx context.Context, token models.RefreshToken) error {
+	db := r.db.GetConnection()
+	return db.WithContext(ctx).Create(&token).Error
+}
+
+// RotateRefreshToken marks current as used and stores next in one
+// transaction. Only one caller can use a given token, a concurrent or later
+// attempt gets ErrRefreshTokenAlreadyUsed.
+func (r *refreshTokenQueryImpl) RotateRefreshToken(ctx context.Context, current models.RefreshToken, next models.RefreshToken) error {
+	db := r.db.GetConnection()
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ?", current.ID).
+			Where("used_at IS NULL AND revoked_at IS NULL").
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrRefreshTokenAlreadyUsed
+		}
+
+		return tx.Create(&next).Error
+	})
+}
+
+func (r *refreshTokenQueryImpl) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	db := r.db.GetConnection()
+
+	return db.WithContext(ctx).
+		Model(&models.RefreshToken{}).
+		Where("family_id = ?", familyID).
+		Where("revoked_at IS NULL").
+		Update("revoked_at", time.Now()).
+		Error
+}
+
+// DeleteStaleRefreshTokens deletes refresh tokens that expired or were
+// revoked, neither can be exchanged anymore. Used tokens are kept until they
+// expire, so replaying one is still caught as reuse.
+func (r *refreshTokenQueryImpl) DeleteStaleRefreshTokens(ctx context.Context) (int64, error) {
+	db := r.db.GetConnection()
+	result := db.WithContext(ctx).
+		Where("expired_at < ? OR revoked_at IS NOT NULL", time.Now()).
+		Delete(&models.RefreshToken{})
+	return result.RowsAffected, result.Error
+}
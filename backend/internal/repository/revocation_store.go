@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"main.go/config"
+	"main.go/internal/models"
+)
+
+// RevocationStore remembers revoked access tokens by jti until they expire.
+type RevocationStore interface {
+	IsRevoked(ctx context.Context, tokenID string) (bool, error)
+	Revoke(ctx context.Context, tokenID string, expiredAt time.Time) error
+	// RemoveExpired drops entries that outlived their token. Stores that
+	// expire entries on their own return 0.
+	RemoveExpired(ctx context.Context) (int64, error)
+}
+
+type postgresRevocationStore struct {
+	db config.GormPostgres
+}
+
+func NewPostgresRevocationStore(db config.GormPostgres) RevocationStore {
+	return &postgresRevocationStore{db: db}
+}
+
+func (p *postgresRevocationStore) IsRevoked(ctx context.Context, tokenID string) (bool, error) {
+	db := p.db.GetConnection()
+	var blacklisted models.BlackListedToken
+
+	err := db.WithContext(ctx).Where("token = ?", tokenID).First(&blacklisted).Error
+	if err == nil {
+		return true, nil
+	} else if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	return false, err
+}
+
+func (p *postgresRevocationStore) Revoke(ctx context.Context, tokenID string, expiredAt time.Time) error {
+	db := p.db.GetConnection()
+
+	blacklistedToken := models.BlackListedToken{
+		Token:     tokenID,
+		CreatedAt: time.Now(),
+		ExpiredAt: expiredAt,
+	}
+
+	return db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&blacklistedToken).Error
+}
+
+func (p *postgresRevocationStore) RemoveExpired(ctx context.Context) (int64, error) {
+	db := p.db.GetConnection()
+	result := db.WithContext(ctx).Where("expired_at < ?", time.Now()).Delete(&models.BlackListedToken{})
+	return result.RowsAffected, result.Error
+}
+
+type redisRevocationStore struct {
+	client *redis.Client
+}
+
+func NewRedisRevocationStore(client *redis.Client) RevocationStore {
+	return &redisRevocationStore{client: client}
+}
+
+func revokedTokenKey(tokenID string) string {
+	return "revoked_token:" + tokenID
+}
+
+func (r *redisRevocationStore) IsRevoked(ctx context.Context, tokenID string) (bool, error) {
+	count, err := r.client.Exists(ctx, revokedTokenKey(tokenID)).Result()
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *redisRevocationStore) Revoke(ctx context.Context, tokenID string, expiredAt time.Time) error {
+	ttl := time.Until(expiredAt)
+	if ttl <= 0 {
+		return nil
+	}
+	return r.client.Set(ctx, revokedTokenKey(tokenID), 1, ttl).Err()
+}
+
+func (r *redisRevocationStore) RemoveExpired(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+type memoryRevocationStore struct {
+	mu      sync.Mutex
+	revoked map[string]time.Time
+}
+
+// NewMemoryRevocationStore keeps revocations in process memory. They are
+// lost on restart and not shared between instances, so a token revoked on
+// one server is still accepted by the others.
+func NewMemoryRevocationStore() RevocationStore {
+	return &memoryRevocationStore{revoked: map[string]time.Time{}}
+}
+
+func (m *memoryRevocationStore) IsRevoked(ctx context.Context, tokenID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	expiredAt, ok := m.revoked[tokenID]
+	return ok && time.Now().Before(expiredAt), nil
+}
+
+func (m *memoryRevocationStore) Revoke(ctx context.Context, tokenID string, expiredAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.revoked[tokenID] = expiredAt
+	return nil
+}
+
+func (m *memoryRevocationStore) RemoveExpired(ctx context.Context) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var removed int64
+	for tokenID, expiredAt := range m.revoked {
+		if time.Now().After(expiredAt) {
+			delete(m.revoked, tokenID)
+			removed++
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"main.go/config"
+	"main.go/internal/models"
+)
+
+// ErrRoleInUse is returned by DeleteRole while active users still have
+// the role.
+var ErrRoleInUse = errors.New("role is still assigned to users")
+
+type RoleQuery interface {
+	// GET
+	GetRoles(ctx context.Context) ([]models.Role, error)
+	GetRoleByID(ctx context.Context, id uint64) (models.Role, error)
+	GetRoleByName(ctx context.Context, name string) (models.Role, error)
+
+	// POST
+	CreateRole(ctx context.Context, role models.Role, permissions []string) (models.Role, error)
+	UpdateRole(ctx context.Context, id uint64, role models.Role, permissions []string) (models.Role, error)
+	DeleteRole(ctx context.Context, id uint64) error
+}
+
+type roleQueryImpl struct {
+	db config.GormPostgres
+}
+
+func NewRoleQuery(db config.GormPostgres) RoleQuery {
+	return &roleQueryImpl{db: db}
+}
+
+func (r *roleQueryImpl) GetRoles(ctx context.Context) ([]models.Role, error) {
+	db := r.db.GetConnection()
+	roles := []models.Role{}
+	if err := db.
+		WithContext(ctx).
+		Preload("Permissions").
+		Order("id").
+		Find(&roles).Error; err != nil {
+		return []models.Role{}, err
+	}
+	return roles, nil
+}
+
+func (r *roleQueryImpl) GetRoleByID(ctx context.Context, id uint64) (models.Role, error) {
+	db := r.db.GetConnection()
+	role := models.Role{}
+	if err := db.
+		WithContext(ctx).
+		Preload("Permissions").
+		Where("id = ?", id).
+		First(&role).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Role{}, nil
+		}
+		return models.Role{}, err
+	}
+	return role, nil
+}
+
+func (r *roleQueryImpl) GetRoleByName(ctx context.Context, name string) (models.Role, error) {
+	db := r.db.GetConnection()
+	role := models.Role{}
+	if err := db.
+		WithContext(ctx).
+		Where("LOWER(name) = LOWER(?)", name).
+		First(&role).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Role{}, nil
+		}
+		return models.Role{}, err
+	}
+	return role, nil
+}
+
+func (r *roleQueryImpl) CreateRole(ctx context.Context, role models.Role, permissions []string) (models.Role, error) {
+	db := r.db.GetConnection()
+
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Permissions").Create(&role).Error; err != nil {
+			return err
+		}
+		return replaceRolePermissions(tx, role.ID, permissions)
+	})
+	if err != nil {
+		return models.Role{}, err
+	}
+
+	return r.GetRoleByID(ctx, uint64(role.ID))
+}
+
+func (r *roleQueryImpl) UpdateRole(ctx context.Context, id uint64, role models.Role, permissions []string) (models.Role, error) {
+	db := r.db.GetConnection()
+
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Role{}).
+			Where("id = ?", id).
+			Update("name", role.Name).
+			Error; err != nil {
+			return err
+		}
+		if permissions == nil {
+			return nil
+		}
+		return replaceRolePermissions(tx, int(id), permissions)
+	})
+	if err != nil {
+		return models.Role{}, err
+	}
+
+	return r.GetRoleByID(ctx, id)
+}
+
+// DeleteRole soft deletes a role no active user has, and returns
+// ErrRoleInUse otherwise. The role stays locked until the delete commits
+// and users lock it before taking it, see lockRoleAndPosition, so nobody can
+// be given the role in between.
+func (r *roleQueryImpl) DeleteRole(ctx context.Context, id uint64) error {
+	db := r.db.GetConnection()
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT id FROM roles WHERE id = ? FOR UPDATE", id).Error; err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Table("users").
+			Where("role_id = ?", id).
+			Where("deleted_at IS NULL").
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrRoleInUse
+		}
+
+		return tx.Delete(&models.Role{}, id).Error
+	})
+}
+
+func replaceRolePermissions(tx *gorm.DB, roleID int, permissions []string) error {
+	if err := tx.Where("role_id = ?", roleID).Delete(&models.RolePermission{}).Error; err != nil {
+		return err
+	}
+	if len(permissions) == 0 {
+		return nil
+	}
+
+	rows := make([]models.RolePermission, 0, len(permissions))
+	for _, permission := range permissions {
+		rows = append(rows, models.RolePermission{RoleID: roleID, Permission: permission})
+	}
+	return tx.Create(&rows).Error
+}
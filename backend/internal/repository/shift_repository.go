@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"main.go/config"
+	"main.go/internal/models"
+)
+
+// shiftAssignmentLockKey is the first half of the advisory lock that
+// serializes assignment changes of one user, the second half is the user ID.
+const shiftAssignmentLockKey = 720903
+
+var ErrShiftAssignmentOverlap = errors.New("shift assignment overlaps another assignment")
+
+type ShiftQuery interface {
+	// GET
+	GetShifts(ctx context.Context) ([]models.Shift, error)
+	GetShiftByID(ctx context.Context, id uint64) (models.Shift, error)
+	GetShiftByName(ctx context.Context, name string) (models.Shift, error)
+	CountShiftAssignments(ctx context.Context, shiftID uint64) (int64, error)
+	GetAssignments(ctx context.Context, filter models.ShiftAssignmentFilter) ([]models.ShiftAssignment, error)
+	GetAssignmentByID(ctx context.Context, id uint64) (models.ShiftAssignment, error)
+	GetDepartmentUsers(ctx context.Context, departmentID uint64) ([]models.User, error)
+
+	// POST
+	CreateShift(ctx context.Context, shift models.Shift) (models.Shift, error)
+	UpdateShift(ctx context.Context, id uint64, shift models.Shift) (models.Shift, error)
+	DeleteShift(ctx context.Context, id uint64) error
+	SaveAssignment(ctx context.Context, assignment models.ShiftAssignment) (models.ShiftAssignment, error)
+	DeleteAssignment(ctx context.Context, id uint64) error
+}
+
+type shiftQueryImpl struct {
+	db config.GormPostgres
+}
+
+func NewShiftQuery(db config.GormPostgres) ShiftQuery {
+	return &shiftQueryImpl{db: db}
+}
+
+func (s *shiftQueryImpl) GetShifts(ctx context.Context) ([]models.Shift, error) {
+	db := s.db.GetConnection()
+	shifts := []models.Shift{}
+	if err := db.WithContext(ctx).Order("start_time, id").Find(&shifts).Error; err != nil {
+		return []models.Shift{}, err
+	}
+	return shifts, nil
+}
+
+func (s *shiftQueryImpl) GetShiftByID(ctx context.Context, id uint64) (models.Shift, error) {
+	db := s.db.GetConnection()
+	shift := models.Shift{}
+	if err := db.WithContext(ctx).Where("id = ?", id).First(&shift).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Shift{}, nil
+		}
+		return models.Shift{}, err
+	}
+	return shift, nil
+}
+
+func (s *shiftQueryImpl) GetShiftByName(ctx context.Context, name string) (models.Shift, error) {
+	db := s.db.GetConnection()
+	shift := models.Shift{}
+	if err := db.WithContext(ctx).Where("LOWER(name) = LOWER(?)", name).First(&shift).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Shift{}, nil
+		}
+		return models.Shift{}, err
+	}
+	return shift, nil
+}
+
+func (s *shiftQueryImpl) CountShiftAssignments(ctx context.Context, shiftID uint64) (int64, error) {
+	db := s.db.GetConnection()
+	var count int64
+	if err := db.WithContext(ctx).
+		Model(&models.ShiftAssignment{}).
+		Where("shift_id = ?", shiftID).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (s *shiftQueryImpl) GetAssignments(ctx context.Context, filter models.ShiftAssignmentFilter) ([]models.ShiftAssignment, error) {
+	db := s.db.GetConnection()
+
+	query := db.WithContext(ctx).
+		Model(&models.ShiftAssignment{}).
+		Preload("User").
+		Preload("Shift")
+
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.DepartmentID != 0 {
+		query = query.Where("user_id IN (SELECT id FROM users WHERE department_id = ? AND deleted_at IS NULL)", filter.DepartmentID)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("end_date >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("start_date <= ?", filter.To)
+	}
+
+	assignments := []models.ShiftAssignment{}
+	if err := query.Order("start_date, id").Find(&assignments).Error; err != nil {
+		return []models.ShiftAssignment{}, err
+	}
+	return assignments, nil
+}
+
+func (s *shiftQueryImpl) GetAssignmentByID(ctx context.Context, id uint64) (models.ShiftAssignment, error) {
+	db := s.db.GetConnection()
+	assignment := models.ShiftAssignment{}
+	if err := db.WithContext(ctx).
+		Preload("User").
+		Preload("Shift").
+		Where("id = ?", id).
+		First(&assignment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.ShiftAssignment{}, nil
+		}
+		return models.ShiftAssignment{}, err
+	}
+	return assignment, nil
+}
+
+func (s *shiftQueryImpl) GetDepartmentUsers(ctx context.Context, departmentID uint64) ([]models.User, error) {
+	db := s.db.GetConnection()
+	users := []models.User{}
+	if err := db.WithContext(ctx).
+		Where("department_id = ?", departmentID).
+		Order("firstname, lastname, id").
+		Find(&users).Error; err != nil {
+		return []models.User{}, err
+	}
+	return users, nil
+}
+
+func (s *shiftQueryImpl) CreateShift(ctx context.Context, shift models.Shift) (models.Shift, error) {
+	db := s.db.GetConnection()
+	if err := db.WithContext(ctx).Create(&shift).Error; err != nil {
+		return models.Shift{}, err
+	}
+	return s.GetShiftByID(ctx, uint64(shift.ID))
+}
+
+func (s *shiftQueryImpl) UpdateShift(ctx context.Context, id uint64, shift models.Shift) (models.Shift, error) {
+	db := s.db.GetConnection()
+	if err := db.WithContext(ctx).
+		Model(&models.Shift{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"name":          shift.Name,
+			"start_time":    shift.StartTime,
+			"end_time":      shift.EndTime,
+			"break_minutes": shift.BreakMinutes,
+			"weekdays":      shift.WeekdayMask,
+		}).Error; err != nil {
+		return models.Shift{}, err
+	}
+	return s.GetShiftByID(ctx, id)
+}
+
+func (s *shiftQueryImpl) DeleteShift(ctx context.Context, id uint64) error {
+	db := s.db.GetConnection()
+	return db.WithContext(ctx).Delete(&models.Shift{}, id).Error
+}
+
+// SaveAssignment creates or updates an assignment unless it puts the user on
+// two shifts on the same day. The assignment's Shift must be loaded.
+func (s *shiftQueryImpl) SaveAssignment(ctx context.Context, assignment models.ShiftAssignment) (models.ShiftAssignment, error) {
+	db := s.db.GetConnection()
+
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?::int, ?::int)", shiftAssignmentLockKey, assignment.UserID).Error; err != nil {
+			return err
+		}
+
+		others := []models.ShiftAssignment{}
+		if err := tx.Preload("Shift").
+			Where("user_id = ? AND id <> ?", assignment.UserID, assignment.ID).
+			Where("start_date <= ? AND end_date >= ?", assignment.EndDate, assignment.StartDate).
+			Find(&others).Error; err != nil {
+			return err
+		}
+		for _, other := range others {
+			if assignment.Overlaps(other) {
+				return ErrShiftAssignmentOverlap
+			}
+		}
+
+		return tx.Omit("User", "Shift").Save(&assignment).Error
+	})
+	if err != nil {
+		return models.ShiftAssignment{}, err
+	}
+	return s.GetAssignmentByID(ctx, uint64(assignment.ID))
+}
+
+func (s *shiftQueryImpl) DeleteAssignment(ctx context.Context, id uint64) error {
+	db := s.db.GetConnection()
+	return db.WithContext(ctx).Delete(&models.ShiftAssignment{}, id).Error
+}
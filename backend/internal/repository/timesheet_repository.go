@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+	"main.go/config"
+	"main.go/internal/models"
+)
+
+var (
+	ErrTimesheetExists       = errors.New("timesheet already exists")
+	ErrTimesheetStateChanged = errors.New("timesheet state changed")
+)
+
+type TimesheetQuery interface {
+	// GET
+	GetTimesheets(ctx context.Context, filter models.TimesheetFilter) ([]models.Timesheet, error)
+	GetTimesheetByID(ctx context.Context, id uint64) (models.Timesheet, error)
+
+	// POST
+	CreateTimesheet(ctx context.Context, timesheet models.Timesheet) (models.Timesheet, error)
+	ReplaceEntries(ctx context.Context, timesheet models.Timesheet) error
+	UpdateTimesheetStatus(ctx context.Context, timesheet models.Timesheet, from models.TimesheetStatus) error
+}
+
+type timesheetQueryImpl struct {
+	db config.GormPostgres
+}
+
+func NewTimesheetQuery(db config.GormPostgres) TimesheetQuery {
+	return &timesheetQueryImpl{db: db}
+}
+
+func (t *timesheetQueryImpl) GetTimesheets(ctx context.Context, filter models.TimesheetFilter) ([]models.Timesheet, error) {
+	db := t.db.GetConnection()
+
+	query := db.WithContext(ctx).
+		Model(&models.Timesheet{}).
+		Preload("User")
+
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.UserIDs != nil {
+		if len(filter.UserIDs) == 0 {
+			return []models.Timesheet{}, nil
+		}
+		query = query.Where("user_id IN ?", filter.UserIDs)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("period_start >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("period_start <= ?", filter.To)
+	}
+
+	timesheets := []models.Timesheet{}
+	if err := query.Order("period_start DESC, user_id").Find(&timesheets).Error; err != nil {
+		return []models.Timesheet{}, err
+	}
+	return timesheets, nil
+}
+
+func (t *timesheetQueryImpl) GetTimesheetByID(ctx context.Context, id uint64) (models.Timesheet, error) {
+	db := t.db.GetConnection()
+	timesheet := models.Timesheet{}
+	if err := db.WithContext(ctx).
+		Preload("User").
+		Preload("Entries", func(tx *gorm.DB) *gorm.DB {
+			return tx.Order("work_date, id")
+		}).
+		Where("id = ?", id).
+		First(&timesheet).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Timesheet{}, nil
+		}
+		return models.Timesheet{}, err
+	}
+	return timesheet, nil
+}
+
+// CreateTimesheet stores a timesheet with its entries unless the user
+// already has one for the period.
+func (t *timesheetQueryImpl) CreateTimesheet(ctx context.Context, timesheet models.Timesheet) (models.Timesheet, error) {
+	db := t.db.GetConnection()
+
+	if err := db.WithContext(ctx).Omit("User").Create(&timesheet).Error; err != nil {
+		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+			return models.Timesheet{}, ErrTimesheetExists
+		}
+		return models.Timesheet{}, err
+	}
+	return timesheet, nil
+}
+
+// ReplaceEntries swaps the entries and total of an editable timesheet. A
+// timesheet submitted in the meantime makes it fail with
+// ErrTimesheetStateChanged.
+func (t *timesheetQueryImpl) ReplaceEntries(ctx context.Context, timesheet models.Timesheet) error {
+	db := t.db.GetConnection()
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Timesheet{}).
+			Where("id = ? AND status IN ?", timesheet.ID, []models.TimesheetStatus{models.TimesheetStatusDraft, models.TimesheetStatusRejected}).
+			Update("total_minutes", timesheet.TotalMinutes)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTimesheetStateChanged
+		}
+
+		if err := tx.Where("timesheet_id = ?", timesheet.ID).Delete(&models.TimesheetEntry{}).Error; err != nil {
+			return err
+		}
+		if len(timesheet.Entries) == 0 {
+			return nil
+		}
+		for i := range timesheet.Entries {
+			timesheet.Entries[i].ID = 0
+			timesheet.Entries[i].TimesheetID = timesheet.ID
+		}
+		return tx.Create(&timesheet.Entries).Error
+	})
+}
+
+// UpdateTimesheetStatus moves a timesheet out of status from. Another
+// change in the meantime makes it fail with ErrTimesheetStateChanged.
+func (t *timesheetQueryImpl) UpdateTimesheetStatus(ctx context.Context, timesheet models.Timesheet, from models.TimesheetStatus) error {
+	db := t.db.GetConnection()
+
+	result := db.WithContext(ctx).
+		Model(&models.Timesheet{}).
+		Where("id = ? AND status = ?", timesheet.ID, from).
+		Updates(map[string]interface{}{
+			"status":        timesheet.Status,
+			"submitted_at":  timesheet.SubmittedAt,
+			"decided_by":    timesheet.DecidedBy,
+			"decision_note": timesheet.DecisionNote,
+			"decided_at":    timesheet.DecidedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTimesheetStateChanged
+	}
+	return nil
+}
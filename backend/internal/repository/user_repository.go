@@ -8,26 +8,54 @@ import (
 
 	"gorm.io/gorm"
 	"main.go/config"
+	"main.go/internal/apperror"
 	"main.go/internal/models"
 )
 
 type UserQuery interface {
 	// GET
-	GetUsers(ctx context.Context) ([]models.User, error)
+	GetUsers(ctx context.Context, filter models.UserFilter) ([]models.User, int64, error)
 	GetUserByID(ctx context.Context, id uint64) (models.User, error)
 	GetUserByEmail(ctx context.Context, email string) (models.User, error)
+	GetPasswordHash(ctx context.Context, id uint64) (string, error)
 	GetRoleByID(ctx context.Context, id uint64) (models.Role, error)
 	GetPositionByID(ctx context.Context, id uint64) (models.Position, error)
+	GetReports(ctx context.Context, managerID uint64, recursive bool) ([]models.Report, error)
+	GetOrgChartNodes(ctx context.Context) ([]models.OrgChartNode, error)
+	IsManagerOf(ctx context.Context, managerID uint64, userID uint64) (bool, error)
 
 	// POST
 	CreateUser(ctx context.Context, user models.User) (models.User, error)
 	UpdateUser(ctx context.Context, id uint64, user models.User) (models.User, error)
-	DeleteUser(ctx context.Context, id uint64) error
-
-	IsTokenBlacklisted(ctx context.Context, token string) (bool, error)
-	AddTokenToBlacklist(ctx context.Context, token string) error
+	DeleteUser(ctx context.Context, id uint64) ([]uint64, error)
+	GetDeletedUsers(ctx context.Context, filter models.DeletedUserFilter) ([]models.User, int64, error)
+	GetDeletedUserByID(ctx context.Context, id uint64) (models.User, error)
+	RestoreUser(ctx context.Context, id uint64) error
+	PurgeUser(ctx context.Context, id uint64) error
+	PurgeDeletedUsers(ctx context.Context, deletedBefore time.Time) ([]uint64, error)
+	UpdateUserDepartment(ctx context.Context, id uint64, departmentID *int) error
+	UpdateUserManager(ctx context.Context, id uint64, managerID *int) error
 }
 
+var ErrReportingCycle = errors.New("reporting line would create a cycle")
+
+// ErrUserVersionMismatch is returned by UpdateUser when the user was changed
+// since the version it was given.
+var ErrUserVersionMismatch = errors.New("user version mismatch")
+
+var (
+	// ErrUserEmailTaken is returned by RestoreUser when an active user has
+	// the email of the user being restored.
+	ErrUserEmailTaken = errors.New("email already exists")
+	// ErrUserHasPayslips is returned by PurgeUser for users with payslips,
+	// which have to be kept.
+	ErrUserHasPayslips = errors.New("user has payslips")
+)
+
+// reportingLineLockKey serializes manager changes so two concurrent updates
+// can't each pass the cycle check and still form a loop together.
+const reportingLineLockKey = 720901
+
 type userQueryImpl struct {
 	db config.GormPostgres
 }
@@ -36,19 +64,91 @@ func NewUserQuery(db config.GormPostgres) UserQuery {
 	return &userQueryImpl{db: db}
 }
 
-func (u *userQueryImpl) GetUsers(ctx context.Context) ([]models.User, error) {
+// GetUsers returns up to filter.Limit+1 users so the caller can tell whether
+// another page follows, together with the number of users matching the
+// filters.
+func (u *userQueryImpl) GetUsers(ctx context.Context, filter models.UserFilter) ([]models.User, int64, error) {
 	db := u.db.GetConnection()
-	users := []models.User{}
-	if err := db.
+
+	query := db.
 		WithContext(ctx).
+		Table("users").
+		Where("deleted_at IS NULL")
+
+	if filter.RoleID != 0 {
+		query = query.Where("role_id = ?", filter.RoleID)
+	}
+	if filter.PositionID != 0 {
+		query = query.Where("position_id = ?", filter.PositionID)
+	}
+	if filter.DepartmentID != 0 {
+		query = query.Where("department_id = ?", filter.DepartmentID)
+	}
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		pattern := "%" + likeEscaper.Replace(search) + "%"
+		query = query.Where(
+			"firstname ILIKE ? OR lastname ILIKE ? OR email ILIKE ? OR CONCAT(firstname, ' ', lastname) ILIKE ?",
+			pattern, pattern, pattern, pattern,
+		)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return []models.User{}, 0, err
+	}
+
+	columns, values := userSortColumns(filter)
+	direction := "ASC"
+	comparison := ">"
+	if filter.Order == "desc" {
+		direction = "DESC"
+		comparison = "<"
+	}
+
+	if filter.After != nil {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+		query = query.Where(
+			"("+strings.Join(columns, ", ")+") "+comparison+" ("+placeholders+")",
+			values...,
+		)
+	} else if filter.Page > 1 {
+		query = query.Offset((filter.Page - 1) * filter.Limit)
+	}
+
+	for _, column := range columns {
+		query = query.Order(column + " " + direction)
+	}
+
+	users := []models.User{}
+	if err := query.
 		Preload("Role").
 		Preload("Position").
-		Table("users").
-		Where("deleted_at IS NULL").
+		Preload("Department").
+		Limit(filter.Limit + 1).
 		Find(&users).Error; err != nil {
-		return []models.User{}, err
+		return []models.User{}, 0, err
+	}
+	return users, total, nil
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// userSortColumns lists the columns of the requested sort order, always
+// ending with id so the order is stable, and the cursor values for them.
+func userSortColumns(filter models.UserFilter) ([]string, []interface{}) {
+	after := filter.After
+	if after == nil {
+		after = &models.UserCursor{}
+	}
+
+	switch filter.Sort {
+	case "name":
+		return []string{"firstname", "lastname", "id"}, []interface{}{after.Firstname, after.Lastname, after.ID}
+	case "email":
+		return []string{"email", "id"}, []interface{}{after.Email, after.ID}
+	default:
+		return []string{"created_at", "id"}, []interface{}{after.CreatedAt, after.ID}
 	}
-	return users, nil
 }
 
 func (u *userQueryImpl) GetUserByID(ctx context.Context, id uint64) (models.User, error) {
@@ -59,6 +159,7 @@ func (u *userQueryImpl) GetUserByID(ctx context.Context, id uint64) (models.User
 		Table("users").
 		Preload("Role").
 		Preload("Position").
+		Preload("Department").
 		Where("id = ?", id).
 		Where("deleted_at IS NULL").
 		Find(&users).Error; err != nil {
@@ -75,24 +176,45 @@ func (u *userQueryImpl) GetRoleByID(ctx context.Context, id uint64) (models.Role
 	var role models.Role
 
 	if err := db.WithContext(ctx).
+		Preload("Permissions").
 		Where("id = ?", id).
 		First(&role).
 		Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return models.Role{}, errors.New("role not found")
+			return models.Role{}, apperror.Validation("role_not_found", "role not found")
 		}
 		return models.Role{}, err
 	}
 	return role, nil
 }
 
+// GetPasswordHash returns the bcrypt hash of an active user's password, or
+// an empty string when there is no such user.
+func (u *userQueryImpl) GetPasswordHash(ctx context.Context, id uint64) (string, error) {
+	db := u.db.GetConnection()
+	var hashes []string
+
+	if err := db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ?", id).
+		Limit(1).
+		Pluck("password", &hashes).
+		Error; err != nil {
+		return "", err
+	}
+	if len(hashes) == 0 {
+		return "", nil
+	}
+	return hashes[0], nil
+}
+
 func (u *userQueryImpl) GetPositionByID(ctx context.Context, id uint64) (models.Position, error) {
 	db := u.db.GetConnection()
 	var position models.Position
 
 	if err := db.WithContext(ctx).Where("id = ?", id).First(&position).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return models.Position{}, errors.New("position not found")
+			return models.Position{}, apperror.Validation("position_not_found", "position not found")
 		}
 		return models.Position{}, err
 	}
@@ -104,7 +226,10 @@ func (u *userQueryImpl) GetUserByEmail(ctx context.Context, email string) (model
 	db := u.db.GetConnection()
 
 	user := models.User{}
-	if err := db.WithContext(ctx).Where("email = ?", email).
+	if err := db.WithContext(ctx).
+		Preload("Role").
+		Preload("Position").
+		Where("email = ?", email).
 		Where("deleted_at is NULL").
 		First(&user).
 		Error; err != nil {
@@ -118,7 +243,12 @@ func (u *userQueryImpl) GetUserByEmail(ctx context.Context, email string) (model
 
 func (u *userQueryImpl) CreateUser(ctx context.Context, user models.User) (models.User, error) {
 	db := u.db.GetConnection()
-	if err := db.WithContext(ctx).Create(&user).Error; err != nil {
+	if err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockRoleAndPosition(tx, user.RoleID, user.PositionID); err != nil {
+			return err
+		}
+		return tx.Create(&user).Error
+	}); err != nil {
 		return models.User{}, err
 	}
 
@@ -132,6 +262,9 @@ func (u *userQueryImpl) CreateUser(ctx context.Context, user models.User) (model
 	return user, nil
 }
 
+// UpdateUser overwrites the user's details and bumps their version. When
+// user.Version is set, the update only happens if it is still the current
+// version, and ErrUserVersionMismatch is returned otherwise.
 func (u *userQueryImpl) UpdateUser(ctx context.Context, id uint64, user models.User) (models.User, error) {
 	db := u.db.GetConnection()
 
@@ -141,27 +274,46 @@ func (u *userQueryImpl) UpdateUser(ctx context.Context, id uint64, user models.U
 	if err := tx.WithContext(ctx).Where("id = ? AND deleted_at IS NULL", id).First(&existingUser).Error; err != nil {
 		tx.Rollback()
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return models.User{}, errors.New("user not found or already deleted")
+			return models.User{}, apperror.NotFound("user_not_found_or_already_deleted", "user not found or already deleted")
 		}
 		return models.User{}, err
 	}
+	if err := lockRoleAndPosition(tx.WithContext(ctx), user.RoleID, user.PositionID); err != nil {
+		tx.Rollback()
+		return models.User{}, err
+	}
 
-	existingUser.Firstname = user.Firstname
-	existingUser.Lastname = user.Lastname
-	existingUser.Email = user.Email
-	existingUser.Password = user.Password
-	existingUser.RoleID = user.RoleID
-	existingUser.PositionID = user.PositionID
+	version := existingUser.Version
+	if user.Version != 0 {
+		version = user.Version
+	}
 
-	if err := tx.WithContext(ctx).Save(&existingUser).Error; err != nil {
+	result := tx.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ? AND version = ?", id, version).
+		Updates(map[string]interface{}{
+			"firstname":   user.Firstname,
+			"lastname":    user.Lastname,
+			"email":       user.Email,
+			"password":    user.Password,
+			"role_id":     user.RoleID,
+			"position_id": user.PositionID,
+			"hire_date":   user.HireDate,
+			"version":     gorm.Expr("version + 1"),
+		})
+	if err := result.Error; err != nil {
 		tx.Rollback()
 
 		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
-			return models.User{}, errors.New("email already exists")
+			return models.User{}, apperror.Conflict("email_already_exists", "email already exists")
 		}
 
 		return models.User{}, err
 	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return models.User{}, ErrUserVersionMismatch
+	}
 
 	tx.Commit()
 
@@ -175,47 +327,303 @@ func (u *userQueryImpl) UpdateUser(ctx context.Context, id uint64, user models.U
 	return existingUser, nil
 }
 
-func (u *userQueryImpl) DeleteUser(ctx context.Context, id uint64) error {
+// DeleteUser soft deletes the user. Their direct reports move up to the
+// deleted user's own manager so nobody is left reporting to a deleted user,
+// and their IDs are returned.
+func (u *userQueryImpl) DeleteUser(ctx context.Context, id uint64) ([]uint64, error) {
+	db := u.db.GetConnection()
+	reportIDs := []uint64{}
+
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Raw(
+			"UPDATE users SET manager_id = (SELECT manager_id FROM users WHERE id = ?), version = version + 1 WHERE manager_id = ? RETURNING id",
+			id, id,
+		).Scan(&reportIDs).Error; err != nil {
+			return err
+		}
+
+		return tx.
+			Model(&models.User{}).
+			Where("id = ?", id).
+			Update("deleted_at", time.Now()).
+			Error
+	})
+	if err != nil {
+		return []uint64{}, err
+	}
+	return reportIDs, nil
+}
+
+// GetDeletedUsers returns a page of soft deleted users, the most recently
+// deleted first, together with the number of deleted users.
+func (u *userQueryImpl) GetDeletedUsers(ctx context.Context, filter models.DeletedUserFilter) ([]models.User, int64, error) {
+	db := u.db.GetConnection()
+
+	query := db.WithContext(ctx).
+		Unscoped().
+		Model(&models.User{}).
+		Where("deleted_at IS NOT NULL")
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return []models.User{}, 0, err
+	}
+
+	users := []models.User{}
+	if err := query.
+		Preload("Role").
+		Preload("Position").
+		Order("deleted_at DESC, id DESC").
+		Offset((filter.Page - 1) * filter.Limit).
+		Limit(filter.Limit).
+		Find(&users).Error; err != nil {
+		return []models.User{}, 0, err
+	}
+	return users, total, nil
+}
+
+func (u *userQueryImpl) GetDeletedUserByID(ctx context.Context, id uint64) (models.User, error) {
 	db := u.db.GetConnection()
 
+	user := models.User{}
 	if err := db.WithContext(ctx).
+		Unscoped().
+		Preload("Role").
+		Preload("Position").
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.User{}, nil
+		}
+		return models.User{}, err
+	}
+	return user, nil
+}
+
+// RestoreUser undoes the soft delete of a user. The user comes back without
+// the reports that were moved to their manager on delete.
+func (u *userQueryImpl) RestoreUser(ctx context.Context, id uint64) error {
+	db := u.db.GetConnection()
+
+	err := db.WithContext(ctx).
+		Unscoped().
 		Model(&models.User{}).
-		Where("id = ?", id).
-		Update("deleted_at", time.Now()).
-		Error; err != nil {
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Updates(map[string]interface{}{
+			"deleted_at": nil,
+			"version":    gorm.Expr("version + 1"),
+		}).Error
+	if err != nil && strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+		return ErrUserEmailTaken
+	}
+	return err
+}
+
+// PurgeUser deletes a soft deleted user for good, together with the records
+// that belong to them.
+func (u *userQueryImpl) PurgeUser(ctx context.Context, id uint64) error {
+	db := u.db.GetConnection()
+
+	err := db.WithContext(ctx).
+		Exec("DELETE FROM users WHERE id = ? AND deleted_at IS NOT NULL", id).
+		Error
+	if err != nil && strings.Contains(err.Error(), "violates foreign key constraint") {
+		return ErrUserHasPayslips
+	}
+	return err
+}
+
+// PurgeDeletedUsers deletes every user soft deleted before deletedBefore
+// for good, except those with payslips, and returns their IDs.
+func (u *userQueryImpl) PurgeDeletedUsers(ctx context.Context, deletedBefore time.Time) ([]uint64, error) {
+	db := u.db.GetConnection()
+
+	ids := []uint64{}
+	if err := db.WithContext(ctx).Raw(`
+		DELETE FROM users
+		WHERE deleted_at IS NOT NULL AND deleted_at < ?
+			AND NOT EXISTS (SELECT 1 FROM payslips WHERE payslips.user_id = users.id)
+		RETURNING id`,
+		deletedBefore,
+	).Scan(&ids).Error; err != nil {
+		return []uint64{}, err
+	}
+	return ids, nil
+}
+
+func (u *userQueryImpl) UpdateUserDepartment(ctx context.Context, id uint64, departmentID *int) error {
+	db := u.db.GetConnection()
+
+	return db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ? AND deleted_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"department_id": departmentID,
+			"version":       gorm.Expr("version + 1"),
+		}).
+		Error
+}
+
+// UpdateUserManager sets the user's manager, refusing with ErrReportingCycle
+// when the new manager is the user or one of their direct or indirect
+// reports.
+func (u *userQueryImpl) UpdateUserManager(ctx context.Context, id uint64, managerID *int) error {
+	db := u.db.GetConnection()
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", reportingLineLockKey).Error; err != nil {
+			return err
+		}
+
+		if managerID != nil {
+			var cycles int64
+			if err := tx.Raw(`
+				WITH RECURSIVE chain AS (
+					SELECT id, manager_id, 1 AS depth FROM users WHERE id = ?
+					UNION ALL
+					SELECT u.id, u.manager_id, c.depth + 1
+					FROM users u
+					JOIN chain c ON u.id = c.manager_id
+					WHERE c.depth < ?
+				)
+				SELECT COUNT(*) FROM chain WHERE id = ?`,
+				*managerID, maxReportingDepth, id,
+			).Scan(&cycles).Error; err != nil {
+				return err
+			}
+			if cycles > 0 {
+				return ErrReportingCycle
+			}
+		}
+
+		return tx.
+			Model(&models.User{}).
+			Where("id = ? AND deleted_at IS NULL", id).
+			Updates(map[string]interface{}{
+				"manager_id": managerID,
+				"version":    gorm.Expr("version + 1"),
+			}).
+			Error
+	})
+}
+
+// lockRoleAndPosition share-locks a user's role and position for the rest of
+// the transaction, so DeleteRole and DeletePosition wait for the user to be
+// written and then count them, or finish first and the role or position is
+// found to be gone here.
+func lockRoleAndPosition(tx *gorm.DB, roleID int, positionID int) error {
+	var ids []int
+	if err := tx.Raw("SELECT id FROM roles WHERE id = ? AND deleted_at IS NULL FOR SHARE", roleID).Scan(&ids).Error; err != nil {
 		return err
 	}
+	if len(ids) == 0 {
+		return apperror.Validation("role_not_found", "role not found")
+	}
 
+	ids = nil
+	if err := tx.Raw("SELECT id FROM positions WHERE id = ? AND deleted_at IS NULL FOR SHARE", positionID).Scan(&ids).Error; err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return apperror.Validation("position_not_found", "position not found")
+	}
 	return nil
 }
 
-func (u *userQueryImpl) IsTokenBlacklisted(ctx context.Context, token string) (bool, error) {
+// maxReportingDepth bounds the recursive queries in case a cycle was ever
+// written to the table by hand.
+const maxReportingDepth = 100
+
+func (u *userQueryImpl) GetReports(ctx context.Context, managerID uint64, recursive bool) ([]models.Report, error) {
 	db := u.db.GetConnection()
-	var blacklisted models.BlackListedToken
 
-	err := db.WithContext(ctx).Where("token = ?", token).First(&blacklisted).Error
-	if err == nil {
-		return true, errors.New("token already blacklisted")
-	} else if errors.
-		Is(err, gorm.ErrRecordNotFound) {
-		return false, nil
+	depthLimit := maxReportingDepth
+	if !recursive {
+		depthLimit = 1
+	}
+
+	type reportDepth struct {
+		ID    int
+		Depth int
+	}
+	var depths []reportDepth
+	if err := db.WithContext(ctx).Raw(`
+		WITH RECURSIVE reports AS (
+			SELECT id, 1 AS depth FROM users WHERE manager_id = ? AND deleted_at IS NULL
+			UNION ALL
+			SELECT u.id, r.depth + 1
+			FROM users u
+			JOIN reports r ON u.manager_id = r.id
+			WHERE u.deleted_at IS NULL AND r.depth < ?
+		)
+		SELECT id, MIN(depth) AS depth FROM reports GROUP BY id`,
+		managerID, depthLimit,
+	).Scan(&depths).Error; err != nil {
+		return []models.Report{}, err
 	}
-	return false, err
+	if len(depths) == 0 {
+		return []models.Report{}, nil
+	}
+
+	ids := make([]int, 0, len(depths))
+	depthByID := make(map[int]int, len(depths))
+	for _, d := range depths {
+		ids = append(ids, d.ID)
+		depthByID[d.ID] = d.Depth
+	}
+
+	users := []models.User{}
+	if err := db.WithContext(ctx).
+		Table("users").
+		Preload("Role").
+		Preload("Position").
+		Preload("Department").
+		Where("id IN ?", ids).
+		Order("lastname, firstname, id").
+		Find(&users).Error; err != nil {
+		return []models.Report{}, err
+	}
+
+	reports := make([]models.Report, 0, len(users))
+	for _, user := range users {
+		reports = append(reports, models.Report{User: user, Depth: depthByID[user.Id]})
+	}
+	return reports, nil
 }
 
-func (u *userQueryImpl) AddTokenToBlacklist(ctx context.Context, token string) error {
+func (u *userQueryImpl) GetOrgChartNodes(ctx context.Context) ([]models.OrgChartNode, error) {
 	db := u.db.GetConnection()
+	nodes := []models.OrgChartNode{}
 
-	blacklistedToken := models.BlackListedToken{
-		Token:     token,
-		CreatedAt: time.Now(),
-		ExpiredAt: time.Now().Add(time.Hour),
+	if err := db.WithContext(ctx).
+		Table("users").
+		Select(`users.id, users.firstname, users.lastname, users.email, users.manager_id,
+			COALESCE(positions.name, '') AS position, COALESCE(departments.name, '') AS department`).
+		Joins("LEFT JOIN positions ON positions.id = users.position_id").
+		Joins("LEFT JOIN departments ON departments.id = users.department_id").
+		Where("users.deleted_at IS NULL").
+		Order("users.lastname, users.firstname, users.id").
+		Scan(&nodes).Error; err != nil {
+		return []models.OrgChartNode{}, err
 	}
-
-	return db.WithContext(ctx).Create(&blacklistedToken).Error
+	return nodes, nil
 }
 
-func (u *userQueryImpl) RemoveExpiredTokens(ctx context.Context) error {
+func (u *userQueryImpl) IsManagerOf(ctx context.Context, managerID uint64, userID uint64) (bool, error) {
 	db := u.db.GetConnection()
-	return db.WithContext(ctx).Where("expired_at < ?", time.Now()).Delete(&models.BlackListedToken{}).Error
+
+	var count int64
+	err := db.WithContext(ctx).Raw(`
+		WITH RECURSIVE chain AS (
+			SELECT manager_id, 1 AS depth FROM users WHERE id = ?
+			UNION ALL
+			SELECT u.manager_id, c.depth + 1
+			FROM users u
+			JOIN chain c ON u.id = c.manager_id
+			WHERE u.deleted_at IS NULL AND c.depth < ?
+		)
+		SELECT COUNT(*) FROM chain WHERE manager_id = ?`,
+		userID, maxReportingDepth, managerID,
+	).Scan(&count).Error
+	return count > 0, err
 }
@@ -0,0 +1,25 @@
+// Package requestinfo carries facts about the HTTP request behind a context,
+// so services can record where a change came from.
+package requestinfo
+
+import "context"
+
+// Info identifies a request. ID is the X-Request-ID the response carries and
+// ClientIP the address of the client.
+type Info struct {
+	ID       string
+	ClientIP string
+}
+
+type infoContextKey struct{}
+
+func WithInfo(ctx context.Context, info Info) context.Context {
+	return context.WithValue(ctx, infoContextKey{}, info)
+}
+
+// FromContext returns the request info of ctx, which is empty outside of a
+// request, e.g. in background jobs.
+func FromContext(ctx context.Context) Info {
+	info, _ := ctx.Value(infoContextKey{}).(Info)
+	return info
+}
@@ -0,0 +1,34 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"main.go/internal/auth"
+	"main.go/internal/handlers"
+	"main.go/internal/middleware"
+	"main.go/internal/repository"
+)
+
+type AttendanceRouter interface {
+	Mount()
+}
+
+type attendanceRouterImpl struct {
+	v           *gin.RouterGroup
+	handler     handlers.AttendanceHandler
+	users       middleware.PrincipalLoader
+	revocations repository.RevocationStore
+	managers    middleware.ManagerChecker
+}
+
+func NewAttendanceRouter(v *gin.RouterGroup, handler handlers.AttendanceHandler, users middleware.PrincipalLoader, revocations repository.RevocationStore, managers middleware.ManagerChecker) AttendanceRouter {
+	return &attendanceRouterImpl{v: v, handler: handler, users: users, revocations: revocations, managers: managers}
+}
+
+func (a *attendanceRouterImpl) Mount() {
+	a.v.Use(middleware.AuthMiddleware(a.users, a.revocations))
+	a.v.POST("/check-in", a.handler.CheckIn)
+	a.v.POST("/check-out", a.handler.CheckOut)
+	a.v.GET("/me", a.handler.GetMyAttendances)
+	a.v.GET("/users/:id", middleware.RequirePermissionSelfOrManager(auth.PermissionAttendanceRead, a.managers), a.handler.GetUserAttendances)
+	a.v.GET("/", middleware.RequirePermission(auth.PermissionAttendanceRead), a.handler.GetAttendances)
+}
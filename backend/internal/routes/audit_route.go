@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"main.go/internal/auth"
+	"main.go/internal/handlers"
+	"main.go/internal/middleware"
+	"main.go/internal/repository"
+)
+
+type AuditRouter interface {
+	Mount()
+}
+
+type auditRouterImpl struct {
+	v           *gin.RouterGroup
+	handler     handlers.AuditHandler
+	users       middleware.PrincipalLoader
+	revocations repository.RevocationStore
+}
+
+func NewAuditRouter(v *gin.RouterGroup, handler handlers.AuditHandler, users middleware.PrincipalLoader, revocations repository.RevocationStore) AuditRouter {
+	return &auditRouterImpl{v: v, handler: handler, users: users, revocations: revocations}
+}
+
+func (a *auditRouterImpl) Mount() {
+	a.v.Use(middleware.AuthMiddleware(a.users, a.revocations))
+
+	a.v.GET("/", middleware.RequirePermission(auth.PermissionAuditRead), a.handler.GetAuditLogs)
+	a.v.GET("/verify", middleware.RequirePermission(auth.PermissionAuditRead), a.handler.VerifyAuditLogs)
+}
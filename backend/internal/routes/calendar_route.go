@@ -0,0 +1,43 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"main.go/internal/auth"
+	"main.go/internal/handlers"
+	"main.go/internal/middleware"
+	"main.go/internal/repository"
+)
+
+type CalendarRouter interface {
+	Mount()
+}
+
+type calendarRouterImpl struct {
+	v           *gin.RouterGroup
+	handler     handlers.CalendarHandler
+	users       middleware.PrincipalLoader
+	revocations repository.RevocationStore
+	managers    middleware.ManagerChecker
+}
+
+func NewCalendarRouter(v *gin.RouterGroup, handler handlers.CalendarHandler, users middleware.PrincipalLoader, revocations repository.RevocationStore, managers middleware.ManagerChecker) CalendarRouter {
+	return &calendarRouterImpl{v: v, handler: handler, users: users, revocations: revocations, managers: managers}
+}
+
+func (c *calendarRouterImpl) Mount() {
+	c.v.Use(middleware.AuthMiddleware(c.users, c.revocations))
+	c.v.GET("/", c.handler.GetCalendars)
+	c.v.GET("/:id", c.handler.GetCalendarByID)
+	c.v.POST("/", middleware.RequirePermission(auth.PermissionCalendarsWrite), c.handler.CreateCalendar)
+	c.v.PUT("/:id", middleware.RequirePermission(auth.PermissionCalendarsWrite), c.handler.UpdateCalendar)
+	c.v.DELETE("/:id", middleware.RequirePermission(auth.PermissionCalendarsWrite), c.handler.DeleteCalendar)
+
+	c.v.GET("/:id/holidays", c.handler.GetHolidays)
+	c.v.POST("/:id/holidays", middleware.RequirePermission(auth.PermissionCalendarsWrite), c.handler.CreateHoliday)
+	c.v.PUT("/:id/holidays/:holiday_id", middleware.RequirePermission(auth.PermissionCalendarsWrite), c.handler.UpdateHoliday)
+	c.v.DELETE("/:id/holidays/:holiday_id", middleware.RequirePermission(auth.PermissionCalendarsWrite), c.handler.DeleteHoliday)
+	c.v.POST("/:id/import", middleware.RequirePermission(auth.PermissionCalendarsWrite), c.handler.ImportHolidays)
+
+	c.v.GET("/working-days/me", c.handler.GetMyWorkingDays)
+	c.v.GET("/working-days/users/:id", middleware.RequirePermissionSelfOrManager(auth.PermissionUsersRead, c.managers), c.handler.GetUserWorkingDays)
+}
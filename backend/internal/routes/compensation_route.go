@@ -0,0 +1,40 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"main.go/internal/auth"
+	"main.go/internal/handlers"
+	"main.go/internal/middleware"
+	"main.go/internal/repository"
+)
+
+type CompensationRouter interface {
+	Mount()
+}
+
+type compensationRouterImpl struct {
+	v           *gin.RouterGroup
+	handler     handlers.CompensationHandler
+	users       middleware.PrincipalLoader
+	revocations repository.RevocationStore
+}
+
+func NewCompensationRouter(v *gin.RouterGroup, handler handlers.CompensationHandler, users middleware.PrincipalLoader, revocations repository.RevocationStore) CompensationRouter {
+	return &compensationRouterImpl{v: v, handler: handler, users: users, revocations: revocations}
+}
+
+func (c *compensationRouterImpl) Mount() {
+	c.v.Use(middleware.AuthMiddleware(c.users, c.revocations))
+
+	// Pay is confidential, so unlike most other data employees can't read
+	// their own and managers can't read their reports'.
+	c.v.GET("/", middleware.RequirePermission(auth.PermissionCompensationRead), c.handler.GetCurrentCompensations)
+	c.v.GET("/:id", middleware.RequirePermission(auth.PermissionCompensationRead), c.handler.GetCompensationByID)
+	c.v.PUT("/:id", middleware.RequirePermission(auth.PermissionCompensationWrite), c.handler.UpdateCompensation)
+	c.v.DELETE("/:id", middleware.RequirePermission(auth.PermissionCompensationWrite), c.handler.DeleteCompensation)
+
+	c.v.GET("/users/:id", middleware.RequirePermission(auth.PermissionCompensationRead), c.handler.GetUserCompensationHistory)
+	c.v.GET("/users/:id/current", middleware.RequirePermission(auth.PermissionCompensationRead), c.handler.GetUserCurrentCompensation)
+	c.v.GET("/users/:id/scheduled", middleware.RequirePermission(auth.PermissionCompensationRead), c.handler.GetUserScheduledCompensations)
+	c.v.POST("/users/:id", middleware.RequirePermission(auth.PermissionCompensationWrite), c.handler.CreateUserCompensation)
+}
@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"main.go/internal/auth"
+	"main.go/internal/handlers"
+	"main.go/internal/middleware"
+	"main.go/internal/repository"
+)
+
+type DepartmentRouter interface {
+	Mount()
+}
+
+type departmentRouterImpl struct {
+	v           *gin.RouterGroup
+	handler     handlers.DepartmentHandler
+	users       middleware.PrincipalLoader
+	revocations repository.RevocationStore
+}
+
+func NewDepartmentRouter(v *gin.RouterGroup, handler handlers.DepartmentHandler, users middleware.PrincipalLoader, revocations repository.RevocationStore) DepartmentRouter {
+	return &departmentRouterImpl{v: v, handler: handler, users: users, revocations: revocations}
+}
+
+func (d *departmentRouterImpl) Mount() {
+	d.v.Use(middleware.AuthMiddleware(d.users, d.revocations))
+	d.v.GET("/", middleware.RequirePermission(auth.PermissionDepartmentsRead), d.handler.GetDepartments)
+	d.v.GET("/:id", middleware.RequirePermission(auth.PermissionDepartmentsRead), d.handler.GetDepartmentByID)
+	d.v.POST("/", middleware.RequirePermission(auth.PermissionDepartmentsWrite), d.handler.CreateDepartment)
+	d.v.PUT("/:id", middleware.RequirePermission(auth.PermissionDepartmentsWrite), d.handler.UpdateDepartment)
+	d.v.DELETE("/:id", middleware.RequirePermission(auth.PermissionDepartmentsWrite), d.handler.DeleteDepartment)
+}
@@ -0,0 +1,59 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"main.go/internal/auth"
+	"main.go/internal/handlers"
+	"main.go/internal/middleware"
+	"main.go/internal/repository"
+)
+
+type LeaveRouter interface {
+	Mount()
+}
+
+type leaveRouterImpl struct {
+	v           *gin.RouterGroup
+	handler     handlers.LeaveHandler
+	accruals    handlers.LeaveAccrualHandler
+	users       middleware.PrincipalLoader
+	revocations repository.RevocationStore
+	managers    middleware.ManagerChecker
+}
+
+func NewLeaveRouter(v *gin.RouterGroup, handler handlers.LeaveHandler, accruals handlers.LeaveAccrualHandler, users middleware.PrincipalLoader, revocations repository.RevocationStore, managers middleware.ManagerChecker) LeaveRouter {
+	return &leaveRouterImpl{v: v, handler: handler, accruals: accruals, users: users, revocations: revocations, managers: managers}
+}
+
+func (l *leaveRouterImpl) Mount() {
+	l.v.Use(middleware.AuthMiddleware(l.users, l.revocations))
+	l.v.GET("/types", l.handler.GetLeaveTypes)
+
+	l.v.GET("/balances/me", l.handler.GetMyLeaveBalances)
+	l.v.GET("/balances/users/:id", middleware.RequirePermissionSelfOrManager(auth.PermissionLeaveRead, l.managers), l.handler.GetUserLeaveBalances)
+	l.v.POST("/balances/users/:id/adjustments", middleware.RequirePermission(auth.PermissionLeaveWrite), l.handler.AdjustUserLeaveBalance)
+	l.v.GET("/ledger/me", l.handler.GetMyLeaveLedger)
+	l.v.GET("/ledger/users/:id", middleware.RequirePermissionSelfOrManager(auth.PermissionLeaveRead, l.managers), l.handler.GetUserLeaveLedger)
+
+	l.v.GET("/policies", middleware.RequirePermission(auth.PermissionLeaveRead), l.accruals.GetPolicies)
+	l.v.GET("/policies/:id", middleware.RequirePermission(auth.PermissionLeaveRead), l.accruals.GetPolicyByID)
+	l.v.POST("/policies", middleware.RequirePermission(auth.PermissionLeaveWrite), l.accruals.CreatePolicy)
+	l.v.PUT("/policies/:id", middleware.RequirePermission(auth.PermissionLeaveWrite), l.accruals.UpdatePolicy)
+	l.v.DELETE("/policies/:id", middleware.RequirePermission(auth.PermissionLeaveWrite), l.accruals.DeletePolicy)
+	l.v.GET("/positions/:id/policies", middleware.RequirePermission(auth.PermissionLeaveRead), l.accruals.GetPositionPolicies)
+	l.v.PUT("/positions/:id/policies", middleware.RequirePermission(auth.PermissionLeaveWrite), l.accruals.SetPositionPolicies)
+	l.v.POST("/accruals/run", middleware.RequirePermission(auth.PermissionLeaveWrite), l.accruals.RunAccruals)
+
+	// Access to single requests is checked by the service, the caller may be
+	// the owner, one of the owner's managers or an HR user.
+	l.v.GET("/requests/me", l.handler.GetMyLeaveRequests)
+	l.v.GET("/requests/team", l.handler.GetTeamLeaveRequests)
+	l.v.GET("/requests", middleware.RequirePermission(auth.PermissionLeaveRead), l.handler.GetLeaveRequests)
+	l.v.GET("/requests/:id", l.handler.GetLeaveRequestByID)
+	l.v.POST("/requests", l.handler.CreateLeaveRequest)
+	l.v.PUT("/requests/:id", l.handler.UpdateLeaveRequest)
+	l.v.POST("/requests/:id/submit", l.handler.SubmitLeaveRequest)
+	l.v.POST("/requests/:id/approve", l.handler.ApproveLeaveRequest)
+	l.v.POST("/requests/:id/reject", l.handler.RejectLeaveRequest)
+	l.v.POST("/requests/:id/cancel", l.handler.CancelLeaveRequest)
+}
@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"main.go/internal/auth"
+	"main.go/internal/handlers"
+	"main.go/internal/middleware"
+	"main.go/internal/repository"
+)
+
+type MonitoringRouter interface {
+	Mount()
+}
+
+type monitoringRouterImpl struct {
+	v           *gin.RouterGroup
+	handler     handlers.MonitoringHandler
+	users       middleware.PrincipalLoader
+	revocations repository.RevocationStore
+	managers    middleware.ManagerChecker
+}
+
+func NewMonitoringRouter(v *gin.RouterGroup, handler handlers.MonitoringHandler, users middleware.PrincipalLoader, revocations repository.RevocationStore, managers middleware.ManagerChecker) MonitoringRouter {
+	return &monitoringRouterImpl{v: v, handler: handler, users: users, revocations: revocations, managers: managers}
+}
+
+func (m *monitoringRouterImpl) Mount() {
+	m.v.Use(middleware.AuthMiddleware(m.users, m.revocations))
+	m.v.POST("/heartbeats", m.handler.RecordHeartbeats)
+	m.v.GET("/team/status", m.handler.GetTeamStatus)
+	m.v.GET("/team/summary", m.handler.GetTeamSummary)
+	m.v.GET("/users/:id/summary", middleware.RequirePermissionSelfOrManager(auth.PermissionMonitoringRead, m.managers), m.handler.GetUserSummary)
+}
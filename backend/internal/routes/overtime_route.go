@@ -0,0 +1,45 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"main.go/internal/auth"
+	"main.go/internal/handlers"
+	"main.go/internal/middleware"
+	"main.go/internal/repository"
+)
+
+type OvertimeRouter interface {
+	Mount()
+}
+
+type overtimeRouterImpl struct {
+	v           *gin.RouterGroup
+	handler     handlers.OvertimeHandler
+	users       middleware.PrincipalLoader
+	revocations repository.RevocationStore
+}
+
+func NewOvertimeRouter(v *gin.RouterGroup, handler handlers.OvertimeHandler, users middleware.PrincipalLoader, revocations repository.RevocationStore) OvertimeRouter {
+	return &overtimeRouterImpl{v: v, handler: handler, users: users, revocations: revocations}
+}
+
+func (o *overtimeRouterImpl) Mount() {
+	o.v.Use(middleware.AuthMiddleware(o.users, o.revocations))
+	o.v.GET("/rates", o.handler.GetRates)
+	o.v.PUT("/rates", middleware.RequirePermission(auth.PermissionOvertimeWrite), o.handler.UpdateRates)
+
+	// Access to single requests is checked by the service, the caller may be
+	// the owner, one of the owner's managers or an HR user.
+	o.v.GET("/requests/me", o.handler.GetMyOvertimeRequests)
+	o.v.GET("/requests/team", o.handler.GetTeamOvertimeRequests)
+	o.v.GET("/requests", middleware.RequirePermission(auth.PermissionOvertimeRead), o.handler.GetOvertimeRequests)
+	o.v.GET("/requests/:id", o.handler.GetOvertimeRequestByID)
+	o.v.POST("/requests", o.handler.CreateOvertimeRequest)
+	o.v.POST("/requests/:id/approve", o.handler.ApproveOvertimeRequest)
+	o.v.POST("/requests/:id/reject", o.handler.RejectOvertimeRequest)
+	o.v.POST("/requests/:id/cancel", o.handler.CancelOvertimeRequest)
+
+	o.v.GET("/summary/me", o.handler.GetMySummary)
+	o.v.GET("/summary", middleware.RequirePermission(auth.PermissionOvertimeRead), o.handler.GetSummary)
+	o.v.GET("/summary/export", middleware.RequirePermission(auth.PermissionOvertimeRead), o.handler.ExportSummary)
+}
@@ -0,0 +1,53 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"main.go/internal/auth"
+	"main.go/internal/handlers"
+	"main.go/internal/middleware"
+	"main.go/internal/repository"
+)
+
+type PayrollRouter interface {
+	Mount()
+}
+
+type payrollRouterImpl struct {
+	v           *gin.RouterGroup
+	handler     handlers.PayrollHandler
+	deductions  handlers.DeductionRuleHandler
+	users       middleware.PrincipalLoader
+	revocations repository.RevocationStore
+}
+
+func NewPayrollRouter(v *gin.RouterGroup, handler handlers.PayrollHandler, deductions handlers.DeductionRuleHandler, users middleware.PrincipalLoader, revocations repository.RevocationStore) PayrollRouter {
+	return &payrollRouterImpl{v: v, handler: handler, deductions: deductions, users: users, revocations: revocations}
+}
+
+func (p *payrollRouterImpl) Mount() {
+	p.v.Use(middleware.AuthMiddleware(p.users, p.revocations))
+
+	p.v.GET("/runs", middleware.RequirePermission(auth.PermissionPayrollRead), p.handler.GetPayrollRuns)
+	p.v.POST("/runs", middleware.RequirePermission(auth.PermissionPayrollWrite), p.handler.CreatePayrollRun)
+	p.v.GET("/runs/:id", middleware.RequirePermission(auth.PermissionPayrollRead), p.handler.GetPayrollRunByID)
+	p.v.DELETE("/runs/:id", middleware.RequirePermission(auth.PermissionPayrollWrite), p.handler.DeletePayrollRun)
+	p.v.POST("/runs/:id/calculate", middleware.RequirePermission(auth.PermissionPayrollWrite), p.handler.CalculatePayrollRun)
+	p.v.POST("/runs/:id/finalize", middleware.RequirePermission(auth.PermissionPayrollWrite), p.handler.FinalizePayrollRun)
+	p.v.GET("/runs/:id/payslips", middleware.RequirePermission(auth.PermissionPayrollRead), p.handler.GetRunPayslips)
+	p.v.GET("/runs/:id/bank-export", middleware.RequirePermission(auth.PermissionPayrollRead), p.handler.ExportBankTransfers)
+
+	// Employees get their own finalized payslips, the service checks that.
+	p.v.GET("/payslips/me", p.handler.GetMyPayslips)
+	p.v.GET("/payslips/:id", p.handler.GetPayslipByID)
+	p.v.GET("/payslips/:id/pdf", p.handler.GetPayslipPDF)
+
+	p.v.GET("/deduction-rules", middleware.RequirePermission(auth.PermissionPayrollRead), p.deductions.GetDeductionRuleSets)
+	p.v.POST("/deduction-rules", middleware.RequirePermission(auth.PermissionPayrollWrite), p.deductions.CreateDeductionRuleSet)
+	p.v.POST("/deduction-rules/dry-run", middleware.RequirePermission(auth.PermissionPayrollRead), p.deductions.DryRun)
+	p.v.GET("/deduction-rules/:id", middleware.RequirePermission(auth.PermissionPayrollRead), p.deductions.GetDeductionRuleSetByID)
+	p.v.PUT("/deduction-rules/:id", middleware.RequirePermission(auth.PermissionPayrollWrite), p.deductions.UpdateDeductionRuleSet)
+	p.v.DELETE("/deduction-rules/:id", middleware.RequirePermission(auth.PermissionPayrollWrite), p.deductions.DeleteDeductionRuleSet)
+
+	p.v.GET("/bank-accounts/users/:id", middleware.RequirePermission(auth.PermissionPayrollRead), p.handler.GetBankAccount)
+	p.v.PUT("/bank-accounts/users/:id", middleware.RequirePermission(auth.PermissionPayrollWrite), p.handler.SaveBankAccount)
+}
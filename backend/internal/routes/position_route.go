@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"main.go/internal/auth"
+	"main.go/internal/handlers"
+	"main.go/internal/middleware"
+	"main.go/internal/repository"
+)
+
+type PositionRouter interface {
+	Mount()
+}
+
+type positionRouterImpl struct {
+	v           *gin.RouterGroup
+	handler     handlers.PositionHandler
+	users       middleware.PrincipalLoader
+	revocations repository.RevocationStore
+}
+
+func NewPositionRouter(v *gin.RouterGroup, handler handlers.PositionHandler, users middleware.PrincipalLoader, revocations repository.RevocationStore) PositionRouter {
+	return &positionRouterImpl{v: v, handler: handler, users: users, revocations: revocations}
+}
+
+func (p *positionRouterImpl) Mount() {
+	p.v.Use(middleware.AuthMiddleware(p.users, p.revocations))
+	p.v.GET("/", middleware.RequirePermission(auth.PermissionPositionsRead), p.handler.GetPositions)
+	p.v.GET("/:id", middleware.RequirePermission(auth.PermissionPositionsRead), p.handler.GetPositionByID)
+	p.v.POST("/", middleware.RequirePermission(auth.PermissionPositionsWrite), p.handler.CreatePosition)
+	p.v.PUT("/:id", middleware.RequirePermission(auth.PermissionPositionsWrite), p.handler.UpdatePosition)
+	p.v.DELETE("/:id", middleware.RequirePermission(auth.PermissionPositionsWrite), p.handler.DeletePosition)
+}
@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"main.go/internal/auth"
+	"main.go/internal/handlers"
+	"main.go/internal/middleware"
+	"main.go/internal/repository"
+)
+
+type RoleRouter interface {
+	Mount()
+}
+
+type roleRouterImpl struct {
+	v           *gin.RouterGroup
+	handler     handlers.RoleHandler
+	users       middleware.PrincipalLoader
+	revocations repository.RevocationStore
+}
+
+func NewRoleRouter(v *gin.RouterGroup, handler handlers.RoleHandler, users middleware.PrincipalLoader, revocations repository.RevocationStore) RoleRouter {
+	return &roleRouterImpl{v: v, handler: handler, users: users, revocations: revocations}
+}
+
+func (r *roleRouterImpl) Mount() {
+	r.v.Use(middleware.AuthMiddleware(r.users, r.revocations))
+	r.v.GET("/", middleware.RequirePermission(auth.PermissionRolesRead), r.handler.GetRoles)
+	r.v.GET("/:id", middleware.RequirePermission(auth.PermissionRolesRead), r.handler.GetRoleByID)
+	r.v.POST("/", middleware.RequirePermission(auth.PermissionRolesWrite), r.handler.CreateRole)
+	r.v.PUT("/:id", middleware.RequirePermission(auth.PermissionRolesWrite), r.handler.UpdateRole)
+	r.v.DELETE("/:id", middleware.RequirePermission(auth.PermissionRolesWrite), r.handler.DeleteRole)
+}
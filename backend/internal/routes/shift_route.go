@@ -0,0 +1,44 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"main.go/internal/auth"
+	"main.go/internal/handlers"
+	"main.go/internal/middleware"
+	"main.go/internal/repository"
+)
+
+type ShiftRouter interface {
+	Mount()
+}
+
+type shiftRouterImpl struct {
+	v           *gin.RouterGroup
+	handler     handlers.ShiftHandler
+	users       middleware.PrincipalLoader
+	revocations repository.RevocationStore
+	managers    middleware.ManagerChecker
+}
+
+func NewShiftRouter(v *gin.RouterGroup, handler handlers.ShiftHandler, users middleware.PrincipalLoader, revocations repository.RevocationStore, managers middleware.ManagerChecker) ShiftRouter {
+	return &shiftRouterImpl{v: v, handler: handler, users: users, revocations: revocations, managers: managers}
+}
+
+func (s *shiftRouterImpl) Mount() {
+	s.v.Use(middleware.AuthMiddleware(s.users, s.revocations))
+	s.v.GET("/", middleware.RequirePermission(auth.PermissionShiftsRead), s.handler.GetShifts)
+	s.v.GET("/:id", middleware.RequirePermission(auth.PermissionShiftsRead), s.handler.GetShiftByID)
+	s.v.POST("/", middleware.RequirePermission(auth.PermissionShiftsWrite), s.handler.CreateShift)
+	s.v.PUT("/:id", middleware.RequirePermission(auth.PermissionShiftsWrite), s.handler.UpdateShift)
+	s.v.DELETE("/:id", middleware.RequirePermission(auth.PermissionShiftsWrite), s.handler.DeleteShift)
+
+	s.v.GET("/assignments/me", s.handler.GetMyAssignments)
+	s.v.GET("/assignments/users/:id", middleware.RequirePermissionSelfOrManager(auth.PermissionShiftsRead, s.managers), s.handler.GetUserAssignments)
+	s.v.GET("/assignments", middleware.RequirePermission(auth.PermissionShiftsRead), s.handler.GetAssignments)
+	s.v.POST("/assignments", middleware.RequirePermission(auth.PermissionShiftsWrite), s.handler.CreateAssignment)
+	s.v.PUT("/assignments/:id", middleware.RequirePermission(auth.PermissionShiftsWrite), s.handler.UpdateAssignment)
+	s.v.DELETE("/assignments/:id", middleware.RequirePermission(auth.PermissionShiftsWrite), s.handler.DeleteAssignment)
+
+	s.v.GET("/roster", middleware.RequirePermission(auth.PermissionShiftsRead), s.handler.GetRoster)
+	s.v.GET("/conflicts", middleware.RequirePermission(auth.PermissionShiftsRead), s.handler.GetConflicts)
+}
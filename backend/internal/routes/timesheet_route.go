@@ -0,0 +1,50 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"main.go/internal/auth"
+	"main.go/internal/handlers"
+	"main.go/internal/middleware"
+	"main.go/internal/repository"
+)
+
+type TimesheetRouter interface {
+	Mount()
+}
+
+type timesheetRouterImpl struct {
+	v           *gin.RouterGroup
+	handler     handlers.TimesheetHandler
+	periods     handlers.PayPeriodHandler
+	users       middleware.PrincipalLoader
+	revocations repository.RevocationStore
+}
+
+func NewTimesheetRouter(v *gin.RouterGroup, handler handlers.TimesheetHandler, periods handlers.PayPeriodHandler, users middleware.PrincipalLoader, revocations repository.RevocationStore) TimesheetRouter {
+	return &timesheetRouterImpl{v: v, handler: handler, periods: periods, users: users, revocations: revocations}
+}
+
+func (t *timesheetRouterImpl) Mount() {
+	t.v.Use(middleware.AuthMiddleware(t.users, t.revocations))
+
+	// Everyone can see which pay periods are closed, only HR closes and
+	// reopens them.
+	t.v.GET("/periods", t.periods.GetPayPeriods)
+	t.v.GET("/periods/:id", t.periods.GetPayPeriodByID)
+	t.v.GET("/periods/:id/events", middleware.RequirePermission(auth.PermissionTimesheetsRead), t.periods.GetPayPeriodEvents)
+	t.v.POST("/periods", middleware.RequirePermission(auth.PermissionTimesheetsLock), t.periods.CreatePayPeriod)
+	t.v.POST("/periods/:id/close", middleware.RequirePermission(auth.PermissionTimesheetsLock), t.periods.ClosePayPeriod)
+	t.v.POST("/periods/:id/reopen", middleware.RequirePermission(auth.PermissionTimesheetsLock), t.periods.ReopenPayPeriod)
+
+	// Access to single timesheets is checked by the service, the caller may
+	// be the owner, one of the owner's managers or an HR user.
+	t.v.GET("/me", t.handler.GetMyTimesheets)
+	t.v.GET("/team", t.handler.GetTeamTimesheets)
+	t.v.GET("/", middleware.RequirePermission(auth.PermissionTimesheetsRead), t.handler.GetTimesheets)
+	t.v.GET("/:id", t.handler.GetTimesheetByID)
+	t.v.POST("/", t.handler.CreateTimesheet)
+	t.v.PUT("/:id/entries", t.handler.UpdateEntries)
+	t.v.POST("/:id/submit", t.handler.SubmitTimesheet)
+	t.v.POST("/:id/approve", t.handler.ApproveTimesheet)
+	t.v.POST("/:id/reject", t.handler.RejectTimesheet)
+}
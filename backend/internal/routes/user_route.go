@@ -2,9 +2,10 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
-	"main.go/config"
+	"main.go/internal/auth"
 	"main.go/internal/handlers"
 	"main.go/internal/middleware"
+	"main.go/internal/repository"
 )
 
 type UserRouter interface {
@@ -12,23 +13,34 @@ type UserRouter interface {
 }
 
 type userRouterImpl struct {
-	v       *gin.RouterGroup
-	handler handlers.UserHandler
-	db      config.GormPostgres
+	v           *gin.RouterGroup
+	handler     handlers.UserHandler
+	users       middleware.PrincipalLoader
+	revocations repository.RevocationStore
+	managers    middleware.ManagerChecker
 }
 
-func NewUserRouter(v *gin.RouterGroup, handler handlers.UserHandler, db config.GormPostgres) UserRouter {
-	return &userRouterImpl{v: v, handler: handler, db: db}
+func NewUserRouter(v *gin.RouterGroup, handler handlers.UserHandler, users middleware.PrincipalLoader, revocations repository.RevocationStore, managers middleware.ManagerChecker) UserRouter {
+	return &userRouterImpl{v: v, handler: handler, users: users, revocations: revocations, managers: managers}
 }
 
 func (u *userRouterImpl) Mount() {
 	u.v.POST("/login", u.handler.LoginUser)
 	u.v.POST("/logout", u.handler.LogoutUser)
+	u.v.POST("/token/refresh", u.handler.RefreshToken)
 
-	u.v.Use(middleware.AuthMiddleware(u.db.GetConnection()))
-	u.v.GET("/", u.handler.GetUsers)
-	u.v.GET("/:id", u.handler.GetUserByID)
-	u.v.POST("/", u.handler.CreateUser)
-	u.v.PUT("/:id", u.handler.UpdateUser)
-	u.v.DELETE("/:id", u.handler.DeleteUser)
+	u.v.Use(middleware.AuthMiddleware(u.users, u.revocations))
+	u.v.GET("/", middleware.RequirePermission(auth.PermissionUsersRead), u.handler.GetUsers)
+	u.v.GET("/org-chart", middleware.RequirePermission(auth.PermissionUsersRead), u.handler.GetOrgChart)
+	u.v.GET("/deleted", middleware.RequirePermission(auth.PermissionUsersDelete), u.handler.GetDeletedUsers)
+	u.v.POST("/deleted/:id/restore", middleware.RequirePermission(auth.PermissionUsersDelete), u.handler.RestoreUser)
+	u.v.DELETE("/deleted/:id", middleware.RequirePermission(auth.PermissionUsersPurge), u.handler.PurgeUser)
+	u.v.GET("/:id", middleware.RequirePermissionSelfOrManager(auth.PermissionUsersRead, u.managers), u.handler.GetUserByID)
+	u.v.GET("/:id/reports", middleware.RequirePermissionSelfOrManager(auth.PermissionUsersRead, u.managers), u.handler.GetReports)
+	u.v.POST("/", middleware.RequirePermission(auth.PermissionUsersWrite), u.handler.CreateUser)
+	u.v.PUT("/:id", middleware.RequirePermissionOrSelf(auth.PermissionUsersWrite), u.handler.UpdateUser)
+	u.v.PATCH("/:id", middleware.RequirePermissionOrSelf(auth.PermissionUsersWrite), u.handler.PatchUser)
+	u.v.DELETE("/:id", middleware.RequirePermission(auth.PermissionUsersDelete), u.handler.DeleteUser)
+	u.v.PUT("/:id/department", middleware.RequirePermission(auth.PermissionUsersWrite), u.handler.MoveUserToDepartment)
+	u.v.PUT("/:id/manager", middleware.RequirePermission(auth.PermissionUsersWrite), u.handler.SetUserManager)
 }
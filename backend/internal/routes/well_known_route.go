@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"main.go/internal/handlers"
+)
+
+type WellKnownRouter interface {
+	Mount()
+}
+
+type wellKnownRouterImpl struct {
+	v       *gin.RouterGroup
+	handler handlers.JWKSHandler
+}
+
+func NewWellKnownRouter(v *gin.RouterGroup, handler handlers.JWKSHandler) WellKnownRouter {
+	return &wellKnownRouterImpl{v: v, handler: handler}
+}
+
+func (w *wellKnownRouterImpl) Mount() {
+	w.v.GET("/jwks.json", w.handler.GetJWKS)
+}
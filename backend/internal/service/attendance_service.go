@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"main.go/config"
+	"main.go/internal/apperror"
+	"main.go/internal/models"
+	"main.go/internal/repository"
+)
+
+// maxShiftLength bounds how long after checking in a user can still check
+// out of the same attendance record.
+const maxShiftLength = 24 * time.Hour
+
+type AttendanceService interface {
+	GetAttendances(ctx context.Context, filter models.AttendanceFilter) ([]models.Attendance, error)
+
+	CheckIn(ctx context.Context, userID uint64) (models.Attendance, error)
+	CheckOut(ctx context.Context, userID uint64) (models.Attendance, error)
+}
+
+type attendanceServiceImpl struct {
+	repo     repository.AttendanceQuery
+	calendar WorkingDayCalculator
+	shifts   ShiftScheduler
+	overtime OvertimeLinker
+	locker   PeriodLocker
+	schedule config.WorkSchedule
+	audit    AuditLogger
+}
+
+func NewAttendanceService(repo repository.AttendanceQuery, calendar WorkingDayCalculator, shifts ShiftScheduler, overtime OvertimeLinker, locker PeriodLocker, schedule config.WorkSchedule, audit AuditLogger) AttendanceService {
+	return &attendanceServiceImpl{repo: repo, calendar: calendar, shifts: shifts, overtime: overtime, locker: locker, schedule: schedule, audit: audit}
+}
+
+func (a *attendanceServiceImpl) GetAttendances(ctx context.Context, filter models.AttendanceFilter) ([]models.Attendance, error) {
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		return []models.Attendance{}, apperror.Validation("to_must_not_be_before_from", "to must not be before from")
+	}
+	return a.repo.GetAttendances(ctx, filter)
+}
+
+func (a *attendanceServiceImpl) CheckIn(ctx context.Context, userID uint64) (models.Attendance, error) {
+	now := time.Now().In(a.schedule.Location)
+	workDate := models.NewDate(now)
+
+	if err := a.locker.EnsureOpen(ctx, workDate, workDate); err != nil {
+		return models.Attendance{}, err
+	}
+
+	existing, err := a.repo.GetAttendanceByUserAndDate(ctx, userID, workDate)
+	if err != nil {
+		return models.Attendance{}, err
+	}
+	if existing.ID != 0 {
+		return models.Attendance{}, apperror.Conflict("already_checked_in_today", "already checked in today")
+	}
+
+	attendance, err := a.scheduledDay(ctx, userID, workDate)
+	if err != nil {
+		return models.Attendance{}, err
+	}
+	attendance.CheckInAt = now.UTC()
+
+	// Work on weekends and holidays is recorded but never late or early.
+	if lateBy := now.Sub(attendance.ScheduledStart); attendance.IsWorkingDay && lateBy > a.schedule.GracePeriod {
+		attendance.IsLate = true
+		attendance.LateMinutes = int(lateBy.Minutes())
+	}
+
+	created, err := a.repo.CreateAttendance(ctx, attendance)
+	if err != nil {
+		if errors.Is(err, repository.ErrAttendanceExists) {
+			return models.Attendance{}, apperror.Conflict("already_checked_in_today", "already checked in today")
+		}
+		return models.Attendance{}, err
+	}
+
+	a.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionCreate, EntityType: models.AuditEntityAttendance, EntityID: uint64(created.ID), After: created})
+	return created, nil
+}
+
+func (a *attendanceServiceImpl) CheckOut(ctx context.Context, userID uint64) (models.Attendance, error) {
+	now := time.Now()
+
+	attendance, err := a.repo.GetOpenAttendance(ctx, userID, now.Add(-maxShiftLength))
+	if err != nil {
+		return models.Attendance{}, err
+	}
+	if attendance.ID == 0 {
+		return models.Attendance{}, apperror.Conflict("not_checked_in", "not checked in")
+	}
+	if err := a.locker.EnsureOpen(ctx, attendance.WorkDate, attendance.WorkDate); err != nil {
+		return models.Attendance{}, err
+	}
+	existing := attendance
+
+	checkOutAt := now.UTC()
+	attendance.CheckOutAt = &checkOutAt
+	attendance.SetWorkedMinutes(int(checkOutAt.Sub(attendance.CheckInAt).Minutes()))
+	if leftEarlyBy := attendance.ScheduledEnd.Sub(checkOutAt); attendance.IsWorkingDay && leftEarlyBy > 0 {
+		attendance.IsEarlyLeave = true
+		attendance.EarlyLeaveMinutes = int(leftEarlyBy.Minutes())
+	}
+
+	updated, err := a.repo.UpdateAttendance(ctx, attendance)
+	if err != nil {
+		return models.Attendance{}, err
+	}
+	a.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionUpdate, EntityType: models.AuditEntityAttendance, EntityID: uint64(updated.ID), Before: existing, After: updated})
+
+	// The check-out is stored already, so failing to link it to a
+	// pre-approval shouldn't fail the request.
+	if err := a.overtime.LinkAttendance(ctx, updated); err != nil {
+		log.Printf("linking overtime of attendance %d failed: %v", updated.ID, err)
+	}
+	return updated, nil
+}
+
+// scheduledDay returns the attendance of userID on workDate before check-in.
+// A rostered shift sets the expected start and end and makes the day a
+// working day, otherwise the default schedule and the user's calendar apply.
+func (a *attendanceServiceImpl) scheduledDay(ctx context.Context, userID uint64, workDate models.Date) (models.Attendance, error) {
+	attendance := models.Attendance{UserID: int(userID), WorkDate: workDate}
+
+	shift, err := a.shifts.ScheduledShift(ctx, userID, workDate)
+	if err != nil {
+		return models.Attendance{}, err
+	}
+
+	start, end := a.schedule.Start, a.schedule.End
+	if shift.ID != 0 {
+		start, end = shift.Offsets()
+		attendance.ShiftID = &shift.ID
+		attendance.IsWorkingDay = true
+	} else {
+		attendance.IsWorkingDay, err = a.calendar.IsWorkingDay(ctx, userID, workDate)
+		if err != nil {
+			return models.Attendance{}, err
+		}
+	}
+
+	attendance.ScheduledStart = workDate.At(a.schedule.Location, start).UTC()
+	attendance.ScheduledEnd = workDate.At(a.schedule.Location, end).UTC()
+	return attendance, nil
+}
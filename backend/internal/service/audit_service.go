@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"reflect"
+	"time"
+
+	"main.go/internal/auth"
+	"main.go/internal/models"
+	"main.go/internal/repository"
+	"main.go/internal/requestinfo"
+)
+
+// AuditLogger records who changed what. Services call it once a change has
+// been saved. The change is already done by then, so a failure to record it
+// is logged instead of failing the request.
+type AuditLogger interface {
+	Record(ctx context.Context, entry models.AuditEntry)
+}
+
+type AuditService interface {
+	AuditLogger
+
+	GetAuditLogs(ctx context.Context, filter models.AuditLogFilter) ([]models.AuditLog, models.PaginationMeta, error)
+	VerifyAuditLogs(ctx context.Context) (models.AuditVerification, error)
+}
+
+type auditServiceImpl struct {
+	repo repository.AuditQuery
+}
+
+func NewAuditService(repo repository.AuditQuery) AuditService {
+	return &auditServiceImpl{repo: repo}
+}
+
+const (
+	defaultAuditLogsLimit = 50
+	maxAuditLogsLimit     = 100
+	auditVerifyBatchSize  = 500
+)
+
+// redactedAuditFields never have their values written to the audit log,
+// only the fact that they changed.
+var redactedAuditFields = map[string]bool{
+	"password":       true,
+	"token":          true,
+	"refresh_token":  true,
+	"account_number": true,
+}
+
+func (a *auditServiceImpl) Record(ctx context.Context, entry models.AuditEntry) {
+	changes, err := auditChanges(entry.Before, entry.After)
+	if err != nil {
+		log.Printf("audit %s %s %d: %v", entry.Action, entry.EntityType, entry.EntityID, err)
+		return
+	}
+
+	info := requestinfo.FromContext(ctx)
+	auditLog := models.AuditLog{
+		Action:     entry.Action,
+		EntityType: entry.EntityType,
+		EntityID:   entry.EntityID,
+		Changes:    changes,
+		RequestID:  info.ID,
+		ClientIP:   info.ClientIP,
+		// Postgres keeps microseconds, the hash has to match what is read
+		// back.
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+	if entry.ActorID != 0 {
+		actorID := entry.ActorID
+		auditLog.ActorID = &actorID
+	} else if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		auditLog.ActorID = &principal.UserID
+	}
+
+	// A client hanging up right after the change must not lose its record.
+	if _, err := a.repo.AppendAuditLog(context.WithoutCancel(ctx), auditLog); err != nil {
+		log.Printf("audit %s %s %d: %v", entry.Action, entry.EntityType, entry.EntityID, err)
+	}
+}
+
+func (a *auditServiceImpl) GetAuditLogs(ctx context.Context, filter models.AuditLogFilter) ([]models.AuditLog, models.PaginationMeta, error) {
+	if filter.Limit == 0 {
+		filter.Limit = defaultAuditLogsLimit
+	}
+	if filter.Limit > maxAuditLogsLimit {
+		filter.Limit = maxAuditLogsLimit
+	}
+	if filter.Page == 0 {
+		filter.Page = 1
+	}
+
+	logs, total, err := a.repo.GetAuditLogs(ctx, filter)
+	if err != nil {
+		return []models.AuditLog{}, models.PaginationMeta{}, err
+	}
+
+	meta := models.PaginationMeta{
+		Page:       filter.Page,
+		Limit:      filter.Limit,
+		Total:      total,
+		TotalPages: int((total + int64(filter.Limit) - 1) / int64(filter.Limit)),
+	}
+	return logs, meta, nil
+}
+
+// VerifyAuditLogs walks the whole chain and reports the first log whose hash
+// doesn't match its content or whose previous hash isn't the hash of the
+// log before it.
+func (a *auditServiceImpl) VerifyAuditLogs(ctx context.Context) (models.AuditVerification, error) {
+	result := models.AuditVerification{Valid: true}
+	prevHash := models.AuditGenesisHash
+
+	var afterID uint64
+	for {
+		logs, err := a.repo.GetAuditLogsAfter(ctx, afterID, auditVerifyBatchSize)
+		if err != nil {
+			return models.AuditVerification{}, err
+		}
+
+		for _, auditLog := range logs {
+			result.Checked++
+			if auditLog.PrevHash != prevHash || auditLog.ComputeHash() != auditLog.Hash {
+				brokenAt := auditLog.ID
+				result.Valid = false
+				result.BrokenAt = &brokenAt
+				return result, nil
+			}
+			prevHash = auditLog.Hash
+			afterID = auditLog.ID
+		}
+
+		if len(logs) < auditVerifyBatchSize {
+			return result, nil
+		}
+	}
+}
+
+// auditChanges lists the fields that differ between before and after by
+// their JSON names. Either side may be nil, for creates and deletes.
+func auditChanges(before, after interface{}) (json.RawMessage, error) {
+	beforeFields, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]models.AuditChange{}
+	for name, value := range beforeFields {
+		if other, ok := afterFields[name]; !ok || !reflect.DeepEqual(value, other) {
+			changes[name] = models.AuditChange{Before: value, After: afterFields[name]}
+		}
+	}
+	for name, value := range afterFields {
+		if _, ok := beforeFields[name]; !ok {
+			changes[name] = models.AuditChange{Before: nil, After: value}
+		}
+	}
+
+	for name, change := range changes {
+		if redactedAuditFields[name] {
+			changes[name] = models.AuditChange{Before: redacted(change.Before), After: redacted(change.After)}
+		}
+	}
+
+	// Maps are encoded with sorted keys, so equal changes give equal text.
+	return json.Marshal(changes)
+}
+
+// auditFields decodes the JSON form of an entity into its top level fields.
+func auditFields(entity interface{}) (map[string]interface{}, error) {
+	if entity == nil {
+		return map[string]interface{}{}, nil
+	}
+
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		// Entities that aren't JSON objects, like lists, are one value.
+		var value interface{}
+		if err := json.Unmarshal(data, &value); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"value": value}, nil
+	}
+	return fields, nil
+}
+
+func redacted(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	return "[redacted]"
+}
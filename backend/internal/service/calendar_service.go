@@ -0,0 +1,360 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"main.go/internal/apperror"
+	"main.go/internal/ical"
+	"main.go/internal/models"
+	"main.go/internal/repository"
+)
+
+// maxWorkingDaysRange bounds the ranges the working day calculator walks
+// through day by day.
+const maxWorkingDaysRange = 366 * 5
+
+// WorkingDayCalculator answers working day questions for a user, based on
+// the calendar of the user's department.
+type WorkingDayCalculator interface {
+	WorkingDays(ctx context.Context, userID uint64, from models.Date, to models.Date) (int, error)
+	IsWorkingDay(ctx context.Context, userID uint64, date models.Date) (bool, error)
+}
+
+type CalendarService interface {
+	WorkingDayCalculator
+
+	GetCalendars(ctx context.Context) ([]models.Calendar, error)
+	GetCalendarByID(ctx context.Context, id uint64) (models.Calendar, error)
+	CreateCalendar(ctx context.Context, createCalendar models.CalendarRequest) (models.Calendar, error)
+	UpdateCalendar(ctx context.Context, id uint64, updateCalendar models.CalendarRequest) (models.Calendar, error)
+	DeleteCalendar(ctx context.Context, id uint64) error
+
+	GetHolidays(ctx context.Context, calendarID uint64, filter models.HolidayFilter) ([]models.Holiday, error)
+	CreateHoliday(ctx context.Context, calendarID uint64, createHoliday models.HolidayRequest) (models.Holiday, error)
+	UpdateHoliday(ctx context.Context, calendarID uint64, id uint64, updateHoliday models.HolidayRequest) (models.Holiday, error)
+	DeleteHoliday(ctx context.Context, calendarID uint64, id uint64) error
+	ImportHolidays(ctx context.Context, calendarID uint64, r io.Reader) (models.HolidayImportResult, error)
+
+	GetWorkingDaysSummary(ctx context.Context, userID uint64, from models.Date, to models.Date) (models.WorkingDaysSummary, error)
+}
+
+type calendarServiceImpl struct {
+	repo  repository.CalendarQuery
+	audit AuditLogger
+}
+
+func NewCalendarService(repo repository.CalendarQuery, audit AuditLogger) CalendarService {
+	return &calendarServiceImpl{repo: repo, audit: audit}
+}
+
+func (c *calendarServiceImpl) GetCalendars(ctx context.Context) ([]models.Calendar, error) {
+	return c.repo.GetCalendars(ctx)
+}
+
+func (c *calendarServiceImpl) GetCalendarByID(ctx context.Context, id uint64) (models.Calendar, error) {
+	calendar, err := c.repo.GetCalendarByID(ctx, id)
+	if err != nil {
+		return models.Calendar{}, err
+	}
+	if calendar.ID == 0 {
+		return models.Calendar{}, apperror.NotFound("calendar_not_found", "calendar not found")
+	}
+	return calendar, nil
+}
+
+func (c *calendarServiceImpl) CreateCalendar(ctx context.Context, createCalendar models.CalendarRequest) (models.Calendar, error) {
+	calendar, err := c.checkCalendar(ctx, createCalendar, 0)
+	if err != nil {
+		return models.Calendar{}, err
+	}
+
+	calendar, err = c.repo.CreateCalendar(ctx, calendar)
+	if err != nil {
+		return models.Calendar{}, err
+	}
+
+	c.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionCreate, EntityType: models.AuditEntityCalendar, EntityID: uint64(calendar.ID), After: calendar})
+	return calendar, nil
+}
+
+func (c *calendarServiceImpl) UpdateCalendar(ctx context.Context, id uint64, updateCalendar models.CalendarRequest) (models.Calendar, error) {
+	existing, err := c.GetCalendarByID(ctx, id)
+	if err != nil {
+		return models.Calendar{}, err
+	}
+	if existing.IsDefault && !updateCalendar.IsDefault {
+		return models.Calendar{}, apperror.Conflict("make_another_calendar_the_default_instead", "make another calendar the default instead")
+	}
+
+	calendar, err := c.checkCalendar(ctx, updateCalendar, id)
+	if err != nil {
+		return models.Calendar{}, err
+	}
+
+	calendar, err = c.repo.UpdateCalendar(ctx, id, calendar)
+	if err != nil {
+		return models.Calendar{}, err
+	}
+
+	c.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionUpdate, EntityType: models.AuditEntityCalendar, EntityID: id, Before: existing, After: calendar})
+	return calendar, nil
+}
+
+// DeleteCalendar removes a calendar and its holidays. Departments using it
+// fall back to the default calendar, which can't be deleted.
+func (c *calendarServiceImpl) DeleteCalendar(ctx context.Context, id uint64) error {
+	calendar, err := c.GetCalendarByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if calendar.IsDefault {
+		return apperror.Conflict("default_calendar_cannot_be_deleted", "default calendar cannot be deleted")
+	}
+
+	if err := c.repo.DeleteCalendar(ctx, id); err != nil {
+		return err
+	}
+
+	c.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionDelete, EntityType: models.AuditEntityCalendar, EntityID: id, Before: calendar})
+	return nil
+}
+
+func (c *calendarServiceImpl) GetHolidays(ctx context.Context, calendarID uint64, filter models.HolidayFilter) ([]models.Holiday, error) {
+	if _, err := c.GetCalendarByID(ctx, calendarID); err != nil {
+		return []models.Holiday{}, err
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		return []models.Holiday{}, apperror.Validation("to_must_not_be_before_from", "to must not be before from")
+	}
+	return c.repo.GetHolidays(ctx, calendarID, filter)
+}
+
+func (c *calendarServiceImpl) CreateHoliday(ctx context.Context, calendarID uint64, createHoliday models.HolidayRequest) (models.Holiday, error) {
+	if _, err := c.GetCalendarByID(ctx, calendarID); err != nil {
+		return models.Holiday{}, err
+	}
+
+	holiday, err := checkHoliday(createHoliday)
+	if err != nil {
+		return models.Holiday{}, err
+	}
+	holiday.CalendarID = int(calendarID)
+
+	holiday, err = c.repo.CreateHoliday(ctx, holiday)
+	if err != nil {
+		return models.Holiday{}, err
+	}
+
+	c.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionCreate, EntityType: models.AuditEntityHoliday, EntityID: uint64(holiday.ID), After: holiday})
+	return holiday, nil
+}
+
+func (c *calendarServiceImpl) UpdateHoliday(ctx context.Context, calendarID uint64, id uint64, updateHoliday models.HolidayRequest) (models.Holiday, error) {
+	existing, err := c.getHoliday(ctx, calendarID, id)
+	if err != nil {
+		return models.Holiday{}, err
+	}
+
+	holiday, err := checkHoliday(updateHoliday)
+	if err != nil {
+		return models.Holiday{}, err
+	}
+	holiday.ID = existing.ID
+	holiday.CalendarID = existing.CalendarID
+
+	holiday, err = c.repo.UpdateHoliday(ctx, holiday)
+	if err != nil {
+		return models.Holiday{}, err
+	}
+
+	c.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionUpdate, EntityType: models.AuditEntityHoliday, EntityID: id, Before: existing, After: holiday})
+	return holiday, nil
+}
+
+func (c *calendarServiceImpl) DeleteHoliday(ctx context.Context, calendarID uint64, id uint64) error {
+	existing, err := c.getHoliday(ctx, calendarID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := c.repo.DeleteHoliday(ctx, calendarID, id); err != nil {
+		return err
+	}
+
+	c.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionDelete, EntityType: models.AuditEntityHoliday, EntityID: id, Before: existing})
+	return nil
+}
+
+// ImportHolidays loads the events of an iCalendar file as holidays. Events
+// spanning several days become one holiday per day, and importing the same
+// file again updates the holidays instead of duplicating them.
+func (c *calendarServiceImpl) ImportHolidays(ctx context.Context, calendarID uint64, r io.Reader) (models.HolidayImportResult, error) {
+	if _, err := c.GetCalendarByID(ctx, calendarID); err != nil {
+		return models.HolidayImportResult{}, err
+	}
+
+	events, err := ical.Parse(r)
+	if err != nil {
+		return models.HolidayImportResult{}, &apperror.Error{
+			Kind:    apperror.KindValidation,
+			Code:    "invalid_calendar_file",
+			Message: "invalid calendar file: " + err.Error(),
+			Err:     err,
+		}
+	}
+
+	holidays := []models.Holiday{}
+	for index, event := range events {
+		baseUID := event.UID
+		if baseUID == "" {
+			baseUID = fmt.Sprintf("%s-%d", event.Start.Format("20060102"), index)
+		}
+		name := strings.TrimSpace(event.Summary)
+		if name == "" {
+			name = "Holiday"
+		}
+
+		days := event.Days()
+		for _, day := range days {
+			uid := baseUID
+			if len(days) > 1 {
+				uid = fmt.Sprintf("%s/%s", baseUID, day.Format("20060102"))
+			}
+			holidays = append(holidays, models.Holiday{
+				CalendarID: int(calendarID),
+				Name:       truncate(name, 255),
+				Date:       models.NewDate(day),
+				Recurring:  event.Yearly,
+				UID:        &uid,
+			})
+		}
+	}
+
+	if err := c.repo.UpsertHolidays(ctx, holidays); err != nil {
+		return models.HolidayImportResult{}, err
+	}
+
+	result := models.HolidayImportResult{Events: len(events), Holidays: len(holidays)}
+	// The import is recorded on the calendar, the holidays have no IDs here.
+	c.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionUpdate, EntityType: models.AuditEntityCalendar, EntityID: calendarID, After: map[string]interface{}{"imported_holidays": result}})
+	return result, nil
+}
+
+func (c *calendarServiceImpl) GetWorkingDaysSummary(ctx context.Context, userID uint64, from models.Date, to models.Date) (models.WorkingDaysSummary, error) {
+	if from.IsZero() || to.IsZero() {
+		return models.WorkingDaysSummary{}, apperror.Validation("from_and_to_are_required", "from and to are required")
+	}
+	if to.Before(from) {
+		return models.WorkingDaysSummary{}, apperror.Validation("to_must_not_be_before_from", "to must not be before from")
+	}
+	if to.Sub(from.Time).Hours()/24 > maxWorkingDaysRange {
+		return models.WorkingDaysSummary{}, apperror.Validation("date_range_is_too_long", "date range is too long")
+	}
+
+	calendar, err := c.repo.GetUserCalendar(ctx, userID)
+	if err != nil {
+		return models.WorkingDaysSummary{}, err
+	}
+	if calendar.ID == 0 {
+		// Without a department or default calendar there are no holidays,
+		// but weekends are still off.
+		calendar.WeekdayMask = models.MondayToFriday
+	}
+	holidays, err := c.repo.GetHolidays(ctx, uint64(calendar.ID), models.HolidayFilter{To: to})
+	if err != nil {
+		return models.WorkingDaysSummary{}, err
+	}
+
+	summary := models.WorkingDaysSummary{
+		UserID:     int(userID),
+		CalendarID: calendar.ID,
+		From:       from,
+		To:         to,
+		Holidays:   []models.HolidayOccurrence{},
+	}
+	for day := from; !day.After(to); day = day.AddDays(1) {
+		if holiday, ok := holidayOn(holidays, day); ok {
+			summary.Holidays = append(summary.Holidays, models.HolidayOccurrence{Date: day, Name: holiday.Name})
+			continue
+		}
+		if calendar.IsWorkingWeekday(day.Weekday()) {
+			summary.WorkingDays++
+		}
+	}
+	return summary, nil
+}
+
+// WorkingDays counts the working days between from and to, both inclusive.
+func (c *calendarServiceImpl) WorkingDays(ctx context.Context, userID uint64, from models.Date, to models.Date) (int, error) {
+	summary, err := c.GetWorkingDaysSummary(ctx, userID, from, to)
+	if err != nil {
+		return 0, err
+	}
+	return summary.WorkingDays, nil
+}
+
+func (c *calendarServiceImpl) IsWorkingDay(ctx context.Context, userID uint64, date models.Date) (bool, error) {
+	days, err := c.WorkingDays(ctx, userID, date, date)
+	return days == 1, err
+}
+
+func (c *calendarServiceImpl) checkCalendar(ctx context.Context, request models.CalendarRequest, id uint64) (models.Calendar, error) {
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		return models.Calendar{}, apperror.Validation("calendar_name_is_required", "calendar name is required")
+	}
+
+	existing, err := c.repo.GetCalendarByName(ctx, name)
+	if err != nil {
+		return models.Calendar{}, err
+	}
+	if existing.ID != 0 && uint64(existing.ID) != id {
+		return models.Calendar{}, apperror.Conflict("calendar_name_already_exists", "calendar name already exists")
+	}
+
+	calendar := models.Calendar{Name: name, IsDefault: request.IsDefault}
+	calendar.SetWorkingWeekdays(request.WorkingWeekdays)
+	return calendar, nil
+}
+
+func (c *calendarServiceImpl) getHoliday(ctx context.Context, calendarID uint64, id uint64) (models.Holiday, error) {
+	holiday, err := c.repo.GetHolidayByID(ctx, calendarID, id)
+	if err != nil {
+		return models.Holiday{}, err
+	}
+	if holiday.ID == 0 {
+		return models.Holiday{}, apperror.NotFound("holiday_not_found", "holiday not found")
+	}
+	return holiday, nil
+}
+
+func checkHoliday(request models.HolidayRequest) (models.Holiday, error) {
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		return models.Holiday{}, apperror.Validation("holiday_name_is_required", "holiday name is required")
+	}
+	if request.Date.IsZero() {
+		return models.Holiday{}, apperror.Validation("holiday_date_is_required", "holiday date is required")
+	}
+	return models.Holiday{Name: name, Date: request.Date, Recurring: request.Recurring}, nil
+}
+
+func holidayOn(holidays []models.Holiday, day models.Date) (models.Holiday, bool) {
+	for _, holiday := range holidays {
+		if holiday.OccursOn(day) {
+			return holiday, true
+		}
+	}
+	return models.Holiday{}, false
+}
+
+func truncate(value string, length int) string {
+	runes := []rune(value)
+	if len(runes) <= length {
+		return value
+	}
+	return string(runes[:length])
+}
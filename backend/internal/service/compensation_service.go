@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"main.go/internal/apperror"
+	"main.go/internal/auth"
+	"main.go/internal/models"
+	"main.go/internal/repository"
+)
+
+type CompensationService interface {
+	GetCompensationHistory(ctx context.Context, userID uint64) ([]models.Compensation, error)
+	GetCurrentCompensation(ctx context.Context, userID uint64) (models.Compensation, error)
+	GetScheduledCompensations(ctx context.Context, userID uint64) ([]models.Compensation, error)
+	GetCurrentCompensations(ctx context.Context, filter models.CompensationFilter) ([]models.Compensation, error)
+	GetCompensationByID(ctx context.Context, id uint64) (models.Compensation, error)
+
+	CreateCompensation(ctx context.Context, userID uint64, request models.CompensationRequest) (models.Compensation, error)
+	UpdateCompensation(ctx context.Context, id uint64, request models.CompensationRequest) (models.Compensation, error)
+	DeleteCompensation(ctx context.Context, id uint64) error
+}
+
+type compensationServiceImpl struct {
+	repo     repository.CompensationQuery
+	userRepo repository.UserQuery
+	location *time.Location
+	audit    AuditLogger
+}
+
+func NewCompensationService(repo repository.CompensationQuery, userRepo repository.UserQuery, location *time.Location, audit AuditLogger) CompensationService {
+	return &compensationServiceImpl{repo: repo, userRepo: userRepo, location: location, audit: audit}
+}
+
+func (c *compensationServiceImpl) GetCompensationHistory(ctx context.Context, userID uint64) ([]models.Compensation, error) {
+	if err := c.checkUser(ctx, userID); err != nil {
+		return []models.Compensation{}, err
+	}
+	return c.repo.GetCompensations(ctx, userID)
+}
+
+func (c *compensationServiceImpl) GetCurrentCompensation(ctx context.Context, userID uint64) (models.Compensation, error) {
+	if err := c.checkUser(ctx, userID); err != nil {
+		return models.Compensation{}, err
+	}
+
+	compensation, err := c.repo.GetCompensationAt(ctx, userID, c.today())
+	if err != nil {
+		return models.Compensation{}, err
+	}
+	if compensation.ID == 0 {
+		return models.Compensation{}, apperror.NotFound("compensation_not_found", "compensation not found")
+	}
+	return compensation, nil
+}
+
+// GetScheduledCompensations lists the compensations of a user that take
+// effect after today, the next one first.
+func (c *compensationServiceImpl) GetScheduledCompensations(ctx context.Context, userID uint64) ([]models.Compensation, error) {
+	history, err := c.GetCompensationHistory(ctx, userID)
+	if err != nil {
+		return []models.Compensation{}, err
+	}
+
+	today := c.today()
+	scheduled := []models.Compensation{}
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].EffectiveDate.After(today) {
+			scheduled = append(scheduled, history[i])
+		}
+	}
+	return scheduled, nil
+}
+
+// GetCurrentCompensations lists the compensation every user has on
+// filter.Date, today when it is left out.
+func (c *compensationServiceImpl) GetCurrentCompensations(ctx context.Context, filter models.CompensationFilter) ([]models.Compensation, error) {
+	if filter.Date.IsZero() {
+		filter.Date = c.today()
+	}
+	return c.repo.GetCompensationsAt(ctx, filter)
+}
+
+func (c *compensationServiceImpl) GetCompensationByID(ctx context.Context, id uint64) (models.Compensation, error) {
+	compensation, err := c.repo.GetCompensationByID(ctx, id)
+	if err != nil {
+		return models.Compensation{}, err
+	}
+	if compensation.ID == 0 {
+		return models.Compensation{}, apperror.NotFound("compensation_not_found", "compensation not found")
+	}
+	return compensation, nil
+}
+
+// CreateCompensation records a compensation of a user. An effective date in
+// the past adds to the history, one in the future schedules a raise.
+func (c *compensationServiceImpl) CreateCompensation(ctx context.Context, userID uint64, request models.CompensationRequest) (models.Compensation, error) {
+	if err := c.checkUser(ctx, userID); err != nil {
+		return models.Compensation{}, err
+	}
+	if err := c.checkCompensationRequest(request); err != nil {
+		return models.Compensation{}, err
+	}
+
+	compensation := c.compensationFromRequest(request)
+	compensation.UserID = int(userID)
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		createdBy := principal.UserID
+		compensation.CreatedBy = &createdBy
+	}
+
+	created, err := c.repo.CreateCompensation(ctx, compensation)
+	if err != nil {
+		if errors.Is(err, repository.ErrCompensationExists) {
+			return models.Compensation{}, apperror.Conflict("compensation_already_exists_for_this_effective_date", "compensation already exists for this effective_date")
+		}
+		return models.Compensation{}, err
+	}
+
+	created, err = c.repo.GetCompensationByID(ctx, uint64(created.ID))
+	if err != nil {
+		return models.Compensation{}, err
+	}
+
+	c.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionCreate, EntityType: models.AuditEntityCompensation, EntityID: uint64(created.ID), After: created})
+	return created, nil
+}
+
+// UpdateCompensation changes a scheduled compensation. Compensations that
+// already took effect are history, a correction is recorded as a new one.
+func (c *compensationServiceImpl) UpdateCompensation(ctx context.Context, id uint64, request models.CompensationRequest) (models.Compensation, error) {
+	existing, err := c.getScheduledCompensation(ctx, id)
+	if err != nil {
+		return models.Compensation{}, err
+	}
+	if err := c.checkCompensationRequest(request); err != nil {
+		return models.Compensation{}, err
+	}
+	if !request.EffectiveDate.After(c.today()) {
+		return models.Compensation{}, apperror.Validation("scheduled_compensation_must_take_effect_after_today", "scheduled compensation must take effect after today")
+	}
+
+	compensation := c.compensationFromRequest(request)
+	compensation.ID = existing.ID
+	compensation.UserID = existing.UserID
+
+	if err := c.repo.UpdateCompensation(ctx, compensation); err != nil {
+		if errors.Is(err, repository.ErrCompensationExists) {
+			return models.Compensation{}, apperror.Conflict("compensation_already_exists_for_this_effective_date", "compensation already exists for this effective_date")
+		}
+		return models.Compensation{}, err
+	}
+
+	updated, err := c.repo.GetCompensationByID(ctx, id)
+	if err != nil {
+		return models.Compensation{}, err
+	}
+
+	c.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionUpdate, EntityType: models.AuditEntityCompensation, EntityID: id, Before: existing, After: updated})
+	return updated, nil
+}
+
+// DeleteCompensation cancels a scheduled compensation.
+func (c *compensationServiceImpl) DeleteCompensation(ctx context.Context, id uint64) error {
+	existing, err := c.getScheduledCompensation(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := c.repo.DeleteCompensation(ctx, id); err != nil {
+		return err
+	}
+
+	c.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionDelete, EntityType: models.AuditEntityCompensation, EntityID: id, Before: existing})
+	return nil
+}
+
+func (c *compensationServiceImpl) getScheduledCompensation(ctx context.Context, id uint64) (models.Compensation, error) {
+	compensation, err := c.GetCompensationByID(ctx, id)
+	if err != nil {
+		return models.Compensation{}, err
+	}
+	if !compensation.EffectiveDate.After(c.today()) {
+		return models.Compensation{}, apperror.Conflict("only_scheduled_compensation_can_be_changed", "only scheduled compensation can be changed")
+	}
+	return compensation, nil
+}
+
+func (c *compensationServiceImpl) checkCompensationRequest(request models.CompensationRequest) error {
+	if request.EffectiveDate.IsZero() {
+		return apperror.Validation("effective_date_is_required", "effective_date is required")
+	}
+	if !isPositiveAmount(request.BasePay) {
+		return apperror.Validation("base_pay_must_be_a_positive_amount_with_at_most_2_decimals", "base_pay must be a positive amount with at most 2 decimals")
+	}
+
+	names := map[string]bool{}
+	for _, allowance := range request.Allowances {
+		if !isPositiveAmount(allowance.Amount) {
+			return apperror.Validation("allowance_amount_must_be_a_positive_amount_with_at_most_2_decimals", "allowance amount must be a positive amount with at most 2 decimals")
+		}
+		if names[allowance.Name] {
+			return apperror.Validation("allowance_names_must_be_unique", "allowance names must be unique")
+		}
+		names[allowance.Name] = true
+	}
+	return nil
+}
+
+func (c *compensationServiceImpl) compensationFromRequest(request models.CompensationRequest) models.Compensation {
+	compensation := models.Compensation{
+		EffectiveDate: request.EffectiveDate,
+		BasePay:       request.BasePay,
+		Currency:      request.Currency,
+		PayFrequency:  request.PayFrequency,
+		Reason:        request.Reason,
+	}
+	for _, allowance := range request.Allowances {
+		compensation.Allowances = append(compensation.Allowances, models.CompensationAllowance{
+			Name:   allowance.Name,
+			Amount: allowance.Amount,
+		})
+	}
+	return compensation
+}
+
+func (c *compensationServiceImpl) checkUser(ctx context.Context, userID uint64) error {
+	user, err := c.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.Id == 0 {
+		return apperror.NotFound("user_not_found", "user not found")
+	}
+	return nil
+}
+
+func (c *compensationServiceImpl) today() models.Date {
+	return models.NewDate(time.Now().In(c.location))
+}
+
+// isPositiveAmount tells whether amount fits a NUMERIC(14,2) column and is
+// more than zero.
+func isPositiveAmount(amount decimal.Decimal) bool {
+	return amount.IsPositive() &&
+		amount.Equal(amount.Round(2)) &&
+		amount.LessThan(decimal.New(1, 12))
+}
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"main.go/internal/apperror"
+	"main.go/internal/auth"
+	"main.go/internal/models"
+	"main.go/internal/payroll"
+	"main.go/internal/repository"
+)
+
+type DeductionRuleService interface {
+	GetDeductionRuleSets(ctx context.Context, filter models.DeductionRuleSetFilter) ([]models.DeductionRuleSet, error)
+	GetDeductionRuleSetByID(ctx context.Context, id uint64) (models.DeductionRuleSet, error)
+	CreateDeductionRuleSet(ctx context.Context, request models.DeductionRuleSetRequest) (models.DeductionRuleSet, error)
+	UpdateDeductionRuleSet(ctx context.Context, id uint64, request models.DeductionRuleSetRequest) (models.DeductionRuleSet, error)
+	DeleteDeductionRuleSet(ctx context.Context, id uint64) error
+	DryRun(ctx context.Context, request models.DeductionDryRunRequest) (models.DeductionDryRun, error)
+}
+
+type deductionRuleServiceImpl struct {
+	repo     repository.DeductionRuleQuery
+	location *time.Location
+	audit    AuditLogger
+}
+
+func NewDeductionRuleService(repo repository.DeductionRuleQuery, location *time.Location, audit AuditLogger) DeductionRuleService {
+	return &deductionRuleServiceImpl{repo: repo, location: location, audit: audit}
+}
+
+func (d *deductionRuleServiceImpl) GetDeductionRuleSets(ctx context.Context, filter models.DeductionRuleSetFilter) ([]models.DeductionRuleSet, error) {
+	return d.repo.GetDeductionRuleSets(ctx, filter)
+}
+
+func (d *deductionRuleServiceImpl) GetDeductionRuleSetByID(ctx context.Context, id uint64) (models.DeductionRuleSet, error) {
+	set, err := d.repo.GetDeductionRuleSetByID(ctx, id)
+	if err != nil {
+		return models.DeductionRuleSet{}, err
+	}
+	if set.ID == 0 {
+		return models.DeductionRuleSet{}, apperror.NotFound("deduction_rule_set_not_found", "deduction rule set not found")
+	}
+	return set, nil
+}
+
+// CreateDeductionRuleSet adds the rules of a currency from an effective
+// date on. Rules change by adding a new set, which keeps the ones earlier
+// payslips were calculated with.
+func (d *deductionRuleServiceImpl) CreateDeductionRuleSet(ctx context.Context, request models.DeductionRuleSetRequest) (models.DeductionRuleSet, error) {
+	if err := d.checkRuleSetRequest(request); err != nil {
+		return models.DeductionRuleSet{}, err
+	}
+
+	set := models.DeductionRuleSet{
+		Name:          request.Name,
+		Currency:      request.Currency,
+		EffectiveDate: request.EffectiveDate,
+		Rules:         request.Rules,
+	}
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		createdBy := principal.UserID
+		set.CreatedBy = &createdBy
+	}
+
+	created, err := d.repo.CreateDeductionRuleSet(ctx, set)
+	if err != nil {
+		if errors.Is(err, repository.ErrDeductionRuleSetExists) {
+			return models.DeductionRuleSet{}, apperror.Conflict("deduction_rule_set_already_exists_for_this_currency_and_effective_date", "deduction rule set already exists for this currency and effective_date")
+		}
+		return models.DeductionRuleSet{}, err
+	}
+
+	d.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionCreate, EntityType: models.AuditEntityDeductionRuleSet, EntityID: uint64(created.ID), After: created})
+	return created, nil
+}
+
+// UpdateDeductionRuleSet changes a rule set that isn't in effect yet.
+func (d *deductionRuleServiceImpl) UpdateDeductionRuleSet(ctx context.Context, id uint64, request models.DeductionRuleSetRequest) (models.DeductionRuleSet, error) {
+	existing, err := d.getScheduledRuleSet(ctx, id)
+	if err != nil {
+		return models.DeductionRuleSet{}, err
+	}
+	if err := d.checkRuleSetRequest(request); err != nil {
+		return models.DeductionRuleSet{}, err
+	}
+	if !request.EffectiveDate.After(d.today()) {
+		return models.DeductionRuleSet{}, apperror.Validation("scheduled_deduction_rule_set_must_take_effect_after_today", "scheduled deduction rule set must take effect after today")
+	}
+
+	set := models.DeductionRuleSet{
+		ID:            existing.ID,
+		Name:          request.Name,
+		Currency:      request.Currency,
+		EffectiveDate: request.EffectiveDate,
+		Rules:         request.Rules,
+	}
+	if err := d.repo.UpdateDeductionRuleSet(ctx, set); err != nil {
+		if errors.Is(err, repository.ErrDeductionRuleSetExists) {
+			return models.DeductionRuleSet{}, apperror.Conflict("deduction_rule_set_already_exists_for_this_currency_and_effective_date", "deduction rule set already exists for this currency and effective_date")
+		}
+		return models.DeductionRuleSet{}, err
+	}
+
+	updated, err := d.repo.GetDeductionRuleSetByID(ctx, id)
+	if err != nil {
+		return models.DeductionRuleSet{}, err
+	}
+
+	d.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionUpdate, EntityType: models.AuditEntityDeductionRuleSet, EntityID: id, Before: existing, After: updated})
+	return updated, nil
+}
+
+func (d *deductionRuleServiceImpl) DeleteDeductionRuleSet(ctx context.Context, id uint64) error {
+	existing, err := d.getScheduledRuleSet(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := d.repo.DeleteDeductionRuleSet(ctx, id); err != nil {
+		return err
+	}
+
+	d.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionDelete, EntityType: models.AuditEntityDeductionRuleSet, EntityID: id, Before: existing})
+	return nil
+}
+
+// DryRun deducts from a gross pay the way a payroll run would, so rules can
+// be checked before they are used.
+func (d *deductionRuleServiceImpl) DryRun(ctx context.Context, request models.DeductionDryRunRequest) (models.DeductionDryRun, error) {
+	if !isPositiveAmount(request.GrossPay) {
+		return models.DeductionDryRun{}, apperror.Validation("gross_pay_must_be_a_positive_amount_with_at_most_2_decimals", "gross_pay must be a positive amount with at most 2 decimals")
+	}
+	date := request.Date
+	if date.IsZero() {
+		date = d.today()
+	}
+
+	set, err := d.repo.GetDeductionRuleSetAt(ctx, request.Currency, date)
+	if err != nil {
+		return models.DeductionDryRun{}, err
+	}
+	if set.ID == 0 {
+		return models.DeductionDryRun{}, apperror.NotFound("deduction_rule_set_not_found", "deduction rule set not found")
+	}
+
+	payslip := models.Payslip{Currency: request.Currency, GrossPay: request.GrossPay, NetPay: request.GrossPay}
+	payroll.ApplyDeductionRules(set.Rules, &payslip)
+
+	lines := payslip.Lines
+	if lines == nil {
+		lines = []models.PayslipLine{}
+	}
+	return models.DeductionDryRun{
+		RuleSet:         &set,
+		Currency:        request.Currency,
+		GrossPay:        payslip.GrossPay,
+		Lines:           lines,
+		TotalDeductions: payslip.TotalDeductions,
+		NetPay:          payslip.NetPay,
+	}, nil
+}
+
+func (d *deductionRuleServiceImpl) getScheduledRuleSet(ctx context.Context, id uint64) (models.DeductionRuleSet, error) {
+	set, err := d.GetDeductionRuleSetByID(ctx, id)
+	if err != nil {
+		return models.DeductionRuleSet{}, err
+	}
+	if !set.EffectiveDate.After(d.today()) {
+		return models.DeductionRuleSet{}, apperror.Conflict("only_scheduled_deduction_rule_sets_can_be_changed", "only scheduled deduction rule sets can be changed")
+	}
+	return set, nil
+}
+
+func (d *deductionRuleServiceImpl) checkRuleSetRequest(request models.DeductionRuleSetRequest) error {
+	if request.EffectiveDate.IsZero() {
+		return apperror.Validation("effective_date_is_required", "effective_date is required")
+	}
+
+	codes := map[string]bool{}
+	for _, rule := range request.Rules {
+		if codes[rule.Code] {
+			return apperror.Validation("deduction_rule_codes_must_be_unique", "deduction rule codes must be unique")
+		}
+		codes[rule.Code] = true
+
+		switch rule.Type {
+		case models.DeductionProgressiveTax:
+			if err := checkTaxBrackets(rule.Brackets); err != nil {
+				return err
+			}
+		case models.DeductionContribution:
+			if rule.Rate == nil {
+				return apperror.Validation("contribution_rules_need_a_rate", "contribution rules need a rate")
+			}
+			if !isRate(*rule.Rate) {
+				return apperror.Validation("rates_must_be_between_0_and_1", "rates must be between 0 and 1")
+			}
+			if rule.BaseCap != nil && !isPositiveAmount(*rule.BaseCap) {
+				return apperror.Validation("base_cap_must_be_a_positive_amount_with_at_most_2_decimals", "base_cap must be a positive amount with at most 2 decimals")
+			}
+		case models.DeductionFixed:
+			if rule.Amount == nil || !isPositiveAmount(*rule.Amount) {
+				return apperror.Validation("fixed_rules_need_a_positive_amount_with_at_most_2_decimals", "fixed rules need a positive amount with at most 2 decimals")
+			}
+		}
+	}
+	return nil
+}
+
+// checkTaxBrackets wants brackets with rising limits and an open last one.
+func checkTaxBrackets(brackets []models.TaxBracket) error {
+	if len(brackets) == 0 {
+		return apperror.Validation("progressive_tax_rules_need_brackets", "progressive_tax rules need brackets")
+	}
+
+	lower := decimal.Zero
+	for i, bracket := range brackets {
+		if !isRate(bracket.Rate) {
+			return apperror.Validation("rates_must_be_between_0_and_1", "rates must be between 0 and 1")
+		}
+		last := i == len(brackets)-1
+		if bracket.UpTo == nil {
+			if !last {
+				return apperror.Validation("tax_brackets_need_rising_up_to_amounts_and_only_the_last_one_may_be_open", "tax brackets need rising up_to amounts and only the last one may be open")
+			}
+			continue
+		}
+		if !bracket.UpTo.GreaterThan(lower) || !isPositiveAmount(*bracket.UpTo) {
+			return apperror.Validation("tax_brackets_need_rising_up_to_amounts_and_only_the_last_one_may_be_open", "tax brackets need rising up_to amounts and only the last one may be open")
+		}
+		lower = *bracket.UpTo
+	}
+	return nil
+}
+
+func (d *deductionRuleServiceImpl) today() models.Date {
+	return models.NewDate(time.Now().In(d.location))
+}
+
+func isRate(rate decimal.Decimal) bool {
+	return !rate.IsNegative() && !rate.GreaterThan(decimal.NewFromInt(1))
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"main.go/internal/apperror"
+	"main.go/internal/models"
+	"main.go/internal/repository"
+)
+
+type DepartmentService interface {
+	GetDepartments(ctx context.Context) ([]models.Department, error)
+	GetDepartmentByID(ctx context.Context, id uint64) (models.Department, error)
+
+	CreateDepartment(ctx context.Context, createDepartment models.DepartmentRequest) (models.Department, error)
+	UpdateDepartment(ctx context.Context, id uint64, updateDepartment models.DepartmentRequest) (models.Department, error)
+	DeleteDepartment(ctx context.Context, id uint64) error
+}
+
+type departmentServiceImpl struct {
+	repo         repository.DepartmentQuery
+	calendarRepo repository.CalendarQuery
+	audit        AuditLogger
+}
+
+func NewDepartmentService(repo repository.DepartmentQuery, calendarRepo repository.CalendarQuery, audit AuditLogger) DepartmentService {
+	return &departmentServiceImpl{repo: repo, calendarRepo: calendarRepo, audit: audit}
+}
+
+func (d *departmentServiceImpl) GetDepartments(ctx context.Context) ([]models.Department, error) {
+	return d.repo.GetDepartments(ctx)
+}
+
+func (d *departmentServiceImpl) GetDepartmentByID(ctx context.Context, id uint64) (models.Department, error) {
+	department, err := d.repo.GetDepartmentByID(ctx, id)
+	if err != nil {
+		return models.Department{}, err
+	}
+	if department.ID == 0 {
+		return models.Department{}, apperror.NotFound("department_not_found", "department not found")
+	}
+	return department, nil
+}
+
+func (d *departmentServiceImpl) CreateDepartment(ctx context.Context, createDepartment models.DepartmentRequest) (models.Department, error) {
+	name := strings.TrimSpace(createDepartment.Name)
+	if err := d.checkDepartmentName(ctx, name, 0); err != nil {
+		return models.Department{}, err
+	}
+	if err := d.checkCalendar(ctx, createDepartment.CalendarID); err != nil {
+		return models.Department{}, err
+	}
+	department, err := d.repo.CreateDepartment(ctx, models.Department{Name: name, CalendarID: createDepartment.CalendarID})
+	if err != nil {
+		return models.Department{}, err
+	}
+
+	d.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionCreate, EntityType: models.AuditEntityDepartment, EntityID: uint64(department.ID), After: department})
+	return department, nil
+}
+
+func (d *departmentServiceImpl) UpdateDepartment(ctx context.Context, id uint64, updateDepartment models.DepartmentRequest) (models.Department, error) {
+	existing, err := d.GetDepartmentByID(ctx, id)
+	if err != nil {
+		return models.Department{}, err
+	}
+
+	name := strings.TrimSpace(updateDepartment.Name)
+	if err := d.checkDepartmentName(ctx, name, id); err != nil {
+		return models.Department{}, err
+	}
+	if err := d.checkCalendar(ctx, updateDepartment.CalendarID); err != nil {
+		return models.Department{}, err
+	}
+	department, err := d.repo.UpdateDepartment(ctx, id, models.Department{Name: name, CalendarID: updateDepartment.CalendarID})
+	if err != nil {
+		return models.Department{}, err
+	}
+
+	d.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionUpdate, EntityType: models.AuditEntityDepartment, EntityID: id, Before: existing, After: department})
+	return department, nil
+}
+
+func (d *departmentServiceImpl) DeleteDepartment(ctx context.Context, id uint64) error {
+	existing, err := d.GetDepartmentByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	count, err := d.repo.CountUsersInDepartment(ctx, id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return apperror.Conflict("department_still_has_users", "department still has users")
+	}
+
+	if err := d.repo.DeleteDepartment(ctx, id); err != nil {
+		return err
+	}
+
+	d.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionDelete, EntityType: models.AuditEntityDepartment, EntityID: id, Before: existing})
+	return nil
+}
+
+func (d *departmentServiceImpl) checkDepartmentName(ctx context.Context, name string, id uint64) error {
+	if name == "" {
+		return apperror.Validation("department_name_is_required", "department name is required")
+	}
+	existing, err := d.repo.GetDepartmentByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	if existing.ID != 0 && uint64(existing.ID) != id {
+		return apperror.Conflict("department_name_already_exists", "department name already exists")
+	}
+	return nil
+}
+
+// checkCalendar makes sure an assigned calendar exists. Departments without
+// a calendar use the default one.
+func (d *departmentServiceImpl) checkCalendar(ctx context.Context, calendarID *int) error {
+	if calendarID == nil {
+		return nil
+	}
+	calendar, err := d.calendarRepo.GetCalendarByID(ctx, uint64(*calendarID))
+	if err != nil {
+		return err
+	}
+	if calendar.ID == 0 {
+		return apperror.Validation("calendar_not_found", "calendar not found")
+	}
+	return nil
+}
@@ -0,0 +1,479 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"main.go/internal/apperror"
+	"main.go/internal/models"
+	"main.go/internal/repository"
+)
+
+type LeaveAccrualService interface {
+	GetPolicies(ctx context.Context) ([]models.LeaveAccrualPolicy, error)
+	GetPolicyByID(ctx context.Context, id uint64) (models.LeaveAccrualPolicy, error)
+	CreatePolicy(ctx context.Context, createPolicy models.LeaveAccrualPolicyRequest) (models.LeaveAccrualPolicy, error)
+	UpdatePolicy(ctx context.Context, id uint64, updatePolicy models.LeaveAccrualPolicyRequest) (models.LeaveAccrualPolicy, error)
+	DeletePolicy(ctx context.Context, id uint64) error
+
+	GetPositionPolicies(ctx context.Context, positionID uint64) ([]models.LeaveAccrualPolicy, error)
+	SetPositionPolicies(ctx context.Context, positionID uint64, policyIDs []int) ([]models.LeaveAccrualPolicy, error)
+
+	RunAccruals(ctx context.Context, date models.Date) (models.AccrualRunResult, error)
+}
+
+type leaveAccrualServiceImpl struct {
+	repo         repository.LeavePolicyQuery
+	leaveRepo    repository.LeaveQuery
+	positionRepo repository.PositionQuery
+	location     *time.Location
+	audit        AuditLogger
+}
+
+func NewLeaveAccrualService(repo repository.LeavePolicyQuery, leaveRepo repository.LeaveQuery, positionRepo repository.PositionQuery, location *time.Location, audit AuditLogger) LeaveAccrualService {
+	return &leaveAccrualServiceImpl{repo: repo, leaveRepo: leaveRepo, positionRepo: positionRepo, location: location, audit: audit}
+}
+
+func (l *leaveAccrualServiceImpl) GetPolicies(ctx context.Context) ([]models.LeaveAccrualPolicy, error) {
+	return l.repo.GetPolicies(ctx)
+}
+
+func (l *leaveAccrualServiceImpl) GetPolicyByID(ctx context.Context, id uint64) (models.LeaveAccrualPolicy, error) {
+	policy, err := l.repo.GetPolicyByID(ctx, id)
+	if err != nil {
+		return models.LeaveAccrualPolicy{}, err
+	}
+	if policy.ID == 0 {
+		return models.LeaveAccrualPolicy{}, apperror.NotFound("leave_policy_not_found", "leave policy not found")
+	}
+	return policy, nil
+}
+
+func (l *leaveAccrualServiceImpl) CreatePolicy(ctx context.Context, createPolicy models.LeaveAccrualPolicyRequest) (models.LeaveAccrualPolicy, error) {
+	policy, err := l.checkPolicy(ctx, createPolicy, 0)
+	if err != nil {
+		return models.LeaveAccrualPolicy{}, err
+	}
+
+	policy, err = l.repo.CreatePolicy(ctx, policy)
+	if err != nil {
+		return models.LeaveAccrualPolicy{}, err
+	}
+
+	l.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionCreate, EntityType: models.AuditEntityLeavePolicy, EntityID: uint64(policy.ID), After: policy})
+	return policy, nil
+}
+
+func (l *leaveAccrualServiceImpl) UpdatePolicy(ctx context.Context, id uint64, updatePolicy models.LeaveAccrualPolicyRequest) (models.LeaveAccrualPolicy, error) {
+	existing, err := l.GetPolicyByID(ctx, id)
+	if err != nil {
+		return models.LeaveAccrualPolicy{}, err
+	}
+
+	policy, err := l.checkPolicy(ctx, updatePolicy, id)
+	if err != nil {
+		return models.LeaveAccrualPolicy{}, err
+	}
+
+	policy, err = l.repo.UpdatePolicy(ctx, id, policy)
+	if err != nil {
+		return models.LeaveAccrualPolicy{}, err
+	}
+
+	l.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionUpdate, EntityType: models.AuditEntityLeavePolicy, EntityID: id, Before: existing, After: policy})
+	return policy, nil
+}
+
+// DeletePolicy removes a policy and its position assignments. Days it
+// already accrued stay on the ledger.
+func (l *leaveAccrualServiceImpl) DeletePolicy(ctx context.Context, id uint64) error {
+	existing, err := l.GetPolicyByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := l.repo.DeletePolicy(ctx, id); err != nil {
+		return err
+	}
+
+	l.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionDelete, EntityType: models.AuditEntityLeavePolicy, EntityID: id, Before: existing})
+	return nil
+}
+
+func (l *leaveAccrualServiceImpl) GetPositionPolicies(ctx context.Context, positionID uint64) ([]models.LeaveAccrualPolicy, error) {
+	if err := l.checkPosition(ctx, positionID); err != nil {
+		return []models.LeaveAccrualPolicy{}, err
+	}
+	return l.repo.GetPositionPolicies(ctx, positionID)
+}
+
+// SetPositionPolicies replaces the policies of a position. A position can
+// have at most one policy per leave type.
+func (l *leaveAccrualServiceImpl) SetPositionPolicies(ctx context.Context, positionID uint64, policyIDs []int) ([]models.LeaveAccrualPolicy, error) {
+	if err := l.checkPosition(ctx, positionID); err != nil {
+		return []models.LeaveAccrualPolicy{}, err
+	}
+	existing, err := l.repo.GetPositionPolicies(ctx, positionID)
+	if err != nil {
+		return []models.LeaveAccrualPolicy{}, err
+	}
+
+	seenPolicies := map[int]bool{}
+	seenLeaveTypes := map[int]bool{}
+	uniqueIDs := make([]int, 0, len(policyIDs))
+	for _, policyID := range policyIDs {
+		if seenPolicies[policyID] {
+			continue
+		}
+		seenPolicies[policyID] = true
+
+		policy, err := l.repo.GetPolicyByID(ctx, uint64(policyID))
+		if err != nil {
+			return []models.LeaveAccrualPolicy{}, err
+		}
+		if policy.ID == 0 {
+			return []models.LeaveAccrualPolicy{}, apperror.Validation("leave_policy_not_found", fmt.Sprintf("leave policy %d not found", policyID))
+		}
+		if seenLeaveTypes[policy.LeaveTypeID] {
+			return []models.LeaveAccrualPolicy{}, apperror.Validation("only_one_leave_policy_per_leave_type_is_allowed", "only one leave policy per leave type is allowed")
+		}
+		seenLeaveTypes[policy.LeaveTypeID] = true
+		uniqueIDs = append(uniqueIDs, policyID)
+	}
+
+	if err := l.repo.SetPositionPolicies(ctx, positionID, uniqueIDs); err != nil {
+		return []models.LeaveAccrualPolicy{}, err
+	}
+
+	policies, err := l.repo.GetPositionPolicies(ctx, positionID)
+	if err != nil {
+		return []models.LeaveAccrualPolicy{}, err
+	}
+
+	l.audit.Record(ctx, models.AuditEntry{
+		Action:     models.AuditActionUpdate,
+		EntityType: models.AuditEntityPositionLeavePolicy,
+		EntityID:   positionID,
+		Before:     map[string][]int{"policy_ids": policyIDsOf(existing)},
+		After:      map[string][]int{"policy_ids": policyIDsOf(policies)},
+	})
+	return policies, nil
+}
+
+// RunAccruals posts every accrual, carry-over and expiry that is due on date
+// and not posted yet. A zero date means today. Running it again for the
+// same date posts nothing new.
+func (l *leaveAccrualServiceImpl) RunAccruals(ctx context.Context, date models.Date) (models.AccrualRunResult, error) {
+	if date.IsZero() {
+		date = models.NewDate(time.Now().In(l.location))
+	}
+	result := models.AccrualRunResult{Date: date}
+
+	policies, err := l.repo.GetPolicies(ctx)
+	if err != nil {
+		return models.AccrualRunResult{}, err
+	}
+	policiesByID := make(map[int]models.LeaveAccrualPolicy, len(policies))
+	for _, policy := range policies {
+		policiesByID[policy.ID] = policy
+	}
+
+	assignments, err := l.repo.GetAccrualAssignments(ctx)
+	if err != nil {
+		return models.AccrualRunResult{}, err
+	}
+
+	users := map[int]bool{}
+	for _, assignment := range assignments {
+		policy, ok := policiesByID[assignment.PolicyID]
+		if !ok {
+			continue
+		}
+		users[assignment.UserID] = true
+
+		posted, err := l.accrue(ctx, assignment, policy, date)
+		if err != nil {
+			return models.AccrualRunResult{}, err
+		}
+		result.Entries += posted
+	}
+
+	result.Users = len(users)
+	if result.Entries > 0 {
+		log.Printf("posted %d leave ledger entries for %d users", result.Entries, result.Users)
+	}
+	return result, nil
+}
+
+// accrue posts what one policy owes one user on date and returns the number
+// of new ledger entries.
+func (l *leaveAccrualServiceImpl) accrue(ctx context.Context, assignment models.AccrualAssignment, policy models.LeaveAccrualPolicy, date models.Date) (int, error) {
+	hireDate := assignment.HireDate
+	if hireDate.IsZero() {
+		hireDate = models.NewDate(assignment.CreatedAt)
+	}
+
+	entries := accrualEntries(policy, assignment.UserID, hireDate, date)
+	// The job may not have run on the last days of December, so the previous
+	// year is caught up in January before its balance is carried over.
+	if date.Month() == time.January {
+		lastYearEnd := models.NewDate(time.Date(date.Year()-1, time.December, 31, 0, 0, 0, 0, time.UTC))
+		entries = append(accrualEntries(policy, assignment.UserID, hireDate, lastYearEnd), entries...)
+	}
+
+	posted := 0
+	for _, entry := range entries {
+		ok, err := l.leaveRepo.PostLedgerEntry(ctx, entry)
+		if err != nil {
+			return posted, err
+		}
+		if ok {
+			posted++
+		}
+	}
+
+	if policy.MaxCarryOver > 0 {
+		// Days carried into last year can expire at its very end, and have
+		// to be gone before what is left of last year is carried over.
+		if policy.CarryOverExpiryMonths > 0 && date.Month() == time.January {
+			ok, err := l.expireCarryOver(ctx, assignment.UserID, policy, date.Year()-1, date)
+			if err != nil {
+				return posted, err
+			}
+			if ok {
+				posted++
+			}
+		}
+
+		ok, err := l.carryOver(ctx, assignment.UserID, policy, date.Year())
+		if err != nil {
+			return posted, err
+		}
+		if ok {
+			posted++
+		}
+
+		if policy.CarryOverExpiryMonths > 0 {
+			ok, err := l.expireCarryOver(ctx, assignment.UserID, policy, date.Year(), date)
+			if err != nil {
+				return posted, err
+			}
+			if ok {
+				posted++
+			}
+		}
+	}
+	return posted, nil
+}
+
+// carryOver moves the unused days of the previous year, up to the policy's
+// maximum, into year.
+func (l *leaveAccrualServiceImpl) carryOver(ctx context.Context, userID int, policy models.LeaveAccrualPolicy, year int) (bool, error) {
+	previous, err := l.leaveRepo.GetLeaveBalance(ctx, uint64(userID), uint64(policy.LeaveTypeID), year-1)
+	if err != nil || previous.ID == 0 {
+		return false, err
+	}
+
+	days := roundDays(math.Min(previous.RemainingDays, policy.MaxCarryOver))
+	if days <= 0 {
+		return false, nil
+	}
+
+	periodKey := strconv.Itoa(year - 1)
+	return l.leaveRepo.PostLedgerEntry(ctx, models.LeaveLedgerEntry{
+		UserID:        userID,
+		LeaveTypeID:   policy.LeaveTypeID,
+		Year:          year,
+		EntryType:     models.LedgerCarryOver,
+		Days:          days,
+		PeriodKey:     &periodKey,
+		PolicyID:      &policy.ID,
+		Note:          fmt.Sprintf("carried over from %d", year-1),
+		EffectiveDate: models.NewDate(time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)),
+	})
+}
+
+// expireCarryOver removes the days carried into year that were not used by
+// the policy's expiry date, once date has reached it. Leave taken in the
+// year is counted against the carried days first.
+func (l *leaveAccrualServiceImpl) expireCarryOver(ctx context.Context, userID int, policy models.LeaveAccrualPolicy, year int, date models.Date) (bool, error) {
+	expiresOn := carryOverExpiry(year, policy.CarryOverExpiryMonths)
+	if date.Before(expiresOn) {
+		return false, nil
+	}
+
+	entries, err := l.leaveRepo.GetLedgerEntries(ctx, uint64(userID), models.LeaveLedgerFilter{Year: year, LeaveTypeID: policy.LeaveTypeID})
+	if err != nil {
+		return false, err
+	}
+
+	carried, used := 0.0, 0.0
+	for _, entry := range entries {
+		switch {
+		case entry.EntryType == models.LedgerCarryOver:
+			carried += entry.Days
+		case entry.EntryType.IsUsage() && entry.EffectiveDate.Before(expiresOn):
+			used -= entry.Days
+		}
+	}
+
+	days := roundDays(carried - used)
+	if days <= 0 {
+		return false, nil
+	}
+
+	periodKey := strconv.Itoa(year)
+	return l.leaveRepo.PostLedgerEntry(ctx, models.LeaveLedgerEntry{
+		UserID:        userID,
+		LeaveTypeID:   policy.LeaveTypeID,
+		Year:          year,
+		EntryType:     models.LedgerExpiry,
+		Days:          -days,
+		PeriodKey:     &periodKey,
+		PolicyID:      &policy.ID,
+		Note:          "unused carried over days expired",
+		EffectiveDate: expiresOn,
+	})
+}
+
+func (l *leaveAccrualServiceImpl) checkPolicy(ctx context.Context, request models.LeaveAccrualPolicyRequest, id uint64) (models.LeaveAccrualPolicy, error) {
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		return models.LeaveAccrualPolicy{}, apperror.Validation("leave_policy_name_is_required", "leave policy name is required")
+	}
+
+	existing, err := l.repo.GetPolicyByName(ctx, name)
+	if err != nil {
+		return models.LeaveAccrualPolicy{}, err
+	}
+	if existing.ID != 0 && uint64(existing.ID) != id {
+		return models.LeaveAccrualPolicy{}, apperror.Conflict("leave_policy_name_already_exists", "leave policy name already exists")
+	}
+
+	leaveType, err := l.leaveRepo.GetLeaveTypeByID(ctx, uint64(request.LeaveTypeID))
+	if err != nil {
+		return models.LeaveAccrualPolicy{}, err
+	}
+	if leaveType.ID == 0 {
+		return models.LeaveAccrualPolicy{}, apperror.NotFound("leave_type_not_found", "leave type not found")
+	}
+	if !leaveType.IsPaid {
+		return models.LeaveAccrualPolicy{}, apperror.Validation("leave_type_has_no_balance", "leave type has no balance")
+	}
+
+	return models.LeaveAccrualPolicy{
+		Name:                  name,
+		LeaveTypeID:           request.LeaveTypeID,
+		Frequency:             request.Frequency,
+		DaysPerYear:           request.DaysPerYear,
+		MaxCarryOver:          request.MaxCarryOver,
+		CarryOverExpiryMonths: request.CarryOverExpiryMonths,
+	}, nil
+}
+
+func (l *leaveAccrualServiceImpl) checkPosition(ctx context.Context, positionID uint64) error {
+	position, err := l.positionRepo.GetPositionByID(ctx, positionID)
+	if err != nil {
+		return err
+	}
+	if position.ID == 0 {
+		return apperror.NotFound("position_not_found", "position not found")
+	}
+	return nil
+}
+
+// accrualEntries lists the accruals a user has earned under policy in the
+// year of date, up to and including date. Yearly policies grant the year at
+// once and monthly ones a twelfth at the start of each month. The period a
+// user is hired in is pro-rated by the days left in it.
+func accrualEntries(policy models.LeaveAccrualPolicy, userID int, hireDate models.Date, date models.Date) []models.LeaveLedgerEntry {
+	year := date.Year()
+	entries := []models.LeaveLedgerEntry{}
+
+	type period struct {
+		key        string
+		start, end models.Date
+		days       float64
+	}
+	periods := []period{}
+
+	if policy.Frequency == models.AccrualYearly {
+		periods = append(periods, period{
+			key:   strconv.Itoa(year),
+			start: models.NewDate(time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)),
+			end:   models.NewDate(time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)),
+			days:  policy.DaysPerYear,
+		})
+	} else {
+		for month := time.January; month <= date.Month(); month++ {
+			start := models.NewDate(time.Date(year, month, 1, 0, 0, 0, 0, time.UTC))
+			periods = append(periods, period{
+				key:   fmt.Sprintf("%d-%02d", year, month),
+				start: start,
+				end:   models.NewDate(start.Time.AddDate(0, 1, -1)),
+				days:  policy.DaysPerYear / 12,
+			})
+		}
+	}
+
+	for _, p := range periods {
+		if hireDate.After(p.end) || hireDate.After(date) {
+			continue
+		}
+
+		effective := p.start
+		days := p.days
+		if hireDate.After(p.start) {
+			effective = hireDate
+			days *= daysBetween(hireDate, p.end) / daysBetween(p.start, p.end)
+		}
+		days = roundDays(days)
+		if days <= 0 {
+			continue
+		}
+
+		periodKey := p.key
+		policyID := policy.ID
+		entries = append(entries, models.LeaveLedgerEntry{
+			UserID:        userID,
+			LeaveTypeID:   policy.LeaveTypeID,
+			Year:          year,
+			EntryType:     models.LedgerAccrual,
+			Days:          days,
+			PeriodKey:     &periodKey,
+			PolicyID:      &policyID,
+			Note:          policy.Name,
+			EffectiveDate: effective,
+		})
+	}
+	return entries
+}
+
+func policyIDsOf(policies []models.LeaveAccrualPolicy) []int {
+	ids := make([]int, 0, len(policies))
+	for _, policy := range policies {
+		ids = append(ids, policy.ID)
+	}
+	return ids
+}
+
+// carryOverExpiry returns the day days carried into year expire on, months
+// after it starts. Twelve months expire them on the first day of the next
+// year.
+func carryOverExpiry(year int, months int) models.Date {
+	return models.NewDate(time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC).AddDate(0, months, 0))
+}
+
+// daysBetween counts the calendar days from start to end, both inclusive.
+func daysBetween(start models.Date, end models.Date) float64 {
+	return end.Sub(start.Time).Hours()/24 + 1
+}
+
+func roundDays(days float64) float64 {
+	return math.Round(days*100) / 100
+}
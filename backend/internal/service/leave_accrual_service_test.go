@@ -0,0 +1,150 @@
+package service
+
+import (
+	"testing"
+
+	"main.go/internal/models"
+)
+
+func date(t *testing.T, value string) models.Date {
+	t.Helper()
+	d, err := models.ParseDate(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestAccrualEntries(t *testing.T) {
+	yearly := models.LeaveAccrualPolicy{ID: 1, Name: "Annual", LeaveTypeID: 2, Frequency: models.AccrualYearly, DaysPerYear: 12}
+	monthly := models.LeaveAccrualPolicy{ID: 3, Name: "Annual", LeaveTypeID: 2, Frequency: models.AccrualMonthly, DaysPerYear: 15}
+
+	type entry struct {
+		key       string
+		effective string
+		days      float64
+	}
+	tests := []struct {
+		name     string
+		policy   models.LeaveAccrualPolicy
+		hireDate string
+		date     string
+		want     []entry
+	}{
+		{
+			name:     "yearly grants the year at once",
+			policy:   yearly,
+			hireDate: "2024-05-01",
+			date:     "2026-01-01",
+			want:     []entry{{"2026", "2026-01-01", 12}},
+		},
+		{
+			name:     "yearly pro-rated in the hire year",
+			policy:   yearly,
+			hireDate: "2026-07-02",
+			date:     "2026-07-02",
+			// 183 of 365 days.
+			want: []entry{{"2026", "2026-07-02", 6.02}},
+		},
+		{
+			name:     "yearly hired on the last day",
+			policy:   yearly,
+			hireDate: "2026-12-31",
+			date:     "2026-12-31",
+			want:     []entry{{"2026", "2026-12-31", 0.03}},
+		},
+		{
+			name:     "nothing before the hire date",
+			policy:   yearly,
+			hireDate: "2026-07-02",
+			date:     "2026-07-01",
+			want:     []entry{},
+		},
+		{
+			name:     "monthly up to the month of date",
+			policy:   monthly,
+			hireDate: "2024-05-01",
+			date:     "2026-03-15",
+			want: []entry{
+				{"2026-01", "2026-01-01", 1.25},
+				{"2026-02", "2026-02-01", 1.25},
+				{"2026-03", "2026-03-01", 1.25},
+			},
+		},
+		{
+			name:     "monthly pro-rated in the hire month",
+			policy:   monthly,
+			hireDate: "2026-02-15",
+			date:     "2026-03-01",
+			// 14 of 28 days of February.
+			want: []entry{
+				{"2026-02", "2026-02-15", 0.63},
+				{"2026-03", "2026-03-01", 1.25},
+			},
+		},
+		{
+			name:     "monthly hired on the first keeps the whole month",
+			policy:   monthly,
+			hireDate: "2026-03-01",
+			date:     "2026-03-01",
+			want:     []entry{{"2026-03", "2026-03-01", 1.25}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := accrualEntries(tt.policy, 7, date(t, tt.hireDate), date(t, tt.date))
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d entries, want %d", len(got), len(tt.want))
+			}
+			for i, want := range tt.want {
+				e := got[i]
+				if *e.PeriodKey != want.key || e.EffectiveDate.String() != want.effective || e.Days != want.days {
+					t.Errorf("entry %d = %s on %s for %v days, want %s on %s for %v days", i, *e.PeriodKey, e.EffectiveDate, e.Days, want.key, want.effective, want.days)
+				}
+				if e.UserID != 7 || e.LeaveTypeID != tt.policy.LeaveTypeID || *e.PolicyID != tt.policy.ID || e.EntryType != models.LedgerAccrual {
+					t.Errorf("entry %d = %+v, not an accrual of the policy for the user", i, e)
+				}
+			}
+		})
+	}
+}
+
+func TestDaysBetween(t *testing.T) {
+	tests := []struct {
+		start, end string
+		want       float64
+	}{
+		{"2026-03-10", "2026-03-10", 1},
+		{"2026-02-01", "2026-02-28", 28},
+		{"2028-02-01", "2028-02-29", 29},
+		{"2026-01-01", "2026-12-31", 365},
+		{"2028-01-01", "2028-12-31", 366},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.start+" to "+tt.end, func(t *testing.T) {
+			if got := daysBetween(date(t, tt.start), date(t, tt.end)); got != tt.want {
+				t.Errorf("daysBetween = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCarryOverExpiry(t *testing.T) {
+	tests := []struct {
+		months int
+		want   string
+	}{
+		{1, "2026-02-01"},
+		{3, "2026-04-01"},
+		{11, "2026-12-01"},
+		{12, "2027-01-01"},
+	}
+
+	for _, tt := range tests {
+		if got := carryOverExpiry(2026, tt.months); got.String() != tt.want {
+			t.Errorf("carryOverExpiry(2026, %d) = %s, want %s", tt.months, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,491 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"main.go/internal/apperror"
+	"main.go/internal/auth"
+	"main.go/internal/models"
+	"main.go/internal/repository"
+)
+
+type LeaveService interface {
+	GetLeaveTypes(ctx context.Context) ([]models.LeaveType, error)
+	GetLeaveBalances(ctx context.Context, userID uint64, year int) ([]models.LeaveBalance, error)
+	AdjustLeaveBalance(ctx context.Context, userID uint64, adjustment models.LeaveAdjustmentRequest) (models.LeaveLedgerEntry, error)
+	GetLedger(ctx context.Context, userID uint64, filter models.LeaveLedgerFilter) ([]models.LeaveLedgerEntry, error)
+
+	GetLeaveRequests(ctx context.Context, filter models.LeaveRequestFilter) ([]models.LeaveRequest, error)
+	GetTeamLeaveRequests(ctx context.Context, managerID uint64, filter models.LeaveRequestFilter) ([]models.LeaveRequest, error)
+	GetLeaveRequestByID(ctx context.Context, id uint64) (models.LeaveRequest, error)
+	CreateLeaveRequest(ctx context.Context, userID uint64, request models.LeaveRequestRequest) (models.LeaveRequest, error)
+	UpdateLeaveRequest(ctx context.Context, id uint64, request models.LeaveRequestRequest) (models.LeaveRequest, error)
+
+	SubmitLeaveRequest(ctx context.Context, id uint64) (models.LeaveRequest, error)
+	ApproveLeaveRequest(ctx context.Context, id uint64, note string) (models.LeaveRequest, error)
+	RejectLeaveRequest(ctx context.Context, id uint64, note string) (models.LeaveRequest, error)
+	CancelLeaveRequest(ctx context.Context, id uint64) (models.LeaveRequest, error)
+}
+
+type leaveServiceImpl struct {
+	repo     repository.LeaveQuery
+	userRepo repository.UserQuery
+	calendar WorkingDayCalculator
+	locker   PeriodLocker
+	location *time.Location
+	audit    AuditLogger
+}
+
+func NewLeaveService(repo repository.LeaveQuery, userRepo repository.UserQuery, calendar WorkingDayCalculator, locker PeriodLocker, location *time.Location, audit AuditLogger) LeaveService {
+	return &leaveServiceImpl{repo: repo, userRepo: userRepo, calendar: calendar, locker: locker, location: location, audit: audit}
+}
+
+func (l *leaveServiceImpl) GetLeaveTypes(ctx context.Context) ([]models.LeaveType, error) {
+	return l.repo.GetLeaveTypes(ctx)
+}
+
+// GetLeaveBalances returns the user's balances of every paid leave type. A
+// zero year means the current year.
+func (l *leaveServiceImpl) GetLeaveBalances(ctx context.Context, userID uint64, year int) ([]models.LeaveBalance, error) {
+	if year == 0 {
+		year = time.Now().In(l.location).Year()
+	}
+
+	user, err := l.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return []models.LeaveBalance{}, err
+	}
+	if user.Id == 0 {
+		return []models.LeaveBalance{}, apperror.NotFound("user_not_found", "user not found")
+	}
+
+	if err := l.repo.EnsureLeaveBalances(ctx, userID, year); err != nil {
+		return []models.LeaveBalance{}, err
+	}
+	return l.repo.GetLeaveBalances(ctx, userID, year)
+}
+
+// AdjustLeaveBalance posts a manual correction to the user's ledger.
+func (l *leaveServiceImpl) AdjustLeaveBalance(ctx context.Context, userID uint64, adjustment models.LeaveAdjustmentRequest) (models.LeaveLedgerEntry, error) {
+	user, err := l.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return models.LeaveLedgerEntry{}, err
+	}
+	if user.Id == 0 {
+		return models.LeaveLedgerEntry{}, apperror.NotFound("user_not_found", "user not found")
+	}
+
+	leaveType, err := l.getLeaveType(ctx, uint64(adjustment.LeaveTypeID))
+	if err != nil {
+		return models.LeaveLedgerEntry{}, err
+	}
+	if !leaveType.IsPaid {
+		return models.LeaveLedgerEntry{}, apperror.Validation("leave_type_has_no_balance", "leave type has no balance")
+	}
+
+	entry := models.LeaveLedgerEntry{
+		UserID:        int(userID),
+		LeaveTypeID:   adjustment.LeaveTypeID,
+		Year:          adjustment.Year,
+		EntryType:     models.LedgerAdjustment,
+		Days:          adjustment.Days,
+		Note:          adjustment.Note,
+		EffectiveDate: models.NewDate(time.Now().In(l.location)),
+	}
+	if _, err := l.repo.PostLedgerEntry(ctx, entry); err != nil {
+		return models.LeaveLedgerEntry{}, err
+	}
+
+	l.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionCreate, EntityType: models.AuditEntityLeaveBalance, EntityID: userID, After: entry})
+	return entry, nil
+}
+
+// GetLedger returns the user's ledger entries with the running remaining
+// balance of each leave type and year after every entry.
+func (l *leaveServiceImpl) GetLedger(ctx context.Context, userID uint64, filter models.LeaveLedgerFilter) ([]models.LeaveLedgerEntry, error) {
+	user, err := l.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return []models.LeaveLedgerEntry{}, err
+	}
+	if user.Id == 0 {
+		return []models.LeaveLedgerEntry{}, apperror.NotFound("user_not_found", "user not found")
+	}
+
+	entries, err := l.repo.GetLedgerEntries(ctx, userID, filter)
+	if err != nil {
+		return []models.LeaveLedgerEntry{}, err
+	}
+
+	type balanceKey struct{ year, leaveTypeID int }
+	balances := map[balanceKey]float64{}
+	for i := range entries {
+		key := balanceKey{entries[i].Year, entries[i].LeaveTypeID}
+		balances[key] = roundDays(balances[key] + entries[i].Days)
+		entries[i].Balance = balances[key]
+	}
+	return entries, nil
+}
+
+func (l *leaveServiceImpl) GetLeaveRequests(ctx context.Context, filter models.LeaveRequestFilter) ([]models.LeaveRequest, error) {
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		return []models.LeaveRequest{}, apperror.Validation("to_must_not_be_before_from", "to must not be before from")
+	}
+	return l.repo.GetLeaveRequests(ctx, filter)
+}
+
+// GetTeamLeaveRequests lists the requests of the manager's direct and
+// indirect reports.
+func (l *leaveServiceImpl) GetTeamLeaveRequests(ctx context.Context, managerID uint64, filter models.LeaveRequestFilter) ([]models.LeaveRequest, error) {
+	reports, err := l.userRepo.GetReports(ctx, managerID, true)
+	if err != nil {
+		return []models.LeaveRequest{}, err
+	}
+
+	filter.UserIDs = make([]uint64, 0, len(reports))
+	for _, report := range reports {
+		filter.UserIDs = append(filter.UserIDs, uint64(report.Id))
+	}
+	return l.GetLeaveRequests(ctx, filter)
+}
+
+func (l *leaveServiceImpl) GetLeaveRequestByID(ctx context.Context, id uint64) (models.LeaveRequest, error) {
+	request, err := l.getLeaveRequest(ctx, id)
+	if err != nil {
+		return models.LeaveRequest{}, err
+	}
+
+	allowed, err := l.canManage(ctx, request, auth.PermissionLeaveRead)
+	if err != nil {
+		return models.LeaveRequest{}, err
+	}
+	if !allowed && !isOwnLeaveRequest(ctx, request) {
+		return models.LeaveRequest{}, apperror.Forbidden("not_allowed_to_view_this_leave_request", "not allowed to view this leave request")
+	}
+	return request, nil
+}
+
+func (l *leaveServiceImpl) CreateLeaveRequest(ctx context.Context, userID uint64, request models.LeaveRequestRequest) (models.LeaveRequest, error) {
+	days, err := l.checkLeaveRequest(ctx, userID, request)
+	if err != nil {
+		return models.LeaveRequest{}, err
+	}
+
+	created, err := l.repo.CreateLeaveRequest(ctx, models.LeaveRequest{
+		UserID:      int(userID),
+		LeaveTypeID: request.LeaveTypeID,
+		StartDate:   request.StartDate,
+		EndDate:     request.EndDate,
+		Days:        days,
+		Reason:      request.Reason,
+		Status:      models.LeaveStatusDraft,
+	})
+	if err != nil {
+		return models.LeaveRequest{}, err
+	}
+
+	created, err = l.repo.GetLeaveRequestByID(ctx, uint64(created.ID))
+	if err != nil {
+		return models.LeaveRequest{}, err
+	}
+
+	l.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionCreate, EntityType: models.AuditEntityLeaveRequest, EntityID: uint64(created.ID), After: created})
+	return created, nil
+}
+
+func (l *leaveServiceImpl) UpdateLeaveRequest(ctx context.Context, id uint64, request models.LeaveRequestRequest) (models.LeaveRequest, error) {
+	existing, err := l.getOwnLeaveRequest(ctx, id)
+	if err != nil {
+		return models.LeaveRequest{}, err
+	}
+	if existing.Status != models.LeaveStatusDraft {
+		return models.LeaveRequest{}, apperror.Conflict("only_draft_leave_requests_can_be_changed", "only draft leave requests can be changed")
+	}
+
+	days, err := l.checkLeaveRequest(ctx, uint64(existing.UserID), request)
+	if err != nil {
+		return models.LeaveRequest{}, err
+	}
+
+	updated := existing
+	updated.LeaveTypeID = request.LeaveTypeID
+	updated.StartDate = request.StartDate
+	updated.EndDate = request.EndDate
+	updated.Days = days
+	updated.Reason = request.Reason
+
+	if _, err := l.repo.UpdateLeaveRequest(ctx, updated); err != nil {
+		if errors.Is(err, repository.ErrLeaveRequestStateChanged) {
+			return models.LeaveRequest{}, apperror.Conflict("only_draft_leave_requests_can_be_changed", "only draft leave requests can be changed")
+		}
+		return models.LeaveRequest{}, err
+	}
+	return l.auditLeaveUpdate(ctx, existing)
+}
+
+// SubmitLeaveRequest sends a draft to the approvers. Requests that overlap
+// another submitted or approved request of the same user are refused.
+func (l *leaveServiceImpl) SubmitLeaveRequest(ctx context.Context, id uint64) (models.LeaveRequest, error) {
+	request, err := l.getOwnLeaveRequest(ctx, id)
+	if err != nil {
+		return models.LeaveRequest{}, err
+	}
+	if request.Status != models.LeaveStatusDraft {
+		return models.LeaveRequest{}, apperror.Conflict("only_draft_leave_requests_can_be_submitted", "only draft leave requests can be submitted")
+	}
+
+	if request.LeaveType != nil && request.LeaveType.IsPaid {
+		balance, err := l.getLeaveBalance(ctx, request)
+		if err != nil {
+			return models.LeaveRequest{}, err
+		}
+		if balance.RemainingDays < float64(request.Days) {
+			return models.LeaveRequest{}, apperror.Conflict("insufficient_leave_balance", "insufficient leave balance")
+		}
+	}
+
+	existing := request
+	now := time.Now().UTC()
+	request.Status = models.LeaveStatusSubmitted
+	request.SubmittedAt = &now
+
+	if err := l.repo.SubmitLeaveRequest(ctx, request); err != nil {
+		return models.LeaveRequest{}, l.transitionError(err, apperror.Conflict("only_draft_leave_requests_can_be_submitted", "only draft leave requests can be submitted"))
+	}
+	return l.auditLeaveUpdate(ctx, existing)
+}
+
+// ApproveLeaveRequest approves a submitted request and takes paid leave from
+// the user's balance. Only the user's managers and leave approvers may
+// decide, and nobody decides their own request. Leave in a closed pay period
+// can't be approved anymore.
+func (l *leaveServiceImpl) ApproveLeaveRequest(ctx context.Context, id uint64, note string) (models.LeaveRequest, error) {
+	request, err := l.getDecidableLeaveRequest(ctx, id)
+	if err != nil {
+		return models.LeaveRequest{}, err
+	}
+	existing := request
+	if err := l.locker.EnsureOpen(ctx, request.StartDate, request.EndDate); err != nil {
+		return models.LeaveRequest{}, err
+	}
+
+	deduct := request.LeaveType != nil && request.LeaveType.IsPaid
+	if deduct {
+		if _, err := l.getLeaveBalance(ctx, request); err != nil {
+			return models.LeaveRequest{}, err
+		}
+	}
+
+	l.decide(ctx, &request, models.LeaveStatusApproved, note)
+	if err := l.repo.ApproveLeaveRequest(ctx, request, deduct); err != nil {
+		return models.LeaveRequest{}, l.transitionError(err, apperror.Conflict("only_submitted_leave_requests_can_be_decided", "only submitted leave requests can be decided"))
+	}
+	return l.auditLeaveUpdate(ctx, existing)
+}
+
+func (l *leaveServiceImpl) RejectLeaveRequest(ctx context.Context, id uint64, note string) (models.LeaveRequest, error) {
+	request, err := l.getDecidableLeaveRequest(ctx, id)
+	if err != nil {
+		return models.LeaveRequest{}, err
+	}
+	existing := request
+
+	l.decide(ctx, &request, models.LeaveStatusRejected, note)
+	if err := l.repo.RejectLeaveRequest(ctx, request); err != nil {
+		return models.LeaveRequest{}, l.transitionError(err, apperror.Conflict("only_submitted_leave_requests_can_be_decided", "only submitted leave requests can be decided"))
+	}
+	return l.auditLeaveUpdate(ctx, existing)
+}
+
+// CancelLeaveRequest withdraws a draft, submitted or approved request. The
+// days of an approved paid request go back to the balance. Users cancel
+// their own requests, approved ones only before they start, and leave
+// approvers can cancel anyone's. Leave in a closed pay period stays as it
+// is.
+func (l *leaveServiceImpl) CancelLeaveRequest(ctx context.Context, id uint64) (models.LeaveRequest, error) {
+	request, err := l.getLeaveRequest(ctx, id)
+	if err != nil {
+		return models.LeaveRequest{}, err
+	}
+
+	principal, _ := auth.PrincipalFromContext(ctx)
+	approver := principal.HasPermission(auth.PermissionLeaveApprove)
+	if !isOwnLeaveRequest(ctx, request) && !approver {
+		return models.LeaveRequest{}, apperror.Forbidden("not_allowed_to_change_this_leave_request", "not allowed to change this leave request")
+	}
+
+	from := request.Status
+	switch from {
+	case models.LeaveStatusDraft, models.LeaveStatusSubmitted, models.LeaveStatusApproved:
+	default:
+		return models.LeaveRequest{}, apperror.Conflict("leave_request_cannot_be_cancelled", "leave request cannot be cancelled")
+	}
+
+	// Days already taken can't turn back into balance on the user's say.
+	today := models.NewDate(time.Now().In(l.location))
+	if from == models.LeaveStatusApproved && !approver && !today.Before(request.StartDate) {
+		return models.LeaveRequest{}, apperror.Forbidden("leave_has_already_started", "leave has already started, ask a leave approver to cancel it")
+	}
+	if err := l.locker.EnsureOpen(ctx, request.StartDate, request.EndDate); err != nil {
+		return models.LeaveRequest{}, err
+	}
+
+	existing := request
+	now := time.Now().UTC()
+	request.Status = models.LeaveStatusCancelled
+	request.CancelledAt = &now
+
+	restore := from == models.LeaveStatusApproved && request.LeaveType != nil && request.LeaveType.IsPaid
+	if err := l.repo.CancelLeaveRequest(ctx, request, from, restore); err != nil {
+		return models.LeaveRequest{}, l.transitionError(err, apperror.Conflict("leave_request_cannot_be_cancelled", "leave request cannot be cancelled"))
+	}
+	return l.auditLeaveUpdate(ctx, existing)
+}
+
+// auditLeaveUpdate logs a leave request moving through its workflow, from
+// before to what is stored now, and returns the stored request.
+func (l *leaveServiceImpl) auditLeaveUpdate(ctx context.Context, before models.LeaveRequest) (models.LeaveRequest, error) {
+	request, err := l.repo.GetLeaveRequestByID(ctx, uint64(before.ID))
+	if err != nil {
+		return models.LeaveRequest{}, err
+	}
+
+	l.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionUpdate, EntityType: models.AuditEntityLeaveRequest, EntityID: uint64(request.ID), Before: before, After: request})
+	return request, nil
+}
+
+// checkLeaveRequest validates the dates and leave type of a request and
+// returns the number of working days it covers on the user's calendar.
+func (l *leaveServiceImpl) checkLeaveRequest(ctx context.Context, userID uint64, request models.LeaveRequestRequest) (int, error) {
+	if request.StartDate.IsZero() || request.EndDate.IsZero() {
+		return 0, apperror.Validation("start_date_and_end_date_are_required", "start_date and end_date are required")
+	}
+	if request.EndDate.Before(request.StartDate) {
+		return 0, apperror.Validation("end_date_must_not_be_before_start_date", "end_date must not be before start_date")
+	}
+	if request.StartDate.Year() != request.EndDate.Year() {
+		return 0, apperror.Validation("leave_request_must_not_span_two_years", "leave request must not span two years")
+	}
+	if _, err := l.getLeaveType(ctx, uint64(request.LeaveTypeID)); err != nil {
+		return 0, err
+	}
+
+	days, err := l.calendar.WorkingDays(ctx, userID, request.StartDate, request.EndDate)
+	if err != nil {
+		return 0, err
+	}
+	if days == 0 {
+		return 0, apperror.Validation("leave_request_has_no_working_days", "leave request has no working days")
+	}
+	return days, nil
+}
+
+func (l *leaveServiceImpl) getLeaveType(ctx context.Context, id uint64) (models.LeaveType, error) {
+	leaveType, err := l.repo.GetLeaveTypeByID(ctx, id)
+	if err != nil {
+		return models.LeaveType{}, err
+	}
+	if leaveType.ID == 0 {
+		return models.LeaveType{}, apperror.NotFound("leave_type_not_found", "leave type not found")
+	}
+	return leaveType, nil
+}
+
+// getLeaveBalance returns the balance the request is taken from, creating
+// the year's balances first if needed.
+func (l *leaveServiceImpl) getLeaveBalance(ctx context.Context, request models.LeaveRequest) (models.LeaveBalance, error) {
+	year := request.StartDate.Year()
+	if err := l.repo.EnsureLeaveBalances(ctx, uint64(request.UserID), year); err != nil {
+		return models.LeaveBalance{}, err
+	}
+	return l.repo.GetLeaveBalance(ctx, uint64(request.UserID), uint64(request.LeaveTypeID), year)
+}
+
+func (l *leaveServiceImpl) getLeaveRequest(ctx context.Context, id uint64) (models.LeaveRequest, error) {
+	request, err := l.repo.GetLeaveRequestByID(ctx, id)
+	if err != nil {
+		return models.LeaveRequest{}, err
+	}
+	if request.ID == 0 {
+		return models.LeaveRequest{}, apperror.NotFound("leave_request_not_found", "leave request not found")
+	}
+	return request, nil
+}
+
+func (l *leaveServiceImpl) getOwnLeaveRequest(ctx context.Context, id uint64) (models.LeaveRequest, error) {
+	request, err := l.getLeaveRequest(ctx, id)
+	if err != nil {
+		return models.LeaveRequest{}, err
+	}
+	if !isOwnLeaveRequest(ctx, request) {
+		return models.LeaveRequest{}, apperror.Forbidden("not_allowed_to_change_this_leave_request", "not allowed to change this leave request")
+	}
+	return request, nil
+}
+
+func (l *leaveServiceImpl) getDecidableLeaveRequest(ctx context.Context, id uint64) (models.LeaveRequest, error) {
+	request, err := l.getLeaveRequest(ctx, id)
+	if err != nil {
+		return models.LeaveRequest{}, err
+	}
+	if isOwnLeaveRequest(ctx, request) {
+		return models.LeaveRequest{}, apperror.Forbidden("not_allowed_to_decide_this_leave_request", "not allowed to decide this leave request")
+	}
+
+	allowed, err := l.canManage(ctx, request, auth.PermissionLeaveApprove)
+	if err != nil {
+		return models.LeaveRequest{}, err
+	}
+	if !allowed {
+		return models.LeaveRequest{}, apperror.Forbidden("not_allowed_to_decide_this_leave_request", "not allowed to decide this leave request")
+	}
+	if request.Status != models.LeaveStatusSubmitted {
+		return models.LeaveRequest{}, apperror.Conflict("only_submitted_leave_requests_can_be_decided", "only submitted leave requests can be decided")
+	}
+	return request, nil
+}
+
+// canManage tells whether the caller holds permission or is one of the
+// managers of the request's user.
+func (l *leaveServiceImpl) canManage(ctx context.Context, request models.LeaveRequest, permission string) (bool, error) {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return false, nil
+	}
+	if principal.HasPermission(permission) {
+		return true, nil
+	}
+	return l.userRepo.IsManagerOf(ctx, uint64(principal.UserID), uint64(request.UserID))
+}
+
+func (l *leaveServiceImpl) decide(ctx context.Context, request *models.LeaveRequest, status models.LeaveStatus, note string) {
+	principal, _ := auth.PrincipalFromContext(ctx)
+	decidedBy := principal.UserID
+	now := time.Now().UTC()
+
+	request.Status = status
+	request.DecidedBy = &decidedBy
+	request.DecidedAt = &now
+	request.DecisionNote = note
+}
+
+// transitionError turns repository errors of a status change into
+// errors for the client. stateErr is returned when the request was
+// changed by someone else in the meantime.
+func (l *leaveServiceImpl) transitionError(err error, stateErr error) error {
+	switch {
+	case errors.Is(err, repository.ErrLeaveRequestStateChanged):
+		return stateErr
+	case errors.Is(err, repository.ErrLeaveRequestOverlap):
+		return apperror.Conflict("leave_request_overlaps_an_existing_request", "leave request overlaps an existing request")
+	case errors.Is(err, repository.ErrInsufficientLeaveBalance):
+		return apperror.Conflict("insufficient_leave_balance", "insufficient leave balance")
+	}
+	return err
+}
+
+func isOwnLeaveRequest(ctx context.Context, request models.LeaveRequest) bool {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	return ok && principal.UserID == request.UserID
+}
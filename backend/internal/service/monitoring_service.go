@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"main.go/internal/apperror"
+	"main.go/internal/auth"
+	"main.go/internal/models"
+	"main.go/internal/repository"
+)
+
+// heartbeatClockSkew is how far in the future a client clock may be before
+// its heartbeats are rejected.
+const heartbeatClockSkew = 5 * time.Minute
+
+type MonitoringService interface {
+	RecordHeartbeats(ctx context.Context, userID uint64, heartbeats []models.HeartbeatInput) (models.HeartbeatsResult, error)
+
+	GetTeamStatus(ctx context.Context, managerID uint64) ([]models.TeamMemberStatus, error)
+	GetDailySummary(ctx context.Context, userID uint64, date models.Date) (models.DailyActivitySummary, error)
+	GetTeamSummary(ctx context.Context, managerID uint64, date models.Date) ([]models.DailyActivitySummary, error)
+}
+
+type monitoringServiceImpl struct {
+	repo     repository.HeartbeatQuery
+	userRepo repository.UserQuery
+	timeout  time.Duration
+	location *time.Location
+}
+
+func NewMonitoringService(repo repository.HeartbeatQuery, userRepo repository.UserQuery, timeout time.Duration, location *time.Location) MonitoringService {
+	return &monitoringServiceImpl{repo: repo, userRepo: userRepo, timeout: timeout, location: location}
+}
+
+// RecordHeartbeats stores a batch of heartbeats. Batches may arrive out of
+// order and may be retried, every event ID is only stored once.
+func (m *monitoringServiceImpl) RecordHeartbeats(ctx context.Context, userID uint64, heartbeats []models.HeartbeatInput) (models.HeartbeatsResult, error) {
+	latest := time.Now().Add(heartbeatClockSkew)
+	seen := make(map[string]bool, len(heartbeats))
+
+	rows := make([]models.Heartbeat, 0, len(heartbeats))
+	for _, heartbeat := range heartbeats {
+		if heartbeat.OccurredAt.After(latest) {
+			return models.HeartbeatsResult{}, apperror.Validation("heartbeat_occurred_at_is_in_the_future", "heartbeat occurred_at is in the future")
+		}
+		if seen[heartbeat.EventID] {
+			continue
+		}
+		seen[heartbeat.EventID] = true
+
+		rows = append(rows, models.Heartbeat{
+			UserID:     int(userID),
+			EventID:    heartbeat.EventID,
+			State:      heartbeat.State,
+			OccurredAt: heartbeat.OccurredAt.UTC(),
+			ReceivedAt: time.Now().UTC(),
+		})
+	}
+
+	inserted, err := m.repo.CreateHeartbeats(ctx, rows)
+	if err != nil {
+		return models.HeartbeatsResult{}, err
+	}
+
+	return models.HeartbeatsResult{
+		Accepted:   int(inserted),
+		Duplicates: len(heartbeats) - int(inserted),
+	}, nil
+}
+
+// authorizeTeam lets callers see their own team, teams of managers below
+// them and, with monitoring:read, every team.
+func (m *monitoringServiceImpl) authorizeTeam(ctx context.Context, managerID uint64) error {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return apperror.Forbidden("not_allowed_to_view_this_team", "not allowed to view this team")
+	}
+	if uint64(principal.UserID) == managerID || principal.HasPermission(auth.PermissionMonitoringRead) {
+		return nil
+	}
+
+	isManager, err := m.userRepo.IsManagerOf(ctx, uint64(principal.UserID), managerID)
+	if err != nil {
+		return err
+	}
+	if !isManager {
+		return apperror.Forbidden("not_allowed_to_view_this_team", "not allowed to view this team")
+	}
+	return nil
+}
+
+func (m *monitoringServiceImpl) GetTeamStatus(ctx context.Context, managerID uint64) ([]models.TeamMemberStatus, error) {
+	if err := m.authorizeTeam(ctx, managerID); err != nil {
+		return nil, err
+	}
+
+	reports, err := m.userRepo.GetReports(ctx, managerID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := m.repo.GetLatestHeartbeats(ctx, reportIDs(reports))
+	if err != nil {
+		return nil, err
+	}
+	latestByUser := make(map[int]models.Heartbeat, len(latest))
+	for _, heartbeat := range latest {
+		latestByUser[heartbeat.UserID] = heartbeat
+	}
+
+	now := time.Now()
+	statuses := make([]models.TeamMemberStatus, 0, len(reports))
+	for _, report := range reports {
+		status := models.TeamMemberStatus{
+			UserID:    report.Id,
+			Firstname: report.Firstname,
+			Lastname:  report.Lastname,
+			Status:    models.ActivityStateOffline,
+		}
+		if heartbeat, ok := latestByUser[report.Id]; ok {
+			lastSeenAt := heartbeat.OccurredAt
+			status.LastSeenAt = &lastSeenAt
+			if now.Sub(heartbeat.OccurredAt) <= m.timeout {
+				status.Status = heartbeat.State
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func (m *monitoringServiceImpl) GetDailySummary(ctx context.Context, userID uint64, date models.Date) (models.DailyActivitySummary, error) {
+	user, err := m.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return models.DailyActivitySummary{}, err
+	}
+	if user.Id == 0 {
+		return models.DailyActivitySummary{}, apperror.NotFound("user_not_found", "user not found")
+	}
+
+	summaries, err := m.summarize(ctx, []models.User{user}, date)
+	if err != nil {
+		return models.DailyActivitySummary{}, err
+	}
+	return summaries[0], nil
+}
+
+func (m *monitoringServiceImpl) GetTeamSummary(ctx context.Context, managerID uint64, date models.Date) ([]models.DailyActivitySummary, error) {
+	if err := m.authorizeTeam(ctx, managerID); err != nil {
+		return nil, err
+	}
+
+	reports, err := m.userRepo.GetReports(ctx, managerID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]models.User, 0, len(reports))
+	for _, report := range reports {
+		users = append(users, report.User)
+	}
+	return m.summarize(ctx, users, date)
+}
+
+// summarize builds the daily summaries of users. A zero date means today.
+func (m *monitoringServiceImpl) summarize(ctx context.Context, users []models.User, date models.Date) ([]models.DailyActivitySummary, error) {
+	if date.IsZero() {
+		date = models.NewDate(time.Now().In(m.location))
+	}
+	dayStart := date.At(m.location, 0)
+	dayEnd := date.AddDays(1).At(m.location, 0)
+
+	userIDs := make([]int, 0, len(users))
+	for _, user := range users {
+		userIDs = append(userIDs, user.Id)
+	}
+
+	// Heartbeats shortly before midnight still cover the first minutes of
+	// the day.
+	heartbeats, err := m.repo.GetHeartbeats(ctx, userIDs, dayStart.Add(-m.timeout), dayEnd)
+	if err != nil {
+		return nil, err
+	}
+	byUser := make(map[int][]models.Heartbeat, len(users))
+	for _, heartbeat := range heartbeats {
+		byUser[heartbeat.UserID] = append(byUser[heartbeat.UserID], heartbeat)
+	}
+
+	summaries := make([]models.DailyActivitySummary, 0, len(users))
+	for _, user := range users {
+		summary := summarizeActivity(byUser[user.Id], dayStart, dayEnd, m.timeout)
+		summary.UserID = user.Id
+		summary.Firstname = user.Firstname
+		summary.Lastname = user.Lastname
+		summary.Date = date
+		summaries = append(summaries, summary)
+	}
+	return summaries, nil
+}
+
+// summarizeActivity turns heartbeats into periods. Every heartbeat covers
+// the time until the next one, but at most timeout; time not covered by any
+// heartbeat is offline. Periods are clipped to [dayStart, dayEnd).
+func summarizeActivity(heartbeats []models.Heartbeat, dayStart time.Time, dayEnd time.Time, timeout time.Duration) models.DailyActivitySummary {
+	summary := models.DailyActivitySummary{
+		Sessions: []models.ActivityPeriod{},
+		IdleGaps: []models.ActivityPeriod{},
+	}
+
+	sort.Slice(heartbeats, func(i, j int) bool {
+		return heartbeats[i].OccurredAt.Before(heartbeats[j].OccurredAt)
+	})
+
+	var periods []models.ActivityPeriod
+	for i, heartbeat := range heartbeats {
+		start := heartbeat.OccurredAt
+		end := start.Add(timeout)
+		if i+1 < len(heartbeats) && heartbeats[i+1].OccurredAt.Before(end) {
+			end = heartbeats[i+1].OccurredAt
+		}
+		if start.Before(dayStart) {
+			start = dayStart
+		}
+		if end.After(dayEnd) {
+			end = dayEnd
+		}
+		if !end.After(start) {
+			continue
+		}
+
+		if n := len(periods); n > 0 {
+			last := &periods[n-1]
+			if last.End.Before(start) {
+				periods = append(periods, models.ActivityPeriod{State: models.ActivityStateOffline, Start: last.End, End: start})
+			} else if last.State == heartbeat.State {
+				last.End = end
+				continue
+			}
+		}
+		periods = append(periods, models.ActivityPeriod{State: heartbeat.State, Start: start, End: end})
+	}
+
+	for _, period := range periods {
+		period.Minutes = period.End.Sub(period.Start).Minutes()
+		switch period.State {
+		case models.ActivityStateActive:
+			summary.ActiveMinutes += period.Minutes
+			summary.Sessions = append(summary.Sessions, period)
+		case models.ActivityStateIdle:
+			summary.IdleMinutes += period.Minutes
+			summary.IdleGaps = append(summary.IdleGaps, period)
+		default:
+			summary.IdleGaps = append(summary.IdleGaps, period)
+		}
+	}
+
+	if len(periods) > 0 {
+		firstSeenAt := periods[0].Start
+		lastSeenAt := periods[len(periods)-1].End
+		summary.FirstSeenAt = &firstSeenAt
+		summary.LastSeenAt = &lastSeenAt
+	}
+	return summary
+}
+
+func reportIDs(reports []models.Report) []int {
+	ids := make([]int, 0, len(reports))
+	for _, report := range reports {
+		ids = append(ids, report.Id)
+	}
+	return ids
+}
@@ -0,0 +1,461 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"main.go/internal/apperror"
+	"main.go/internal/auth"
+	"main.go/internal/models"
+	"main.go/internal/repository"
+)
+
+const monthLayout = "2006-01"
+
+// OvertimeLinker attaches attendance records to the overtime requested for
+// their day.
+type OvertimeLinker interface {
+	LinkAttendance(ctx context.Context, attendance models.Attendance) error
+}
+
+type OvertimeService interface {
+	OvertimeLinker
+
+	GetRates(ctx context.Context) ([]models.OvertimeRate, error)
+	UpdateRates(ctx context.Context, request models.OvertimeRatesRequest) ([]models.OvertimeRate, error)
+
+	GetOvertimeRequests(ctx context.Context, filter models.OvertimeFilter) ([]models.OvertimeRequest, error)
+	GetTeamOvertimeRequests(ctx context.Context, managerID uint64, filter models.OvertimeFilter) ([]models.OvertimeRequest, error)
+	GetOvertimeRequestByID(ctx context.Context, id uint64) (models.OvertimeRequest, error)
+	CreateOvertimeRequest(ctx context.Context, userID uint64, request models.OvertimeRequestRequest) (models.OvertimeRequest, error)
+
+	ApproveOvertimeRequest(ctx context.Context, id uint64, note string) (models.OvertimeRequest, error)
+	RejectOvertimeRequest(ctx context.Context, id uint64, note string) (models.OvertimeRequest, error)
+	CancelOvertimeRequest(ctx context.Context, id uint64) (models.OvertimeRequest, error)
+
+	GetMonthlySummary(ctx context.Context, month string, userID uint64) ([]models.OvertimeSummary, error)
+}
+
+type overtimeServiceImpl struct {
+	repo           repository.OvertimeQuery
+	attendanceRepo repository.AttendanceQuery
+	userRepo       repository.UserQuery
+	calendar       CalendarService
+	locker         PeriodLocker
+	location       *time.Location
+	audit          AuditLogger
+}
+
+func NewOvertimeService(repo repository.OvertimeQuery, attendanceRepo repository.AttendanceQuery, userRepo repository.UserQuery, calendar CalendarService, locker PeriodLocker, location *time.Location, audit AuditLogger) OvertimeService {
+	return &overtimeServiceImpl{repo: repo, attendanceRepo: attendanceRepo, userRepo: userRepo, calendar: calendar, locker: locker, location: location, audit: audit}
+}
+
+func (o *overtimeServiceImpl) GetRates(ctx context.Context) ([]models.OvertimeRate, error) {
+	return o.repo.GetRates(ctx)
+}
+
+// UpdateRates changes the multipliers of future approvals. Requests that are
+// already approved keep the multiplier they were approved with.
+func (o *overtimeServiceImpl) UpdateRates(ctx context.Context, request models.OvertimeRatesRequest) ([]models.OvertimeRate, error) {
+	rates := []models.OvertimeRate{
+		{DayType: models.DayTypeWeekday, Multiplier: request.Weekday},
+		{DayType: models.DayTypeWeekend, Multiplier: request.Weekend},
+		{DayType: models.DayTypeHoliday, Multiplier: request.Holiday},
+	}
+
+	existing, err := o.repo.GetRates(ctx)
+	if err != nil {
+		return []models.OvertimeRate{}, err
+	}
+	if err := o.repo.UpdateRates(ctx, rates); err != nil {
+		return []models.OvertimeRate{}, err
+	}
+
+	updated, err := o.repo.GetRates(ctx)
+	if err != nil {
+		return []models.OvertimeRate{}, err
+	}
+
+	o.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionUpdate, EntityType: models.AuditEntityOvertimeRates, Before: existing, After: updated})
+	return updated, nil
+}
+
+func (o *overtimeServiceImpl) GetOvertimeRequests(ctx context.Context, filter models.OvertimeFilter) ([]models.OvertimeRequest, error) {
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		return []models.OvertimeRequest{}, apperror.Validation("to_must_not_be_before_from", "to must not be before from")
+	}
+	return o.repo.GetOvertimeRequests(ctx, filter)
+}
+
+// GetTeamOvertimeRequests lists the requests of the manager's direct and
+// indirect reports.
+func (o *overtimeServiceImpl) GetTeamOvertimeRequests(ctx context.Context, managerID uint64, filter models.OvertimeFilter) ([]models.OvertimeRequest, error) {
+	reports, err := o.userRepo.GetReports(ctx, managerID, true)
+	if err != nil {
+		return []models.OvertimeRequest{}, err
+	}
+
+	filter.UserIDs = make([]uint64, 0, len(reports))
+	for _, report := range reports {
+		filter.UserIDs = append(filter.UserIDs, uint64(report.Id))
+	}
+	return o.GetOvertimeRequests(ctx, filter)
+}
+
+func (o *overtimeServiceImpl) GetOvertimeRequestByID(ctx context.Context, id uint64) (models.OvertimeRequest, error) {
+	request, err := o.getOvertimeRequest(ctx, id)
+	if err != nil {
+		return models.OvertimeRequest{}, err
+	}
+
+	allowed, err := o.canManage(ctx, request, auth.PermissionOvertimeRead)
+	if err != nil {
+		return models.OvertimeRequest{}, err
+	}
+	if !allowed && !isOwnOvertimeRequest(ctx, request) {
+		return models.OvertimeRequest{}, apperror.Forbidden("not_allowed_to_view_this_overtime_request", "not allowed to view this overtime request")
+	}
+	return request, nil
+}
+
+// CreateOvertimeRequest asks for overtime. Pre-approvals are for today or
+// later, claims need a checked-out attendance of the day and can't exceed
+// the overtime it recorded.
+func (o *overtimeServiceImpl) CreateOvertimeRequest(ctx context.Context, userID uint64, request models.OvertimeRequestRequest) (models.OvertimeRequest, error) {
+	if request.WorkDate.IsZero() {
+		return models.OvertimeRequest{}, apperror.Validation("work_date_is_required", "work_date is required")
+	}
+
+	attendance, err := o.attendanceRepo.GetAttendanceByUserAndDate(ctx, userID, request.WorkDate)
+	if err != nil {
+		return models.OvertimeRequest{}, err
+	}
+
+	overtime := models.OvertimeRequest{
+		UserID:   int(userID),
+		WorkDate: request.WorkDate,
+		Kind:     request.Kind,
+		Minutes:  request.Minutes,
+		Reason:   request.Reason,
+		Status:   models.OvertimeStatusPending,
+	}
+	if attendance.ID != 0 {
+		overtime.AttendanceID = &attendance.ID
+	}
+
+	today := models.NewDate(time.Now().In(o.location))
+	switch request.Kind {
+	case models.OvertimePreApproval:
+		if request.WorkDate.Before(today) {
+			return models.OvertimeRequest{}, apperror.Validation("pre_approval_must_be_requested_before_the_work_date", "pre-approval must be requested before the work date")
+		}
+		if request.Minutes == 0 {
+			return models.OvertimeRequest{}, apperror.Validation("minutes_is_required", "minutes is required")
+		}
+	case models.OvertimeClaim:
+		if attendance.ID == 0 || attendance.CheckOutAt == nil {
+			return models.OvertimeRequest{}, apperror.Validation("no_checked_out_attendance_on_work_date", "no checked-out attendance on work_date")
+		}
+		worked := attendance.OvertimeMinutes()
+		if worked == 0 {
+			return models.OvertimeRequest{}, apperror.Validation("no_overtime_recorded_on_work_date", "no overtime recorded on work_date")
+		}
+		if overtime.Minutes == 0 {
+			overtime.Minutes = worked
+		}
+		if overtime.Minutes > worked {
+			return models.OvertimeRequest{}, apperror.Validation("overtime_claim_exceeds_recorded_overtime", "overtime claim exceeds recorded overtime")
+		}
+	}
+
+	overtime.DayType, err = o.dayType(ctx, userID, request.WorkDate)
+	if err != nil {
+		return models.OvertimeRequest{}, err
+	}
+
+	created, err := o.repo.CreateOvertimeRequest(ctx, overtime)
+	if err != nil {
+		if errors.Is(err, repository.ErrOvertimeRequestExists) {
+			return models.OvertimeRequest{}, apperror.Conflict("overtime_already_requested_for_this_day", "overtime already requested for this day")
+		}
+		return models.OvertimeRequest{}, err
+	}
+
+	created, err = o.repo.GetOvertimeRequestByID(ctx, uint64(created.ID))
+	if err != nil {
+		return models.OvertimeRequest{}, err
+	}
+
+	o.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionCreate, EntityType: models.AuditEntityOvertimeRequest, EntityID: uint64(created.ID), After: created})
+	return created, nil
+}
+
+// ApproveOvertimeRequest approves a pending request at the current rate of
+// its type of day. Only the user's managers and overtime approvers may
+// decide, and nobody decides their own request. Overtime in a closed pay
+// period can't be approved anymore.
+func (o *overtimeServiceImpl) ApproveOvertimeRequest(ctx context.Context, id uint64, note string) (models.OvertimeRequest, error) {
+	request, err := o.getDecidableOvertimeRequest(ctx, id)
+	if err != nil {
+		return models.OvertimeRequest{}, err
+	}
+	existing := request
+	if err := o.locker.EnsureOpen(ctx, request.WorkDate, request.WorkDate); err != nil {
+		return models.OvertimeRequest{}, err
+	}
+
+	rates, err := o.rates(ctx)
+	if err != nil {
+		return models.OvertimeRequest{}, err
+	}
+	multiplier := rates[request.DayType]
+	request.Multiplier = &multiplier
+
+	o.decide(ctx, &request, models.OvertimeStatusApproved, note)
+	if err := o.repo.UpdateOvertimeStatus(ctx, request, models.OvertimeStatusPending); err != nil {
+		return models.OvertimeRequest{}, o.transitionError(err, apperror.Conflict("only_pending_overtime_requests_can_be_decided", "only pending overtime requests can be decided"))
+	}
+	return o.auditOvertimeUpdate(ctx, existing)
+}
+
+func (o *overtimeServiceImpl) RejectOvertimeRequest(ctx context.Context, id uint64, note string) (models.OvertimeRequest, error) {
+	request, err := o.getDecidableOvertimeRequest(ctx, id)
+	if err != nil {
+		return models.OvertimeRequest{}, err
+	}
+	existing := request
+
+	o.decide(ctx, &request, models.OvertimeStatusRejected, note)
+	if err := o.repo.UpdateOvertimeStatus(ctx, request, models.OvertimeStatusPending); err != nil {
+		return models.OvertimeRequest{}, o.transitionError(err, apperror.Conflict("only_pending_overtime_requests_can_be_decided", "only pending overtime requests can be decided"))
+	}
+	return o.auditOvertimeUpdate(ctx, existing)
+}
+
+// CancelOvertimeRequest withdraws a pending or approved request. Users cancel
+// their own requests, overtime approvers can cancel anyone's, as long as its
+// pay period is open.
+func (o *overtimeServiceImpl) CancelOvertimeRequest(ctx context.Context, id uint64) (models.OvertimeRequest, error) {
+	request, err := o.getOvertimeRequest(ctx, id)
+	if err != nil {
+		return models.OvertimeRequest{}, err
+	}
+
+	principal, _ := auth.PrincipalFromContext(ctx)
+	if !isOwnOvertimeRequest(ctx, request) && !principal.HasPermission(auth.PermissionOvertimeApprove) {
+		return models.OvertimeRequest{}, apperror.Forbidden("not_allowed_to_change_this_overtime_request", "not allowed to change this overtime request")
+	}
+
+	from := request.Status
+	if from != models.OvertimeStatusPending && from != models.OvertimeStatusApproved {
+		return models.OvertimeRequest{}, apperror.Conflict("overtime_request_cannot_be_cancelled", "overtime request cannot be cancelled")
+	}
+	if err := o.locker.EnsureOpen(ctx, request.WorkDate, request.WorkDate); err != nil {
+		return models.OvertimeRequest{}, err
+	}
+
+	existing := request
+	now := time.Now().UTC()
+	request.Status = models.OvertimeStatusCancelled
+	request.CancelledAt = &now
+
+	if err := o.repo.UpdateOvertimeStatus(ctx, request, from); err != nil {
+		return models.OvertimeRequest{}, o.transitionError(err, apperror.Conflict("overtime_request_cannot_be_cancelled", "overtime request cannot be cancelled"))
+	}
+	return o.auditOvertimeUpdate(ctx, existing)
+}
+
+// GetMonthlySummary adds up the approved overtime of every user, or of
+// userID when it is not zero, in month. Only overtime backed by attendance
+// counts.
+func (o *overtimeServiceImpl) GetMonthlySummary(ctx context.Context, month string, userID uint64) ([]models.OvertimeSummary, error) {
+	start, err := o.parseMonth(month)
+	if err != nil {
+		return []models.OvertimeSummary{}, err
+	}
+	from := models.NewDate(start)
+	to := models.NewDate(start.AddDate(0, 1, -1))
+
+	requests, err := o.repo.GetOvertimeRequests(ctx, models.OvertimeFilter{
+		UserID: int(userID),
+		Status: models.OvertimeStatusApproved,
+		From:   from,
+		To:     to,
+	})
+	if err != nil {
+		return []models.OvertimeSummary{}, err
+	}
+	rates, err := o.rates(ctx)
+	if err != nil {
+		return []models.OvertimeSummary{}, err
+	}
+
+	summaries := map[int]*models.OvertimeSummary{}
+	weighted := map[int]float64{}
+	for i := range requests {
+		request := &requests[i]
+		summary, ok := summaries[request.UserID]
+		if !ok {
+			summary = &models.OvertimeSummary{UserID: request.UserID, Month: start.Format(monthLayout)}
+			if request.User != nil {
+				summary.Firstname = request.User.Firstname
+				summary.Lastname = request.User.Lastname
+				summary.Email = request.User.Email
+			}
+			summaries[request.UserID] = summary
+		}
+
+		minutes := request.PaidMinutes()
+		switch request.DayType {
+		case models.DayTypeWeekday:
+			summary.WeekdayMinutes += minutes
+		case models.DayTypeWeekend:
+			summary.WeekendMinutes += minutes
+		case models.DayTypeHoliday:
+			summary.HolidayMinutes += minutes
+		}
+		summary.TotalMinutes += minutes
+
+		multiplier := rates[request.DayType]
+		if request.Multiplier != nil {
+			multiplier = *request.Multiplier
+		}
+		weighted[request.UserID] += float64(minutes) * multiplier
+	}
+
+	result := make([]models.OvertimeSummary, 0, len(summaries))
+	for id, summary := range summaries {
+		summary.WeightedHours = math.Round(weighted[id]/60*100) / 100
+		result = append(result, *summary)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].UserID < result[j].UserID })
+	return result, nil
+}
+
+// auditOvertimeUpdate logs an approval, rejection or cancellation with the
+// decision fields it set, and returns the request as it is stored now.
+func (o *overtimeServiceImpl) auditOvertimeUpdate(ctx context.Context, before models.OvertimeRequest) (models.OvertimeRequest, error) {
+	request, err := o.repo.GetOvertimeRequestByID(ctx, uint64(before.ID))
+	if err != nil {
+		return models.OvertimeRequest{}, err
+	}
+
+	o.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionUpdate, EntityType: models.AuditEntityOvertimeRequest, EntityID: uint64(request.ID), Before: before, After: request})
+	return request, nil
+}
+
+func (o *overtimeServiceImpl) LinkAttendance(ctx context.Context, attendance models.Attendance) error {
+	return o.repo.LinkAttendance(ctx, attendance)
+}
+
+// dayType tells whether day is a holiday, a working weekday or otherwise a
+// weekend on the user's calendar.
+func (o *overtimeServiceImpl) dayType(ctx context.Context, userID uint64, day models.Date) (models.DayType, error) {
+	summary, err := o.calendar.GetWorkingDaysSummary(ctx, userID, day, day)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case len(summary.Holidays) > 0:
+		return models.DayTypeHoliday, nil
+	case summary.WorkingDays > 0:
+		return models.DayTypeWeekday, nil
+	}
+	return models.DayTypeWeekend, nil
+}
+
+func (o *overtimeServiceImpl) rates(ctx context.Context) (map[models.DayType]float64, error) {
+	rates, err := o.repo.GetRates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	multipliers := map[models.DayType]float64{}
+	for _, rate := range rates {
+		multipliers[rate.DayType] = rate.Multiplier
+	}
+	return multipliers, nil
+}
+
+func (o *overtimeServiceImpl) parseMonth(month string) (time.Time, error) {
+	if month == "" {
+		now := time.Now().In(o.location)
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC), nil
+	}
+	start, err := time.Parse(monthLayout, month)
+	if err != nil {
+		return time.Time{}, apperror.Validation("month_must_look_like_2006_01", "month must look like 2006-01")
+	}
+	return start, nil
+}
+
+func (o *overtimeServiceImpl) getOvertimeRequest(ctx context.Context, id uint64) (models.OvertimeRequest, error) {
+	request, err := o.repo.GetOvertimeRequestByID(ctx, id)
+	if err != nil {
+		return models.OvertimeRequest{}, err
+	}
+	if request.ID == 0 {
+		return models.OvertimeRequest{}, apperror.NotFound("overtime_request_not_found", "overtime request not found")
+	}
+	return request, nil
+}
+
+func (o *overtimeServiceImpl) getDecidableOvertimeRequest(ctx context.Context, id uint64) (models.OvertimeRequest, error) {
+	request, err := o.getOvertimeRequest(ctx, id)
+	if err != nil {
+		return models.OvertimeRequest{}, err
+	}
+	if isOwnOvertimeRequest(ctx, request) {
+		return models.OvertimeRequest{}, apperror.Forbidden("not_allowed_to_decide_this_overtime_request", "not allowed to decide this overtime request")
+	}
+
+	allowed, err := o.canManage(ctx, request, auth.PermissionOvertimeApprove)
+	if err != nil {
+		return models.OvertimeRequest{}, err
+	}
+	if !allowed {
+		return models.OvertimeRequest{}, apperror.Forbidden("not_allowed_to_decide_this_overtime_request", "not allowed to decide this overtime request")
+	}
+	if request.Status != models.OvertimeStatusPending {
+		return models.OvertimeRequest{}, apperror.Conflict("only_pending_overtime_requests_can_be_decided", "only pending overtime requests can be decided")
+	}
+	return request, nil
+}
+
+// canManage tells whether the caller holds permission or is one of the
+// managers of the request's user.
+func (o *overtimeServiceImpl) canManage(ctx context.Context, request models.OvertimeRequest, permission string) (bool, error) {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return false, nil
+	}
+	if principal.HasPermission(permission) {
+		return true, nil
+	}
+	return o.userRepo.IsManagerOf(ctx, uint64(principal.UserID), uint64(request.UserID))
+}
+
+func (o *overtimeServiceImpl) decide(ctx context.Context, request *models.OvertimeRequest, status models.OvertimeStatus, note string) {
+	principal, _ := auth.PrincipalFromContext(ctx)
+	decidedBy := principal.UserID
+	now := time.Now().UTC()
+
+	request.Status = status
+	request.DecidedBy = &decidedBy
+	request.DecidedAt = &now
+	request.DecisionNote = note
+}
+
+func (o *overtimeServiceImpl) transitionError(err error, stateErr error) error {
+	if errors.Is(err, repository.ErrOvertimeRequestStateChanged) {
+		return stateErr
+	}
+	return err
+}
+
+func isOwnOvertimeRequest(ctx context.Context, request models.OvertimeRequest) bool {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	return ok && principal.UserID == request.UserID
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"main.go/internal/apperror"
+	"main.go/internal/auth"
+	"main.go/internal/models"
+	"main.go/internal/repository"
+)
+
+// maxPayPeriodDays bounds the length of a pay period.
+const maxPayPeriodDays = 366
+
+// PeriodLocker guards the days of closed pay periods against changes.
+type PeriodLocker interface {
+	// EnsureOpen fails with "pay period is closed" when any day from from
+	// to to falls in a closed pay period.
+	EnsureOpen(ctx context.Context, from, to models.Date) error
+}
+
+type PayPeriodService interface {
+	PeriodLocker
+
+	GetPayPeriods(ctx context.Context, filter models.PayPeriodFilter) ([]models.PayPeriod, error)
+	GetPayPeriodByID(ctx context.Context, id uint64) (models.PayPeriod, error)
+	GetPayPeriodEvents(ctx context.Context, id uint64) ([]models.PayPeriodEvent, error)
+	CreatePayPeriod(ctx context.Context, request models.PayPeriodRequest) (models.PayPeriod, error)
+
+	ClosePayPeriod(ctx context.Context, id uint64, reason string) (models.PayPeriod, error)
+	ReopenPayPeriod(ctx context.Context, id uint64, reason string) (models.PayPeriod, error)
+}
+
+type payPeriodServiceImpl struct {
+	repo  repository.PayPeriodQuery
+	audit AuditLogger
+}
+
+func NewPayPeriodService(repo repository.PayPeriodQuery, audit AuditLogger) PayPeriodService {
+	return &payPeriodServiceImpl{repo: repo, audit: audit}
+}
+
+func (p *payPeriodServiceImpl) GetPayPeriods(ctx context.Context, filter models.PayPeriodFilter) ([]models.PayPeriod, error) {
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		return []models.PayPeriod{}, apperror.Validation("to_must_not_be_before_from", "to must not be before from")
+	}
+	return p.repo.GetPayPeriods(ctx, filter)
+}
+
+func (p *payPeriodServiceImpl) GetPayPeriodByID(ctx context.Context, id uint64) (models.PayPeriod, error) {
+	period, err := p.repo.GetPayPeriodByID(ctx, id)
+	if err != nil {
+		return models.PayPeriod{}, err
+	}
+	if period.ID == 0 {
+		return models.PayPeriod{}, apperror.NotFound("pay_period_not_found", "pay period not found")
+	}
+	return period, nil
+}
+
+func (p *payPeriodServiceImpl) GetPayPeriodEvents(ctx context.Context, id uint64) ([]models.PayPeriodEvent, error) {
+	if _, err := p.GetPayPeriodByID(ctx, id); err != nil {
+		return []models.PayPeriodEvent{}, err
+	}
+	return p.repo.GetPayPeriodEvents(ctx, id)
+}
+
+// CreatePayPeriod opens a pay period. Pay periods can't share days.
+func (p *payPeriodServiceImpl) CreatePayPeriod(ctx context.Context, request models.PayPeriodRequest) (models.PayPeriod, error) {
+	if request.StartDate.IsZero() || request.EndDate.IsZero() {
+		return models.PayPeriod{}, apperror.Validation("start_date_and_end_date_are_required", "start_date and end_date are required")
+	}
+	if request.EndDate.Before(request.StartDate) {
+		return models.PayPeriod{}, apperror.Validation("end_date_must_not_be_before_start_date", "end_date must not be before start_date")
+	}
+	if request.StartDate.AddDays(maxPayPeriodDays).Before(request.EndDate) {
+		return models.PayPeriod{}, apperror.Validation("pay_period_is_too_long", "pay period is too long")
+	}
+
+	period, err := p.repo.CreatePayPeriod(ctx, models.PayPeriod{
+		StartDate: request.StartDate,
+		EndDate:   request.EndDate,
+		Status:    models.PayPeriodStatusOpen,
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrPayPeriodOverlap) {
+			return models.PayPeriod{}, apperror.Conflict("pay_period_overlaps_another_pay_period", "pay period overlaps another pay period")
+		}
+		return models.PayPeriod{}, err
+	}
+
+	p.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionCreate, EntityType: models.AuditEntityPayPeriod, EntityID: uint64(period.ID), After: period})
+	return period, nil
+}
+
+// ClosePayPeriod locks the attendance and timesheets of the period's days.
+func (p *payPeriodServiceImpl) ClosePayPeriod(ctx context.Context, id uint64, reason string) (models.PayPeriod, error) {
+	period, err := p.GetPayPeriodByID(ctx, id)
+	if err != nil {
+		return models.PayPeriod{}, err
+	}
+	if period.Status != models.PayPeriodStatusOpen {
+		return models.PayPeriod{}, apperror.Conflict("pay_period_is_already_closed", "pay period is already closed")
+	}
+	existing := period
+
+	principal, _ := auth.PrincipalFromContext(ctx)
+	closedBy := principal.UserID
+	now := time.Now().UTC()
+	period.Status = models.PayPeriodStatusClosed
+	period.ClosedBy = &closedBy
+	period.ClosedAt = &now
+
+	if err := p.setStatus(ctx, period, models.PayPeriodStatusOpen, models.PayPeriodActionClose, reason); err != nil {
+		return models.PayPeriod{}, p.transitionError(err, apperror.Conflict("pay_period_is_already_closed", "pay period is already closed"))
+	}
+	return p.auditPayPeriodUpdate(ctx, existing)
+}
+
+// ReopenPayPeriod unlocks a closed period again. Unlike closing it needs a
+// reason, which stays in the period's events.
+func (p *payPeriodServiceImpl) ReopenPayPeriod(ctx context.Context, id uint64, reason string) (models.PayPeriod, error) {
+	if strings.TrimSpace(reason) == "" {
+		return models.PayPeriod{}, apperror.Validation("reason_is_required_to_reopen_a_pay_period", "reason is required to reopen a pay period")
+	}
+
+	period, err := p.GetPayPeriodByID(ctx, id)
+	if err != nil {
+		return models.PayPeriod{}, err
+	}
+	if period.Status != models.PayPeriodStatusClosed {
+		return models.PayPeriod{}, apperror.Conflict("pay_period_is_not_closed", "pay period is not closed")
+	}
+	existing := period
+
+	period.Status = models.PayPeriodStatusOpen
+	period.ClosedBy = nil
+	period.ClosedAt = nil
+
+	if err := p.setStatus(ctx, period, models.PayPeriodStatusClosed, models.PayPeriodActionReopen, reason); err != nil {
+		return models.PayPeriod{}, p.transitionError(err, apperror.Conflict("pay_period_is_not_closed", "pay period is not closed"))
+	}
+	return p.auditPayPeriodUpdate(ctx, existing)
+}
+
+func (p *payPeriodServiceImpl) EnsureOpen(ctx context.Context, from, to models.Date) error {
+	closed, err := p.repo.HasClosedPayPeriod(ctx, from, to)
+	if err != nil {
+		return err
+	}
+	if closed {
+		return apperror.Conflict("pay_period_is_closed", "pay period is closed")
+	}
+	return nil
+}
+
+func (p *payPeriodServiceImpl) setStatus(ctx context.Context, period models.PayPeriod, from models.PayPeriodStatus, action models.PayPeriodAction, reason string) error {
+	event := models.PayPeriodEvent{Action: action, Reason: strings.TrimSpace(reason)}
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		userID := principal.UserID
+		event.UserID = &userID
+	}
+	return p.repo.UpdatePayPeriodStatus(ctx, period, from, event)
+}
+
+// auditPayPeriodUpdate logs closing or reopening a period and returns the
+// period as it is stored now.
+func (p *payPeriodServiceImpl) auditPayPeriodUpdate(ctx context.Context, before models.PayPeriod) (models.PayPeriod, error) {
+	period, err := p.GetPayPeriodByID(ctx, uint64(before.ID))
+	if err != nil {
+		return models.PayPeriod{}, err
+	}
+
+	p.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionUpdate, EntityType: models.AuditEntityPayPeriod, EntityID: uint64(period.ID), Before: before, After: period})
+	return period, nil
+}
+
+func (p *payPeriodServiceImpl) transitionError(err error, stateErr error) error {
+	if errors.Is(err, repository.ErrPayPeriodStateChanged) {
+		return stateErr
+	}
+	return err
+}
@@ -0,0 +1,484 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"main.go/internal/apperror"
+	"main.go/internal/auth"
+	"main.go/internal/models"
+	"main.go/internal/payroll"
+	"main.go/internal/repository"
+)
+
+// BankTransfer is one line of a payroll run's bank transfer file.
+type BankTransfer struct {
+	Payslip models.Payslip
+	Account models.BankAccount
+}
+
+type PayrollService interface {
+	GetPayrollRuns(ctx context.Context, filter models.PayrollRunFilter) ([]models.PayrollRun, error)
+	GetPayrollRunByID(ctx context.Context, id uint64) (models.PayrollRun, error)
+	CreatePayrollRun(ctx context.Context, request models.PayrollRunRequest) (models.PayrollRun, error)
+	DeletePayrollRun(ctx context.Context, id uint64) error
+	CalculatePayrollRun(ctx context.Context, id uint64) (models.PayrollRun, error)
+	FinalizePayrollRun(ctx context.Context, id uint64) (models.PayrollRun, error)
+
+	GetRunPayslips(ctx context.Context, runID uint64) ([]models.Payslip, error)
+	GetUserPayslips(ctx context.Context, userID uint64) ([]models.Payslip, error)
+	GetPayslipByID(ctx context.Context, id uint64) (models.Payslip, error)
+	GetFinalizedPayslip(ctx context.Context, id uint64) (models.Payslip, error)
+	GetBankTransfers(ctx context.Context, runID uint64) ([]BankTransfer, error)
+
+	GetBankAccount(ctx context.Context, userID uint64) (models.BankAccount, error)
+	SaveBankAccount(ctx context.Context, userID uint64, request models.BankAccountRequest) (models.BankAccount, error)
+}
+
+type payrollServiceImpl struct {
+	repo             repository.PayrollQuery
+	payPeriodRepo    repository.PayPeriodQuery
+	compensationRepo repository.CompensationQuery
+	attendanceRepo   repository.AttendanceQuery
+	overtimeRepo     repository.OvertimeQuery
+	leaveRepo        repository.LeaveQuery
+	userRepo         repository.UserQuery
+	deductionRepo    repository.DeductionRuleQuery
+	calendar         WorkingDayCalculator
+	engine           *payroll.Engine
+	hoursPerWeek     decimal.Decimal
+	audit            AuditLogger
+}
+
+func NewPayrollService(
+	repo repository.PayrollQuery,
+	payPeriodRepo repository.PayPeriodQuery,
+	compensationRepo repository.CompensationQuery,
+	attendanceRepo repository.AttendanceQuery,
+	overtimeRepo repository.OvertimeQuery,
+	leaveRepo repository.LeaveQuery,
+	userRepo repository.UserQuery,
+	deductionRepo repository.DeductionRuleQuery,
+	calendar WorkingDayCalculator,
+	engine *payroll.Engine,
+	hoursPerWeek float64,
+	audit AuditLogger,
+) PayrollService {
+	return &payrollServiceImpl{
+		repo:             repo,
+		payPeriodRepo:    payPeriodRepo,
+		compensationRepo: compensationRepo,
+		attendanceRepo:   attendanceRepo,
+		overtimeRepo:     overtimeRepo,
+		leaveRepo:        leaveRepo,
+		userRepo:         userRepo,
+		deductionRepo:    deductionRepo,
+		calendar:         calendar,
+		engine:           engine,
+		hoursPerWeek:     decimal.NewFromFloat(hoursPerWeek),
+		audit:            audit,
+	}
+}
+
+func (p *payrollServiceImpl) GetPayrollRuns(ctx context.Context, filter models.PayrollRunFilter) ([]models.PayrollRun, error) {
+	return p.repo.GetPayrollRuns(ctx, filter)
+}
+
+func (p *payrollServiceImpl) GetPayrollRunByID(ctx context.Context, id uint64) (models.PayrollRun, error) {
+	run, err := p.repo.GetPayrollRunByID(ctx, id)
+	if err != nil {
+		return models.PayrollRun{}, err
+	}
+	if run.ID == 0 {
+		return models.PayrollRun{}, apperror.NotFound("payroll_run_not_found", "payroll run not found")
+	}
+	return run, nil
+}
+
+// CreatePayrollRun starts the run of a pay period. The pay date defaults to
+// the last day of the period.
+func (p *payrollServiceImpl) CreatePayrollRun(ctx context.Context, request models.PayrollRunRequest) (models.PayrollRun, error) {
+	period, err := p.payPeriodRepo.GetPayPeriodByID(ctx, uint64(request.PayPeriodID))
+	if err != nil {
+		return models.PayrollRun{}, err
+	}
+	if period.ID == 0 {
+		return models.PayrollRun{}, apperror.NotFound("pay_period_not_found", "pay period not found")
+	}
+
+	run := models.PayrollRun{
+		PayPeriodID: period.ID,
+		PayDate:     request.PayDate,
+		Status:      models.PayrollRunStatusDraft,
+	}
+	if run.PayDate.IsZero() {
+		run.PayDate = period.EndDate
+	}
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		createdBy := principal.UserID
+		run.CreatedBy = &createdBy
+	}
+
+	created, err := p.repo.CreatePayrollRun(ctx, run)
+	if err != nil {
+		if errors.Is(err, repository.ErrPayrollRunExists) {
+			return models.PayrollRun{}, apperror.Conflict("pay_period_already_has_a_payroll_run", "pay period already has a payroll run")
+		}
+		return models.PayrollRun{}, err
+	}
+
+	created, err = p.repo.GetPayrollRunByID(ctx, uint64(created.ID))
+	if err != nil {
+		return models.PayrollRun{}, err
+	}
+
+	p.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionCreate, EntityType: models.AuditEntityPayrollRun, EntityID: uint64(created.ID), After: created})
+	return created, nil
+}
+
+func (p *payrollServiceImpl) DeletePayrollRun(ctx context.Context, id uint64) error {
+	existing, err := p.GetPayrollRunByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := p.repo.DeletePayrollRun(ctx, id); err != nil {
+		return p.transitionError(err)
+	}
+
+	p.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionDelete, EntityType: models.AuditEntityPayrollRun, EntityID: id, Before: existing})
+	return nil
+}
+
+// CalculatePayrollRun works out a payslip for every employee with a
+// compensation in the run's pay period, replacing earlier results. It can
+// be repeated until the run is finalized.
+func (p *payrollServiceImpl) CalculatePayrollRun(ctx context.Context, id uint64) (models.PayrollRun, error) {
+	run, err := p.GetPayrollRunByID(ctx, id)
+	if err != nil {
+		return models.PayrollRun{}, err
+	}
+	if run.Status == models.PayrollRunStatusFinalized {
+		return models.PayrollRun{}, apperror.Conflict("payroll_run_is_finalized", "payroll run is finalized")
+	}
+	existing := run
+	from, to := run.PayPeriod.StartDate, run.PayPeriod.EndDate
+
+	inputs, err := p.inputs(ctx, from, to)
+	if err != nil {
+		return models.PayrollRun{}, err
+	}
+	if err := p.addDeductionRules(ctx, inputs, run.PayDate); err != nil {
+		return models.PayrollRun{}, err
+	}
+
+	payslips := make([]models.Payslip, 0, len(inputs))
+	for _, input := range inputs {
+		payslip, err := p.engine.Calculate(input)
+		if err != nil {
+			return models.PayrollRun{}, err
+		}
+		payslips = append(payslips, payslip)
+	}
+
+	now := time.Now().UTC()
+	run.CalculatedAt = &now
+	if err := p.repo.SavePayslips(ctx, run, payslips); err != nil {
+		return models.PayrollRun{}, p.transitionError(err)
+	}
+	return p.auditPayrollRunUpdate(ctx, existing)
+}
+
+// FinalizePayrollRun locks a calculated run. Its pay period has to be closed
+// first, so the attendance it was calculated from can't change anymore.
+func (p *payrollServiceImpl) FinalizePayrollRun(ctx context.Context, id uint64) (models.PayrollRun, error) {
+	run, err := p.GetPayrollRunByID(ctx, id)
+	if err != nil {
+		return models.PayrollRun{}, err
+	}
+	switch run.Status {
+	case models.PayrollRunStatusDraft:
+		return models.PayrollRun{}, apperror.Conflict("payroll_run_is_not_calculated", "payroll run is not calculated")
+	case models.PayrollRunStatusFinalized:
+		return models.PayrollRun{}, apperror.Conflict("payroll_run_is_finalized", "payroll run is finalized")
+	}
+	if run.PayPeriod.Status != models.PayPeriodStatusClosed {
+		return models.PayrollRun{}, apperror.Conflict("pay_period_must_be_closed_before_finalizing", "pay period must be closed before finalizing")
+	}
+
+	existing := run
+	principal, _ := auth.PrincipalFromContext(ctx)
+	finalizedBy := principal.UserID
+	now := time.Now().UTC()
+	run.FinalizedBy = &finalizedBy
+	run.FinalizedAt = &now
+
+	if err := p.repo.FinalizePayrollRun(ctx, run); err != nil {
+		return models.PayrollRun{}, p.transitionError(err)
+	}
+	return p.auditPayrollRunUpdate(ctx, existing)
+}
+
+// auditPayrollRunUpdate reloads a run changed from before and records the
+// change.
+func (p *payrollServiceImpl) auditPayrollRunUpdate(ctx context.Context, before models.PayrollRun) (models.PayrollRun, error) {
+	run, err := p.repo.GetPayrollRunByID(ctx, uint64(before.ID))
+	if err != nil {
+		return models.PayrollRun{}, err
+	}
+
+	p.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionUpdate, EntityType: models.AuditEntityPayrollRun, EntityID: uint64(run.ID), Before: before, After: run})
+	return run, nil
+}
+
+func (p *payrollServiceImpl) GetRunPayslips(ctx context.Context, runID uint64) ([]models.Payslip, error) {
+	if _, err := p.GetPayrollRunByID(ctx, runID); err != nil {
+		return []models.Payslip{}, err
+	}
+	return p.repo.GetPayslips(ctx, models.PayslipFilter{PayrollRunID: int(runID)})
+}
+
+// GetUserPayslips lists the finalized payslips of a user.
+func (p *payrollServiceImpl) GetUserPayslips(ctx context.Context, userID uint64) ([]models.Payslip, error) {
+	return p.repo.GetPayslips(ctx, models.PayslipFilter{UserID: int(userID), FinalizedOnly: true})
+}
+
+// GetPayslipByID returns a payslip to payroll staff, or a finalized one to
+// its employee.
+func (p *payrollServiceImpl) GetPayslipByID(ctx context.Context, id uint64) (models.Payslip, error) {
+	payslip, err := p.repo.GetPayslipByID(ctx, id)
+	if err != nil {
+		return models.Payslip{}, err
+	}
+	if payslip.ID == 0 {
+		return models.Payslip{}, apperror.NotFound("payslip_not_found", "payslip not found")
+	}
+
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if ok && principal.HasPermission(auth.PermissionPayrollRead) {
+		return payslip, nil
+	}
+	if !ok || principal.UserID != payslip.UserID || payslip.PayrollRun.Status != models.PayrollRunStatusFinalized {
+		// Other people's pay stays hidden, not just forbidden.
+		return models.Payslip{}, apperror.NotFound("payslip_not_found", "payslip not found")
+	}
+	return payslip, nil
+}
+
+// GetFinalizedPayslip is GetPayslipByID for payslips that can't change
+// anymore, like the ones handed out as PDF.
+func (p *payrollServiceImpl) GetFinalizedPayslip(ctx context.Context, id uint64) (models.Payslip, error) {
+	payslip, err := p.GetPayslipByID(ctx, id)
+	if err != nil {
+		return models.Payslip{}, err
+	}
+	if payslip.PayrollRun.Status != models.PayrollRunStatusFinalized {
+		return models.Payslip{}, apperror.Conflict("payroll_run_is_not_finalized", "payroll run is not finalized")
+	}
+	return payslip, nil
+}
+
+// GetBankTransfers pairs every payslip of a finalized run with the bank
+// account its net pay goes to. Every employee with pay to transfer needs a
+// bank account.
+func (p *payrollServiceImpl) GetBankTransfers(ctx context.Context, runID uint64) ([]BankTransfer, error) {
+	run, err := p.GetPayrollRunByID(ctx, runID)
+	if err != nil {
+		return []BankTransfer{}, err
+	}
+	if run.Status != models.PayrollRunStatusFinalized {
+		return []BankTransfer{}, apperror.Conflict("payroll_run_is_not_finalized", "payroll run is not finalized")
+	}
+
+	payslips, err := p.repo.GetPayslips(ctx, models.PayslipFilter{PayrollRunID: run.ID})
+	if err != nil {
+		return []BankTransfer{}, err
+	}
+	userIDs := make([]int, 0, len(payslips))
+	for _, payslip := range payslips {
+		userIDs = append(userIDs, payslip.UserID)
+	}
+	accounts, err := p.repo.GetBankAccounts(ctx, userIDs)
+	if err != nil {
+		return []BankTransfer{}, err
+	}
+	byUser := map[int]models.BankAccount{}
+	for _, account := range accounts {
+		byUser[account.UserID] = account
+	}
+
+	transfers := []BankTransfer{}
+	for _, payslip := range payslips {
+		if !payslip.NetPay.IsPositive() {
+			continue
+		}
+		account, ok := byUser[payslip.UserID]
+		if !ok {
+			return []BankTransfer{}, apperror.Conflict("some_employees_in_the_payroll_run_have_no_bank_account", "some employees in the payroll run have no bank account")
+		}
+		transfers = append(transfers, BankTransfer{Payslip: payslip, Account: account})
+	}
+	return transfers, nil
+}
+
+func (p *payrollServiceImpl) GetBankAccount(ctx context.Context, userID uint64) (models.BankAccount, error) {
+	account, err := p.repo.GetBankAccount(ctx, userID)
+	if err != nil {
+		return models.BankAccount{}, err
+	}
+	if account.ID == 0 {
+		return models.BankAccount{}, apperror.NotFound("bank_account_not_found", "bank account not found")
+	}
+	return account, nil
+}
+
+func (p *payrollServiceImpl) SaveBankAccount(ctx context.Context, userID uint64, request models.BankAccountRequest) (models.BankAccount, error) {
+	user, err := p.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return models.BankAccount{}, err
+	}
+	if user.Id == 0 {
+		return models.BankAccount{}, apperror.NotFound("user_not_found", "user not found")
+	}
+
+	existing, err := p.repo.GetBankAccount(ctx, userID)
+	if err != nil {
+		return models.BankAccount{}, err
+	}
+
+	account, err := p.repo.SaveBankAccount(ctx, models.BankAccount{
+		UserID:        int(userID),
+		BankName:      request.BankName,
+		AccountNumber: request.AccountNumber,
+		AccountHolder: request.AccountHolder,
+	})
+	if err != nil {
+		return models.BankAccount{}, err
+	}
+
+	if existing.ID == 0 {
+		p.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionCreate, EntityType: models.AuditEntityBankAccount, EntityID: uint64(account.ID), After: account})
+	} else {
+		p.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionUpdate, EntityType: models.AuditEntityBankAccount, EntityID: uint64(account.ID), Before: existing, After: account})
+	}
+	return account, nil
+}
+
+// inputs gathers the compensation, attendance, approved overtime and unpaid
+// leave of every paid employee from from to to. Employees who left during
+// the period are paid up to the day they left.
+func (p *payrollServiceImpl) inputs(ctx context.Context, from, to models.Date) ([]payroll.Input, error) {
+	compensations, err := p.compensationRepo.GetCompensationsBetween(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := []int{}
+	compensationsByUser := map[int][]models.Compensation{}
+	for _, compensation := range compensations {
+		if _, ok := compensationsByUser[compensation.UserID]; !ok {
+			userIDs = append(userIDs, compensation.UserID)
+		}
+		compensationsByUser[compensation.UserID] = append(compensationsByUser[compensation.UserID], compensation)
+	}
+
+	inputs := make([]payroll.Input, 0, len(userIDs))
+	for _, userID := range userIDs {
+		userCompensations := compensationsByUser[userID]
+		input := payroll.Input{UserID: userID, From: from, To: to, Compensations: userCompensations, HoursPerWeek: p.hoursPerWeek}
+		if user := userCompensations[0].User; user != nil && user.DeletedAt.Valid {
+			if left := models.NewDate(user.DeletedAt.Time); left.Before(to) {
+				input.To = left
+			}
+		}
+		inputs = append(inputs, input)
+	}
+	byUser := make(map[int]*payroll.Input, len(inputs))
+	for i := range inputs {
+		byUser[inputs[i].UserID] = &inputs[i]
+	}
+
+	attendances, err := p.attendanceRepo.GetAttendances(ctx, models.AttendanceFilter{From: from, To: to})
+	if err != nil {
+		return nil, err
+	}
+	for _, attendance := range attendances {
+		if input, ok := byUser[attendance.UserID]; ok && attendance.CheckOutAt != nil {
+			input.Attendances = append(input.Attendances, attendance)
+		}
+	}
+
+	overtime, err := p.overtimeRepo.GetOvertimeRequests(ctx, models.OvertimeFilter{Status: models.OvertimeStatusApproved, From: from, To: to})
+	if err != nil {
+		return nil, err
+	}
+	for _, request := range overtime {
+		if input, ok := byUser[request.UserID]; ok {
+			input.Overtime = append(input.Overtime, request)
+		}
+	}
+
+	leaves, err := p.leaveRepo.GetLeaveRequests(ctx, models.LeaveRequestFilter{Status: models.LeaveStatusApproved, From: from, To: to})
+	if err != nil {
+		return nil, err
+	}
+	for _, leave := range leaves {
+		input, ok := byUser[leave.UserID]
+		if !ok || leave.LeaveType == nil || leave.LeaveType.IsPaid {
+			continue
+		}
+		start, end := leave.StartDate, leave.EndDate
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(input.To) {
+			end = input.To
+		}
+		if end.Before(start) {
+			continue
+		}
+		days, err := p.calendar.WorkingDays(ctx, uint64(leave.UserID), start, end)
+		if err != nil {
+			return nil, err
+		}
+		input.UnpaidLeaveDays += days
+	}
+
+	for i := range inputs {
+		inputs[i].WorkingDays, err = p.calendar.WorkingDays(ctx, uint64(inputs[i].UserID), from, inputs[i].To)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return inputs, nil
+}
+
+// addDeductionRules gives every input the deduction rule set of its
+// currency in effect on the pay date.
+func (p *payrollServiceImpl) addDeductionRules(ctx context.Context, inputs []payroll.Input, payDate models.Date) error {
+	sets := map[string]*models.DeductionRuleSet{}
+	for i := range inputs {
+		currency := inputs[i].Compensations[0].Currency
+		set, ok := sets[currency]
+		if !ok {
+			found, err := p.deductionRepo.GetDeductionRuleSetAt(ctx, currency, payDate)
+			if err != nil {
+				return err
+			}
+			if found.ID != 0 {
+				set = &found
+			}
+			sets[currency] = set
+		}
+		inputs[i].DeductionRules = set
+	}
+	return nil
+}
+
+func (p *payrollServiceImpl) transitionError(err error) error {
+	if errors.Is(err, repository.ErrPayrollRunStateChanged) {
+		return apperror.Conflict("payroll_run_is_finalized", "payroll run is finalized")
+	}
+	return err
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"main.go/internal/apperror"
+	"main.go/internal/models"
+	"main.go/internal/repository"
+)
+
+type PositionService interface {
+	GetPositions(ctx context.Context) ([]models.Position, error)
+	GetPositionByID(ctx context.Context, id uint64) (models.Position, error)
+
+	CreatePosition(ctx context.Context, createPosition models.PositionRequest) (models.Position, error)
+	UpdatePosition(ctx context.Context, id uint64, updatePosition models.PositionRequest) (models.Position, error)
+	DeletePosition(ctx context.Context, id uint64) error
+}
+
+type positionServiceImpl struct {
+	repo  repository.PositionQuery
+	audit AuditLogger
+}
+
+func NewPositionService(repo repository.PositionQuery, audit AuditLogger) PositionService {
+	return &positionServiceImpl{repo: repo, audit: audit}
+}
+
+func (p *positionServiceImpl) GetPositions(ctx context.Context) ([]models.Position, error) {
+	return p.repo.GetPositions(ctx)
+}
+
+func (p *positionServiceImpl) GetPositionByID(ctx context.Context, id uint64) (models.Position, error) {
+	position, err := p.repo.GetPositionByID(ctx, id)
+	if err != nil {
+		return models.Position{}, err
+	}
+	if position.ID == 0 {
+		return models.Position{}, apperror.NotFound("position_not_found", "position not found")
+	}
+	return position, nil
+}
+
+func (p *positionServiceImpl) CreatePosition(ctx context.Context, createPosition models.PositionRequest) (models.Position, error) {
+	name := strings.TrimSpace(createPosition.Name)
+	if err := p.checkPositionName(ctx, name, 0); err != nil {
+		return models.Position{}, err
+	}
+	position, err := p.repo.CreatePosition(ctx, models.Position{Name: name})
+	if err != nil {
+		return models.Position{}, err
+	}
+
+	p.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionCreate, EntityType: models.AuditEntityPosition, EntityID: uint64(position.ID), After: position})
+	return position, nil
+}
+
+func (p *positionServiceImpl) UpdatePosition(ctx context.Context, id uint64, updatePosition models.PositionRequest) (models.Position, error) {
+	existing, err := p.GetPositionByID(ctx, id)
+	if err != nil {
+		return models.Position{}, err
+	}
+
+	name := strings.TrimSpace(updatePosition.Name)
+	if err := p.checkPositionName(ctx, name, id); err != nil {
+		return models.Position{}, err
+	}
+	position, err := p.repo.UpdatePosition(ctx, id, models.Position{Name: name})
+	if err != nil {
+		return models.Position{}, err
+	}
+
+	p.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionUpdate, EntityType: models.AuditEntityPosition, EntityID: id, Before: existing, After: position})
+	return position, nil
+}
+
+func (p *positionServiceImpl) DeletePosition(ctx context.Context, id uint64) error {
+	existing, err := p.GetPositionByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := p.repo.DeletePosition(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrPositionInUse) {
+			return apperror.Conflict("position_is_still_assigned_to_users", "position is still assigned to users")
+		}
+		return err
+	}
+
+	p.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionDelete, EntityType: models.AuditEntityPosition, EntityID: id, Before: existing})
+	return nil
+}
+
+func (p *positionServiceImpl) checkPositionName(ctx context.Context, name string, id uint64) error {
+	if name == "" {
+		return apperror.Validation("position_name_is_required", "position name is required")
+	}
+	existing, err := p.repo.GetPositionByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	if existing.ID != 0 && uint64(existing.ID) != id {
+		return apperror.Conflict("position_name_already_exists", "position name already exists")
+	}
+	return nil
+}
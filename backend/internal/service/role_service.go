@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"main.go/internal/apperror"
+	"main.go/internal/auth"
+	"main.go/internal/models"
+	"main.go/internal/repository"
+)
+
+type RoleService interface {
+	GetRoles(ctx context.Context) ([]models.Role, error)
+	GetRoleByID(ctx context.Context, id uint64) (models.Role, error)
+
+	CreateRole(ctx context.Context, createRole models.RoleRequest) (models.Role, error)
+	UpdateRole(ctx context.Context, id uint64, updateRole models.RoleRequest) (models.Role, error)
+	DeleteRole(ctx context.Context, id uint64) error
+}
+
+type roleServiceImpl struct {
+	repo  repository.RoleQuery
+	audit AuditLogger
+}
+
+func NewRoleService(repo repository.RoleQuery, audit AuditLogger) RoleService {
+	return &roleServiceImpl{repo: repo, audit: audit}
+}
+
+func (r *roleServiceImpl) GetRoles(ctx context.Context) ([]models.Role, error) {
+	return r.repo.GetRoles(ctx)
+}
+
+func (r *roleServiceImpl) GetRoleByID(ctx context.Context, id uint64) (models.Role, error) {
+	role, err := r.repo.GetRoleByID(ctx, id)
+	if err != nil {
+		return models.Role{}, err
+	}
+	if role.ID == 0 {
+		return models.Role{}, apperror.NotFound("role_not_found", "role not found")
+	}
+	return role, nil
+}
+
+func (r *roleServiceImpl) CreateRole(ctx context.Context, createRole models.RoleRequest) (models.Role, error) {
+	name := strings.TrimSpace(createRole.Name)
+	if err := r.checkRoleName(ctx, name, 0); err != nil {
+		return models.Role{}, err
+	}
+	if err := checkPermissions(createRole.Permissions); err != nil {
+		return models.Role{}, err
+	}
+
+	permissions := createRole.Permissions
+	if permissions == nil {
+		permissions = []string{}
+	}
+	role, err := r.repo.CreateRole(ctx, models.Role{Name: name}, permissions)
+	if err != nil {
+		return models.Role{}, err
+	}
+
+	r.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionCreate, EntityType: models.AuditEntityRole, EntityID: uint64(role.ID), After: role})
+	return role, nil
+}
+
+// UpdateRole renames the role and, when permissions are given, replaces its
+// permissions. Leaving permissions out keeps the current ones.
+func (r *roleServiceImpl) UpdateRole(ctx context.Context, id uint64, updateRole models.RoleRequest) (models.Role, error) {
+	existing, err := r.GetRoleByID(ctx, id)
+	if err != nil {
+		return models.Role{}, err
+	}
+
+	name := strings.TrimSpace(updateRole.Name)
+	if err := r.checkRoleName(ctx, name, id); err != nil {
+		return models.Role{}, err
+	}
+	if err := checkPermissions(updateRole.Permissions); err != nil {
+		return models.Role{}, err
+	}
+
+	role, err := r.repo.UpdateRole(ctx, id, models.Role{Name: name}, updateRole.Permissions)
+	if err != nil {
+		return models.Role{}, err
+	}
+
+	r.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionUpdate, EntityType: models.AuditEntityRole, EntityID: id, Before: existing, After: role})
+	return role, nil
+}
+
+func (r *roleServiceImpl) DeleteRole(ctx context.Context, id uint64) error {
+	existing, err := r.GetRoleByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := r.repo.DeleteRole(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrRoleInUse) {
+			return apperror.Conflict("role_is_still_assigned_to_users", "role is still assigned to users")
+		}
+		return err
+	}
+
+	r.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionDelete, EntityType: models.AuditEntityRole, EntityID: id, Before: existing})
+	return nil
+}
+
+func (r *roleServiceImpl) checkRoleName(ctx context.Context, name string, id uint64) error {
+	if name == "" {
+		return apperror.Validation("role_name_is_required", "role name is required")
+	}
+	existing, err := r.repo.GetRoleByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	if existing.ID != 0 && uint64(existing.ID) != id {
+		return apperror.Conflict("role_name_already_exists", "role name already exists")
+	}
+	return nil
+}
+
+func checkPermissions(permissions []string) error {
+	for _, permission := range permissions {
+		if !auth.IsKnownPermission(permission) {
+			return apperror.Validation("unknown_permission", "unknown permission "+permission)
+		}
+	}
+	return nil
+}
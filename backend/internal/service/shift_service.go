@@ -0,0 +1,448 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"main.go/config"
+	"main.go/internal/apperror"
+	"main.go/internal/models"
+	"main.go/internal/repository"
+)
+
+// maxConflictRange bounds the ranges checked for shift conflicts at once.
+const maxConflictRange = 366
+
+// ShiftScheduler finds the shift a user is rostered on for a day.
+type ShiftScheduler interface {
+	ScheduledShift(ctx context.Context, userID uint64, day models.Date) (models.Shift, error)
+}
+
+type ShiftService interface {
+	ShiftScheduler
+
+	GetShifts(ctx context.Context) ([]models.Shift, error)
+	GetShiftByID(ctx context.Context, id uint64) (models.Shift, error)
+	CreateShift(ctx context.Context, createShift models.ShiftRequest) (models.Shift, error)
+	UpdateShift(ctx context.Context, id uint64, updateShift models.ShiftRequest) (models.Shift, error)
+	DeleteShift(ctx context.Context, id uint64) error
+
+	GetAssignments(ctx context.Context, filter models.ShiftAssignmentFilter) ([]models.ShiftAssignment, error)
+	CreateAssignment(ctx context.Context, createAssignment models.ShiftAssignmentRequest) (models.ShiftAssignment, error)
+	UpdateAssignment(ctx context.Context, id uint64, updateAssignment models.ShiftAssignmentRequest) (models.ShiftAssignment, error)
+	DeleteAssignment(ctx context.Context, id uint64) error
+
+	GetRoster(ctx context.Context, query models.RosterQuery) (models.Roster, error)
+	GetConflicts(ctx context.Context, query models.ShiftConflictQuery) ([]models.ShiftConflict, error)
+}
+
+type shiftServiceImpl struct {
+	repo           repository.ShiftQuery
+	leaveRepo      repository.LeaveQuery
+	userRepo       repository.UserQuery
+	departmentRepo repository.DepartmentQuery
+	location       *time.Location
+	audit          AuditLogger
+}
+
+func NewShiftService(repo repository.ShiftQuery, leaveRepo repository.LeaveQuery, userRepo repository.UserQuery, departmentRepo repository.DepartmentQuery, location *time.Location, audit AuditLogger) ShiftService {
+	return &shiftServiceImpl{repo: repo, leaveRepo: leaveRepo, userRepo: userRepo, departmentRepo: departmentRepo, location: location, audit: audit}
+}
+
+func (s *shiftServiceImpl) GetShifts(ctx context.Context) ([]models.Shift, error) {
+	return s.repo.GetShifts(ctx)
+}
+
+func (s *shiftServiceImpl) GetShiftByID(ctx context.Context, id uint64) (models.Shift, error) {
+	shift, err := s.repo.GetShiftByID(ctx, id)
+	if err != nil {
+		return models.Shift{}, err
+	}
+	if shift.ID == 0 {
+		return models.Shift{}, apperror.NotFound("shift_not_found", "shift not found")
+	}
+	return shift, nil
+}
+
+func (s *shiftServiceImpl) CreateShift(ctx context.Context, createShift models.ShiftRequest) (models.Shift, error) {
+	shift, err := s.checkShift(ctx, createShift, 0)
+	if err != nil {
+		return models.Shift{}, err
+	}
+
+	shift, err = s.repo.CreateShift(ctx, shift)
+	if err != nil {
+		return models.Shift{}, err
+	}
+
+	s.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionCreate, EntityType: models.AuditEntityShift, EntityID: uint64(shift.ID), After: shift})
+	return shift, nil
+}
+
+func (s *shiftServiceImpl) UpdateShift(ctx context.Context, id uint64, updateShift models.ShiftRequest) (models.Shift, error) {
+	existing, err := s.GetShiftByID(ctx, id)
+	if err != nil {
+		return models.Shift{}, err
+	}
+
+	shift, err := s.checkShift(ctx, updateShift, id)
+	if err != nil {
+		return models.Shift{}, err
+	}
+
+	shift, err = s.repo.UpdateShift(ctx, id, shift)
+	if err != nil {
+		return models.Shift{}, err
+	}
+
+	s.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionUpdate, EntityType: models.AuditEntityShift, EntityID: id, Before: existing, After: shift})
+	return shift, nil
+}
+
+func (s *shiftServiceImpl) DeleteShift(ctx context.Context, id uint64) error {
+	existing, err := s.GetShiftByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	count, err := s.repo.CountShiftAssignments(ctx, id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return apperror.Conflict("shift_is_still_assigned", "shift is still assigned")
+	}
+
+	if err := s.repo.DeleteShift(ctx, id); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionDelete, EntityType: models.AuditEntityShift, EntityID: id, Before: existing})
+	return nil
+}
+
+func (s *shiftServiceImpl) GetAssignments(ctx context.Context, filter models.ShiftAssignmentFilter) ([]models.ShiftAssignment, error) {
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		return []models.ShiftAssignment{}, apperror.Validation("to_must_not_be_before_from", "to must not be before from")
+	}
+	return s.repo.GetAssignments(ctx, filter)
+}
+
+// CreateAssignment rosters a user on a shift. Assignments putting the user on
+// two shifts on one day are refused, shifts during approved leave are
+// returned as conflicts to resolve.
+func (s *shiftServiceImpl) CreateAssignment(ctx context.Context, createAssignment models.ShiftAssignmentRequest) (models.ShiftAssignment, error) {
+	assignment, err := s.checkAssignment(ctx, createAssignment)
+	if err != nil {
+		return models.ShiftAssignment{}, err
+	}
+	return s.saveAssignment(ctx, nil, assignment)
+}
+
+func (s *shiftServiceImpl) UpdateAssignment(ctx context.Context, id uint64, updateAssignment models.ShiftAssignmentRequest) (models.ShiftAssignment, error) {
+	existing, err := s.getAssignment(ctx, id)
+	if err != nil {
+		return models.ShiftAssignment{}, err
+	}
+
+	assignment, err := s.checkAssignment(ctx, updateAssignment)
+	if err != nil {
+		return models.ShiftAssignment{}, err
+	}
+	assignment.ID = existing.ID
+	assignment.CreatedAt = existing.CreatedAt
+	return s.saveAssignment(ctx, &existing, assignment)
+}
+
+func (s *shiftServiceImpl) DeleteAssignment(ctx context.Context, id uint64) error {
+	existing, err := s.getAssignment(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteAssignment(ctx, id); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionDelete, EntityType: models.AuditEntityShiftAssignment, EntityID: id, Before: existing})
+	return nil
+}
+
+func (s *shiftServiceImpl) ScheduledShift(ctx context.Context, userID uint64, day models.Date) (models.Shift, error) {
+	assignments, err := s.repo.GetAssignments(ctx, models.ShiftAssignmentFilter{UserID: int(userID), From: day, To: day})
+	if err != nil {
+		return models.Shift{}, err
+	}
+	for _, assignment := range assignments {
+		if assignment.ScheduledOn(day) {
+			return *assignment.Shift, nil
+		}
+	}
+	return models.Shift{}, nil
+}
+
+// GetRoster lays out the shifts and leave of a department's users over the
+// week, Monday to Sunday, containing query.Week.
+func (s *shiftServiceImpl) GetRoster(ctx context.Context, query models.RosterQuery) (models.Roster, error) {
+	department, err := s.departmentRepo.GetDepartmentByID(ctx, uint64(query.DepartmentID))
+	if err != nil {
+		return models.Roster{}, err
+	}
+	if department.ID == 0 {
+		return models.Roster{}, apperror.NotFound("department_not_found", "department not found")
+	}
+
+	day := query.Week
+	if day.IsZero() {
+		day = models.NewDate(time.Now().In(s.location))
+	}
+	from := day.AddDays(-((int(day.Weekday()) + 6) % 7))
+	to := from.AddDays(6)
+
+	users, err := s.repo.GetDepartmentUsers(ctx, uint64(department.ID))
+	if err != nil {
+		return models.Roster{}, err
+	}
+	assignments, err := s.repo.GetAssignments(ctx, models.ShiftAssignmentFilter{DepartmentID: department.ID, From: from, To: to})
+	if err != nil {
+		return models.Roster{}, err
+	}
+	leaves, err := s.approvedLeaves(ctx, userIDs(users), from, to)
+	if err != nil {
+		return models.Roster{}, err
+	}
+
+	roster := models.Roster{DepartmentID: department.ID, From: from, To: to, Users: []models.RosterUser{}}
+	for _, user := range users {
+		row := models.RosterUser{UserID: user.Id, Firstname: user.Firstname, Lastname: user.Lastname, Days: []models.RosterDay{}}
+		for day := from; !day.After(to); day = day.AddDays(1) {
+			rosterDay := models.RosterDay{Date: day, OnLeave: onLeave(leaves, user.Id, day) != nil}
+			for _, assignment := range assignments {
+				if assignment.UserID == user.Id && assignment.ScheduledOn(day) {
+					id := assignment.ID
+					rosterDay.AssignmentID = &id
+					rosterDay.Shift = assignment.Shift
+					break
+				}
+			}
+			row.Days = append(row.Days, rosterDay)
+		}
+		roster.Users = append(roster.Users, row)
+	}
+	return roster, nil
+}
+
+func (s *shiftServiceImpl) GetConflicts(ctx context.Context, query models.ShiftConflictQuery) ([]models.ShiftConflict, error) {
+	if query.From.IsZero() || query.To.IsZero() {
+		return []models.ShiftConflict{}, apperror.Validation("from_and_to_are_required", "from and to are required")
+	}
+	if query.To.Before(query.From) {
+		return []models.ShiftConflict{}, apperror.Validation("to_must_not_be_before_from", "to must not be before from")
+	}
+	if query.To.Sub(query.From.Time).Hours()/24 >= maxConflictRange {
+		return []models.ShiftConflict{}, apperror.Validation("date_range_is_too_long", "date range is too long")
+	}
+
+	assignments, err := s.repo.GetAssignments(ctx, models.ShiftAssignmentFilter{
+		UserID:       query.UserID,
+		DepartmentID: query.DepartmentID,
+		From:         query.From,
+		To:           query.To,
+	})
+	if err != nil {
+		return []models.ShiftConflict{}, err
+	}
+
+	ids := []uint64{}
+	for _, assignment := range assignments {
+		ids = append(ids, uint64(assignment.UserID))
+	}
+	leaves, err := s.approvedLeaves(ctx, ids, query.From, query.To)
+	if err != nil {
+		return []models.ShiftConflict{}, err
+	}
+	return shiftConflicts(assignments, leaves, query.From, query.To), nil
+}
+
+// saveAssignment stores a new assignment, or an update of existing when it
+// isn't nil, and lists its conflicts.
+func (s *shiftServiceImpl) saveAssignment(ctx context.Context, existing *models.ShiftAssignment, assignment models.ShiftAssignment) (models.ShiftAssignment, error) {
+	saved, err := s.repo.SaveAssignment(ctx, assignment)
+	if err != nil {
+		if errors.Is(err, repository.ErrShiftAssignmentOverlap) {
+			return models.ShiftAssignment{}, apperror.Conflict("shift_assignment_overlaps_another_assignment", "shift assignment overlaps another assignment")
+		}
+		return models.ShiftAssignment{}, err
+	}
+
+	if existing == nil {
+		s.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionCreate, EntityType: models.AuditEntityShiftAssignment, EntityID: uint64(saved.ID), After: saved})
+	} else {
+		s.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionUpdate, EntityType: models.AuditEntityShiftAssignment, EntityID: uint64(saved.ID), Before: *existing, After: saved})
+	}
+
+	leaves, err := s.approvedLeaves(ctx, []uint64{uint64(saved.UserID)}, saved.StartDate, saved.EndDate)
+	if err != nil {
+		return models.ShiftAssignment{}, err
+	}
+	saved.Conflicts = shiftConflicts([]models.ShiftAssignment{saved}, leaves, saved.StartDate, saved.EndDate)
+	return saved, nil
+}
+
+func (s *shiftServiceImpl) checkShift(ctx context.Context, request models.ShiftRequest, id uint64) (models.Shift, error) {
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		return models.Shift{}, apperror.Validation("shift_name_is_required", "shift name is required")
+	}
+	if _, err := config.ParseClock(request.StartTime); err != nil {
+		return models.Shift{}, apperror.Validation("start_time_must_look_like_15_04", "start_time must look like 15:04")
+	}
+	if _, err := config.ParseClock(request.EndTime); err != nil {
+		return models.Shift{}, apperror.Validation("end_time_must_look_like_15_04", "end_time must look like 15:04")
+	}
+
+	existing, err := s.repo.GetShiftByName(ctx, name)
+	if err != nil {
+		return models.Shift{}, err
+	}
+	if existing.ID != 0 && uint64(existing.ID) != id {
+		return models.Shift{}, apperror.Conflict("shift_name_already_exists", "shift name already exists")
+	}
+
+	shift := models.Shift{
+		Name:         name,
+		StartTime:    request.StartTime,
+		EndTime:      request.EndTime,
+		BreakMinutes: request.BreakMinutes,
+	}
+	shift.SetWeekdays(request.Weekdays)
+
+	start, end := shift.Offsets()
+	if time.Duration(shift.BreakMinutes)*time.Minute >= end-start {
+		return models.Shift{}, apperror.Validation("break_must_be_shorter_than_the_shift", "break must be shorter than the shift")
+	}
+	return shift, nil
+}
+
+func (s *shiftServiceImpl) checkAssignment(ctx context.Context, request models.ShiftAssignmentRequest) (models.ShiftAssignment, error) {
+	if request.StartDate.IsZero() || request.EndDate.IsZero() {
+		return models.ShiftAssignment{}, apperror.Validation("start_date_and_end_date_are_required", "start_date and end_date are required")
+	}
+	if request.EndDate.Before(request.StartDate) {
+		return models.ShiftAssignment{}, apperror.Validation("end_date_must_not_be_before_start_date", "end_date must not be before start_date")
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, uint64(request.UserID))
+	if err != nil {
+		return models.ShiftAssignment{}, err
+	}
+	if user.Id == 0 {
+		return models.ShiftAssignment{}, apperror.NotFound("user_not_found", "user not found")
+	}
+	shift, err := s.GetShiftByID(ctx, uint64(request.ShiftID))
+	if err != nil {
+		return models.ShiftAssignment{}, err
+	}
+
+	return models.ShiftAssignment{
+		UserID:    request.UserID,
+		ShiftID:   shift.ID,
+		Shift:     &shift,
+		StartDate: request.StartDate,
+		EndDate:   request.EndDate,
+	}, nil
+}
+
+func (s *shiftServiceImpl) getAssignment(ctx context.Context, id uint64) (models.ShiftAssignment, error) {
+	assignment, err := s.repo.GetAssignmentByID(ctx, id)
+	if err != nil {
+		return models.ShiftAssignment{}, err
+	}
+	if assignment.ID == 0 {
+		return models.ShiftAssignment{}, apperror.NotFound("shift_assignment_not_found", "shift assignment not found")
+	}
+	return assignment, nil
+}
+
+func (s *shiftServiceImpl) approvedLeaves(ctx context.Context, userIDs []uint64, from models.Date, to models.Date) ([]models.LeaveRequest, error) {
+	return s.leaveRepo.GetLeaveRequests(ctx, models.LeaveRequestFilter{
+		UserIDs: userIDs,
+		Status:  models.LeaveStatusApproved,
+		From:    from,
+		To:      to,
+	})
+}
+
+// shiftConflicts lists, between from and to, the days an assignment shares
+// with a later assignment of the same user and the days it falls on
+// approved leave.
+func shiftConflicts(assignments []models.ShiftAssignment, leaves []models.LeaveRequest, from models.Date, to models.Date) []models.ShiftConflict {
+	conflicts := []models.ShiftConflict{}
+	for i, assignment := range assignments {
+		start, end := assignment.StartDate, assignment.EndDate
+		if from.After(start) {
+			start = from
+		}
+		if to.Before(end) {
+			end = to
+		}
+
+		for day := start; !day.After(end); day = day.AddDays(1) {
+			if !assignment.ScheduledOn(day) {
+				continue
+			}
+			for _, other := range assignments[i+1:] {
+				if other.UserID == assignment.UserID && other.ScheduledOn(day) {
+					otherID := other.ID
+					conflicts = append(conflicts, models.ShiftConflict{
+						Type:              models.ShiftConflictOverlap,
+						UserID:            assignment.UserID,
+						Date:              day,
+						AssignmentID:      assignment.ID,
+						OtherAssignmentID: &otherID,
+					})
+				}
+			}
+			if leave := onLeave(leaves, assignment.UserID, day); leave != nil {
+				leaveID := leave.ID
+				conflicts = append(conflicts, models.ShiftConflict{
+					Type:           models.ShiftConflictLeave,
+					UserID:         assignment.UserID,
+					Date:           day,
+					AssignmentID:   assignment.ID,
+					LeaveRequestID: &leaveID,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(conflicts, func(i, j int) bool {
+		if !conflicts[i].Date.Equal(conflicts[j].Date) {
+			return conflicts[i].Date.Before(conflicts[j].Date)
+		}
+		return conflicts[i].UserID < conflicts[j].UserID
+	})
+	return conflicts
+}
+
+// onLeave returns the approved leave request of the user covering day, or
+// nil.
+func onLeave(leaves []models.LeaveRequest, userID int, day models.Date) *models.LeaveRequest {
+	for i := range leaves {
+		if leaves[i].UserID == userID && !day.Before(leaves[i].StartDate) && !day.After(leaves[i].EndDate) {
+			return &leaves[i]
+		}
+	}
+	return nil
+}
+
+func userIDs(users []models.User) []uint64 {
+	ids := []uint64{}
+	for _, user := range users {
+		ids = append(ids, uint64(user.Id))
+	}
+	return ids
+}
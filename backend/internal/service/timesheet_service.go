@@ -0,0 +1,375 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"main.go/internal/apperror"
+	"main.go/internal/auth"
+	"main.go/internal/models"
+	"main.go/internal/repository"
+)
+
+// timesheetPeriodAnchor is the Monday timesheet periods are counted from, so
+// bi-weekly periods start on the same Mondays for everyone.
+var timesheetPeriodAnchor = models.NewDate(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC))
+
+type TimesheetService interface {
+	GetTimesheets(ctx context.Context, filter models.TimesheetFilter) ([]models.Timesheet, error)
+	GetTeamTimesheets(ctx context.Context, managerID uint64, filter models.TimesheetFilter) ([]models.Timesheet, error)
+	GetTimesheetByID(ctx context.Context, id uint64) (models.Timesheet, error)
+	CreateTimesheet(ctx context.Context, userID uint64, request models.TimesheetRequest) (models.Timesheet, error)
+	UpdateEntries(ctx context.Context, id uint64, request models.TimesheetEntriesRequest) (models.Timesheet, error)
+
+	SubmitTimesheet(ctx context.Context, id uint64) (models.Timesheet, error)
+	ApproveTimesheet(ctx context.Context, id uint64, note string) (models.Timesheet, error)
+	RejectTimesheet(ctx context.Context, id uint64, note string) (models.Timesheet, error)
+}
+
+type timesheetServiceImpl struct {
+	repo           repository.TimesheetQuery
+	attendanceRepo repository.AttendanceQuery
+	userRepo       repository.UserQuery
+	locker         PeriodLocker
+	periodWeeks    int
+	location       *time.Location
+	audit          AuditLogger
+}
+
+func NewTimesheetService(repo repository.TimesheetQuery, attendanceRepo repository.AttendanceQuery, userRepo repository.UserQuery, locker PeriodLocker, periodWeeks int, location *time.Location, audit AuditLogger) TimesheetService {
+	return &timesheetServiceImpl{
+		repo:           repo,
+		attendanceRepo: attendanceRepo,
+		userRepo:       userRepo,
+		locker:         locker,
+		periodWeeks:    periodWeeks,
+		location:       location,
+		audit:          audit,
+	}
+}
+
+func (t *timesheetServiceImpl) GetTimesheets(ctx context.Context, filter models.TimesheetFilter) ([]models.Timesheet, error) {
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		return []models.Timesheet{}, apperror.Validation("to_must_not_be_before_from", "to must not be before from")
+	}
+	return t.repo.GetTimesheets(ctx, filter)
+}
+
+// GetTeamTimesheets lists the timesheets of the manager's direct and
+// indirect reports.
+func (t *timesheetServiceImpl) GetTeamTimesheets(ctx context.Context, managerID uint64, filter models.TimesheetFilter) ([]models.Timesheet, error) {
+	reports, err := t.userRepo.GetReports(ctx, managerID, true)
+	if err != nil {
+		return []models.Timesheet{}, err
+	}
+
+	filter.UserIDs = make([]uint64, 0, len(reports))
+	for _, report := range reports {
+		filter.UserIDs = append(filter.UserIDs, uint64(report.Id))
+	}
+	return t.GetTimesheets(ctx, filter)
+}
+
+func (t *timesheetServiceImpl) GetTimesheetByID(ctx context.Context, id uint64) (models.Timesheet, error) {
+	timesheet, err := t.getTimesheet(ctx, id)
+	if err != nil {
+		return models.Timesheet{}, err
+	}
+
+	allowed, err := t.canManage(ctx, timesheet, auth.PermissionTimesheetsRead)
+	if err != nil {
+		return models.Timesheet{}, err
+	}
+	if !allowed && !isOwnTimesheet(ctx, timesheet) {
+		return models.Timesheet{}, apperror.Forbidden("not_allowed_to_view_this_timesheet", "not allowed to view this timesheet")
+	}
+	return timesheet, nil
+}
+
+// CreateTimesheet starts the user's timesheet of a period with one entry
+// per checked-out attendance in it.
+func (t *timesheetServiceImpl) CreateTimesheet(ctx context.Context, userID uint64, request models.TimesheetRequest) (models.Timesheet, error) {
+	today := models.NewDate(time.Now().In(t.location))
+
+	start := request.PeriodStart
+	if start.IsZero() {
+		start = t.periodStart(today)
+	} else if !start.Equal(t.periodStart(start)) {
+		return models.Timesheet{}, apperror.Validation("period_start_must_be_the_first_day_of_a_timesheet_period", "period_start must be the first day of a timesheet period")
+	}
+	if start.After(today) {
+		return models.Timesheet{}, apperror.Validation("timesheet_period_has_not_started_yet", "timesheet period has not started yet")
+	}
+	end := start.AddDays(7*t.periodWeeks - 1)
+
+	if err := t.locker.EnsureOpen(ctx, start, end); err != nil {
+		return models.Timesheet{}, err
+	}
+
+	attendances, err := t.attendances(ctx, userID, start, end)
+	if err != nil {
+		return models.Timesheet{}, err
+	}
+
+	timesheet := models.Timesheet{
+		UserID:      int(userID),
+		PeriodStart: start,
+		PeriodEnd:   end,
+		Status:      models.TimesheetStatusDraft,
+	}
+	for _, attendance := range attendances {
+		if attendance.WorkedMinutes <= 0 {
+			continue
+		}
+		attendanceID := attendance.ID
+		timesheet.Entries = append(timesheet.Entries, models.TimesheetEntry{
+			AttendanceID: &attendanceID,
+			WorkDate:     attendance.WorkDate,
+			Minutes:      attendance.WorkedMinutes,
+		})
+		timesheet.TotalMinutes += attendance.WorkedMinutes
+	}
+
+	created, err := t.repo.CreateTimesheet(ctx, timesheet)
+	if err != nil {
+		if errors.Is(err, repository.ErrTimesheetExists) {
+			return models.Timesheet{}, apperror.Conflict("timesheet_already_exists_for_this_period", "timesheet already exists for this period")
+		}
+		return models.Timesheet{}, err
+	}
+
+	created, err = t.repo.GetTimesheetByID(ctx, uint64(created.ID))
+	if err != nil {
+		return models.Timesheet{}, err
+	}
+
+	t.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionCreate, EntityType: models.AuditEntityTimesheet, EntityID: uint64(created.ID), After: created})
+	return created, nil
+}
+
+// UpdateEntries replaces the entries of the caller's draft or rejected
+// timesheet. Entries go on days with a checked-out attendance and can't add
+// up to more than the time attended that day.
+func (t *timesheetServiceImpl) UpdateEntries(ctx context.Context, id uint64, request models.TimesheetEntriesRequest) (models.Timesheet, error) {
+	timesheet, err := t.getEditableTimesheet(ctx, id)
+	if err != nil {
+		return models.Timesheet{}, err
+	}
+	existing := timesheet
+
+	attendances, err := t.attendances(ctx, uint64(timesheet.UserID), timesheet.PeriodStart, timesheet.PeriodEnd)
+	if err != nil {
+		return models.Timesheet{}, err
+	}
+	byDay := map[string]models.Attendance{}
+	for _, attendance := range attendances {
+		byDay[attendance.WorkDate.String()] = attendance
+	}
+
+	allocated := map[string]int{}
+	timesheet.Entries = make([]models.TimesheetEntry, 0, len(request.Entries))
+	timesheet.TotalMinutes = 0
+	for _, entry := range request.Entries {
+		if entry.WorkDate.IsZero() {
+			return models.Timesheet{}, apperror.Validation("entry_work_date_is_required", "entry work_date is required")
+		}
+		if entry.WorkDate.Before(timesheet.PeriodStart) || entry.WorkDate.After(timesheet.PeriodEnd) {
+			return models.Timesheet{}, apperror.Validation("entry_work_date_must_be_within_the_timesheet_period", "entry work_date must be within the timesheet period")
+		}
+		day := entry.WorkDate.String()
+		attendance, ok := byDay[day]
+		if !ok {
+			return models.Timesheet{}, apperror.Validation("no_checked_out_attendance_on_entry_work_date", "no checked-out attendance on entry work_date")
+		}
+		allocated[day] += entry.Minutes
+		if allocated[day] > attendance.WorkedMinutes {
+			return models.Timesheet{}, apperror.Validation("entries_exceed_the_time_attended_that_day", "entries exceed the time attended that day")
+		}
+
+		attendanceID := attendance.ID
+		timesheet.Entries = append(timesheet.Entries, models.TimesheetEntry{
+			AttendanceID: &attendanceID,
+			WorkDate:     entry.WorkDate,
+			Minutes:      entry.Minutes,
+			Project:      entry.Project,
+			Task:         entry.Task,
+			Note:         entry.Note,
+		})
+		timesheet.TotalMinutes += entry.Minutes
+	}
+
+	if err := t.repo.ReplaceEntries(ctx, timesheet); err != nil {
+		return models.Timesheet{}, t.transitionError(err, apperror.Conflict("only_draft_or_rejected_timesheets_can_be_changed", "only draft or rejected timesheets can be changed"))
+	}
+	return t.auditTimesheetUpdate(ctx, existing)
+}
+
+// SubmitTimesheet hands the caller's draft or rejected timesheet to their
+// managers for approval.
+func (t *timesheetServiceImpl) SubmitTimesheet(ctx context.Context, id uint64) (models.Timesheet, error) {
+	timesheet, err := t.getEditableTimesheet(ctx, id)
+	if err != nil {
+		return models.Timesheet{}, err
+	}
+	if len(timesheet.Entries) == 0 {
+		return models.Timesheet{}, apperror.Validation("timesheet_has_no_entries", "timesheet has no entries")
+	}
+
+	existing := timesheet
+	from := timesheet.Status
+	now := time.Now().UTC()
+	timesheet.Status = models.TimesheetStatusSubmitted
+	timesheet.SubmittedAt = &now
+	timesheet.DecidedBy = nil
+	timesheet.DecidedAt = nil
+	timesheet.DecisionNote = ""
+
+	if err := t.repo.UpdateTimesheetStatus(ctx, timesheet, from); err != nil {
+		return models.Timesheet{}, t.transitionError(err, apperror.Conflict("only_draft_or_rejected_timesheets_can_be_submitted", "only draft or rejected timesheets can be submitted"))
+	}
+	return t.auditTimesheetUpdate(ctx, existing)
+}
+
+// ApproveTimesheet approves a submitted timesheet. Only the user's managers
+// and timesheet approvers may decide, and nobody decides their own.
+func (t *timesheetServiceImpl) ApproveTimesheet(ctx context.Context, id uint64, note string) (models.Timesheet, error) {
+	return t.decide(ctx, id, models.TimesheetStatusApproved, note)
+}
+
+func (t *timesheetServiceImpl) RejectTimesheet(ctx context.Context, id uint64, note string) (models.Timesheet, error) {
+	return t.decide(ctx, id, models.TimesheetStatusRejected, note)
+}
+
+func (t *timesheetServiceImpl) decide(ctx context.Context, id uint64, status models.TimesheetStatus, note string) (models.Timesheet, error) {
+	timesheet, err := t.getTimesheet(ctx, id)
+	if err != nil {
+		return models.Timesheet{}, err
+	}
+	if isOwnTimesheet(ctx, timesheet) {
+		return models.Timesheet{}, apperror.Forbidden("not_allowed_to_decide_this_timesheet", "not allowed to decide this timesheet")
+	}
+
+	allowed, err := t.canManage(ctx, timesheet, auth.PermissionTimesheetsApprove)
+	if err != nil {
+		return models.Timesheet{}, err
+	}
+	if !allowed {
+		return models.Timesheet{}, apperror.Forbidden("not_allowed_to_decide_this_timesheet", "not allowed to decide this timesheet")
+	}
+	if timesheet.Status != models.TimesheetStatusSubmitted {
+		return models.Timesheet{}, apperror.Conflict("only_submitted_timesheets_can_be_decided", "only submitted timesheets can be decided")
+	}
+	if err := t.locker.EnsureOpen(ctx, timesheet.PeriodStart, timesheet.PeriodEnd); err != nil {
+		return models.Timesheet{}, err
+	}
+
+	existing := timesheet
+	principal, _ := auth.PrincipalFromContext(ctx)
+	decidedBy := principal.UserID
+	now := time.Now().UTC()
+	timesheet.Status = status
+	timesheet.DecidedBy = &decidedBy
+	timesheet.DecidedAt = &now
+	timesheet.DecisionNote = note
+
+	if err := t.repo.UpdateTimesheetStatus(ctx, timesheet, models.TimesheetStatusSubmitted); err != nil {
+		return models.Timesheet{}, t.transitionError(err, apperror.Conflict("only_submitted_timesheets_can_be_decided", "only submitted timesheets can be decided"))
+	}
+	return t.auditTimesheetUpdate(ctx, existing)
+}
+
+// auditTimesheetUpdate logs new entries or a new status of a timesheet and
+// returns it with its entries as they are stored now.
+func (t *timesheetServiceImpl) auditTimesheetUpdate(ctx context.Context, before models.Timesheet) (models.Timesheet, error) {
+	timesheet, err := t.repo.GetTimesheetByID(ctx, uint64(before.ID))
+	if err != nil {
+		return models.Timesheet{}, err
+	}
+
+	t.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionUpdate, EntityType: models.AuditEntityTimesheet, EntityID: uint64(timesheet.ID), Before: before, After: timesheet})
+	return timesheet, nil
+}
+
+// periodStart returns the first day of the timesheet period day is in.
+func (t *timesheetServiceImpl) periodStart(day models.Date) models.Date {
+	length := 7 * t.periodWeeks
+	days := int(day.Sub(timesheetPeriodAnchor.Time).Hours() / 24)
+	offset := days % length
+	if offset < 0 {
+		offset += length
+	}
+	return day.AddDays(-offset)
+}
+
+// attendances returns the checked-out attendances of userID from from to to,
+// oldest first.
+func (t *timesheetServiceImpl) attendances(ctx context.Context, userID uint64, from, to models.Date) ([]models.Attendance, error) {
+	attendances, err := t.attendanceRepo.GetAttendances(ctx, models.AttendanceFilter{UserID: int(userID), From: from, To: to})
+	if err != nil {
+		return nil, err
+	}
+
+	checkedOut := make([]models.Attendance, 0, len(attendances))
+	for i := len(attendances) - 1; i >= 0; i-- {
+		if attendances[i].CheckOutAt != nil {
+			checkedOut = append(checkedOut, attendances[i])
+		}
+	}
+	return checkedOut, nil
+}
+
+func (t *timesheetServiceImpl) getTimesheet(ctx context.Context, id uint64) (models.Timesheet, error) {
+	timesheet, err := t.repo.GetTimesheetByID(ctx, id)
+	if err != nil {
+		return models.Timesheet{}, err
+	}
+	if timesheet.ID == 0 {
+		return models.Timesheet{}, apperror.NotFound("timesheet_not_found", "timesheet not found")
+	}
+	return timesheet, nil
+}
+
+// getEditableTimesheet returns a timesheet its owner may still change, in
+// a pay period that isn't closed.
+func (t *timesheetServiceImpl) getEditableTimesheet(ctx context.Context, id uint64) (models.Timesheet, error) {
+	timesheet, err := t.getTimesheet(ctx, id)
+	if err != nil {
+		return models.Timesheet{}, err
+	}
+	if !isOwnTimesheet(ctx, timesheet) {
+		return models.Timesheet{}, apperror.Forbidden("not_allowed_to_change_this_timesheet", "not allowed to change this timesheet")
+	}
+	if !timesheet.IsEditable() {
+		return models.Timesheet{}, apperror.Conflict("only_draft_or_rejected_timesheets_can_be_changed", "only draft or rejected timesheets can be changed")
+	}
+	if err := t.locker.EnsureOpen(ctx, timesheet.PeriodStart, timesheet.PeriodEnd); err != nil {
+		return models.Timesheet{}, err
+	}
+	return timesheet, nil
+}
+
+// canManage tells whether the caller holds permission or is one of the
+// managers of the timesheet's user.
+func (t *timesheetServiceImpl) canManage(ctx context.Context, timesheet models.Timesheet, permission string) (bool, error) {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return false, nil
+	}
+	if principal.HasPermission(permission) {
+		return true, nil
+	}
+	return t.userRepo.IsManagerOf(ctx, uint64(principal.UserID), uint64(timesheet.UserID))
+}
+
+func (t *timesheetServiceImpl) transitionError(err error, stateErr error) error {
+	if errors.Is(err, repository.ErrTimesheetStateChanged) {
+		return stateErr
+	}
+	return err
+}
+
+func isOwnTimesheet(ctx context.Context, timesheet models.Timesheet) bool {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	return ok && principal.UserID == timesheet.UserID
+}
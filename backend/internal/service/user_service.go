@@ -1,41 +1,135 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
+	"main.go/internal/apperror"
 	"main.go/internal/auth"
 	"main.go/internal/models"
 	"main.go/internal/repository"
+	"main.go/internal/validation"
 )
 
 type UserService interface {
-	GetUsers(ctx context.Context) ([]models.User, error)
+	GetUsers(ctx context.Context, filter models.UserFilter) ([]models.User, models.PaginationMeta, error)
 	GetUserByID(ctx context.Context, id uint64) (models.User, error)
 	GetUserByEmail(ctx context.Context, email string) (models.User, error)
+	GetReports(ctx context.Context, managerID uint64, recursive bool) ([]models.Report, error)
+	GetOrgChart(ctx context.Context, rootID uint64) ([]*models.OrgChartNode, error)
+	IsManagerOf(ctx context.Context, managerID uint64, userID uint64) (bool, error)
 
 	CreateUser(ctx context.Context, createUser models.UserRequest) (models.User, error)
-	UpdateUser(ctx context.Context, id uint64, user models.UserRequest) (models.User, error)
+	UpdateUser(ctx context.Context, id uint64, user models.UserRequest, versions []int) (models.User, error)
+	PatchUser(ctx context.Context, id uint64, patch []byte, versions []int) (models.User, error)
 	DeleteUser(ctx context.Context, id uint64) error
-	Login(ctx context.Context, email string, password string) (models.AuthResponse, error)
-	Logout(ctx context.Context, token string) error
+	GetDeletedUsers(ctx context.Context, filter models.DeletedUserFilter) ([]models.DeletedUser, models.PaginationMeta, error)
+	RestoreUser(ctx context.Context, id uint64) (models.User, error)
+	PurgeUser(ctx context.Context, id uint64) error
+	PurgeDeletedUsers(ctx context.Context) (int64, error)
+	MoveUserToDepartment(ctx context.Context, id uint64, departmentID *int) (models.User, error)
+	SetUserManager(ctx context.Context, id uint64, managerID *int) (models.User, error)
+	Login(ctx context.Context, email string, password string) (models.AuthTokens, error)
+	Logout(ctx context.Context, token string, refreshToken string) error
+	RefreshToken(ctx context.Context, refreshToken string) (models.AuthTokens, error)
 }
 
 type userServiceImpl struct {
-	repo repository.UserQuery
+	repo           repository.UserQuery
+	refreshRepo    repository.RefreshTokenQuery
+	revocations    repository.RevocationStore
+	departmentRepo repository.DepartmentQuery
+	// retention is how long deleted users are kept before they can be
+	// purged.
+	retention time.Duration
+	audit     AuditLogger
 }
 
-func NewUserService(repo repository.UserQuery) UserService {
-	return &userServiceImpl{repo: repo}
+func NewUserService(repo repository.UserQuery, refreshRepo repository.RefreshTokenQuery, revocations repository.RevocationStore, departmentRepo repository.DepartmentQuery, retention time.Duration, audit AuditLogger) UserService {
+	return &userServiceImpl{repo: repo, refreshRepo: refreshRepo, revocations: revocations, departmentRepo: departmentRepo, retention: retention, audit: audit}
 }
 
-func (u *userServiceImpl) GetUsers(ctx context.Context) ([]models.User, error) {
-	user, err := u.repo.GetUsers(ctx)
+const (
+	defaultUsersLimit = 20
+	maxUsersLimit     = 100
+)
+
+func (u *userServiceImpl) GetUsers(ctx context.Context, filter models.UserFilter) ([]models.User, models.PaginationMeta, error) {
+	if filter.Limit == 0 {
+		filter.Limit = defaultUsersLimit
+	}
+	if filter.Limit > maxUsersLimit {
+		filter.Limit = maxUsersLimit
+	}
+	if filter.Page == 0 {
+		filter.Page = 1
+	}
+	if filter.Sort == "" {
+		filter.Sort = "created_at"
+	}
+	if filter.Order == "" {
+		filter.Order = "asc"
+	}
+	if filter.Cursor != "" {
+		after, err := decodeUserCursor(filter.Cursor)
+		if err != nil {
+			return []models.User{}, models.PaginationMeta{}, apperror.Validation("invalid_cursor", "invalid cursor")
+		}
+		filter.After = &after
+	}
+
+	users, total, err := u.repo.GetUsers(ctx, filter)
 	if err != nil {
-		return []models.User{}, err
+		return []models.User{}, models.PaginationMeta{}, err
 	}
-	return user, nil
+
+	meta := models.PaginationMeta{
+		Limit:      filter.Limit,
+		Total:      total,
+		TotalPages: int((total + int64(filter.Limit) - 1) / int64(filter.Limit)),
+	}
+	if filter.After == nil {
+		meta.Page = filter.Page
+	}
+
+	if len(users) > filter.Limit {
+		users = users[:filter.Limit]
+		last := users[len(users)-1]
+		meta.NextCursor = encodeUserCursor(models.UserCursor{
+			ID:        last.Id,
+			Firstname: last.Firstname,
+			Lastname:  last.Lastname,
+			Email:     last.Email,
+			CreatedAt: last.CreatedAt,
+		})
+	}
+
+	return users, meta, nil
+}
+
+func encodeUserCursor(cursor models.UserCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeUserCursor(value string) (models.UserCursor, error) {
+	var cursor models.UserCursor
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return cursor, err
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return cursor, err
+	}
+	if cursor.ID == 0 {
+		return cursor, apperror.Validation("cursor_without_id", "cursor without id")
+	}
+	return cursor, nil
 }
 
 func (u *userServiceImpl) CreateUser(ctx context.Context, createUser models.UserRequest) (models.User, error) {
@@ -44,21 +138,24 @@ func (u *userServiceImpl) CreateUser(ctx context.Context, createUser models.User
 		return models.User{}, err
 	}
 	if existingUser.Id != 0 {
-		return models.User{}, errors.New("email already exists")
+		return models.User{}, apperror.Conflict("email_already_exists", "email already exists")
 	}
 
 	role, err := u.repo.GetRoleByID(ctx, uint64(createUser.RoleID))
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return models.User{}, errors.New("role not found")
+			return models.User{}, apperror.Validation("role_not_found", "role not found")
 		}
 		return models.User{}, err
 	}
+	if err := checkRoleGrant(ctx, role); err != nil {
+		return models.User{}, err
+	}
 
 	position, err := u.repo.GetPositionByID(ctx, uint64(createUser.PositionID))
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return models.User{}, errors.New("position not found")
+			return models.User{}, apperror.Validation("position_not_found", "position not found")
 		}
 		return models.User{}, err
 	}
@@ -66,7 +163,12 @@ func (u *userServiceImpl) CreateUser(ctx context.Context, createUser models.User
 	// Hash password
 	hashedPassword, err := auth.HashPassword(createUser.Password)
 	if err != nil {
-		return models.User{}, errors.New("error hashing password")
+		return models.User{}, apperror.Internal(err)
+	}
+
+	hireDate := createUser.HireDate
+	if hireDate.IsZero() {
+		hireDate = models.NewDate(time.Now())
 	}
 
 	newUser := models.User{
@@ -76,6 +178,7 @@ func (u *userServiceImpl) CreateUser(ctx context.Context, createUser models.User
 		Password:   hashedPassword,
 		RoleID:     role.ID,
 		PositionID: position.ID,
+		HireDate:   hireDate,
 	}
 
 	createdUser, err := u.repo.CreateUser(ctx, newUser)
@@ -83,6 +186,7 @@ func (u *userServiceImpl) CreateUser(ctx context.Context, createUser models.User
 		return models.User{}, err
 	}
 
+	u.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionCreate, EntityType: models.AuditEntityUser, EntityID: uint64(createdUser.Id), After: createdUser})
 	return createdUser, nil
 }
 
@@ -94,13 +198,70 @@ func (u *userServiceImpl) GetUserByEmail(ctx context.Context, email string) (mod
 	return user, nil
 }
 
-func (u *userServiceImpl) UpdateUser(ctx context.Context, id uint64, updateUser models.UserRequest) (models.User, error) {
+// UpdateUser replaces the user's details. versions are the ETag versions of
+// an If-Match header, nil when any version may be overwritten.
+func (u *userServiceImpl) UpdateUser(ctx context.Context, id uint64, updateUser models.UserRequest, versions []int) (models.User, error) {
 	existingUser, err := u.repo.GetUserByID(ctx, id)
 	if err != nil {
 		return models.User{}, err
 	}
 	if existingUser.Id == 0 {
-		return models.User{}, errors.New("user not found")
+		return models.User{}, apperror.NotFound("user_not_found", "user not found")
+	}
+
+	return u.updateUser(ctx, existingUser, updateUser, versions)
+}
+
+// PatchUser applies a JSON Merge Patch (RFC 7386) to the user. The patched
+// user has to pass the same rules as a full update.
+func (u *userServiceImpl) PatchUser(ctx context.Context, id uint64, patch []byte, versions []int) (models.User, error) {
+	existingUser, err := u.repo.GetUserByID(ctx, id)
+	if err != nil {
+		return models.User{}, err
+	}
+	if existingUser.Id == 0 {
+		return models.User{}, apperror.NotFound("user_not_found", "user not found")
+	}
+
+	patched, err := applyUserPatch(models.UserPatch{
+		Firstname:  existingUser.Firstname,
+		Lastname:   existingUser.Lastname,
+		Email:      existingUser.Email,
+		RoleID:     existingUser.RoleID,
+		PositionID: existingUser.PositionID,
+		HireDate:   existingUser.HireDate,
+	}, patch)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	return u.updateUser(ctx, existingUser, models.UserRequest{
+		Firstname:  patched.Firstname,
+		Lastname:   patched.Lastname,
+		Email:      patched.Email,
+		Password:   patched.Password,
+		RoleID:     patched.RoleID,
+		PositionID: patched.PositionID,
+		HireDate:   patched.HireDate,
+	}, versions)
+}
+
+func (u *userServiceImpl) updateUser(ctx context.Context, existingUser models.User, updateUser models.UserRequest, versions []int) (models.User, error) {
+	if err := checkVersion(existingUser, versions); err != nil {
+		return models.User{}, err
+	}
+	id := uint64(existingUser.Id)
+
+	// Users editing their own record without users:write may not promote
+	// themselves, move to another position or change the hire date their
+	// leave accrues from.
+	if principal, ok := auth.PrincipalFromContext(ctx); ok && !principal.HasPermission(auth.PermissionUsersWrite) {
+		if updateUser.RoleID != existingUser.RoleID || updateUser.PositionID != existingUser.PositionID {
+			return models.User{}, apperror.Forbidden("not_allowed_to_change_role_or_position", "not allowed to change role or position")
+		}
+		if !updateUser.HireDate.IsZero() && !updateUser.HireDate.Equal(existingUser.HireDate) {
+			return models.User{}, apperror.Forbidden("not_allowed_to_change_hire_date", "not allowed to change hire date")
+		}
 	}
 
 	userWithSameEmail, err := u.repo.GetUserByEmail(ctx, updateUser.Email)
@@ -108,34 +269,49 @@ func (u *userServiceImpl) UpdateUser(ctx context.Context, id uint64, updateUser
 		return models.User{}, err
 	}
 	if userWithSameEmail.Id != 0 && userWithSameEmail.Id != int(id) {
-		return models.User{}, errors.New("email already exists")
+		return models.User{}, apperror.Conflict("email_already_exists", "email already exists")
 	}
 
 	role, err := u.repo.GetRoleByID(ctx, uint64(updateUser.RoleID))
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return models.User{}, errors.New("role not found")
+			return models.User{}, apperror.Validation("role_not_found", "role not found")
 		}
 		return models.User{}, err
 	}
+	if role.ID != existingUser.RoleID {
+		if err := checkRoleGrant(ctx, role); err != nil {
+			return models.User{}, err
+		}
+	}
 
 	position, err := u.repo.GetPositionByID(ctx, uint64(updateUser.PositionID))
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return models.User{}, errors.New("position not found")
+			return models.User{}, apperror.Validation("position_not_found", "position not found")
 		}
 		return models.User{}, err
 	}
 
-	updatedPassword := existingUser.Password
-	if updateUser.Password != "" && updateUser.Password != existingUser.Password {
+	// An empty password, or the one the user already has, keeps the hash.
+	// Users may come from the cache, which leaves the hash out.
+	updatedPassword, err := u.repo.GetPasswordHash(ctx, id)
+	if err != nil {
+		return models.User{}, err
+	}
+	if updateUser.Password != "" && !auth.CheckPasswordHash(updateUser.Password, updatedPassword) {
 		hashedPassword, err := auth.HashPassword(updateUser.Password)
 		if err != nil {
-			return models.User{}, errors.New("error hashing password")
+			return models.User{}, apperror.Internal(err)
 		}
 		updatedPassword = hashedPassword
 	}
 
+	hireDate := updateUser.HireDate
+	if hireDate.IsZero() {
+		hireDate = existingUser.HireDate
+	}
+
 	updatedUser := models.User{
 		Id:         existingUser.Id,
 		Firstname:  updateUser.Firstname,
@@ -144,39 +320,346 @@ func (u *userServiceImpl) UpdateUser(ctx context.Context, id uint64, updateUser
 		Password:   updatedPassword,
 		RoleID:     role.ID,
 		PositionID: position.ID,
+		HireDate:   hireDate,
+	}
+	if versions != nil {
+		// Only write over the version the client has seen.
+		updatedUser.Version = existingUser.Version
 	}
 
 	savedUser, err := u.repo.UpdateUser(ctx, id, updatedUser)
 	if err != nil {
+		if errors.Is(err, repository.ErrUserVersionMismatch) {
+			return models.User{}, errUserChanged
+		}
 		return models.User{}, err
 	}
 
+	u.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionUpdate, EntityType: models.AuditEntityUser, EntityID: id, Before: existingUser, After: savedUser})
 	return savedUser, nil
 }
 
+// checkRoleGrant refuses to give a user a role with permissions the caller
+// doesn't have, unless the caller manages roles. users:write alone must not
+// be a way to hand out admin access, to others or to oneself.
+func checkRoleGrant(ctx context.Context, role models.Role) error {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok || principal.HasPermission(auth.PermissionRolesWrite) {
+		return nil
+	}
+	for _, permission := range role.Permissions {
+		if !principal.HasPermission(permission.Permission) {
+			return apperror.Forbidden("not_allowed_to_grant_this_role", "not allowed to grant a role with permissions you don't have")
+		}
+	}
+	return nil
+}
+
+var errUserChanged = apperror.PreconditionFailed("user_changed", "user was changed by someone else, fetch it again")
+
+// checkVersion refuses to change a user whose version is none of versions.
+func checkVersion(user models.User, versions []int) error {
+	if versions == nil {
+		return nil
+	}
+	for _, version := range versions {
+		if version == user.Version {
+			return nil
+		}
+	}
+	return errUserChanged
+}
+
+// applyUserPatch merges patch into user and checks the result.
+func applyUserPatch(user models.UserPatch, patch []byte) (models.UserPatch, error) {
+	var patchDoc interface{}
+	if err := json.Unmarshal(patch, &patchDoc); err != nil {
+		return models.UserPatch{}, apperror.Binding(err)
+	}
+	if _, ok := patchDoc.(map[string]interface{}); !ok {
+		return models.UserPatch{}, apperror.Validation("invalid_merge_patch", "merge patch must be a JSON object")
+	}
+
+	current, err := json.Marshal(user)
+	if err != nil {
+		return models.UserPatch{}, err
+	}
+	var target interface{}
+	if err := json.Unmarshal(current, &target); err != nil {
+		return models.UserPatch{}, err
+	}
+
+	merged, err := json.Marshal(mergePatch(target, patchDoc))
+	if err != nil {
+		return models.UserPatch{}, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(merged))
+	decoder.DisallowUnknownFields()
+	var patched models.UserPatch
+	if err := decoder.Decode(&patched); err != nil {
+		return models.UserPatch{}, apperror.Binding(err)
+	}
+	if err := validation.Struct(patched); err != nil {
+		return models.UserPatch{}, apperror.Binding(err)
+	}
+	return patched, nil
+}
+
+// mergePatch applies an RFC 7386 merge patch to target: members of an
+// object patch replace those of the target, null members remove them, and
+// any other patch replaces the target whole.
+func mergePatch(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+			continue
+		}
+		targetObject[name] = mergePatch(targetObject[name], value)
+	}
+	return targetObject
+}
+
 func (u *userServiceImpl) DeleteUser(ctx context.Context, id uint64) error {
-	err := u.repo.DeleteUser(ctx, id)
-	return err
+	user, err := u.GetUserByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := u.repo.DeleteUser(ctx, id); err != nil {
+		return err
+	}
+
+	u.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionDelete, EntityType: models.AuditEntityUser, EntityID: id, Before: user})
+	return nil
+}
+
+func (u *userServiceImpl) GetDeletedUsers(ctx context.Context, filter models.DeletedUserFilter) ([]models.DeletedUser, models.PaginationMeta, error) {
+	if filter.Limit == 0 {
+		filter.Limit = defaultUsersLimit
+	}
+	if filter.Limit > maxUsersLimit {
+		filter.Limit = maxUsersLimit
+	}
+	if filter.Page == 0 {
+		filter.Page = 1
+	}
+
+	users, total, err := u.repo.GetDeletedUsers(ctx, filter)
+	if err != nil {
+		return []models.DeletedUser{}, models.PaginationMeta{}, err
+	}
+
+	deletedUsers := make([]models.DeletedUser, 0, len(users))
+	for _, user := range users {
+		deletedUsers = append(deletedUsers, models.DeletedUser{
+			User:       user,
+			DeletedAt:  user.DeletedAt.Time,
+			PurgeAfter: user.DeletedAt.Time.Add(u.retention),
+		})
+	}
+
+	meta := models.PaginationMeta{
+		Page:       filter.Page,
+		Limit:      filter.Limit,
+		Total:      total,
+		TotalPages: int((total + int64(filter.Limit) - 1) / int64(filter.Limit)),
+	}
+	return deletedUsers, meta, nil
+}
+
+// RestoreUser brings back a deleted user, unless someone active has taken
+// their email in the meantime.
+func (u *userServiceImpl) RestoreUser(ctx context.Context, id uint64) (models.User, error) {
+	deletedUser, err := u.repo.GetDeletedUserByID(ctx, id)
+	if err != nil {
+		return models.User{}, err
+	}
+	if deletedUser.Id == 0 {
+		return models.User{}, apperror.NotFound("deleted_user_not_found", "deleted user not found")
+	}
+
+	userWithSameEmail, err := u.repo.GetUserByEmail(ctx, deletedUser.Email)
+	if err != nil {
+		return models.User{}, err
+	}
+	if userWithSameEmail.Id != 0 {
+		return models.User{}, errEmailTakenOnRestore
+	}
+
+	if err := u.repo.RestoreUser(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrUserEmailTaken) {
+			return models.User{}, errEmailTakenOnRestore
+		}
+		return models.User{}, err
+	}
+
+	user, err := u.GetUserByID(ctx, id)
+	if err != nil {
+		return models.User{}, err
+	}
+	u.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionUpdate, EntityType: models.AuditEntityUser, EntityID: id, Before: deletedUser, After: user})
+	return user, nil
+}
+
+var errEmailTakenOnRestore = apperror.Conflict("email_already_exists", "another user has this email, change it before restoring")
+
+// PurgeUser deletes a deleted user for good once the retention period is
+// over.
+func (u *userServiceImpl) PurgeUser(ctx context.Context, id uint64) error {
+	deletedUser, err := u.repo.GetDeletedUserByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if deletedUser.Id == 0 {
+		return apperror.NotFound("deleted_user_not_found", "deleted user not found")
+	}
+
+	purgeAfter := deletedUser.DeletedAt.Time.Add(u.retention)
+	if time.Now().Before(purgeAfter) {
+		return apperror.Conflict("retention_period_not_over", "user can't be purged before "+purgeAfter.Format(time.RFC3339))
+	}
+
+	if err := u.repo.PurgeUser(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrUserHasPayslips) {
+			return apperror.Conflict("user_has_payslips", "user has payslips, which have to be kept")
+		}
+		return err
+	}
+
+	u.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionDelete, EntityType: models.AuditEntityUser, EntityID: id, Before: deletedUser})
+	return nil
 }
 
-func (u *userServiceImpl) Login(ctx context.Context, email string, password string) (models.AuthResponse, error) {
+// PurgeDeletedUsers deletes every user whose retention period is over for
+// good. Users with payslips are kept.
+func (u *userServiceImpl) PurgeDeletedUsers(ctx context.Context) (int64, error) {
+	ids, err := u.repo.PurgeDeletedUsers(ctx, time.Now().Add(-u.retention))
+	if err != nil {
+		return 0, err
+	}
+
+	for _, id := range ids {
+		u.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionDelete, EntityType: models.AuditEntityUser, EntityID: id})
+	}
+	return int64(len(ids)), nil
+}
+
+func (u *userServiceImpl) Login(ctx context.Context, email string, password string) (models.AuthTokens, error) {
 	user, err := u.repo.GetUserByEmail(ctx, email)
 	if err != nil || user.Id == 0 {
-		return models.AuthResponse{}, errors.New("invalid email or password")
+		return models.AuthTokens{}, apperror.Unauthorized("invalid_email_or_password", "invalid email or password")
 	}
 	if !auth.CheckPasswordHash(password, user.Password) {
-		return models.AuthResponse{}, errors.New("invalid email or password")
+		return models.AuthTokens{}, apperror.Unauthorized("invalid_email_or_password", "invalid email or password")
 	}
 
-	token, err := auth.GenerateJWT(user.Email)
+	familyID, err := auth.NewTokenFamilyID()
 	if err != nil {
-		return models.AuthResponse{}, err
+		return models.AuthTokens{}, err
+	}
+
+	tokens, err := u.issueTokens(ctx, user, familyID, nil)
+	if err != nil {
+		return models.AuthTokens{}, err
+	}
+
+	u.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionLogin, EntityType: models.AuditEntityUser, EntityID: uint64(user.Id), ActorID: user.Id})
+	return tokens, nil
+}
+
+func (u *userServiceImpl) RefreshToken(ctx context.Context, refreshToken string) (models.AuthTokens, error) {
+	current, err := u.refreshRepo.GetRefreshTokenByHash(ctx, auth.HashRefreshToken(refreshToken))
+	if err != nil {
+		return models.AuthTokens{}, err
+	}
+	if current.ID == 0 {
+		return models.AuthTokens{}, apperror.Unauthorized("invalid_refresh_token", "invalid refresh token")
+	}
+
+	// A refresh token that was already rotated or revoked is being replayed,
+	// so whoever holds the newer tokens of this family can't be trusted.
+	if current.UsedAt != nil || current.RevokedAt != nil {
+		if err := u.refreshRepo.RevokeRefreshTokenFamily(ctx, current.FamilyID); err != nil {
+			return models.AuthTokens{}, err
+		}
+		return models.AuthTokens{}, apperror.Unauthorized("refresh_token_reuse_detected", "refresh token reuse detected")
+	}
+
+	if time.Now().After(current.ExpiredAt) {
+		return models.AuthTokens{}, apperror.Unauthorized("refresh_token_expired", "refresh token expired")
+	}
+
+	user, err := u.repo.GetUserByID(ctx, uint64(current.UserID))
+	if err != nil {
+		return models.AuthTokens{}, err
+	}
+	if user.Id == 0 {
+		if err := u.refreshRepo.RevokeRefreshTokenFamily(ctx, current.FamilyID); err != nil {
+			return models.AuthTokens{}, err
+		}
+		return models.AuthTokens{}, apperror.Unauthorized("invalid_refresh_token", "invalid refresh token")
+	}
+
+	return u.issueTokens(ctx, user, current.FamilyID, &current)
+}
+
+// issueTokens signs a new access token and stores a new refresh token in the
+// given family. When current is set it is rotated out in the same step.
+func (u *userServiceImpl) issueTokens(ctx context.Context, user models.User, familyID string, current *models.RefreshToken) (models.AuthTokens, error) {
+	token, err := auth.GenerateJWT(auth.Principal{
+		UserID:     user.Id,
+		Email:      user.Email,
+		RoleID:     user.RoleID,
+		Role:       user.Role.Name,
+		PositionID: user.PositionID,
+		Position:   user.Position.Name,
+	})
+	if err != nil {
+		return models.AuthTokens{}, err
+	}
+
+	refreshToken, refreshTokenHash, err := auth.GenerateRefreshToken()
+	if err != nil {
+		return models.AuthTokens{}, err
+	}
+
+	next := models.RefreshToken{
+		UserID:    user.Id,
+		FamilyID:  familyID,
+		TokenHash: refreshTokenHash,
+		CreatedAt: time.Now(),
+		ExpiredAt: time.Now().Add(auth.RefreshTokenTTL),
+	}
+
+	if current == nil {
+		err = u.refreshRepo.CreateRefreshToken(ctx, next)
+	} else {
+		err = u.refreshRepo.RotateRefreshToken(ctx, *current, next)
+		if errors.Is(err, repository.ErrRefreshTokenAlreadyUsed) {
+			if err := u.refreshRepo.RevokeRefreshTokenFamily(ctx, familyID); err != nil {
+				return models.AuthTokens{}, err
+			}
+			return models.AuthTokens{}, apperror.Unauthorized("refresh_token_reuse_detected", "refresh token reuse detected")
+		}
 	}
-	return models.AuthResponse{
-		Status:  200,
-		Message: "Login successful",
-		Token:   token,
-		Error:   false,
+	if err != nil {
+		return models.AuthTokens{}, err
+	}
+
+	return models.AuthTokens{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(auth.AccessTokenTTL.Seconds()),
 	}, nil
 }
 
@@ -186,24 +669,149 @@ func (u *userServiceImpl) GetUserByID(ctx context.Context, id uint64) (models.Us
 		return models.User{}, err
 	}
 	if user.Id == 0 {
-		return models.User{}, errors.New("user not found")
+		return models.User{}, apperror.NotFound("user_not_found", "user not found")
 	}
 
 	return user, nil
 }
 
-func (u *userServiceImpl) Logout(ctx context.Context, token string) error {
-	isBlacklisted, err := u.repo.IsTokenBlacklisted(ctx, token)
+func (u *userServiceImpl) Logout(ctx context.Context, token string, refreshToken string) error {
+	claims, err := auth.ValidateJWT(token)
+	if err != nil {
+		return apperror.Unauthorized("invalid_token", "invalid token")
+	}
+
+	isBlacklisted, err := u.revocations.IsRevoked(ctx, claims.ID)
 	if err != nil {
 		return err
 	}
 	if isBlacklisted {
-		return errors.New("token already blacklisted")
+		return apperror.Unauthorized("token_already_blacklisted", "token already blacklisted")
 	}
 
-	if err := u.repo.AddTokenToBlacklist(ctx, token); err != nil {
+	if err := u.revocations.Revoke(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
 		return err
 	}
 
+	if refreshToken != "" {
+		current, err := u.refreshRepo.GetRefreshTokenByHash(ctx, auth.HashRefreshToken(refreshToken))
+		if err != nil {
+			return err
+		}
+		if current.ID != 0 {
+			if err := u.refreshRepo.RevokeRefreshTokenFamily(ctx, current.FamilyID); err != nil {
+				return err
+			}
+		}
+	}
+
+	principal := claims.Principal()
+	u.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionLogout, EntityType: models.AuditEntityUser, EntityID: uint64(principal.UserID), ActorID: principal.UserID})
 	return nil
 }
+
+func (u *userServiceImpl) GetReports(ctx context.Context, managerID uint64, recursive bool) ([]models.Report, error) {
+	if _, err := u.GetUserByID(ctx, managerID); err != nil {
+		return []models.Report{}, err
+	}
+	return u.repo.GetReports(ctx, managerID, recursive)
+}
+
+// GetOrgChart returns the reporting lines as a forest of trees. With a
+// rootID only the subtree below that user is returned.
+func (u *userServiceImpl) GetOrgChart(ctx context.Context, rootID uint64) ([]*models.OrgChartNode, error) {
+	nodes, err := u.repo.GetOrgChartNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int]*models.OrgChartNode, len(nodes))
+	for i := range nodes {
+		nodes[i].Reports = []*models.OrgChartNode{}
+		byID[nodes[i].ID] = &nodes[i]
+	}
+
+	roots := []*models.OrgChartNode{}
+	for i := range nodes {
+		node := &nodes[i]
+		if node.ManagerID != nil {
+			if manager, ok := byID[*node.ManagerID]; ok {
+				manager.Reports = append(manager.Reports, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+
+	if rootID != 0 {
+		root, ok := byID[int(rootID)]
+		if !ok {
+			return nil, apperror.NotFound("user_not_found", "user not found")
+		}
+		return []*models.OrgChartNode{root}, nil
+	}
+	return roots, nil
+}
+
+func (u *userServiceImpl) IsManagerOf(ctx context.Context, managerID uint64, userID uint64) (bool, error) {
+	return u.repo.IsManagerOf(ctx, managerID, userID)
+}
+
+func (u *userServiceImpl) MoveUserToDepartment(ctx context.Context, id uint64, departmentID *int) (models.User, error) {
+	existingUser, err := u.GetUserByID(ctx, id)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	if departmentID != nil {
+		department, err := u.departmentRepo.GetDepartmentByID(ctx, uint64(*departmentID))
+		if err != nil {
+			return models.User{}, err
+		}
+		if department.ID == 0 {
+			return models.User{}, apperror.Validation("department_not_found", "department not found")
+		}
+	}
+
+	if err := u.repo.UpdateUserDepartment(ctx, id, departmentID); err != nil {
+		return models.User{}, err
+	}
+	return u.auditUserUpdate(ctx, existingUser)
+}
+
+func (u *userServiceImpl) SetUserManager(ctx context.Context, id uint64, managerID *int) (models.User, error) {
+	existingUser, err := u.GetUserByID(ctx, id)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	if managerID != nil {
+		manager, err := u.repo.GetUserByID(ctx, uint64(*managerID))
+		if err != nil {
+			return models.User{}, err
+		}
+		if manager.Id == 0 {
+			return models.User{}, apperror.Validation("manager_not_found", "manager not found")
+		}
+	}
+
+	if err := u.repo.UpdateUserManager(ctx, id, managerID); err != nil {
+		if errors.Is(err, repository.ErrReportingCycle) {
+			return models.User{}, apperror.Conflict("reporting_line_would_create_a_cycle", "reporting line would create a cycle")
+		}
+		return models.User{}, err
+	}
+	return u.auditUserUpdate(ctx, existingUser)
+}
+
+// auditUserUpdate reloads a user changed from before and records the
+// change.
+func (u *userServiceImpl) auditUserUpdate(ctx context.Context, before models.User) (models.User, error) {
+	user, err := u.GetUserByID(ctx, uint64(before.Id))
+	if err != nil {
+		return models.User{}, err
+	}
+
+	u.audit.Record(ctx, models.AuditEntry{Action: models.AuditActionUpdate, EntityType: models.AuditEntityUser, EntityID: uint64(user.Id), Before: before, After: user})
+	return user, nil
+}
@@ -0,0 +1,183 @@
+package validation
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	id_translations "github.com/go-playground/validator/v10/translations/id"
+)
+
+// DefaultLanguage is used when the client accepts none of the languages the
+// API speaks.
+const DefaultLanguage = "en"
+
+// translators falls back to English when the client accepts none of the
+// languages the API speaks.
+var translators = ut.New(en.New(), en.New(), id.New())
+
+// messages are the translations of rules the validator has none for, and of
+// the invalid and type keys used by Message and TypeMessage.
+var messages = map[string]map[string]string{
+	"en": {
+		"password":     "{0} must be at least " + strconv.Itoa(MinPasswordLength) + " characters long and contain a lowercase letter, an uppercase letter and a digit",
+		"personname":   "{0} may only contain letters, spaces, hyphens, apostrophes and periods",
+		"iso4217":      "{0} must be an ISO 4217 currency code",
+		"invalid":      "{0} is invalid",
+		"type":         "{0} must be a {1}",
+		"type_number":  "number",
+		"type_boolean": "boolean",
+		"type_string":  "string",
+		"type_list":    "list",
+		"type_object":  "object",
+	},
+	"id": {
+		"password":     "{0} harus terdiri dari minimal " + strconv.Itoa(MinPasswordLength) + " karakter dan memuat huruf kecil, huruf besar, dan angka",
+		"personname":   "{0} hanya boleh berisi huruf, spasi, tanda hubung, apostrof, dan titik",
+		"iso4217":      "{0} harus berupa kode mata uang ISO 4217",
+		"invalid":      "{0} tidak valid",
+		"type":         "{0} harus berupa {1}",
+		"type_number":  "angka",
+		"type_boolean": "boolean",
+		"type_string":  "teks",
+		"type_list":    "daftar",
+		"type_object":  "objek",
+	},
+}
+
+func registerTranslations(v *validator.Validate) {
+	defaults := map[string]func(*validator.Validate, ut.Translator) error{
+		"en": en_translations.RegisterDefaultTranslations,
+		"id": id_translations.RegisterDefaultTranslations,
+	}
+
+	for language, register := range defaults {
+		trans, _ := translators.GetTranslator(language)
+		if err := register(v, trans); err != nil {
+			panic(err)
+		}
+
+		for key, text := range messages[language] {
+			if err := trans.Add(key, text, true); err != nil {
+				panic(err)
+			}
+		}
+		for _, tag := range []string{"password", "personname", "iso4217"} {
+			if err := v.RegisterTranslation(tag, trans, noopRegistration, translateTag); err != nil {
+				panic(err)
+			}
+		}
+	}
+}
+
+// noopRegistration leaves the text of a rule to the messages map, which
+// registerTranslations has already added.
+func noopRegistration(ut.Translator) error {
+	return nil
+}
+
+func translateTag(trans ut.Translator, fieldErr validator.FieldError) string {
+	message, err := trans.T(fieldErr.Tag(), fieldErr.Field(), fieldErr.Param())
+	if err != nil {
+		return fieldErr.Error()
+	}
+	return message
+}
+
+// Translator picks the translator for an Accept-Language header.
+func Translator(acceptLanguage string) ut.Translator {
+	trans, _ := translators.FindTranslator(languages(acceptLanguage)...)
+	return trans
+}
+
+// Message translates a failed rule.
+func Message(trans ut.Translator, fieldErr validator.FieldError) string {
+	message := fieldErr.Translate(trans)
+	if message != fieldErr.Error() {
+		return message
+	}
+	// The rule has no translation.
+	message, err := trans.T("invalid", fieldErr.Field())
+	if err != nil {
+		return fieldErr.Error()
+	}
+	return message
+}
+
+// TypeMessage translates a value that does not have the type of its field,
+// like a string sent for a number.
+func TypeMessage(trans ut.Translator, field string, kind reflect.Kind) string {
+	name := typeName(kind)
+	if translated, err := trans.T("type_" + name); err == nil {
+		name = translated
+	}
+
+	message, err := trans.T("type", field, name)
+	if err != nil {
+		return field + " must be a " + name
+	}
+	return message
+}
+
+func typeName(kind reflect.Kind) string {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Slice, reflect.Array:
+		return "list"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	}
+	return kind.String()
+}
+
+// languages lists the base languages of an Accept-Language header, most
+// preferred first. A header like "id-ID,id;q=0.9,en;q=0.8" gives id, id,
+// en.
+func languages(acceptLanguage string) []string {
+	type weighted struct {
+		language string
+		quality  float64
+	}
+
+	var ranges []weighted
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		language := strings.ToLower(strings.TrimSpace(fields[0]))
+		if language == "" || language == "*" {
+			continue
+		}
+		language, _, _ = strings.Cut(language, "-")
+
+		quality := 1.0
+		for _, param := range fields[1:] {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if q, err := strconv.ParseFloat(value, 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		if quality > 0 {
+			ranges = append(ranges, weighted{language: language, quality: quality})
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].quality > ranges[j].quality
+	})
+
+	result := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		result = append(result, r.language)
+	}
+	return result
+}
@@ -0,0 +1,88 @@
+// Package validation configures the validator behind gin's binding: field
+// names as clients send them, the repo's own rules, and messages in the
+// languages the API speaks.
+package validation
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// MinPasswordLength is the shortest password the password rule accepts.
+const MinPasswordLength = 8
+
+var personNamePattern = regexp.MustCompile(`^\p{L}[\p{L}\p{M}]*(?:(?:\.? |['.-])\p{L}[\p{L}\p{M}]*)*\.?$`)
+
+func init() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+
+	// Field errors name fields the way clients send them.
+	v.RegisterTagNameFunc(fieldName)
+
+	if err := v.RegisterValidation("password", password); err != nil {
+		panic(err)
+	}
+	if err := v.RegisterValidation("personname", personName); err != nil {
+		panic(err)
+	}
+	registerTranslations(v)
+}
+
+// Struct checks v against its binding rules, like gin does for bound
+// requests.
+func Struct(v interface{}) error {
+	return binding.Validator.ValidateStruct(v)
+}
+
+// password accepts passwords of at least MinPasswordLength characters with
+// a lowercase letter, an uppercase letter and a digit.
+func password(fl validator.FieldLevel) bool {
+	value := fl.Field().String()
+	if len([]rune(value)) < MinPasswordLength {
+		return false
+	}
+
+	var lower, upper, digit bool
+	for _, r := range value {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		}
+	}
+	return lower && upper && digit
+}
+
+// personName accepts words of letters in any script, joined by a space,
+// a hyphen, an apostrophe or a period, like "Anne-Marie", "O'Neil" or
+// "J. R. R.".
+func personName(fl validator.FieldLevel) bool {
+	value := fl.Field().String()
+	return value == "" || personNamePattern.MatchString(value)
+}
+
+// fieldName names a struct field by its json tag, or its form tag for query
+// parameters.
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name := strings.Split(field.Tag.Get(tag), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}